@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/kairos/internal/archive"
@@ -23,7 +24,7 @@ var archiveAutoCmd = &cobra.Command{
 	Long:  `Automatically archive all complete months before the current month.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		historyPath := filepath.Join(filepath.Dir(cfg.DatabasePath), "history")
-		archiver := archive.New(db, historyPath, trackerService.WeeklyGoal())
+		archiver := newArchiver(historyPath)
 
 		archived, err := archiver.AutoArchivePastMonths()
 		if err != nil {
@@ -56,15 +57,16 @@ var archiveMonthCmd = &cobra.Command{
 		}
 
 		clean, _ := cmd.Flags().GetBool("clean")
+		force, _ := cmd.Flags().GetBool("force")
 		historyPath := filepath.Join(filepath.Dir(cfg.DatabasePath), "history")
-		archiver := archive.New(db, historyPath, trackerService.WeeklyGoal())
+		archiver := newArchiver(historyPath)
 
-		err = archiver.ArchiveMonth(t.Year(), t.Month(), clean)
+		err = archiver.ArchiveMonth(t.Year(), t.Month(), clean, force)
 		if err != nil {
 			return err
 		}
 
-		fmt.Printf("Archived %s to %s/%d-%02d.md\n", t.Format("January 2006"), historyPath, t.Year(), t.Month())
+		fmt.Printf("Archived %s to %s\n", t.Format("January 2006"), strings.Join(archiver.ArchivePaths(t.Year(), t.Month()), " and "))
 		if clean {
 			fmt.Println("Database cleaned for this month")
 		}
@@ -77,7 +79,7 @@ var archiveListCmd = &cobra.Command{
 	Short: "List archived months",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		historyPath := filepath.Join(filepath.Dir(cfg.DatabasePath), "history")
-		archiver := archive.New(db, historyPath, trackerService.WeeklyGoal())
+		archiver := newArchiver(historyPath)
 
 		archives, err := archiver.ListArchives()
 		if err != nil {
@@ -108,7 +110,7 @@ var archiveShowCmd = &cobra.Command{
 		}
 
 		historyPath := filepath.Join(filepath.Dir(cfg.DatabasePath), "history")
-		archiver := archive.New(db, historyPath, trackerService.WeeklyGoal())
+		archiver := newArchiver(historyPath)
 
 		content, err := archiver.ReadArchive(t.Year(), t.Month())
 		if err != nil {
@@ -120,6 +122,67 @@ var archiveShowCmd = &cobra.Command{
 	},
 }
 
+var archiveRestoreCmd = &cobra.Command{
+	Use:   "restore <YYYY-MM>",
+	Short: "Put an archived month's sessions back into the database",
+	Long: `Re-insert the sessions of an archived month, e.g. one archived with --clean,
+so they can be edited again. Sessions already in the database (same date and
+start time) are skipped; the archive files are kept.
+
+Examples:
+  kairos archive restore 2025-01`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t, err := time.Parse("2006-01", args[0])
+		if err != nil {
+			return fmt.Errorf("invalid format, use YYYY-MM (e.g., 2025-01)")
+		}
+
+		historyPath := filepath.Join(filepath.Dir(cfg.DatabasePath), "history")
+		archiver := newArchiver(historyPath)
+
+		restored, err := archiver.RestoreMonth(t.Year(), t.Month())
+		if err != nil {
+			return err
+		}
+		if restored == 0 {
+			fmt.Printf("Nothing to restore: every archived session of %s is already in the database\n", t.Format("January 2006"))
+			return nil
+		}
+		fmt.Printf("Restored %d session(s) from %s\n", restored, t.Format("January 2006"))
+		return nil
+	},
+}
+
+var archiveYearCmd = &cobra.Command{
+	Use:   "year <YYYY>",
+	Short: "Summarize a year's months into YYYY.md",
+	Long: `Write a year summary with each month's hours and a grand total to
+YYYY.md in the history folder. Months come from their monthly archives, or
+from the database for months not archived yet; months with neither are left
+out. The file is regenerated on every run.
+
+Examples:
+  kairos archive year 2025`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t, err := time.Parse("2006", args[0])
+		if err != nil {
+			return fmt.Errorf("invalid format, use YYYY (e.g., 2025)")
+		}
+
+		historyPath := filepath.Join(filepath.Dir(cfg.DatabasePath), "history")
+		archiver := newArchiver(historyPath)
+
+		path, err := archiver.ArchiveYear(t.Year())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Summarized %d to %s\n", t.Year(), path)
+		return nil
+	},
+}
+
 var historyCmd = &cobra.Command{
 	Use:   "history [months]",
 	Short: "Show historical summary",
@@ -135,7 +198,7 @@ var historyCmd = &cobra.Command{
 		}
 
 		historyPath := filepath.Join(filepath.Dir(cfg.DatabasePath), "history")
-		archiver := archive.New(db, historyPath, trackerService.WeeklyGoal())
+		archiver := newArchiver(historyPath)
 
 		context, err := archiver.GetHistoryContext(monthsBack)
 		if err != nil {
@@ -152,11 +215,71 @@ var historyCmd = &cobra.Command{
 	},
 }
 
+// newArchiver creates an archiver for historyPath using the configured goal,
+// week numbering and archive format
+func newArchiver(historyPath string) *archive.Archiver {
+	archiver := archive.New(db, historyPath, trackerService.WeeklyGoal())
+	archiver.SetWeekNumbering(cfg.WeekNumbering)
+	archiver.SetWeekStart(cfg.WeekStartsOn)
+	archiver.SetRoundingMinutes(cfg.RoundingMinutes)
+	archiver.SetFormat(cfg.ArchiveFormat)
+	return archiver
+}
+
+var historySearchCmd = &cobra.Command{
+	Use:   "search [term]",
+	Short: "Search archived months by note or monthly total",
+	Long: `Find archived months whose session notes contain a term (case-insensitive)
+and/or whose total hours fall within --over/--under. Prints each matching
+month with the matching session rows. A month's JSON copy is searched when
+there is one, so notes the markdown table shortens match in full.
+
+Examples:
+  kairos history search release
+  kairos history search --over 170
+  kairos history search oncall --under 120`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var q archive.SearchQuery
+		if len(args) > 0 {
+			q.Term = args[0]
+		}
+		q.MinHours, _ = cmd.Flags().GetFloat64("over")
+		q.MaxHours, _ = cmd.Flags().GetFloat64("under")
+
+		historyPath := filepath.Join(filepath.Dir(cfg.DatabasePath), "history")
+		matches, err := archive.Search(historyPath, q)
+		if err != nil {
+			return err
+		}
+
+		if len(matches) == 0 {
+			fmt.Println("No archived months matched.")
+			return nil
+		}
+
+		for _, m := range matches {
+			fmt.Printf("%s (%s): %sh\n", m.Month.Format("January 2006"), m.File, formatHours(m.TotalHours))
+			for _, line := range m.Lines {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+		return nil
+	},
+}
+
 func init() {
+	historyCmd.AddCommand(historySearchCmd)
+	historySearchCmd.Flags().Float64("over", 0, "Only months with at least this many total hours")
+	historySearchCmd.Flags().Float64("under", 0, "Only months with at most this many total hours")
+
 	archiveCmd.AddCommand(archiveAutoCmd)
 	archiveCmd.AddCommand(archiveMonthCmd)
 	archiveCmd.AddCommand(archiveListCmd)
 	archiveCmd.AddCommand(archiveShowCmd)
+	archiveCmd.AddCommand(archiveRestoreCmd)
+	archiveCmd.AddCommand(archiveYearCmd)
 
 	archiveMonthCmd.Flags().Bool("clean", false, "Remove archived data from database")
+	archiveMonthCmd.Flags().BoolP("force", "f", false, "Overwrite an existing archive file")
 }
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kairos/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var backupCmd = &cobra.Command{
+	Use:         "backup [path]",
+	Short:       "Copy the database to a backup file",
+	Annotations: map[string]string{lightAnnotation: "true"},
+	Long: `Write a copy of the database, safe to take while kairos is in use.
+
+Without a path the copy goes to a timestamped file in the backups folder next
+to the database. A directory (existing, or ending in /) gets a timestamped
+file inside it; any other path is used as the file name.
+
+Examples:
+  kairos backup
+  kairos backup ~/Dropbox/kairos/
+  kairos backup before-cleanup.db`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dst := filepath.Join(backupDir(), storage.BackupName(cfg.Now()))
+		if len(args) == 1 {
+			dst = args[0]
+			if info, err := os.Stat(dst); (err == nil && info.IsDir()) || strings.HasSuffix(dst, string(os.PathSeparator)) {
+				dst = filepath.Join(dst, storage.BackupName(cfg.Now()))
+			}
+		}
+
+		warnActiveSession("the backup records it as still running")
+		if err := db.Backup(dst); err != nil {
+			return err
+		}
+		fmt.Printf("Backed up to %s\n", dst)
+		return nil
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:         "restore <path>",
+	Short:       "Replace the database with a backup",
+	Annotations: map[string]string{lightAnnotation: "true"},
+	Long: `Replace the database with a file written by 'kairos backup'. The current
+database is backed up first, so a restore can itself be reverted.
+
+Examples:
+  kairos restore ~/.kairos/backups/kairos-20250115-090000.db --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		src := args[0]
+		if err := storage.VerifyBackup(src); err != nil {
+			return err
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+		if !force {
+			fmt.Printf("Replace the database at %s with %s? Use --force to confirm.\n", cfg.DatabasePath, src)
+			return nil
+		}
+
+		warnActiveSession("restoring replaces it with the backup's sessions")
+		current := filepath.Join(backupDir(), strings.TrimSuffix(storage.BackupName(cfg.Now()), ".db")+"-pre-restore.db")
+		if err := db.Backup(current); err != nil {
+			return err
+		}
+
+		if err := db.Close(); err != nil {
+			return err
+		}
+		db = nil
+		if err := storage.Restore(src, cfg.DatabasePath); err != nil {
+			return fmt.Errorf("restore failed (previous database saved to %s): %w", current, err)
+		}
+		fmt.Printf("Restored %s (previous database saved to %s)\n", src, current)
+		return nil
+	},
+}
+
+// backupDir is where backups go by default: next to the database
+func backupDir() string {
+	return filepath.Join(filepath.Dir(cfg.DatabasePath), "backups")
+}
+
+// warnActiveSession prints a warning, with why it matters, when a session is open
+func warnActiveSession(consequence string) {
+	session, err := trackerService.GetActiveSession()
+	if err != nil || session == nil {
+		return
+	}
+	fmt.Printf("Warning: session %s is still active (started %s); %s\n",
+		session.ID[:8], session.StartTime.Format("2006-01-02 15:04"), consequence)
+}
+
+func init() {
+	restoreCmd.Flags().BoolP("force", "f", false, "Replace the database without asking")
+}
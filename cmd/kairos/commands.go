@@ -2,17 +2,26 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"html"
 	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/go-pdf/fpdf"
 	"github.com/kairos/internal/ai"
+	"github.com/kairos/internal/archive"
 	"github.com/kairos/internal/config"
+	"github.com/kairos/internal/mcp"
 	"github.com/kairos/internal/storage"
 	"github.com/kairos/internal/tracker"
 	"github.com/kairos/internal/work"
@@ -25,6 +34,14 @@ var clockinCmd = &cobra.Command{
 	Short:   "Start a work session",
 	Long:    `Clock in to start tracking your work hours. Optionally add a note or override time with -t.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		applyTruncateNote(cmd)
+		// Join all args as note (don't skip colons - notes can contain them).
+		// Check its length before touching an open session.
+		note, err := trackerService.FitNote(strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+
 		active, err := trackerService.GetActiveSession()
 		if err != nil {
 			return err
@@ -51,7 +68,7 @@ var clockinCmd = &cobra.Command{
 					continue
 				}
 
-				breakMinutes := work.GetBreakMinutesForDay(active.StartTime)
+				breakMinutes := trackerService.DefaultBreak(active.StartTime, trackerService.ResolveEndTime(active, timeStr))
 				updated, err := trackerService.ClockOutWithTime(active.ID, breakMinutes, "", timeStr)
 				if err != nil {
 					return err
@@ -62,16 +79,19 @@ var clockinCmd = &cobra.Command{
 			}
 		}
 
-		// Join all args as note (don't skip colons - notes can contain them)
-		note := strings.Join(args, " ")
-
 		timeStr, _ := cmd.Flags().GetString("time")
-		session, err := trackerService.ClockInWithTime(note, timeStr)
+		project, _ := cmd.Flags().GetString("project")
+
+		tags, _ := cmd.Flags().GetStringSlice("tags")
+		session, err := trackerService.ClockInWithProject(note, timeStr, project, tags...)
 		if err != nil {
 			return err
 		}
 
 		fmt.Printf("Clocked in at %s\n", session.StartTime.Format("15:04"))
+		if project != "" {
+			fmt.Printf("Project: %s\n", project)
+		}
 		if note != "" {
 			fmt.Printf("Note: %s\n", note)
 		}
@@ -84,8 +104,12 @@ var clockoutCmd = &cobra.Command{
 	Aliases: []string{"out", "co"},
 	Short:   "End current work session",
 	Long: `Clock out to end your current work session.
-Break time defaults based on day (30 min Mon-Thu, 0 on Friday).
-Override with argument or use -b flag.`,
+Break time defaults based on day (30 min Mon-Thu, 0 on Friday; Friday shifts
+of at least FridayShortShiftHours get the full break when that is set); a
+session no longer than the break gets none. Override with argument or use -b
+flag.
+Use --round-up to record the end at the next quarter hour (14:07 -> 14:15).
+Use --discard to drop an accidental session instead of recording it.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		session, err := trackerService.GetActiveSession()
@@ -96,8 +120,27 @@ Override with argument or use -b flag.`,
 			return fmt.Errorf("no active session found")
 		}
 
-		// Default break based on the session's start day
-		breakMinutes := work.GetBreakMinutesForDay(session.StartTime)
+		if discard, _ := cmd.Flags().GetBool("discard"); discard {
+			force, _ := cmd.Flags().GetBool("force")
+			if !force {
+				fmt.Printf("Discard active session %s started at %s on %s? Restore it with 'kairos undo' if needed. Use --force to confirm.\n",
+					session.ID[:8], session.StartTime.Format("15:04"), session.StartTime.Format("2006-01-02"))
+				return nil
+			}
+			if err := trackerService.DeleteSession(session.ID); err != nil {
+				return err
+			}
+			fmt.Printf("Discarded session %s (started %s)\n", session.ID[:8], session.StartTime.Format("15:04"))
+			return nil
+		}
+
+		// Default break based on the session's start day and length
+		timeStr, _ := cmd.Flags().GetString("time")
+		endTime := trackerService.ResolveEndTime(session, timeStr)
+		if roundUp, _ := cmd.Flags().GetBool("round-up"); roundUp {
+			endTime = work.RoundUpToQuarterHour(endTime)
+		}
+		breakMinutes := trackerService.DefaultBreak(session.StartTime, endTime)
 
 		// Override from flag first
 		if cmd.Flags().Changed("break") {
@@ -111,16 +154,13 @@ Override with argument or use -b flag.`,
 			breakMinutes = parsed
 		}
 
-		// Handle time override
-		timeStr, _ := cmd.Flags().GetString("time")
-		updated, err := trackerService.ClockOutWithTime(session.ID, breakMinutes, "", timeStr)
+		updated, err := trackerService.ClockOutAt(session.ID, breakMinutes, "", endTime)
 		if err != nil {
 			return err
 		}
 
-		duration := updated.EndTime.Sub(updated.StartTime)
-		hours := duration.Hours() - float64(breakMinutes)/60.0
-		fmt.Printf("Clocked out: %s | Duration: %.2fh | Break: %dmin\n", updated.EndTime.Format("15:04"), hours, breakMinutes)
+		hours, _ := sessionHours(*updated)
+		fmt.Printf("Clocked out: %s | Duration: %sh | Break: %dmin\n", updated.EndTime.Format("15:04"), formatHours(hours), breakMinutes)
 		return nil
 	},
 }
@@ -131,6 +171,7 @@ var statusCmd = &cobra.Command{
 	Short:   "Show today's progress",
 	Long:    `Display your work hours progress for today.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		applyIncludeActive(cmd)
 		progress, err := trackerService.GetTodayProgress()
 		if err != nil {
 			return err
@@ -142,14 +183,34 @@ var statusCmd = &cobra.Command{
 		}
 
 		if active != nil {
-			elapsed := time.Since(active.StartTime)
+			age := trackerService.OpenSessionAge(active)
+			elapsed := age.Elapsed
 			h := int(elapsed.Hours())
 			m := int(elapsed.Minutes()) % 60
-			fmt.Printf("Today: %s | Hours worked: %.2f | Status: Currently working | Clocked in: %s (%dh %dm elapsed)\n",
-				progress.Date.Format("Monday, Jan 2"), progress.TotalHours, active.StartTime.Format("15:04"), h, m)
+			elapsedNote := fmt.Sprintf("%dh %dm elapsed", h, m)
+			if age.Stale {
+				elapsedNote = fmt.Sprintf("%dh+ elapsed", h)
+			}
+			fmt.Printf("Today: %s | Hours worked: %s%s%s | Status: Currently working | Clocked in: %s (%s)\n",
+				progress.Date.Format("Monday, Jan 2"), formatBannerHours(progress.TotalHours), inProgressNote(progress.ActiveHours), todayTargetNote(progress),
+				active.StartTime.Format("15:04"), elapsedNote)
+
+			if age.Stale {
+				fmt.Printf("Warning: %s | Fix: %s\n", age.Warning, age.Fix)
+				return nil
+			}
+
+			// Preview the default break that clockout would deduct
+			breakMinutes := trackerService.DefaultBreak(active.StartTime, time.Now())
+			net := elapsed.Hours() - float64(breakMinutes)/60.0
+			if net < 0 {
+				net = 0
+			}
+			fmt.Printf("If you clock out now: %s net after %dmin default break | Day total: %s\n",
+				formatBannerHoursUnit(net), breakMinutes, formatBannerHoursUnit(progress.TotalHours-progress.ActiveHours+net))
 		} else {
-			fmt.Printf("Today: %s | Hours worked: %.2f | Status: Not clocked in\n",
-				progress.Date.Format("Monday, Jan 2"), progress.TotalHours)
+			fmt.Printf("Today: %s | Hours worked: %s%s | Status: Not clocked in\n",
+				progress.Date.Format("Monday, Jan 2"), formatBannerHours(progress.TotalHours), todayTargetNote(progress))
 		}
 
 		return nil
@@ -160,34 +221,45 @@ var weekCmd = &cobra.Command{
 	Use:     "week [last|date]",
 	Aliases: []string{"w"},
 	Short:   "Show weekly summary",
-	Long:    `Display your work hours summary for the current week. Use "last" for previous week or a date (YYYY-MM-DD) for that week's summary.`,
-	Args:    cobra.MaximumNArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		var progress *tracker.WeekProgress
-		var err error
+	Long: `Display your work hours summary for the current week. Use "last" for previous week or a date (YYYY-MM-DD) for that week's summary.
 
-		if len(args) == 0 {
-			progress, err = trackerService.GetWeeklyProgress()
-		} else if args[0] == "last" {
-			progress, err = trackerService.GetLastWeekProgress()
-		} else {
-			loc := cfg.GetLocation()
-			// Try parsing as date
-			t, parseErr := time.ParseInLocation("2006-01-02", args[0], loc)
-			if parseErr != nil {
-				// Try YYYY-MM-DD format variations
-				for _, fmt := range []string{"2006-01-02", "Jan 2", "Jan 02", "1/2"} {
-					if t, parseErr = time.ParseInLocation(fmt, args[0], loc); parseErr == nil {
-						break
-					}
-				}
-				if parseErr != nil {
-					return fmt.Errorf("invalid date format: %s (use YYYY-MM-DD)", args[0])
+A week with different contracted hours can have its own goal in place of the
+weekly goal:
+  kairos week --set-goal 30        # this week is 30h
+  kairos week last --clear-goal    # last week goes back to the weekly goal`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		day := trackerService.Now()
+		if len(args) > 0 {
+			if args[0] == "last" {
+				day = day.AddDate(0, 0, -7)
+			} else {
+				t, err := tracker.ParseDateInput(args[0], cfg.GetLocation())
+				if err != nil {
+					return err
 				}
+				day = t
 			}
-			progress, err = trackerService.GetWeekProgressForDate(t)
 		}
 
+		if cmd.Flags().Changed("set-goal") {
+			goal, _ := cmd.Flags().GetFloat64("set-goal")
+			if err := trackerService.SetWeekGoal(day, goal); err != nil {
+				return err
+			}
+		}
+		if clearGoal, _ := cmd.Flags().GetBool("clear-goal"); clearGoal {
+			cleared, err := trackerService.ClearWeekGoal(day)
+			if err != nil {
+				return err
+			}
+			if !cleared {
+				fmt.Println("No goal was set for this week")
+			}
+		}
+
+		applyIncludeActive(cmd)
+		progress, err := trackerService.GetWeekProgressForDate(day)
 		if err != nil {
 			return err
 		}
@@ -195,29 +267,39 @@ var weekCmd = &cobra.Command{
 		// Summary row
 		var summary string
 		if progress.RemainingHours > 0 {
-			summary = fmt.Sprintf("Remaining: %.2fh", progress.RemainingHours)
+			summary = fmt.Sprintf("Remaining: %sh", formatHours(progress.RemainingHours))
 		} else {
-			summary = fmt.Sprintf("Overtime: +%.2fh", -progress.RemainingHours)
+			summary = fmt.Sprintf("Overtime: +%sh", formatHours(-progress.RemainingHours))
 		}
-		fmt.Printf("Week: %s - %s | Total: %.2f/%gh | %s\n",
+		fmt.Printf("Week: %s - %s | Total: %s/%gh%s | %s\n",
 			progress.WeekStart.Format("Jan 2"), progress.WeekEnd.Format("Jan 2"),
-			progress.TotalHours, trackerService.WeeklyGoal(), summary)
+			formatHours(progress.TotalHours), progress.Goal, inProgressNote(progress.ActiveHours), summary)
+		if progress.GoalOverride {
+			fmt.Printf("  Goal: %gh set for this week (weekly goal %gh)\n", progress.Goal, trackerService.WeeklyGoal())
+		}
 
 		// One row per day
-		dayNames := []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+		fillMissing, _ := cmd.Flags().GetBool("fill-missing")
+		today := cfg.Now().Format("2006-01-02")
 		for i := 0; i < 7; i++ {
 			dayDate := progress.WeekStart.AddDate(0, 0, i)
 			dayKey := dayDate.Format("2006-01-02")
 			hours := progress.DaysWorked[dayKey]
-			dayName := dayNames[i]
-			// Highlight today
-			if i == 6 { // Sunday
-				dayName = "Sun"
+			dayName := dayDate.Format("Mon")
+			target := progress.DayTargets[dayKey]
+			flag := ""
+			if fillMissing && target > 0 {
+				flag = missingDayFlag(dayDate, dayKey, today, hours)
+			}
+			note := dayTargetNote(hours, target, dayKey > today)
+			if progress.Holidays[dayKey] {
+				note = " (holiday)"
 			}
-			if dayDate.Format("2006-01-02") == cfg.Now().Format("2006-01-02") {
-				fmt.Printf("  %s %s: %.2fh *\n", dayDate.Format("01/02"), dayName, hours)
+			// Highlight today
+			if dayKey == today {
+				fmt.Printf("  %s %s: %sh%s *%s\n", dayDate.Format("01/02"), dayName, formatHours(hours), note, flag)
 			} else {
-				fmt.Printf("  %s %s: %.2fh\n", dayDate.Format("01/02"), dayName, hours)
+				fmt.Printf("  %s %s: %sh%s%s\n", dayDate.Format("01/02"), dayName, formatHours(hours), note, flag)
 			}
 		}
 
@@ -229,15 +311,39 @@ var monthCmd = &cobra.Command{
 	Use:     "month",
 	Aliases: []string{"m"},
 	Short:   "Show monthly summary",
-	Long:    `Display your work hours summary for the current month.`,
+	Long: `Display your work hours summary for the current month, with hours per week.
+Weeks are ISO weeks (W52, W1, ...) or, with --week-numbering month (config
+week_numbering), weeks of the month (Wk 1-6).`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("week-numbering") {
+			numbering, _ := cmd.Flags().GetString("week-numbering")
+			if numbering != work.WeekNumberingISO && numbering != work.WeekNumberingMonth {
+				return fmt.Errorf("invalid week numbering %q (use: iso or month)", numbering)
+			}
+			trackerService.SetWeekNumbering(numbering)
+		}
+
 		progress, err := trackerService.GetMonthlyProgress()
 		if err != nil {
 			return err
 		}
 
-		fmt.Printf("Month: %s | Total hours: %.2f | Weeks tracked: %d | Daily avg: %.2f hrs\n",
-			progress.Month.Format("January 2006"), progress.TotalHours, progress.WeekCount, progress.DailyAverage)
+		var summary string
+		if progress.RemainingHours > 0 {
+			summary = fmt.Sprintf("Remaining: %sh", formatHours(progress.RemainingHours))
+		} else {
+			summary = fmt.Sprintf("Overtime: +%sh", formatHours(-progress.RemainingHours))
+		}
+		fmt.Printf("Month: %s | Total: %s/%sh | %s | Weeks tracked: %d | Daily avg: %s hrs\n",
+			progress.Month.Format("January 2006"), formatHours(progress.TotalHours), formatHours(progress.GoalHours), summary,
+			progress.WeekCount, formatHours(progress.DailyAverage))
+		if len(progress.Weeks) > 0 {
+			parts := make([]string, 0, len(progress.Weeks))
+			for _, week := range progress.Weeks {
+				parts = append(parts, fmt.Sprintf("%s %sh", work.WeekLabel(week, progress.WeekNumbering), formatHours(progress.WeekHours[week])))
+			}
+			fmt.Printf("Weeks: %s\n", strings.Join(parts, " | "))
+		}
 
 		return nil
 	},
@@ -250,6 +356,7 @@ var editCmd = &cobra.Command{
 	Long:    `Edit the current session, or a specific session by ID. Use without ID to edit today's session.`,
 	Args:    cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		applyTruncateNote(cmd)
 		breakMinutes, _ := cmd.Flags().GetInt("break")
 		note, _ := cmd.Flags().GetString("note")
 		timeStr, _ := cmd.Flags().GetString("time")
@@ -270,11 +377,24 @@ var editCmd = &cobra.Command{
 		}
 
 		// Only update fields that were explicitly set
-		breakChanged := cmd.Flags().Changed("break")
-		noteChanged := cmd.Flags().Changed("note")
+		edit := tracker.SessionEdit{StartTime: timeStr, EndTime: endTimeStr}
+		if cmd.Flags().Changed("break") {
+			edit.BreakMinutes = &breakMinutes
+		}
+		if cmd.Flags().Changed("note") {
+			edit.Note = &note
+		}
+		edit.AppendNote, _ = cmd.Flags().GetString("append-note")
+		if cmd.Flags().Changed("project") {
+			project, _ := cmd.Flags().GetString("project")
+			edit.Project = &project
+		}
+		if cmd.Flags().Changed("tags") {
+			tags, _ := cmd.Flags().GetStringSlice("tags")
+			edit.Tags = &tags
+		}
 
-		err := trackerService.EditSessionSelective(id, breakMinutes, breakChanged, note, noteChanged, timeStr, endTimeStr)
-		if err != nil {
+		if err := trackerService.ApplySessionEdit(id, edit); err != nil {
 			return err
 		}
 
@@ -294,7 +414,7 @@ var deleteCmd = &cobra.Command{
 
 		force, _ := cmd.Flags().GetBool("force")
 		if !force {
-			fmt.Printf("Delete session %s? This cannot be undone. Use --force to confirm.\n", id)
+			fmt.Printf("Delete session %s? Restore it with 'kairos undo' if needed. Use --force to confirm.\n", id)
 			return nil
 		}
 
@@ -309,55 +429,319 @@ var deleteCmd = &cobra.Command{
 }
 
 var sessionsCmd = &cobra.Command{
-	Use:     "sessions",
+	Use:     "sessions [date]",
 	Aliases: []string{"ls", "list"},
 	Short:   "List recent sessions",
-	Long:    `Show your recent work sessions with IDs for editing.`,
+	Long: `Show your recent work sessions with IDs for editing.
+Scope with --today, --week (default), --month, --last N (days, today included)
+or --start/--end (YYYY-MM-DD). IDs are shortened to 8 characters unless
+--full-id is given.
+Use --gaps to show one day's sessions with the idle time between them (default: today).`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		progress, err := trackerService.GetWeeklyProgress()
+		gaps, _ := cmd.Flags().GetBool("gaps")
+		if gaps {
+			day := cfg.Now()
+			if len(args) > 0 {
+				t, err := tracker.ParseDateInput(args[0], cfg.GetLocation())
+				if err != nil {
+					return err
+				}
+				day = t
+			}
+			return printSessionGaps(day)
+		}
+		if len(args) > 0 {
+			return fmt.Errorf("a date argument requires --gaps")
+		}
+
+		scope, sessions, err := scopedSessions(cmd)
 		if err != nil {
 			return err
 		}
 
-		if len(progress.Sessions) == 0 {
-			fmt.Println("No sessions this week")
+		if len(sessions) == 0 {
+			fmt.Printf("No sessions %s\n", scope)
 			return nil
 		}
 
+		fullID, _ := cmd.Flags().GetBool("full-id")
 		var lines []string
-		for _, s := range progress.Sessions {
+		for _, s := range sessions {
 			duration := "active"
-			if s.EndTime != nil {
-				d := s.EndTime.Sub(s.StartTime).Hours() - float64(s.BreakMinutes)/60.0
-				duration = fmt.Sprintf("%.1fh", d)
+			if d, complete := sessionHours(s); complete {
+				duration = formatHours(d) + "h"
 			}
 			note := ""
+			if s.Project != "" {
+				note = " [" + s.Project + "]"
+			}
 			if s.Note != "" {
-				note = " - " + s.Note
+				note += " - " + s.Note
 			}
 			status := ""
 			if s.EndTime == nil {
 				status = " [ACTIVE]"
 			}
-			lines = append(lines, fmt.Sprintf("%s %s %s (%s)%s%s", s.ID[:8], s.Date.Format("Jan 02"), s.StartTime.Format("15:04"), duration, note, status))
+			id := s.ID[:8]
+			if fullID {
+				id = s.ID
+			}
+			lines = append(lines, fmt.Sprintf("%s %s %s (%s)%s%s", id, s.Date.Format("Jan 02"), s.StartTime.Format("15:04"), duration, note, status))
 		}
 		fmt.Printf("Sessions: %s\n", strings.Join(lines, " | "))
 		return nil
 	},
 }
 
+// scopedSessions returns the sessions selected by the sessions scope flags and
+// a label for them; this week when no scope is given
+func scopedSessions(cmd *cobra.Command) (string, []storage.WorkSession, error) {
+	today, _ := cmd.Flags().GetBool("today")
+	month, _ := cmd.Flags().GetBool("month")
+	last, _ := cmd.Flags().GetInt("last")
+
+	switch {
+	case cmd.Flags().Changed("last"):
+		if today || month || cmd.Flags().Changed("start") || cmd.Flags().Changed("end") {
+			return "", nil, fmt.Errorf("--last can't be combined with --today, --month, --start or --end")
+		}
+		if last < 1 {
+			return "", nil, fmt.Errorf("--last must be at least 1 day")
+		}
+		end := cfg.Now()
+		sessions, err := trackerService.GetSessionsInRange(end.AddDate(0, 0, 1-last), end)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("in the last %d days", last), sessions, nil
+	case today:
+		progress, err := trackerService.GetTodayProgress()
+		if err != nil {
+			return "", nil, err
+		}
+		return "today", progress.Sessions, nil
+	case month:
+		progress, err := trackerService.GetMonthlyProgress()
+		if err != nil {
+			return "", nil, err
+		}
+		return "this month", progress.Sessions, nil
+	case cmd.Flags().Changed("start") || cmd.Flags().Changed("end"):
+		start, end, err := statsRange(cmd)
+		if err != nil {
+			return "", nil, err
+		}
+		sessions, err := trackerService.GetSessionsInRange(start, end)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("between %s and %s", start.Format("Jan 2"), end.Format("Jan 2")), sessions, nil
+	}
+
+	progress, err := trackerService.GetWeeklyProgress()
+	if err != nil {
+		return "", nil, err
+	}
+	return "this week", progress.Sessions, nil
+}
+
+// printSessionGaps prints a day's sessions interleaved with the gaps between them
+func printSessionGaps(day time.Time) error {
+	sessions, err := trackerService.GetDaySessions(day)
+	if err != nil {
+		return err
+	}
+
+	if len(sessions) == 0 {
+		fmt.Printf("No sessions on %s\n", day.Format("Mon Jan 2"))
+		return nil
+	}
+
+	gaps := tracker.FindGaps(sessions)
+	var idle time.Duration
+	for _, g := range gaps {
+		idle += g.Duration()
+	}
+	fmt.Printf("%s: %d sessions | Gaps: %d (%s idle)\n", day.Format("Mon Jan 2"), len(sessions), len(gaps), formatDuration(idle))
+
+	gapIndex := 0
+	for _, s := range sessions {
+		for gapIndex < len(gaps) && !gaps[gapIndex].End.After(s.StartTime) {
+			g := gaps[gapIndex]
+			fmt.Printf("  %s-%s  gap %s\n", g.Start.Format("15:04"), g.End.Format("15:04"), formatDuration(g.Duration()))
+			gapIndex++
+		}
+
+		end := "now"
+		duration := "active"
+		if hours, complete := sessionHours(s); complete {
+			end = s.EndTime.Format("15:04")
+			duration = fmt.Sprintf("%sh, %dm break", formatHours(hours), s.BreakMinutes)
+		}
+		note := ""
+		if s.Note != "" {
+			note = " - " + s.Note
+		}
+		fmt.Printf("  %s-%s  %s (%s)%s\n", s.StartTime.Format("15:04"), end, s.ID[:8], duration, note)
+	}
+	return nil
+}
+
+// applyIncludeActive lets --include-active override the IncludeActive config
+func applyIncludeActive(cmd *cobra.Command) {
+	if cmd.Flags().Changed("include-active") {
+		include, _ := cmd.Flags().GetBool("include-active")
+		trackerService.SetIncludeActive(include)
+	}
+}
+
+// applyTruncateNote lets --truncate-note shorten notes over max_note_length
+// instead of rejecting them
+func applyTruncateNote(cmd *cobra.Command) {
+	truncate, _ := cmd.Flags().GetBool("truncate-note")
+	trackerService.SetTruncateNotes(truncate)
+}
+
+// inProgressNote marks totals that include a running session
+func inProgressNote(activeHours float64) string {
+	if activeHours <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (includes %sh in progress)", formatHours(activeHours))
+}
+
+// todayTargetNote compares today's hours with its target: " | Target: 7.70h
+// (met)" or " | Target: 7.70h (1.20h to go)"; empty on days off
+func todayTargetNote(progress *tracker.DayProgress) string {
+	if progress.Goal <= 0 {
+		return ""
+	}
+	if left := progress.Goal - progress.TotalHours; left > 0 {
+		return fmt.Sprintf(" | Target: %s (%s to go)", formatBannerHoursUnit(progress.Goal), formatBannerHoursUnit(left))
+	}
+	return fmt.Sprintf(" | Target: %s (met)", formatBannerHoursUnit(progress.Goal))
+}
+
+// formatHours renders hours with the configured number of decimal places
+func formatHours(hours float64) string {
+	return work.FormatHours(hours, cfg.DecimalPlaces)
+}
+
+// sessionHours is storage.SessionNetHours with completed sessions rounded
+// to the configured rounding_minutes, so listings and exports add up to the
+// tracker's totals
+func sessionHours(s storage.WorkSession) (float64, bool) {
+	hours, complete := storage.SessionNetHours(s, cfg.Now())
+	if complete {
+		hours = work.RoundSessionHours(hours, cfg.RoundingMinutes)
+	}
+	return hours, complete
+}
+
+// missingDayFlag marks zero-hour days for week --fill-missing: past work days
+// are MISSED, non-work days are off. Today and future days are not flagged.
+func missingDayFlag(day time.Time, dayKey, today string, hours float64) string {
+	if hours > 0 {
+		return ""
+	}
+	if !work.IsWorkDay(trackerService.Rules(), day) {
+		return " off"
+	}
+	if dayKey < today {
+		return " MISSED"
+	}
+	return ""
+}
+
+// dayTargetNote shows a day's hours against its share of the weekly goal,
+// green when at or over and red when under. Non-work days are marked off
+// and future days, which can't be behind yet, get no note.
+func dayTargetNote(hours, target float64, future bool) string {
+	switch {
+	case target <= 0:
+		return " (off)"
+	case future:
+		return ""
+	case hours >= target:
+		return " " + colorize("(+"+formatHours(hours-target)+")", ansiGreen)
+	default:
+		return " " + colorize("("+formatHours(hours-target)+")", ansiRed)
+	}
+}
+
+// ANSI colors for terminal output
+const (
+	ansiDim   = "2"
+	ansiRed   = "31"
+	ansiGreen = "32"
+)
+
+// colorize wraps s in an ANSI color when stdout is a terminal and NO_COLOR
+// is unset, so piped output stays plain
+func colorize(s, color string) string {
+	if os.Getenv("NO_COLOR") != "" {
+		return s
+	}
+	if info, err := os.Stdout.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return s
+	}
+	return "\033[" + color + "m" + s + "\033[0m"
+}
+
+// formatBannerHours renders at-a-glance hours in the configured duration
+// format; reports and exports keep using formatHours
+func formatBannerHours(hours float64) string {
+	if cfg.DurationFormat == work.DurationHM {
+		return work.FormatHoursHM(hours)
+	}
+	return formatHours(hours)
+}
+
+// formatBannerHoursUnit is formatBannerHours with an "h" suffix in decimal mode
+func formatBannerHoursUnit(hours float64) string {
+	if cfg.DurationFormat == work.DurationHM {
+		return work.FormatHoursHM(hours)
+	}
+	return formatHours(hours) + "h"
+}
+
+// formatDuration renders a duration as "1h05m" or "45m"
+func formatDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	if minutes >= 60 {
+		return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
+
 var askCmd = &cobra.Command{
 	Use:     "ask \"your question\"",
 	Aliases: []string{"a", "ai"},
 	Short:   "Ask AI about your work hours",
-	Long:    `Ask an AI-powered question about your work hours. Configure provider with: kairos config --provider ollama|openai|claude|gemini`,
-	Args:    cobra.MinimumNArgs(1),
+	Long: `Ask an AI-powered question about your work hours. Configure provider with: kairos config --provider ollama|openai|claude|gemini
+With --tools, questions matching an MCP tool (e.g. "evolve my habits") run that tool locally instead.`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if !aiService.IsAvailable() {
-			return fmt.Errorf("%s is not available. Configure with: kairos config", aiService.Name())
+		question := strings.Join(args, " ")
+
+		useTools, _ := cmd.Flags().GetBool("tools")
+		if useTools {
+			if tool, toolArgs, ok := mcp.MatchTool(question); ok {
+				server := mcp.NewServer(db, trackerService, aiService, 0)
+				result, err := server.CallTool(context.Background(), tool, toolArgs)
+				if err != nil {
+					return err
+				}
+				data, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Printf("[%s]\n%s\n", tool, data)
+				return nil
+			}
 		}
 
-		question := strings.Join(args, " ")
+		if err := aiService.CheckAvailable(); err != nil {
+			return err
+		}
 
 		// Build work context
 		ctx, err := ai.BuildWorkContext(trackerService)
@@ -365,23 +749,59 @@ var askCmd = &cobra.Command{
 			return err
 		}
 
-		answer, err := aiService.Ask(question, ctx)
-		if err != nil {
-			return err
+		// Questions about a named month can reach into archived history
+		if month, ok := ai.MonthInQuestion(question, trackerService.Now()); ok {
+			hours, err := dataQuerier.GetHoursInRange(month, month.AddDate(0, 1, -1))
+			if err != nil {
+				return err
+			}
+			ctx.PeriodLabel = month.Format("January 2006")
+			ctx.PeriodHours = hours
 		}
 
-		fmt.Println(answer)
-		return nil
+		// Print the answer as it arrives; Ctrl+C stops the stream
+		stream, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		err = aiService.AskStream(stream, question, ctx, os.Stdout)
+		fmt.Println()
+		if stream.Err() != nil {
+			return fmt.Errorf("interrupted")
+		}
+		if err == nil {
+			printUsage(cmd)
+		}
+		return err
 	},
 }
 
+// printUsage prints the tokens the last AI answer cost when --verbose is set.
+// Offline answers and providers that report nothing print no line.
+func printUsage(cmd *cobra.Command) {
+	if verbose, _ := cmd.Flags().GetBool("verbose"); !verbose {
+		return
+	}
+	usage := aiService.LastUsage()
+	if usage.Total() == 0 {
+		return
+	}
+	fmt.Println(colorize(fmt.Sprintf("[%d prompt + %d completion tokens]", usage.PromptTokens, usage.CompletionTokens), ansiDim))
+}
+
 var predictCmd = &cobra.Command{
 	Use:   "predict",
 	Short: "AI prediction for goal completion",
-	Long:  `Get AI-powered predictions about when you'll reach your weekly goal.`,
+	Long: `Get AI-powered predictions about when you'll reach your weekly goal.
+
+With --hours and/or --days, run an offline what-if plan instead: the projected
+week total if you work that many hours a day for that many days.
+--hours defaults to the daily target, --days to the work days left this week.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if !aiService.IsAvailable() {
-			return fmt.Errorf("%s is not available. Configure with: kairos config", aiService.Name())
+		if cmd.Flags().Changed("hours") || cmd.Flags().Changed("days") {
+			return printWeekPlan(cmd)
+		}
+
+		if err := aiService.CheckAvailable(); err != nil {
+			return err
 		}
 
 		weekProgress, err := trackerService.GetWeeklyProgress()
@@ -395,38 +815,214 @@ var predictCmd = &cobra.Command{
 		}
 
 		fmt.Println(prediction)
+		printUsage(cmd)
 		return nil
 	},
 }
 
+// printWeekPlan prints predict's offline what-if projection
+func printWeekPlan(cmd *cobra.Command) error {
+	weekProgress, err := trackerService.GetWeeklyProgress()
+	if err != nil {
+		return err
+	}
+
+	goal := weekProgress.Goal
+	dailyHours := weekProgress.WorkDayTarget()
+	if cmd.Flags().Changed("hours") {
+		dailyHours, _ = cmd.Flags().GetFloat64("hours")
+	}
+	days := weekProgress.RemainingWorkDays
+	if cmd.Flags().Changed("days") {
+		days, _ = cmd.Flags().GetInt("days")
+	}
+	if dailyHours < 0 || days < 0 {
+		return fmt.Errorf("--hours and --days must not be negative")
+	}
+
+	plan := tracker.PlanWeek(weekProgress.TotalHours, goal, dailyHours, days)
+	result := fmt.Sprintf("Surplus: +%sh", formatHours(plan.Surplus))
+	if !plan.MeetsGoal() {
+		result = fmt.Sprintf("Deficit: %sh", formatHours(-plan.Surplus))
+	}
+	fmt.Printf("Plan: %sh now + %sh/day x %d days | Projected: %s/%gh | %s\n",
+		formatHours(plan.CurrentHours), formatHours(plan.DailyHours), plan.Days,
+		formatHours(plan.ProjectedHours), plan.Goal, result)
+	return nil
+}
+
 var analyzeCmd = &cobra.Command{
 	Use:   "analyze",
 	Short: "AI analysis of work patterns",
-	Long:  `Get AI-powered analysis of your work patterns and suggestions.`,
+	Long: `Get AI-powered analysis of your work patterns and suggestions.
+
+With --compare-history and no AI provider available, the offline analysis
+and the comparison against archived months are shown instead.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if !aiService.IsAvailable() {
-			return fmt.Errorf("%s is not available. Configure with: kairos config", aiService.Name())
+		compare, _ := cmd.Flags().GetBool("compare-history")
+		if err := aiService.CheckAvailable(); err != nil {
+			if !compare {
+				return err
+			}
+			fmt.Printf("Warning: %v; showing the offline analysis\n", err)
 		}
 
+		dataQuerier.SetCompareHistory(compare)
+
 		analysis, err := aiService.Analyze(dataQuerier)
 		if err != nil {
 			return err
 		}
 
 		fmt.Println(analysis)
+		printUsage(cmd)
 		return nil
 	},
 }
 
 var configCmd = &cobra.Command{
 	Use:   "config",
-	Short: "Show current configuration",
-	Long:  `Display the current configuration settings and work rules.`,
+	Short: "Show or change configuration",
+	Long: `Display the current configuration settings and work rules, or change
+settings with flags. Changes are validated before the config file is saved.
+
+Examples:
+  kairos config
+  kairos config --provider claude --claude-key YOUR_KEY
+  kairos config --weekly-goal 40 --timezone Europe/Vienna`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, name := range []string{"provider", "weekly-goal", "timezone", "ollama-url", "ollama-model", "openai-key", "claude-key", "gemini-key"} {
+			if cmd.Flags().Changed(name) {
+				return updateConfig(cmd)
+			}
+		}
 		fmt.Printf("Config: DB=%s | Ollama=%s (%s)\n", cfg.DatabasePath, cfg.OllamaURL, cfg.OllamaModel)
-		dailyTarget := cfg.WeeklyGoal / float64(work.WorkDaysPerWeek)
-		fmt.Printf("Rules: Weekly: %.2fh | Daily: %.2fh | Break: %dmin (Fri: %dmin)\n",
-			cfg.WeeklyGoal, dailyTarget, work.DefaultBreakMinutes, work.FridayBreakMinutes)
+		if cfg.OpenAIBaseURL != config.DefaultOpenAIBaseURL {
+			fmt.Printf("OpenAI base URL: %s\n", cfg.OpenAIBaseURL)
+		}
+		rules := cfg.Rules()
+		dailyTarget := cfg.WeeklyGoal / float64(rules.DaysPerWeek())
+		fmt.Printf("Rules: Weekly: %.2fh | Daily: %.2fh | Break: %s | Work days: %d\n",
+			cfg.WeeklyGoal, dailyTarget, rules.BreakSummary(), rules.DaysPerWeek())
+		return nil
+	},
+}
+
+// updateConfig applies the config command's flags to cfg, validates the
+// result and saves it, listing each setting that changed. API keys are
+// reported as set without showing them.
+func updateConfig(cmd *cobra.Command) error {
+	var changes []string
+	change := func(name, from, to string) {
+		if from != to {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", name, from, to))
+		}
+	}
+	flags := cmd.Flags()
+
+	if flags.Changed("provider") {
+		provider, _ := flags.GetString("provider")
+		to := config.AIProvider(strings.ToLower(strings.TrimSpace(provider)))
+		change("AI provider", string(cfg.AIProvider), string(to))
+		cfg.AIProvider = to
+	}
+	if flags.Changed("weekly-goal") {
+		goal, _ := flags.GetFloat64("weekly-goal")
+		change("Weekly goal", fmt.Sprintf("%gh", cfg.WeeklyGoal), fmt.Sprintf("%gh", goal))
+		cfg.WeeklyGoal = goal
+	}
+	if flags.Changed("timezone") {
+		timezone, _ := flags.GetString("timezone")
+		change("Timezone", cfg.TimeZone, timezone)
+		cfg.TimeZone = timezone
+	}
+	if flags.Changed("ollama-url") {
+		url, _ := flags.GetString("ollama-url")
+		change("Ollama URL", cfg.OllamaURL, url)
+		cfg.OllamaURL = url
+	}
+	if flags.Changed("ollama-model") {
+		model, _ := flags.GetString("ollama-model")
+		change("Ollama model", cfg.OllamaModel, model)
+		cfg.OllamaModel = model
+	}
+	for _, key := range []struct {
+		flag     string
+		provider config.AIProvider
+		name     string
+	}{
+		{"openai-key", config.ProviderOpenAI, "OpenAI API key"},
+		{"claude-key", config.ProviderClaude, "Claude API key"},
+		{"gemini-key", config.ProviderGemini, "Gemini API key"},
+	} {
+		if !flags.Changed(key.flag) {
+			continue
+		}
+		value, _ := flags.GetString(key.flag)
+		cfg.SetAPIKey(key.provider, value)
+		if value == "" {
+			changes = append(changes, key.name+": cleared")
+		} else {
+			changes = append(changes, key.name+": set")
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration not saved: %w", err)
+	}
+	if len(changes) == 0 {
+		fmt.Println("Configuration unchanged")
+		return nil
+	}
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Println("Configuration updated:")
+	for _, c := range changes {
+		fmt.Printf("  %s\n", c)
+	}
+	if flags.Changed("timezone") {
+		fmt.Println("Existing sessions keep their dates; run 'kairos doctor' to check them against the new timezone")
+	}
+	return nil
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate legacy .samaya data to .kairos",
+	Long: `Copy config, database and history from a legacy .samaya directory into
+the current .kairos location. Existing files are never overwritten and the
+legacy directory is left in place. When .kairos already has a database, the
+legacy one is not copied; the output says how to restore it instead.`,
+	// Skip the root setup so no empty database is created before copying
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := config.MigrateLegacy()
+		if err != nil {
+			return err
+		}
+		if result == nil {
+			fmt.Println("No legacy .samaya data found; nothing to migrate")
+			return nil
+		}
+
+		fmt.Printf("Migrated %s -> %s\n", result.From, result.To)
+		for _, f := range result.Copied {
+			fmt.Printf("  copied:  %s\n", f)
+		}
+		for _, f := range result.Skipped {
+			fmt.Printf("  skipped: %s (already exists)\n", f)
+		}
+		for _, f := range result.SkippedDatabases() {
+			legacy := filepath.Join(result.From, f)
+			fmt.Printf("Warning: %s already exists, so the sessions in %s were not migrated.\n",
+				filepath.Join(result.To, f), legacy)
+			fmt.Printf("To use the legacy database instead, run: kairos restore %s --force\n", legacy)
+			fmt.Println("(the current database is backed up first)")
+		}
 		return nil
 	},
 }
@@ -484,57 +1080,183 @@ PowerShell:
 }
 
 var batchCmd = &cobra.Command{
-	Use:     "batch <command>",
+	Use:     "batch <edit|delete>",
 	Aliases: []string{"bulk", "batchedit"},
 	Short:   "Batch edit sessions",
-	Long: `Batch edit sessions matching criteria.
+	Long: `Edit or delete several sessions at once. Sessions are picked by --ids
+(full IDs or the 8-character prefixes shown by 'sessions'), by --date, or
+both, in which case only the listed IDs on that date are used.
+
+If any ID does not match a session nothing is changed; --force skips the
+unknown IDs instead. Deleting also needs --force to confirm.
 
 Examples:
   kairos batch edit --ids a1b2c3d4,e5f6g7h8 --note "Team meeting"
+  kairos batch edit --date 2024-01-15 --break 30
   kairos batch delete --date 2024-01-15 --force
 
 Use --dry-run to preview changes without applying them.`,
-	Args: cobra.ExactArgs(1),
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"edit", "delete"},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Batch operations for editing multiple sessions
+		action := args[0]
+		if action != "edit" && action != "delete" {
+			return fmt.Errorf("unknown batch command %q (use edit or delete)", action)
+		}
+		applyTruncateNote(cmd)
 		idsStr, _ := cmd.Flags().GetString("ids")
 		dateStr, _ := cmd.Flags().GetString("date")
-		note, _ := cmd.Flags().GetString("note")
-		breakMinutes, _ := cmd.Flags().GetInt("break")
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+		if idsStr == "" && dateStr == "" {
+			return fmt.Errorf("choose sessions with --ids or --date")
+		}
+
+		// Only fields that were explicitly set are changed
+		var edit tracker.SessionEdit
+		if action == "edit" {
+			if cmd.Flags().Changed("note") {
+				note, _ := cmd.Flags().GetString("note")
+				fitted, err := trackerService.FitNote(note)
+				if err != nil {
+					return err
+				}
+				edit.Note = &fitted
+			}
+			if cmd.Flags().Changed("break") {
+				breakMinutes, _ := cmd.Flags().GetInt("break")
+				if breakMinutes < 0 {
+					return fmt.Errorf("break cannot be negative")
+				}
+				edit.BreakMinutes = &breakMinutes
+			}
+			if cmd.Flags().Changed("project") {
+				project, _ := cmd.Flags().GetString("project")
+				edit.Project = &project
+			}
+			if edit.Note == nil && edit.BreakMinutes == nil && edit.Project == nil {
+				return fmt.Errorf("nothing to change: set --note, --break or --project")
+			}
+		}
 
-		fmt.Printf("Batch command: %s\n", args[0])
-		fmt.Printf("  IDs: %s\n", idsStr)
-		fmt.Printf("  Date: %s\n", dateStr)
-		fmt.Printf("  Note: %s\n", note)
-		fmt.Printf("  Break: %d min\n", breakMinutes)
-		fmt.Printf("  Dry run: %t\n", dryRun)
+		sessions, err := batchSessions(idsStr, dateStr, force)
+		if err != nil {
+			return err
+		}
+		if len(sessions) == 0 {
+			fmt.Println("No matching sessions")
+			return nil
+		}
+
+		verb := "Edited"
+		if action == "delete" {
+			verb = "Deleted"
+		}
+		if dryRun || (action == "delete" && !force) {
+			for _, s := range sessions {
+				fmt.Printf("  %s %s %s", s.ID[:8], s.Date.Format("2006-01-02"), s.StartTime.Format("15:04"))
+				if s.Note != "" {
+					fmt.Printf(" - %s", s.Note)
+				}
+				fmt.Println()
+			}
+			if dryRun {
+				fmt.Printf("Dry run: would %s %d session(s), nothing written\n", action, len(sessions))
+			} else {
+				fmt.Printf("Delete %d session(s)? 'kairos undo' restores them one at a time (the last %d changes are kept). Use --force to confirm.\n",
+					len(sessions), storage.UndoLogSize)
+			}
+			return nil
+		}
 
-		if args[0] == "edit" && idsStr != "" {
-			fmt.Println("Batch edit mode - feature coming soon")
-		} else if args[0] == "delete" {
-			fmt.Println("Batch delete mode - feature coming soon")
+		if action == "delete" {
+			for _, s := range sessions {
+				if err := trackerService.DeleteSession(s.ID); err != nil {
+					return fmt.Errorf("session %s: %w", s.ID[:8], err)
+				}
+			}
+		} else {
+			ids := make([]string, len(sessions))
+			for i, s := range sessions {
+				ids[i] = s.ID
+			}
+			// Edits are all checked first and saved together
+			if err := trackerService.ApplySessionEdits(ids, edit); err != nil {
+				return fmt.Errorf("%w; nothing changed", err)
+			}
 		}
+		fmt.Printf("%s %d session(s)\n", verb, len(sessions))
 		return nil
 	},
 }
 
+// batchSessions resolves the sessions picked by the batch --ids and --date
+// flags. Unknown IDs are an error unless force is set, when they are skipped.
+func batchSessions(idsStr, dateStr string, force bool) ([]storage.WorkSession, error) {
+	var sessions []storage.WorkSession
+	if idsStr != "" {
+		found, missing, err := trackerService.ResolveSessionIDs(strings.Split(idsStr, ","))
+		if err != nil {
+			return nil, err
+		}
+		if len(missing) > 0 {
+			if !force {
+				return nil, fmt.Errorf("no session matches %s; nothing changed (use --force to skip unknown IDs)", strings.Join(missing, ", "))
+			}
+			fmt.Printf("Skipping unknown IDs: %s\n", strings.Join(missing, ", "))
+		}
+		sessions = found
+	}
+	if dateStr == "" {
+		return sessions, nil
+	}
+
+	date, err := tracker.ParseDateInput(dateStr, cfg.GetLocation())
+	if err != nil {
+		return nil, err
+	}
+	day, err := trackerService.GetDaySessions(date)
+	if err != nil {
+		return nil, err
+	}
+	if idsStr == "" {
+		return day, nil
+	}
+	onDay := make(map[string]bool, len(day))
+	for _, s := range day {
+		onDay[s.ID] = true
+	}
+	var filtered []storage.WorkSession
+	for _, s := range sessions {
+		if onDay[s.ID] {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered, nil
+}
+
 var exportCmd = &cobra.Command{
 	Use:     "export [format]",
 	Aliases: []string{"exp"},
-	Short:   "Export sessions to CSV, JSON, or HTML",
+	Short:   "Export sessions to CSV, JSON, HTML, Markdown, iCalendar, or PDF",
 	Long: `Export your work sessions to various formats.
 
 Examples:
   kairos export csv -o hours.csv
   kairos export json -s 2024-01-01 -e 2024-01-31
-  kairos export html -o report.html`,
+  kairos export html -o report.html --title "Q1 Hours" --author "Jane Doe"
+  kairos export markdown -s 2024-01-08 -e 2024-01-14
+  kairos export ics -o hours.ics
+  kairos export pdf -o report.pdf --title "January invoice"`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		format, _ := cmd.Flags().GetString("format")
 		startStr, _ := cmd.Flags().GetString("start")
 		endStr, _ := cmd.Flags().GetString("end")
 		outputPath, _ := cmd.Flags().GetString("output")
+		title, _ := cmd.Flags().GetString("title")
+		author, _ := cmd.Flags().GetString("author")
+		meta := exportMeta{Title: title, Author: author}
 
 		if len(args) > 0 {
 			format = args[0]
@@ -558,10 +1280,13 @@ Examples:
 			}
 		}
 
-		sessions, err := db.GetSessionsInRange(startDate, endDate)
+		// Open sessions are left out unless --include-active is given
+		includeActive, _ := cmd.Flags().GetBool("include-active")
+		sessions, err := trackerService.GetSessionsInRange(startDate, endDate)
 		if err != nil {
 			return err
 		}
+		sessions = tracker.ResolveActive(sessions, cfg.Now(), includeActive)
 
 		var output io.Writer
 		if outputPath != "" {
@@ -571,33 +1296,51 @@ Examples:
 			}
 			defer f.Close()
 			output = f
+		} else if info, err := os.Stdout.Stat(); format == "pdf" && err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			return fmt.Errorf("pdf export is binary: write it to a file with -o report.pdf")
 		} else {
 			output = os.Stdout
 		}
 
 		switch format {
 		case "csv":
-			return exportCSV(output, sessions)
+			return exportCSV(output, sessions, meta)
 		case "json":
-			return exportJSON(output, sessions)
+			return exportJSON(output, sessions, meta)
 		case "html":
-			return exportHTML(output, sessions, startDate, endDate)
+			return exportHTML(output, sessions, startDate, endDate, meta)
+		case "markdown", "md":
+			return exportMarkdown(output, sessions, startDate, endDate)
+		case "ics":
+			return exportICS(output, sessions)
+		case "pdf":
+			return exportPDF(output, sessions, startDate, endDate, meta)
 		default:
-			return fmt.Errorf("unknown format: %s (use csv, json, or html)", format)
+			return fmt.Errorf("unknown format: %s (use csv, json, html, markdown, ics, or pdf)", format)
 		}
 	},
 }
 
+// addRangeFlags adds the flags rangeCmd reads to cmd
+func addRangeFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("start", "s", "", "Start date (YYYY-MM-DD)")
+	cmd.Flags().StringP("end", "e", "", "End date (YYYY-MM-DD)")
+	cmd.Flags().Bool("include-active", false, "Include open sessions using their elapsed time")
+}
+
 var rangeCmd = &cobra.Command{
 	Use:     "range [start|date]",
 	Short:   "Show hours for a date range",
-	Aliases: []string{"report", "between"},
+	Aliases: []string{"between"},
 	Long: `Show work hours for a custom date range.
 
 Examples:
   kairos range                          # Last 7 days
   kairos range --start 2024-01-01 --end 2024-01-31  # January 2024
-  kairos range last-month`,
+  kairos range last-month
+
+'kairos report' with a date or --start/--end still shows a range, as when
+report was an alias of range.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		startStr, _ := cmd.Flags().GetString("start")
@@ -630,17 +1373,19 @@ Examples:
 			}
 		}
 
-		sessions, err := db.GetSessionsInRange(startDate, endDate)
+		// Open sessions are left out unless --include-active is given
+		includeActive, _ := cmd.Flags().GetBool("include-active")
+		sessions, err := trackerService.GetSessionsInRange(startDate, endDate)
 		if err != nil {
 			return err
 		}
+		sessions = tracker.ResolveActive(sessions, cfg.Now(), includeActive)
 
 		totalHours := 0.0
 		byDate := make(map[string]float64)
 
 		for _, s := range sessions {
-			if s.EndTime != nil {
-				hours := s.EndTime.Sub(s.StartTime).Hours() - float64(s.BreakMinutes)/60.0
+			if hours, complete := sessionHours(s); complete {
 				totalHours += hours
 				dateKey := s.Date.Format("2006-01-02")
 				byDate[dateKey] += hours
@@ -648,10 +1393,10 @@ Examples:
 		}
 
 		fmt.Printf("Range: %s - %s\n", startDate.Format("Jan 2, 2006"), endDate.Format("Jan 2, 2006"))
-		fmt.Printf("Total: %.2f hours (%d sessions)\n", totalHours, len(sessions))
+		fmt.Printf("Total: %s hours (%d sessions)\n", formatHours(totalHours), len(sessions))
 		fmt.Println("\nDaily breakdown:")
 		for date, hours := range byDate {
-			fmt.Printf("  %s: %.2fh\n", date, hours)
+			fmt.Printf("  %s: %sh\n", date, formatHours(hours))
 		}
 
 		return nil
@@ -703,17 +1448,39 @@ This wizard helps you configure:
 
 // Export helper functions
 
-func exportCSV(w io.Writer, sessions []storage.WorkSession) error {
+// defaultExportTitle heads exports when --title is not given
+const defaultExportTitle = "Kairos Work Report"
+
+// exportMeta identifies an export for whoever receives it
+type exportMeta struct {
+	Title  string
+	Author string
+}
+
+func (m exportMeta) title() string {
+	if m.Title == "" {
+		return defaultExportTitle
+	}
+	return m.Title
+}
+
+func exportCSV(w io.Writer, sessions []storage.WorkSession, meta exportMeta) error {
 	writer := csv.NewWriter(w)
 	defer writer.Flush()
 
+	// Comment rows, skippable by readers that treat # as a comment
+	writer.Write([]string{"# " + meta.title()})
+	if meta.Author != "" {
+		writer.Write([]string{"# Author: " + meta.Author})
+	}
+
 	// Header
-	writer.Write([]string{"Date", "Start", "End", "Break (min)", "Hours", "Note"})
+	writer.Write([]string{"Date", "Start", "End", "Break (min)", "Hours", "Note", "Project", "Tags"})
 
 	for _, s := range sessions {
-		hours := 0.0
-		if s.EndTime != nil {
-			hours = s.EndTime.Sub(s.StartTime).Hours() - float64(s.BreakMinutes)/60.0
+		hours, complete := sessionHours(s)
+		if !complete {
+			hours = 0
 		}
 		endStr := ""
 		if s.EndTime != nil {
@@ -724,28 +1491,33 @@ func exportCSV(w io.Writer, sessions []storage.WorkSession) error {
 			s.StartTime.Format("15:04"),
 			endStr,
 			strconv.Itoa(s.BreakMinutes),
-			fmt.Sprintf("%.2f", hours),
+			formatHours(hours),
 			s.Note,
+			s.Project,
+			strings.Join(s.Tags, ";"),
 		})
 	}
 	return nil
 }
 
-func exportJSON(w io.Writer, sessions []storage.WorkSession) error {
+func exportJSON(w io.Writer, sessions []storage.WorkSession, meta exportMeta) error {
 	type sessionExport struct {
-		Date         string  `json:"date"`
-		StartTime    string  `json:"start_time"`
-		EndTime      string  `json:"end_time,omitempty"`
-		BreakMinutes int     `json:"break_minutes"`
-		HoursWorked  float64 `json:"hours_worked"`
-		Note         string  `json:"note,omitempty"`
+		Date         string   `json:"date"`
+		StartTime    string   `json:"start_time"`
+		EndTime      string   `json:"end_time,omitempty"`
+		BreakMinutes int      `json:"break_minutes"`
+		HoursWorked  float64  `json:"hours_worked"`
+		Note         string   `json:"note,omitempty"`
+		Project      string   `json:"project,omitempty"`
+		Tags         []string `json:"tags,omitempty"`
+		TimeZone     string   `json:"tz,omitempty"`
 	}
 
 	exports := make([]sessionExport, 0, len(sessions))
 	for _, s := range sessions {
-		hours := 0.0
-		if s.EndTime != nil {
-			hours = s.EndTime.Sub(s.StartTime).Hours() - float64(s.BreakMinutes)/60.0
+		hours, complete := sessionHours(s)
+		if !complete {
+			hours = 0
 		}
 		exp := sessionExport{
 			Date:         s.Date.Format("2006-01-02"),
@@ -753,6 +1525,9 @@ func exportJSON(w io.Writer, sessions []storage.WorkSession) error {
 			BreakMinutes: s.BreakMinutes,
 			HoursWorked:  hours,
 			Note:         s.Note,
+			Project:      s.Project,
+			Tags:         s.Tags,
+			TimeZone:     s.TimeZone,
 		}
 		if s.EndTime != nil {
 			exp.EndTime = s.EndTime.Format("15:04")
@@ -762,29 +1537,49 @@ func exportJSON(w io.Writer, sessions []storage.WorkSession) error {
 
 	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
-	return encoder.Encode(map[string]interface{}{
+	result := map[string]interface{}{
+		"title":          meta.title(),
 		"export_date":    cfg.Now().Format("2006-01-02"),
 		"total_sessions": len(sessions),
 		"sessions":       exports,
-	})
+	}
+	if meta.Author != "" {
+		result["author"] = meta.Author
+	}
+	return encoder.Encode(result)
 }
 
-func exportHTML(w io.Writer, sessions []storage.WorkSession, start, end time.Time) error {
-	totalHours := 0.0
-	byDate := make(map[string]float64)
-
+// exportTotals sums completed session hours overall and per day, with the
+// days in date order; the HTML and PDF reports share it so their totals match
+func exportTotals(sessions []storage.WorkSession) (total float64, byDate map[string]float64, dates []string) {
+	byDate = make(map[string]float64)
 	for _, s := range sessions {
-		if s.EndTime != nil {
-			hours := s.EndTime.Sub(s.StartTime).Hours() - float64(s.BreakMinutes)/60.0
-			totalHours += hours
-			byDate[s.Date.Format("2006-01-02")] += hours
+		if hours, complete := sessionHours(s); complete {
+			total += hours
+			day := s.Date.Format("2006-01-02")
+			if _, seen := byDate[day]; !seen {
+				dates = append(dates, day)
+			}
+			byDate[day] += hours
 		}
 	}
+	sort.Strings(dates)
+	return total, byDate, dates
+}
+
+func exportHTML(w io.Writer, sessions []storage.WorkSession, start, end time.Time, meta exportMeta) error {
+	totalHours, byDate, dates := exportTotals(sessions)
+
+	title := html.EscapeString(meta.title())
+	byline := ""
+	if meta.Author != "" {
+		byline = fmt.Sprintf("\n    <p>Prepared by %s</p>", html.EscapeString(meta.Author))
+	}
 
-	html := fmt.Sprintf(`<!DOCTYPE html>
+	page := fmt.Sprintf(`<!DOCTYPE html>
 <html>
 <head>
-    <title>Kairos Report</title>
+    <title>%s</title>
     <style>
         body { font-family: system-ui, sans-serif; max-width: 800px; margin: 40px auto; padding: 20px; }
         h1 { color: #333; }
@@ -796,29 +1591,194 @@ func exportHTML(w io.Writer, sessions []storage.WorkSession, start, end time.Tim
     </style>
 </head>
 <body>
-    <h1>Kairos Work Report</h1>
+    <h1>%s</h1>%s
     <p>Period: %s - %s</p>
     <div class="summary">
-        <p class="total">Total Hours: %.2f</p>
+        <p class="total">Total Hours: %s</p>
         <p>Sessions: %d</p>
     </div>
     <h2>Daily Breakdown</h2>
     <table>
         <tr><th>Date</th><th>Hours</th></tr>
-`, start.Format("Jan 2, 2006"), end.Format("Jan 2, 2006"), totalHours, len(sessions))
+`, title, title, byline, start.Format("Jan 2, 2006"), end.Format("Jan 2, 2006"), formatHours(totalHours), len(sessions))
 
-	for date, hours := range byDate {
-		html += fmt.Sprintf("        <tr><td>%s</td><td>%.2f</td></tr>\n", date, hours)
+	for _, date := range dates {
+		page += fmt.Sprintf("        <tr><td>%s</td><td>%s</td></tr>\n", date, formatHours(byDate[date]))
 	}
 
-	html += `    </table>
+	page += `    </table>
 </body>
 </html>`
 
-	_, err := w.Write([]byte(html))
+	_, err := w.Write([]byte(page))
 	return err
 }
 
+// exportPDF writes the HTML report's content as a PDF: title, period,
+// summary box and the daily breakdown table, paginated as needed
+func exportPDF(w io.Writer, sessions []storage.WorkSession, start, end time.Time, meta exportMeta) error {
+	totalHours, byDate, dates := exportTotals(sessions)
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(meta.title(), true)
+	pdf.SetAuthor(meta.Author, true)
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+	// Core fonts are cp1252; translate so accented names survive
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+	width, _ := pdf.GetPageSize()
+	width -= 40
+
+	pdf.SetTextColor(0x33, 0x33, 0x33)
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.CellFormat(width, 12, tr(meta.title()), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	if meta.Author != "" {
+		pdf.CellFormat(width, 7, tr("Prepared by "+meta.Author), "", 1, "L", false, 0, "")
+	}
+	pdf.CellFormat(width, 7, fmt.Sprintf("Period: %s - %s", start.Format("Jan 2, 2006"), end.Format("Jan 2, 2006")), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	// Summary box
+	pdf.SetFillColor(0xf5, 0xf5, 0xf5)
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.CellFormat(width, 10, "  Total Hours: "+formatHours(totalHours), "", 1, "L", true, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(width, 8, fmt.Sprintf("  Sessions: %d", len(sessions)), "", 1, "L", true, 0, "")
+	pdf.Ln(6)
+
+	pdf.SetFont("Helvetica", "B", 15)
+	pdf.CellFormat(width, 10, "Daily Breakdown", "", 1, "L", false, 0, "")
+
+	pdf.SetDrawColor(0xee, 0xee, 0xee)
+	pdf.SetFillColor(0xf9, 0xf9, 0xf9)
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(width/2, 9, "Date", "B", 0, "L", true, 0, "")
+	pdf.CellFormat(width/2, 9, "Hours", "B", 1, "L", true, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	for _, date := range dates {
+		pdf.CellFormat(width/2, 8, date, "B", 0, "L", false, 0, "")
+		pdf.CellFormat(width/2, 8, formatHours(byDate[date]), "B", 1, "L", false, 0, "")
+	}
+
+	return pdf.Output(w)
+}
+
+// exportMarkdown writes a pipe table for pasting into GitHub or Slack: one
+// row per completed session, a subtotal row after each day and a grand total.
+// Tables share archive.MarkdownTable with the monthly archives.
+func exportMarkdown(w io.Writer, sessions []storage.WorkSession, start, end time.Time) error {
+	var rows [][]string
+	total, days, count := 0.0, 0, 0
+	dayTotal, day := 0.0, ""
+	closeDay := func() {
+		if day != "" {
+			rows = append(rows, []string{"", "", "", "", "**" + formatHours(dayTotal) + "**", "*" + day + " total*"})
+		}
+	}
+
+	for _, s := range sessions {
+		hours, complete := sessionHours(s)
+		if !complete {
+			continue
+		}
+		if date := s.Date.Format("2006-01-02"); date != day {
+			closeDay()
+			day, dayTotal = date, 0
+			days++
+		}
+		dayTotal += hours
+		total += hours
+		count++
+		rows = append(rows, []string{
+			s.Date.Format("2006-01-02"),
+			s.StartTime.Format("15:04"),
+			s.EndTime.Format("15:04"),
+			fmt.Sprintf("%dm", s.BreakMinutes),
+			formatHours(hours),
+			strings.ReplaceAll(s.Note, "|", `\|`),
+		})
+	}
+	closeDay()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("## Work Hours: %s - %s\n\n", start.Format("Jan 2, 2006"), end.Format("Jan 2, 2006")))
+	sb.WriteString(archive.MarkdownTable([]string{"Date", "Start", "End", "Break", "Hours", "Note"}, rows))
+	sb.WriteString(fmt.Sprintf("\n**Total: %sh** over %d days (%d sessions)\n", formatHours(total), days, count))
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// icsTimeLayout is the iCalendar UTC date-time form, e.g. 20240115T083000Z
+const icsTimeLayout = "20060102T150405Z"
+
+// exportICS writes completed sessions as VEVENTs in a VCALENDAR. Open
+// sessions have no end to report and are skipped.
+func exportICS(w io.Writer, sessions []storage.WorkSession) error {
+	var sb strings.Builder
+	line := func(content string) {
+		sb.WriteString(foldICSLine(content))
+		sb.WriteString("\r\n")
+	}
+
+	stamp := time.Now().UTC().Format(icsTimeLayout)
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Kairos//Work Sessions//EN")
+	line("CALSCALE:GREGORIAN")
+	for _, s := range sessions {
+		hours, complete := sessionHours(s)
+		if !complete {
+			continue
+		}
+		summary := s.Note
+		if summary == "" {
+			summary = "Work session"
+		}
+		description := fmt.Sprintf("%sh worked, %dmin break", formatHours(hours), s.BreakMinutes)
+		if s.Project != "" {
+			description += ", project " + s.Project
+		}
+
+		line("BEGIN:VEVENT")
+		line("UID:" + s.ID + "@kairos")
+		line("DTSTAMP:" + stamp)
+		line("DTSTART:" + s.StartTime.UTC().Format(icsTimeLayout))
+		line("DTEND:" + s.EndTime.UTC().Format(icsTimeLayout))
+		line("SUMMARY:" + escapeICSText(summary))
+		line("DESCRIPTION:" + escapeICSText(description))
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// escapeICSText escapes a TEXT value per RFC 5545
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// foldICSLine splits content lines longer than 75 octets, continuing them on
+// lines that start with a space, without breaking UTF-8 sequences
+func foldICSLine(content string) string {
+	var sb strings.Builder
+	width := 0
+	for _, r := range content {
+		size := utf8.RuneLen(r)
+		if width+size > 75 {
+			sb.WriteString("\r\n ")
+			width = 1
+		}
+		sb.WriteRune(r)
+		width += size
+	}
+	return sb.String()
+}
+
 func isValidTimeInput(input string) bool {
 	for _, format := range []string{"15:04", "3:04", "15:04:05", "3:04:05"} {
 		if _, err := time.Parse(format, input); err == nil {
@@ -829,38 +1789,103 @@ func isValidTimeInput(input string) bool {
 }
 
 func init() {
+	configCmd.AddCommand(configMigrateCmd)
+
 	editCmd.Flags().IntP("break", "b", 0, "Break time in minutes")
-	editCmd.Flags().StringP("note", "n", "", "Add a note")
+	editCmd.Flags().StringP("note", "n", "", "Replace the note")
+	editCmd.Flags().String("append-note", "", "Add to the end of the existing note")
+	editCmd.MarkFlagsMutuallyExclusive("note", "append-note")
 	editCmd.Flags().StringP("time", "t", "", "Override start time (HH:MM)")
 	editCmd.Flags().StringP("end", "e", "", "Override end time (HH:MM)")
+	editCmd.Flags().StringP("project", "p", "", "Set the project (empty to clear)")
+	editCmd.RegisterFlagCompletionFunc("project", completeProjectNames)
+	editCmd.Flags().StringSlice("tags", nil, "Replace the tags (comma-separated, empty to clear)")
+	editCmd.Flags().Bool("truncate-note", false, "Shorten a note over max_note_length instead of failing")
 
 	deleteCmd.Flags().BoolP("force", "f", false, "Force delete without confirmation")
 
 	clockinCmd.Flags().StringP("time", "t", "", "Override start time (HH:MM)")
+	clockinCmd.Flags().StringP("project", "p", "", "Project for this session (new names are registered)")
+	clockinCmd.RegisterFlagCompletionFunc("project", completeProjectNames)
+	clockinCmd.Flags().StringSlice("tags", nil, "Comma-separated tags for this session")
+	clockinCmd.Flags().Bool("truncate-note", false, "Shorten a note over max_note_length instead of failing")
 
 	clockoutCmd.Flags().StringP("time", "t", "", "Override end time (HH:MM)")
 	clockoutCmd.Flags().IntP("break", "b", -1, "Override break time in minutes")
+	clockoutCmd.Flags().Bool("round-up", false, "Round the end time up to the next quarter hour (14:07 -> 14:15)")
+	clockoutCmd.Flags().Bool("discard", false, "Delete the active session instead of closing it")
+	clockoutCmd.Flags().BoolP("force", "f", false, "Discard without confirmation")
 
 	// Batch command flags
 	batchCmd.Flags().String("ids", "", "Comma-separated session IDs")
 	batchCmd.Flags().String("date", "", "Filter by date (YYYY-MM-DD)")
 	batchCmd.Flags().StringP("note", "n", "", "Note to set")
+	batchCmd.Flags().StringP("project", "p", "", "Project to set")
 	batchCmd.Flags().IntP("break", "b", 0, "Break time in minutes")
 	batchCmd.Flags().Bool("dry-run", false, "Preview changes without applying")
+	batchCmd.Flags().BoolP("force", "f", false, "Skip unknown IDs and confirm deletes")
+	batchCmd.Flags().Bool("truncate-note", false, "Shorten a note over max_note_length instead of failing")
 
 	// Export command
-	exportCmd.Flags().StringP("format", "f", "csv", "Output format: csv, json, html")
+	exportCmd.Flags().StringP("format", "f", "csv", "Output format: csv, json, html, markdown, ics, pdf")
 	exportCmd.Flags().StringP("start", "s", "", "Start date (YYYY-MM-DD)")
 	exportCmd.Flags().StringP("end", "e", "", "End date (YYYY-MM-DD)")
 	exportCmd.Flags().StringP("output", "o", "", "Output file (stdout if empty)")
+	exportCmd.Flags().String("title", defaultExportTitle, "Report title (HTML/PDF header, JSON title, CSV comment)")
+	exportCmd.Flags().String("author", "", "Author name to include in the export")
+	exportCmd.Flags().Bool("include-active", false, "Include open sessions using their elapsed time")
+
+	// Status and week commands
+	statusCmd.Flags().Bool("include-active", false, "Include the running session in totals")
+	weekCmd.Flags().Bool("include-active", false, "Include the running session in totals")
+	weekCmd.Flags().Bool("fill-missing", false, "Flag past work days with no hours as MISSED and weekends as off")
+	weekCmd.Flags().Float64("set-goal", 0, "Set the shown week's goal in hours, in place of the weekly goal")
+	weekCmd.Flags().Bool("clear-goal", false, "Remove the shown week's goal so it uses the weekly goal again")
+
+	// Sessions command
+	sessionsCmd.Flags().Bool("gaps", false, "Show one day's sessions with the gaps between them")
+	sessionsCmd.Flags().Bool("today", false, "Show today's sessions")
+	sessionsCmd.Flags().Bool("week", false, "Show this week's sessions (default)")
+	sessionsCmd.Flags().Bool("month", false, "Show this month's sessions")
+	sessionsCmd.Flags().StringP("start", "s", "", "Range start date (YYYY-MM-DD)")
+	sessionsCmd.Flags().StringP("end", "e", "", "Range end date (YYYY-MM-DD)")
+	sessionsCmd.Flags().IntP("last", "l", 0, "Show the last N days of sessions, today included")
+	sessionsCmd.Flags().Bool("full-id", false, "Show full session IDs")
+	sessionsCmd.MarkFlagsMutuallyExclusive("today", "week", "month", "start")
+	sessionsCmd.MarkFlagsMutuallyExclusive("today", "week", "month", "end")
 
 	// Range command
-	rangeCmd.Flags().StringP("start", "s", "", "Start date (YYYY-MM-DD)")
-	rangeCmd.Flags().StringP("end", "e", "", "End date (YYYY-MM-DD)")
+	addRangeFlags(rangeCmd)
+
+	// Ask command
+	askCmd.Flags().Bool("tools", false, "Route questions matching an MCP tool to that tool")
+	askCmd.Flags().Bool("verbose", false, "Show the tokens the answer used")
+
+	// Analyze command
+	analyzeCmd.Flags().Bool("compare-history", false, "Compare this month against the trailing 3-month archive average")
+	analyzeCmd.Flags().Bool("verbose", false, "Show the tokens the analysis used")
+
+	// Month command
+	monthCmd.Flags().String("week-numbering", "", "Week labels: iso (W52, W1) or month (Wk 1-6) (default: config week_numbering)")
+
+	// Predict command
+	predictCmd.Flags().Float64("hours", 0, "What-if: hours per day (default: daily target)")
+	predictCmd.Flags().Int("days", 0, "What-if: number of days (default: work days left this week)")
+	predictCmd.Flags().Bool("verbose", false, "Show the tokens the prediction used")
+
+	// Config command
+	configCmd.Flags().String("provider", "", "AI provider (ollama, openai, claude, gemini, echo)")
+	configCmd.Flags().Float64("weekly-goal", 0, "Weekly goal in hours")
+	configCmd.Flags().String("timezone", "", "Timezone (e.g., America/New_York, UTC+01:00, local)")
+	configCmd.Flags().String("ollama-url", "", "Ollama server URL")
+	configCmd.Flags().String("ollama-model", "", "Ollama model")
+	configCmd.Flags().String("openai-key", "", "OpenAI API key")
+	configCmd.Flags().String("claude-key", "", "Claude (Anthropic) API key")
+	configCmd.Flags().String("gemini-key", "", "Gemini API key")
 
 	// Setup command
 	setupCmd.Flags().Bool("interactive", false, "Run in interactive mode")
 	setupCmd.Flags().Float64("goal", 38.5, "Weekly goal in hours")
 	setupCmd.Flags().String("timezone", "", "Timezone (e.g., America/New_York)")
-	setupCmd.Flags().String("provider", "ollama", "AI provider (ollama, openai, claude, gemini)")
+	setupCmd.Flags().String("provider", "ollama", "AI provider (ollama, openai, claude, gemini, echo)")
 }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kairos/internal/mcp"
+	"github.com/kairos/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+var digestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Save a weekly summary for the AI to look back on",
+	Long: `Summarize a week in one line (hours against the goal, days worked, longest
+day, usual hours, most common note) and store it in the memories table under
+digest-<week start> with category "digest". Running it again for the same week
+replaces the earlier digest. The persist MCP tool can list them with
+category=digest.
+
+Examples:
+  kairos digest                     # this week so far
+  kairos digest --last              # last week
+  kairos digest --date 2025-03-10   # the week containing that day`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		date := trackerService.Now()
+		if last, _ := cmd.Flags().GetBool("last"); last {
+			date = date.AddDate(0, 0, -7)
+		}
+		if cmd.Flags().Changed("date") {
+			input, _ := cmd.Flags().GetString("date")
+			parsed, err := tracker.ParseDateInput(input, cfg.GetLocation())
+			if err != nil {
+				return err
+			}
+			date = parsed
+		}
+
+		digest, err := dataQuerier.WeeklyDigest(date)
+		if err != nil {
+			return err
+		}
+		fmt.Println(digest.Text)
+
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			return nil
+		}
+		key, err := mcp.StoreDigest(db, digest)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Saved as %s\n", key)
+		return nil
+	},
+}
+
+func init() {
+	digestCmd.Flags().Bool("last", false, "Summarize last week")
+	digestCmd.Flags().String("date", "", "Summarize the week containing this date (YYYY-MM-DD)")
+	digestCmd.Flags().Bool("dry-run", false, "Print the digest without storing it")
+	digestCmd.MarkFlagsMutuallyExclusive("last", "date")
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check stored data for inconsistencies",
+	Long: `Run consistency checks against the database.
+
+Currently checks that each session's stored date matches its start time in the
+configured timezone. Fix mismatches with 'kairos migrate-tz'.
+
+With --fix, repairs common data issues one at a time, asking before each:
+  stale-open     open longer than stale_session_hours: closed after the daily
+                 target plus the default break (no later than 23:59)
+  zero-duration  end at or before start: deleted
+  long-break     break negative or not shorter than the session: clamped
+  date           date column out of step with the timezone: recomputed
+Answer y to apply, n to skip, q to stop. --yes applies every fix.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if fix, _ := cmd.Flags().GetBool("fix"); fix {
+			yes, _ := cmd.Flags().GetBool("yes")
+			return runDoctorFix(yes)
+		}
+
+		mismatches, err := db.FindDateMismatches()
+		if err != nil {
+			return err
+		}
+		if len(mismatches) == 0 {
+			fmt.Printf("OK: all session dates match %s\n", cfg.GetLocation())
+			return nil
+		}
+
+		fmt.Printf("%d session(s) stored on the wrong date for %s:\n", len(mismatches), cfg.GetLocation())
+		for _, m := range mismatches {
+			fmt.Printf("  %s: %s -> %s\n", m.ID[:8], m.Stored, m.Expected)
+		}
+		fmt.Println("Run 'kairos migrate-tz --force' to fix.")
+		return nil
+	},
+}
+
+var migrateTzCmd = &cobra.Command{
+	Use:   "migrate-tz",
+	Short: "Recompute session dates in the configured timezone",
+	Long: `Recompute every session's date from its start time in the configured
+timezone, e.g. after changing the timezone setting.
+
+Sessions remember the zone they were recorded in and keep their times and
+dates when the configured timezone changes; only sessions recorded before
+zones were stored follow the new setting. --record-zone stamps those with the
+configured zone so they stay put from now on.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+
+		if record, _ := cmd.Flags().GetBool("record-zone"); record {
+			if cfg.GetLocation() == time.Local {
+				return fmt.Errorf("the machine-local timezone can't be recorded; set an explicit timezone in the config first")
+			}
+			if !force {
+				count, err := db.CountUnzonedSessions()
+				if err != nil {
+					return err
+				}
+				if count == 0 {
+					fmt.Println("All sessions already have a recorded timezone")
+					return nil
+				}
+				fmt.Printf("Would record %s for %d session(s). Use --force to confirm.\n", cfg.GetLocation(), count)
+				return nil
+			}
+			changed, err := db.RecordSessionZones()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Recorded %s for %d session(s)\n", cfg.GetLocation(), changed)
+			return nil
+		}
+
+		if !force {
+			mismatches, err := db.FindDateMismatches()
+			if err != nil {
+				return err
+			}
+			if len(mismatches) == 0 {
+				fmt.Println("No session dates need changing")
+				return nil
+			}
+			fmt.Printf("Would change %d session date(s) for %s. Use --force to confirm.\n", len(mismatches), cfg.GetLocation())
+			return nil
+		}
+
+		changed, err := db.FixSessionDates()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Updated %d session date(s) for %s\n", changed, cfg.GetLocation())
+		return nil
+	},
+}
+
+// runDoctorFix prints each repair's before/after and applies it when
+// confirmed (or always with yes)
+func runDoctorFix(yes bool) error {
+	repairs, err := trackerService.FindRepairs()
+	if err != nil {
+		return err
+	}
+	if len(repairs) == 0 {
+		fmt.Println("OK: nothing to fix")
+		return nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	applied, skipped := 0, 0
+	for i, r := range repairs {
+		fmt.Printf("[%d/%d] %s %s: %s -> %s\n", i+1, len(repairs), r.Kind, r.SessionID[:8], r.Before, r.After)
+		if !yes {
+			fmt.Print("Apply? [y/N/q] ")
+			line, err := reader.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return err
+			}
+			answer := strings.ToLower(strings.TrimSpace(line))
+			if answer == "q" || (answer == "" && err == io.EOF) {
+				skipped += len(repairs) - i
+				break
+			}
+			if answer != "y" && answer != "yes" {
+				skipped++
+				continue
+			}
+		}
+		if err := trackerService.ApplyRepair(r); err != nil {
+			return fmt.Errorf("%s %s: %w", r.Kind, r.SessionID, err)
+		}
+		applied++
+	}
+
+	fmt.Printf("Applied %d fix(es), skipped %d\n", applied, skipped)
+	return nil
+}
+
+func init() {
+	doctorCmd.Flags().Bool("fix", false, "Repair stale, zero-length and inconsistent sessions")
+	doctorCmd.Flags().BoolP("yes", "y", false, "Apply every fix without asking (with --fix)")
+	migrateTzCmd.Flags().BoolP("force", "f", false, "Apply the changes")
+	migrateTzCmd.Flags().Bool("record-zone", false, "Record the configured timezone on sessions that have none")
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kairos/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+var flexCmd = &cobra.Command{
+	Use:         "flex",
+	Short:       "Running flex time balance",
+	Annotations: map[string]string{lightAnnotation: "true"},
+	Long: `Show the flex balance: every completed week's hours minus its goal, added up
+since flex_start_date (default: the first session). Overtime builds the balance
+up and short weeks draw it down; the current week counts once it is over.
+
+Examples:
+  kairos flex
+  kairos flex --since 2024-01-01`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if since, _ := cmd.Flags().GetString("since"); since != "" {
+			start, err := tracker.ParseDateInput(since, cfg.GetLocation())
+			if err != nil {
+				return err
+			}
+			trackerService.SetFlexStart(start)
+		}
+
+		start, ok, err := trackerService.FlexStart()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("No sessions yet; flex balance is 0")
+			return nil
+		}
+		now := trackerService.Now()
+		balance, err := trackerService.GetFlexBalance(now)
+		if err != nil {
+			return err
+		}
+		week, err := trackerService.GetWeeklyProgress()
+		if err != nil {
+			return err
+		}
+
+		weeks := int(week.WeekStart.Sub(start).Hours()/24+0.5) / 7
+		fmt.Printf("Flex balance: %sh over %d completed weeks since %s\n",
+			signedHours(balance), weeks, start.Format("Mon Jan 2, 2006"))
+		fmt.Printf("This week so far: %sh of %sh (%sh), counted once the week is over\n",
+			formatHours(week.TotalHours), formatHours(week.Goal), signedHours(week.TotalHours-week.Goal))
+		return nil
+	},
+}
+
+func init() {
+	flexCmd.Flags().String("since", "", "Count from this date instead of flex_start_date (YYYY-MM-DD, \"Jan 2\" or \"1/2\")")
+}
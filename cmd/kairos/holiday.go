@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kairos/internal/storage"
+	"github.com/kairos/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+var holidayCmd = &cobra.Command{
+	Use:         "holiday",
+	Aliases:     []string{"holidays"},
+	Short:       "Manage holidays and days off",
+	Annotations: map[string]string{lightAnnotation: "true"},
+	Long: `Mark public holidays and PTO as days off. A holiday has no target, so the
+week and month goals shrink by that day's share and the remaining daily target
+is spread over the work days left. Holidays can also be listed in the config
+under holidays.`,
+}
+
+var holidayAddCmd = &cobra.Command{
+	Use:   "add <date> [name]",
+	Short: "Add a holiday",
+	Long: `Add a holiday on a date (YYYY-MM-DD, "Dec 25" or "12/25"), optionally named.
+
+Examples:
+  kairos holiday add 2024-12-25 Christmas
+  kairos holiday add "Aug 15" PTO`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		date, err := tracker.ParseDateInput(args[0], cfg.GetLocation())
+		if err != nil {
+			return err
+		}
+		day := date.Format("2006-01-02")
+		name := strings.Join(args[1:], " ")
+		if err := db.AddHoliday(day, name); err != nil {
+			return err
+		}
+
+		if name != "" {
+			name = " (" + name + ")"
+		}
+		fmt.Printf("Added holiday %s%s\n", date.Format("Mon Jan 2, 2006"), name)
+		return nil
+	},
+}
+
+var holidayRemoveCmd = &cobra.Command{
+	Use:     "remove <date>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a holiday",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		date, err := tracker.ParseDateInput(args[0], cfg.GetLocation())
+		if err != nil {
+			return err
+		}
+		day := date.Format("2006-01-02")
+		removed, err := db.RemoveHoliday(day)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			for _, configured := range cfg.Holidays {
+				if configured == day {
+					return fmt.Errorf("%s is a holiday in the config file; remove it there", day)
+				}
+			}
+			return fmt.Errorf("no holiday on %s", day)
+		}
+
+		fmt.Printf("Removed holiday %s\n", date.Format("Mon Jan 2, 2006"))
+		return nil
+	},
+}
+
+var holidayListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List holidays",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		holidays, err := db.ListHolidays()
+		if err != nil {
+			return err
+		}
+		stored := make(map[string]bool, len(holidays))
+		for _, h := range holidays {
+			stored[h.Date] = true
+		}
+		for _, day := range cfg.Holidays {
+			if !stored[day] {
+				stored[day] = true
+				holidays = append(holidays, storage.Holiday{Date: day, Name: "(config)"})
+			}
+		}
+
+		if len(holidays) == 0 {
+			fmt.Println("No holidays. Add one with: kairos holiday add <date> [name]")
+			return nil
+		}
+		sort.Slice(holidays, func(i, j int) bool { return holidays[i].Date < holidays[j].Date })
+		for _, h := range holidays {
+			line := "  " + h.Date
+			if h.Name != "" {
+				line += " " + h.Name
+			}
+			fmt.Println(line)
+		}
+		return nil
+	},
+}
+
+func init() {
+	holidayCmd.AddCommand(holidayAddCmd)
+	holidayCmd.AddCommand(holidayRemoveCmd)
+	holidayCmd.AddCommand(holidayListCmd)
+}
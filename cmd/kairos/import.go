@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kairos/internal/importer"
+	"github.com/kairos/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:         "import",
+	Short:       "Load sessions from an export or spreadsheet",
+	Annotations: map[string]string{lightAnnotation: "true"},
+}
+
+var importCSVCmd = &cobra.Command{
+	Use:   "csv <file>",
+	Short: "Import sessions from CSV in the export's columns",
+	Long: `Import sessions from a CSV file with the columns kairos export writes: Date
+(YYYY-MM-DD), Start, End (HH:MM), Break (min), Hours, Note, Project, Tags.
+Only Date and Start are required, in any order; without End, the session ends
+after Hours plus the break. Sessions already in the database (same date and
+start time) are skipped, and rows that can't be read, or whose note is over
+max_note_length (see --truncate-note), are listed. Use - to read standard
+input.
+
+Examples:
+  kairos import csv hours-2024.csv
+  kairos export csv | kairos import csv -`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r, err := openImport(args[0])
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		result, err := importer.ParseCSV(r, cfg.GetLocation())
+		if err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		applyTruncateNote(cmd)
+		fitNotes(result)
+		return importSessions(result)
+	},
+}
+
+var importJSONCmd = &cobra.Command{
+	Use:   "json <file>",
+	Short: "Import sessions from a kairos JSON export",
+	Long: `Import the sessions of a file written by kairos export json, with their
+notes, breaks, projects, tags and time zones; each gets a new ID. Sessions already in the
+database (same date and start time) are skipped, and so are notes over
+max_note_length unless --truncate-note shortens them. With --replace-range, the
+completed sessions on the days the file covers are replaced, so importing the
+same export again leaves one copy; the replacement is all or nothing, and a
+single kairos undo reverts it. Use - to read standard input.
+
+Examples:
+  kairos import json january.json
+  kairos import json january.json --replace-range`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r, err := openImport(args[0])
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		result, err := importer.ParseJSON(r, cfg.GetLocation())
+		if err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		applyTruncateNote(cmd)
+		fitNotes(result)
+		if replace, _ := cmd.Flags().GetBool("replace-range"); replace {
+			deleted, imported, skipped, err := importer.ReplaceRange(db, result.Sessions)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Deleted %d session(s) in the imported range\n", deleted)
+			printImport(result, imported, skipped)
+			return nil
+		}
+		return importSessions(result)
+	},
+}
+
+// openImport opens path for reading, or standard input for "-"
+func openImport(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// fitNotes holds imported notes to the same length limit as new sessions,
+// listing the sessions whose note is rejected as invalid
+func fitNotes(result *importer.Result) {
+	result.Check(func(s *storage.WorkSession) error {
+		var err error
+		s.Note, err = trackerService.FitNote(s.Note)
+		return err
+	})
+}
+
+// importSessions inserts the parsed sessions and reports the counts, with
+// each invalid row
+func importSessions(result *importer.Result) error {
+	imported, skipped, err := importer.Import(db, result.Sessions)
+	if err != nil {
+		return err
+	}
+	printImport(result, imported, skipped)
+	return nil
+}
+
+// printImport reports the import counts and each invalid row
+func printImport(result *importer.Result, imported, skipped int) {
+	fmt.Printf("Imported %d session(s), skipped %d already present, %d invalid row(s)\n",
+		imported, skipped, len(result.Invalid))
+	for _, invalid := range result.Invalid {
+		fmt.Printf("  %s\n", invalid)
+	}
+}
+
+func init() {
+	importCmd.AddCommand(importCSVCmd)
+	importCmd.AddCommand(importJSONCmd)
+	importJSONCmd.Flags().Bool("replace-range", false, "Delete the sessions on the days the file covers before importing")
+	for _, cmd := range []*cobra.Command{importCSVCmd, importJSONCmd} {
+		cmd.Flags().Bool("truncate-note", false, "Shorten notes over max_note_length instead of skipping the session")
+	}
+}
@@ -1,24 +1,27 @@
 package main
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/kairos/internal/ai"
-	"github.com/kairos/internal/archive"
 	"github.com/kairos/internal/config"
+	"github.com/kairos/internal/hooks"
+	"github.com/kairos/internal/logger"
 	"github.com/kairos/internal/storage"
 	"github.com/kairos/internal/tracker"
 	"github.com/spf13/cobra"
 )
 
 var (
+	logLevel       string
 	cfg            *config.Config
 	db             *storage.Database
 	trackerService *tracker.Tracker
 	aiService      *ai.AIService
 	dataQuerier    *ai.DataQuerier
+	hookRunner     = hooks.New(nil)
 )
 
 var rootCmd = &cobra.Command{
@@ -26,29 +29,58 @@ var rootCmd = &cobra.Command{
 	Short: "AI-powered time tracking with insights",
 	Long:  `Kairos helps you track your working hours and provides AI-powered insights about your schedule.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		var err error
-		cfg, err = config.Load()
-		if err != nil {
+		if err := logger.SetLevel(logLevel); err != nil {
 			return err
 		}
-		db, err = storage.New(cfg.DatabasePath, cfg.GetLocation())
-		if err != nil {
+
+		if err := openStorage(); err != nil {
 			return err
 		}
 		trackerService = tracker.NewWithLocation(db, cfg.WeeklyGoal, cfg.GetLocation())
+		trackerService.SetIncludeActive(cfg.IncludeActive)
+		trackerService.SetWeekNumbering(cfg.WeekNumbering)
+		trackerService.SetWeekStart(cfg.WeekStartsOn)
+		trackerService.SetRoundingMinutes(cfg.RoundingMinutes)
+		trackerService.SetSplitAtMidnight(cfg.SplitAtMidnight)
+		if start, err := time.ParseInLocation("2006-01-02", cfg.FlexStartDate, cfg.GetLocation()); err == nil {
+			trackerService.SetFlexStart(start)
+		}
+		rules := cfg.Rules()
+		holidays, err := db.ListHolidays()
+		if err != nil {
+			return err
+		}
+		for _, h := range holidays {
+			rules.AddHoliday(h.Date)
+		}
+		trackerService.SetRules(rules)
+		trackerService.SetStaleSessionHours(cfg.StaleSessionHours)
+		trackerService.SetMaxNoteLength(cfg.MaxNoteLength)
+		trackerService.SetAutoClockoutMinutes(cfg.AutoClockoutMinutes)
+		trackerService.SetHooks(configHooks())
+		autoClockout()
+		if cmd.Annotations[lightAnnotation] != "" {
+			return nil
+		}
 		aiService = ai.NewAIService(cfg)
-		aiService.Initialize()
+		if err := aiService.Initialize(); err != nil {
+			logger.Debug("AI provider not initialized", "err", err)
+		}
 		historyPath := filepath.Join(filepath.Dir(cfg.DatabasePath), "history")
 		dataQuerier = ai.NewDataQuerierWithHistory(db, trackerService, historyPath)
+		dataQuerier.SetHistoryMonths(cfg.HistoryContextMonths)
 
 		if cfg.AutoArchive {
 			// Auto-archive past months (silent, non-blocking)
 			go func() {
 				historyPath := filepath.Join(filepath.Dir(cfg.DatabasePath), "history")
-				archiver := archive.New(db, historyPath, trackerService.WeeklyGoal())
-				archived, _ := archiver.AutoArchivePastMonths()
+				archiver := newArchiver(historyPath)
+				archived, err := archiver.AutoArchivePastMonths()
+				if err != nil {
+					logger.Warn("auto-archive failed", "err", err)
+				}
 				if len(archived) > 0 {
-					fmt.Printf("Auto-archived %d month(s) to %s\n", len(archived), historyPath)
+					logger.Info("auto-archived past months", "count", len(archived), "path", historyPath)
 				}
 			}()
 		}
@@ -63,6 +95,23 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// openStorage loads the config and opens the database, and nothing more:
+// no auto clock-out, hooks, AI or archiving, so shell completion can use it
+func openStorage() error {
+	var err error
+	cfg, err = config.Load()
+	if err != nil {
+		return err
+	}
+	db, err = storage.NewWithBusyTimeout(cfg.DatabasePath, cfg.GetLocation(), time.Duration(cfg.BusyTimeout)*time.Millisecond)
+	return err
+}
+
+// configHooks returns the configured hook commands for a tracker
+func configHooks() tracker.Hooks {
+	return tracker.Hooks{Runner: hookRunner, PreClockin: cfg.PreClockin, PostClockout: cfg.PostClockout}
+}
+
 func init() {
 	rootCmd.AddCommand(clockinCmd)
 	rootCmd.AddCommand(clockoutCmd)
@@ -83,6 +132,24 @@ func init() {
 	rootCmd.AddCommand(exportCmd)
 	rootCmd.AddCommand(rangeCmd)
 	rootCmd.AddCommand(setupCmd)
+	rootCmd.AddCommand(templateCmd)
+	rootCmd.AddCommand(holidayCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(flexCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(migrateTzCmd)
+	rootCmd.AddCommand(projectsCmd)
+	rootCmd.AddCommand(summaryCmd)
+	rootCmd.AddCommand(digestCmd)
+	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(backupCmd)
+	rootCmd.AddCommand(restoreCmd)
+
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "Diagnostics level on stderr: debug, info, warn, error (env "+logger.EnvVar+")")
 
 	// Enable completion for all commands
 	rootCmd.CompletionOptions.HiddenDefaultCmd = true
@@ -6,13 +6,20 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/kairos/internal/mcp"
+	"github.com/kairos/internal/mcp/core"
 	"github.com/spf13/cobra"
 )
 
-var mcpPort int
+var (
+	mcpPort           int
+	mcpMaxConcurrency int
+	mcpTransport      string
+	mcpToken          string
+)
 
 var mcpCmd = &cobra.Command{
 	Use:   "mcp [command]",
@@ -31,7 +38,7 @@ Examples:
   kairos mcp start           # Start server on default port 8765
   kairos mcp start -p 9000   # Start on custom port
   kairos mcp tools           # List available tools
-  kairos mcp query think     # Query a tool directly
+  kairos mcp query think     # Query a tool directly (--raw-mcp for the wire format)
   kairos mcp register        # Print MCP config for AI clients
 `,
 	Aliases: []string{"server"},
@@ -44,6 +51,10 @@ var mcpStartCmd = &cobra.Command{
 The server will run until interrupted (Ctrl+C).
 
 AI assistants can connect to: http://localhost:8765/mcp
+or, over Server-Sent Events:   http://localhost:8765/sse
+
+With --token (or MCPToken in the config) clients must send
+"Authorization: Bearer <token>"; 'kairos mcp register' includes the header.
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if mcpPort == 0 {
@@ -53,9 +64,15 @@ AI assistants can connect to: http://localhost:8765/mcp
 		fmt.Printf("Kairos MCP Server v1.0\n")
 		fmt.Printf("========================\n")
 		fmt.Printf("Port: http://localhost:%d/mcp\n", mcpPort)
+		fmt.Printf("SSE:  http://localhost:%d/sse\n", mcpPort)
+		fmt.Printf("Max concurrent tool calls: %d\n", mcpMaxConcurrency)
+		token := resolveMCPToken()
+		if token != "" {
+			fmt.Printf("Auth: bearer token required\n")
+		}
 		fmt.Printf("Press Ctrl+C to stop\n\n")
 
-		return mcp.RunServer(db, aiService, mcpPort)
+		return mcp.RunServer(db, trackerService, aiService, dataQuerier, mcpPort, mcpMaxConcurrency, token)
 	},
 }
 
@@ -65,7 +82,7 @@ var mcpToolsCmd = &cobra.Command{
 	Long: `List all available MCP tools with their descriptions and parameters.
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		server := mcp.NewServer(db, aiService, 0)
+		server := mcp.NewServer(db, trackerService, aiService, 0)
 
 		fmt.Println("Available MCP Tools:")
 		fmt.Println("====================")
@@ -74,16 +91,14 @@ var mcpToolsCmd = &cobra.Command{
 		for _, tool := range tools {
 			fmt.Printf("  %s\n", tool.Name)
 			fmt.Printf("    Description: %s\n", tool.Description)
-			if params, ok := tool.Parameters["properties"].(map[string]interface{}); ok {
+			if params := toolProperties(tool); len(params) > 0 {
 				fmt.Printf("    Parameters:\n")
-				for name, param := range params {
-					if p, ok := param.(map[string]interface{}); ok {
-						enum := ""
-						if e, ok := p["enum"]; ok {
-							enum = fmt.Sprintf(" (one of: %v)", e)
-						}
-						fmt.Printf("      - %s: %s%s\n", name, p["description"], enum)
+				for name, p := range params {
+					enum := ""
+					if e, ok := p["enum"]; ok {
+						enum = fmt.Sprintf(" (one of: %v)", e)
 					}
+					fmt.Printf("      - %s: %s%s\n", name, p["description"], enum)
 				}
 			}
 			fmt.Println()
@@ -98,38 +113,58 @@ var mcpQueryCmd = &cobra.Command{
 	Long: `Query an MCP tool directly from the command line.
 Useful for testing or quick lookups.
 
+Arguments are key=value pairs. Values for array parameters are split on
+commas; --json passes a whole arguments object, which key=value pairs then
+override. Every handler registered for the tool runs, as for a client.
+
 Examples:
   kairos mcp query consciousness aspect=current
   kairos mcp query think question="Should I take a break?" analysis_type=productivity
   kairos mcp query persist action=list
+  kairos mcp query persist action=store key=x value=y tags=a,b,c
+  kairos mcp query persist --json '{"action":"search","query":"deadline","limit":5}'
+  kairos mcp query consciousness --raw-mcp   # Show the tools/call response a client receives
 `,
 	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		toolName := args[0]
 
-		server := mcp.NewServer(db, aiService, 0)
-		_, exists := server.ToolRegistry.Get(toolName)
-		if !exists {
-			return fmt.Errorf("unknown tool: %s", toolName)
-		}
+		server := mcp.NewServer(db, trackerService, aiService, 0)
+		server.SetDataQuerier(dataQuerier)
 
-		// Parse remaining args as key=value pairs
 		toolArgs := make(map[string]interface{})
+		if raw, _ := cmd.Flags().GetString("json"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &toolArgs); err != nil {
+				return fmt.Errorf("invalid --json arguments: %w", err)
+			}
+		}
+
+		// Parse remaining args as key=value pairs, typed by the tool's schema
+		tool, _ := server.ToolRegistry.Get(toolName)
+		properties := toolProperties(tool)
 		for _, arg := range args[1:] {
 			parts := splitOnce(arg, "=")
 			if len(parts) == 2 {
-				toolArgs[parts[0]] = parseValue(parts[1])
+				toolArgs[parts[0]] = parseValue(parts[1], properties[parts[0]])
 			}
 		}
 
-		// Execute tool
-		handlers := server.Hooks[toolName]
-		if len(handlers) == 0 {
-			return fmt.Errorf("no handler for tool: %s", toolName)
+		// Route through the HTTP dispatch path so the output matches the wire format
+		if rawMCP, _ := cmd.Flags().GetBool("raw-mcp"); rawMCP {
+			response := server.HandleRequest(context.Background(), core.MCPRequest{
+				Method: "tools/call",
+				Params: map[string]interface{}{
+					"name":      toolName,
+					"arguments": toolArgs,
+				},
+			})
+			data, _ := json.MarshalIndent(response, "", "  ")
+			fmt.Println(string(data))
+			return nil
 		}
 
-		ctx := context.Background()
-		result, err := handlers[0](ctx, toolArgs)
+		// Execute tool; CallTool runs every registered handler like tools/call
+		result, err := server.CallTool(context.Background(), toolName, toolArgs)
 		if err != nil {
 			return err
 		}
@@ -146,6 +181,10 @@ var mcpRegisterCmd = &cobra.Command{
 	Short: "Print MCP configuration for AI clients",
 	Long: `Print the MCP server configuration in JSON format.
 Use this output to configure AI assistants like Claude or Cursor.
+
+Examples:
+  kairos mcp register                   # POST requests to /mcp
+  kairos mcp register --transport sse   # Event stream at /sse (e.g. Claude Desktop)
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		port := mcpPort
@@ -153,12 +192,26 @@ Use this output to configure AI assistants like Claude or Cursor.
 			port = 8765
 		}
 
+		var path string
+		switch mcpTransport {
+		case "http":
+			path = "/mcp"
+		case "sse":
+			path = "/sse"
+		default:
+			return fmt.Errorf("unknown transport: %s (use http or sse)", mcpTransport)
+		}
+
+		server := map[string]interface{}{
+			"url":       fmt.Sprintf("http://localhost:%d%s", port, path),
+			"transport": mcpTransport,
+		}
+		if token := resolveMCPToken(); token != "" {
+			server["headers"] = map[string]string{"Authorization": "Bearer " + token}
+		}
 		config := map[string]interface{}{
 			"mcpServers": map[string]interface{}{
-				"kairos": map[string]interface{}{
-					"url": fmt.Sprintf("http://localhost:%d/mcp", port),
-					"transport": "http",
-				},
+				"kairos": server,
 			},
 		}
 
@@ -214,12 +267,27 @@ func init() {
 	mcpCmd.AddCommand(mcpStatusCmd)
 
 	mcpStartCmd.Flags().IntVarP(&mcpPort, "port", "p", 8765, "Port for MCP server")
+	mcpStartCmd.Flags().IntVar(&mcpMaxConcurrency, "max-concurrency", 4, "Maximum tool calls run at once; extra calls queue (0 = unlimited)")
 	mcpRegisterCmd.Flags().IntVarP(&mcpPort, "port", "p", 8765, "Port for MCP server")
+	mcpRegisterCmd.Flags().StringVarP(&mcpTransport, "transport", "t", "http", "Transport for the client config: http or sse")
+	mcpStartCmd.Flags().StringVar(&mcpToken, "token", "", "Require this bearer token on /mcp and /sse (default: MCPToken from config)")
+	mcpRegisterCmd.Flags().StringVar(&mcpToken, "token", "", "Bearer token to put in the client config (default: MCPToken from config)")
+	mcpQueryCmd.Flags().Bool("raw-mcp", false, "Print the full tools/call response envelope")
+	mcpQueryCmd.Flags().String("json", "", "Tool arguments as a JSON object")
 
 	rootCmd.AddCommand(mcpCmd)
 }
 
 // Helper functions
+
+// resolveMCPToken is the --token flag, or the configured MCPToken without it
+func resolveMCPToken() string {
+	if mcpToken != "" {
+		return mcpToken
+	}
+	return cfg.MCPToken
+}
+
 func splitOnce(s, sep string) []string {
 	for i := 0; i < len(s); i++ {
 		if s[i] == sep[0] {
@@ -229,7 +297,44 @@ func splitOnce(s, sep string) []string {
 	return []string{s}
 }
 
-func parseValue(s string) interface{} {
+// toolProperties returns a tool's parameter schemas by name. Tools built
+// with core.ToolParameters hold them as map[string]map[string]interface{};
+// a schema decoded from JSON holds map[string]interface{}.
+func toolProperties(tool core.Tool) map[string]map[string]interface{} {
+	switch props := tool.Parameters["properties"].(type) {
+	case map[string]map[string]interface{}:
+		return props
+	case map[string]interface{}:
+		out := make(map[string]map[string]interface{}, len(props))
+		for name, param := range props {
+			if p, ok := param.(map[string]interface{}); ok {
+				out[name] = p
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// parseValue converts a command-line value to the type its parameter
+// schema declares: array values split on commas (each element typed by the
+// schema's items), strings stay as given. Without a schema, booleans and
+// numbers are recognized.
+func parseValue(s string, param map[string]interface{}) interface{} {
+	switch param["type"] {
+	case "array":
+		items, _ := param["items"].(map[string]interface{})
+		values := []interface{}{}
+		for _, part := range strings.Split(s, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, parseValue(part, items))
+			}
+		}
+		return values
+	case "string":
+		return s
+	}
+
 	// Try bool
 	if s == "true" {
 		return true
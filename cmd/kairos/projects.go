@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var projectsCmd = &cobra.Command{
+	Use:     "projects",
+	Aliases: []string{"proj"},
+	Short:   "Show hours per project",
+	Long: `Show this week's completed hours per project (set with 'clockin --project'
+or 'edit --project'). --all lists every project with all-time totals.
+Rename a project on all its sessions with 'kairos projects rename <old> <new>'.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			projects, err := trackerService.ListProjects()
+			if err != nil {
+				return err
+			}
+			if len(projects) == 0 {
+				fmt.Println("No projects yet. Start one with: kairos clockin --project <name>")
+				return nil
+			}
+
+			fmt.Println("Projects:")
+			for _, p := range projects {
+				fmt.Printf("  %s: %sh (%d sessions)\n", p.Name, formatHours(p.Hours), p.Sessions)
+			}
+			return nil
+		}
+
+		week, err := trackerService.GetWeeklyProgress()
+		if err != nil {
+			return err
+		}
+		breakdown, err := trackerService.GetProjectBreakdown(week.WeekStart, week.WeekEnd)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Projects %s - %s:\n", week.WeekStart.Format("Jan 2"), week.WeekEnd.Format("Jan 2"))
+		if len(breakdown) == 0 {
+			fmt.Println("  No completed sessions this week")
+			return nil
+		}
+		for _, p := range breakdown {
+			name := p.Name
+			if name == "" {
+				name = "(no project)"
+			}
+			fmt.Printf("  %s: %sh (%d sessions)\n", name, formatHours(p.Hours), p.Sessions)
+		}
+		return nil
+	},
+}
+
+var projectsRenameCmd = &cobra.Command{
+	Use:               "rename <old> <new>",
+	Short:             "Rename a project on all its sessions",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeProjectNames,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if args[1] == "" {
+			return fmt.Errorf("new project name cannot be empty")
+		}
+		changed, err := db.RenameProject(args[0], args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Renamed project %s -> %s (%d sessions)\n", args[0], args[1], changed)
+		return nil
+	},
+}
+
+// completeProjectNames offers known project names for shell completion
+func completeProjectNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 && cmd.Name() == "rename" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if err := openStorage(); err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	defer db.Close()
+
+	names, err := db.ProjectNames()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	projectsCmd.AddCommand(projectsRenameCmd)
+	projectsCmd.Flags().Bool("all", false, "List every project with all-time totals")
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kairos/internal/archive"
+	"github.com/kairos/internal/storage"
+	"github.com/kairos/internal/tracker"
+	"github.com/kairos/internal/visualization"
+	"github.com/spf13/cobra"
+)
+
+// reportTopNotes is how many of the most-logged notes a report lists
+const reportTopNotes = 5
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Combined week, month or quarter report",
+	Long: `Print a review combining progress, top notes, schedule averages and (for
+month and quarter) the trend against archived months.
+
+Examples:
+  kairos report                        # This week
+  kairos report --period month         # This month with 3-month trend
+  kairos report --period quarter -o q.html
+  kairos report --all-time             # Everything tracked, archives included
+  kairos report --split                # Night shifts split at midnight
+
+Given a date, last-week, last-month or --start/--end, report shows that range
+like kairos range, which it used to be an alias of.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 || cmd.Flags().Changed("start") || cmd.Flags().Changed("end") {
+			return rangeCmd.RunE(cmd, args)
+		}
+
+		period, _ := cmd.Flags().GetString("period")
+		output, _ := cmd.Flags().GetString("output")
+		if allTime, _ := cmd.Flags().GetBool("all-time"); allTime {
+			if cmd.Flags().Changed("period") {
+				return fmt.Errorf("--all-time can't be combined with --period")
+			}
+			period = "all"
+		}
+		if split, _ := cmd.Flags().GetBool("split"); split {
+			trackerService.SetSplitAtMidnight(true)
+		}
+
+		data, err := buildReport(period)
+		if err != nil {
+			return err
+		}
+
+		if output == "" {
+			printReport(data)
+			return nil
+		}
+
+		if err := os.WriteFile(output, []byte(reportHTML(data)), 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Report written to %s\n", output)
+		return nil
+	},
+}
+
+type noteCount struct {
+	Note  string
+	Hours float64
+	Count int
+}
+
+type reportData struct {
+	Period     string
+	Title      string
+	Start      time.Time
+	End        time.Time
+	TotalHours float64
+	Goal       float64
+	DaysWorked int
+	Sessions   []storage.WorkSession
+	Schedule   tracker.ScheduleStats
+	TopNotes   []noteCount
+	Trend      []archive.ArchivedMonth
+	Chart      string
+}
+
+func buildReport(period string) (*reportData, error) {
+	now := trackerService.Now()
+	data := &reportData{Period: period, End: now}
+	trendMonths := 0
+
+	switch period {
+	case "week":
+		progress, err := trackerService.GetWeeklyProgress()
+		if err != nil {
+			return nil, err
+		}
+		data.Start = progress.WeekStart
+		data.Goal = progress.Goal
+		data.Title = fmt.Sprintf("Week %s - %s", progress.WeekStart.Format("Jan 2"), progress.WeekEnd.Format("Jan 2, 2006"))
+		data.Chart = reportVisualizer().GenerateWeekSVG(progress)
+	case "month":
+		progress, err := trackerService.GetMonthlyProgress()
+		if err != nil {
+			return nil, err
+		}
+		data.Start = progress.Month
+		data.Goal = trackerService.MonthlyGoal(progress.Month)
+		data.Title = progress.Month.Format("January 2006")
+		data.Chart = reportVisualizer().GenerateMonthSVG(progress)
+		trendMonths = 3
+	case "quarter":
+		quarterMonth := time.Month((int(now.Month())-1)/3*3 + 1)
+		data.Start = time.Date(now.Year(), quarterMonth, 1, 0, 0, 0, 0, now.Location())
+		for m := 0; m < 3; m++ {
+			data.Goal += trackerService.MonthlyGoal(data.Start.AddDate(0, m, 0))
+		}
+		data.Title = fmt.Sprintf("Q%d %d", (int(quarterMonth)-1)/3+1, now.Year())
+		trendMonths = 6
+	case "all":
+		start, _, err := allTimeRange()
+		if err != nil {
+			return nil, err
+		}
+		data.Start = start
+		data.Title = "All time since " + start.Format("Jan 2, 2006")
+	default:
+		return nil, fmt.Errorf("unknown period: %s (use: week, month, or quarter)", period)
+	}
+
+	var sessions []storage.WorkSession
+	var err error
+	if period == "all" {
+		// Archived months count too; the goal is the weekly goal per week spanned
+		sessions, err = dataQuerier.SessionsWithArchive(data.Start, data.End)
+		if err != nil {
+			return nil, err
+		}
+		sessions = trackerService.SessionsOnDays(sessions, data.Start, data.End)
+		weeks, err := trackerService.OvertimeFromSessions(sessions, data.Start, data.End)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range weeks {
+			data.Goal += w.Goal
+		}
+	} else if sessions, err = trackerService.GetSessionsInRange(data.Start, data.End); err != nil {
+		return nil, err
+	}
+	data.Sessions = sessions
+
+	days := make(map[string]bool)
+	notes := make(map[string]*noteCount)
+	for _, s := range sessions {
+		hours, complete := sessionHours(s)
+		if !complete {
+			continue
+		}
+		data.TotalHours += hours
+		days[s.Date.Format("2006-01-02")] = true
+		if s.Note != "" {
+			nc, ok := notes[s.Note]
+			if !ok {
+				nc = &noteCount{Note: s.Note}
+				notes[s.Note] = nc
+			}
+			nc.Hours += hours
+			nc.Count++
+		}
+	}
+	data.DaysWorked = len(days)
+	data.Schedule = tracker.ComputeScheduleStats(sessions)
+
+	for _, nc := range notes {
+		data.TopNotes = append(data.TopNotes, *nc)
+	}
+	sort.Slice(data.TopNotes, func(i, j int) bool {
+		if data.TopNotes[i].Hours != data.TopNotes[j].Hours {
+			return data.TopNotes[i].Hours > data.TopNotes[j].Hours
+		}
+		return data.TopNotes[i].Note < data.TopNotes[j].Note
+	})
+	if len(data.TopNotes) > reportTopNotes {
+		data.TopNotes = data.TopNotes[:reportTopNotes]
+	}
+
+	if trendMonths > 0 {
+		historyPath := filepath.Join(filepath.Dir(cfg.DatabasePath), "history")
+		trend, err := archive.TrailingMonths(historyPath, data.Start, trendMonths)
+		if err != nil {
+			return nil, err
+		}
+		data.Trend = trend
+	}
+
+	return data, nil
+}
+
+func reportVisualizer() *visualization.Visualizer {
+	v := visualization.New()
+	v.SetDecimalPlaces(cfg.DecimalPlaces)
+	v.SetWeeklyGoal(trackerService.WeeklyGoal())
+	v.SetWeekStart(cfg.WeekStartsOn)
+	v.SetColorBuckets(visualization.ColorBuckets{
+		MinHours: cfg.ChartMinHours,
+		LongDay:  cfg.ChartLongDayHours,
+		Overwork: cfg.ChartOverworkHours,
+	})
+	return v
+}
+
+// trendAverage returns the mean monthly hours across the trend months
+func (r *reportData) trendAverage() float64 {
+	if len(r.Trend) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, m := range r.Trend {
+		total += m.TotalHours
+	}
+	return total / float64(len(r.Trend))
+}
+
+func printReport(r *reportData) {
+	fmt.Printf("Report: %s | Total: %s/%sh | Days worked: %d | Sessions: %d\n",
+		r.Title, formatHours(r.TotalHours), formatHours(r.Goal), r.DaysWorked, len(r.Sessions))
+
+	if r.Schedule.DaysCounted > 0 {
+		fmt.Printf("Schedule: avg %s-%s | %sh/day\n",
+			tracker.FormatClock(r.Schedule.AvgStart), tracker.FormatClock(r.Schedule.AvgEnd), formatHours(r.Schedule.AvgDayHours))
+	}
+
+	if len(r.TopNotes) > 0 {
+		fmt.Println("Top notes:")
+		for _, nc := range r.TopNotes {
+			fmt.Printf("  %s: %sh (%d sessions)\n", nc.Note, formatHours(nc.Hours), nc.Count)
+		}
+	}
+
+	if len(r.Trend) > 0 {
+		fmt.Printf("Trend (avg %sh over %d archived months):\n", formatHours(r.trendAverage()), len(r.Trend))
+		for _, m := range r.Trend {
+			fmt.Printf("  %s: %sh (%d days)\n", m.Month.Format("Jan 2006"), formatHours(m.TotalHours), m.DaysWorked)
+		}
+	} else if r.Period == "month" || r.Period == "quarter" {
+		fmt.Println("Trend: no archived months. Run 'kairos archive auto' first.")
+	}
+}
+
+func reportHTML(r *reportData) string {
+	var sb strings.Builder
+	sb.WriteString(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Kairos Report - ` + html.EscapeString(r.Title) + `</title>
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, sans-serif; max-width: 800px; margin: 0 auto; padding: 20px; }
+        h1 { color: #333; }
+        .summary { background: #f5f5f5; padding: 20px; border-radius: 8px; margin-bottom: 20px; }
+        table { width: 100%; border-collapse: collapse; margin-bottom: 20px; }
+        th, td { text-align: left; padding: 12px; border-bottom: 1px solid #eee; }
+        th { background: #f9f9f9; }
+        .total { font-weight: bold; font-size: 1.2em; }
+    </style>
+</head>
+<body>
+`)
+	sb.WriteString(fmt.Sprintf("    <h1>Kairos Report: %s</h1>\n", html.EscapeString(r.Title)))
+	sb.WriteString(`    <div class="summary">` + "\n")
+	sb.WriteString(fmt.Sprintf("        <p class=\"total\">Total Hours: %s / %s</p>\n", formatHours(r.TotalHours), formatHours(r.Goal)))
+	sb.WriteString(fmt.Sprintf("        <p>Days worked: %d | Sessions: %d</p>\n", r.DaysWorked, len(r.Sessions)))
+	if r.Schedule.DaysCounted > 0 {
+		sb.WriteString(fmt.Sprintf("        <p>Average day: %s - %s (%s hours)</p>\n",
+			tracker.FormatClock(r.Schedule.AvgStart), tracker.FormatClock(r.Schedule.AvgEnd), formatHours(r.Schedule.AvgDayHours)))
+	}
+	sb.WriteString("    </div>\n")
+
+	if r.Chart != "" {
+		// Inline the SVG without its XML declaration
+		chart := r.Chart
+		if i := strings.Index(chart, "<svg"); i >= 0 {
+			chart = chart[i:]
+		}
+		sb.WriteString(chart + "\n")
+	}
+
+	if len(r.TopNotes) > 0 {
+		sb.WriteString("    <h2>Top Notes</h2>\n    <table>\n        <tr><th>Note</th><th>Hours</th><th>Sessions</th></tr>\n")
+		for _, nc := range r.TopNotes {
+			sb.WriteString(fmt.Sprintf("        <tr><td>%s</td><td>%s</td><td>%d</td></tr>\n", html.EscapeString(nc.Note), formatHours(nc.Hours), nc.Count))
+		}
+		sb.WriteString("    </table>\n")
+	}
+
+	if len(r.Trend) > 0 {
+		sb.WriteString(fmt.Sprintf("    <h2>Trend (average %s hours)</h2>\n    <table>\n        <tr><th>Month</th><th>Hours</th><th>Days</th></tr>\n", formatHours(r.trendAverage())))
+		for _, m := range r.Trend {
+			sb.WriteString(fmt.Sprintf("        <tr><td>%s</td><td>%s</td><td>%d</td></tr>\n", m.Month.Format("January 2006"), formatHours(m.TotalHours), m.DaysWorked))
+		}
+		sb.WriteString("    </table>\n")
+	}
+
+	sb.WriteString(`</body>
+</html>`)
+	return sb.String()
+}
+
+func init() {
+	reportCmd.Flags().StringP("period", "p", "week", "Report period: week, month, quarter")
+	reportCmd.Flags().StringP("output", "o", "", "Write an HTML report to this file")
+	reportCmd.Flags().Bool("all-time", false, "Report on everything tracked, including archived months")
+	reportCmd.Flags().Bool("split", false, "Split sessions at midnight so each day gets its own hours (default: config split_at_midnight)")
+	addRangeFlags(reportCmd)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:         "search <query>",
+	Short:       "Find sessions by note text",
+	Annotations: map[string]string{lightAnnotation: "true"},
+	Long: `List sessions whose note contains every word of the query (case-insensitive),
+newest first. Quote a phrase to match the words together. Only sessions still
+in the database are searched; use 'kairos history search' for archived months.
+
+Examples:
+  kairos search migration
+  kairos search "db migration" --project acme
+  kairos search oncall -s 2025-01-01 -e 2025-03-31`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// An argument with spaces was quoted on the command line: keep it a phrase
+		terms := make([]string, len(args))
+		for i, arg := range args {
+			if strings.ContainsAny(arg, " \t") {
+				arg = `"` + arg + `"`
+			}
+			terms[i] = arg
+		}
+
+		start, err := optionalDateFlag(cmd, "start")
+		if err != nil {
+			return err
+		}
+		end, err := optionalDateFlag(cmd, "end")
+		if err != nil {
+			return err
+		}
+
+		sessions, err := db.SearchSessions(strings.Join(terms, " "), start, end)
+		if err != nil {
+			return err
+		}
+
+		project, _ := cmd.Flags().GetString("project")
+		found := 0
+		for _, s := range sessions {
+			if project != "" && !strings.EqualFold(s.Project, project) {
+				continue
+			}
+			found++
+			duration := "active"
+			if hours, complete := sessionHours(s); complete {
+				duration = formatHours(hours) + "h"
+			}
+			tag := ""
+			if s.Project != "" {
+				tag = " [" + s.Project + "]"
+			}
+			fmt.Printf("%s %s %s (%s)%s - %s\n", s.ID[:8], s.Date.Format("2006-01-02"), s.StartTime.Format("15:04"), duration, tag, s.Note)
+		}
+		if found == 0 {
+			fmt.Println("No sessions matched.")
+		}
+		return nil
+	},
+}
+
+// optionalDateFlag parses a YYYY-MM-DD flag, returning the zero time when it
+// is unset
+func optionalDateFlag(cmd *cobra.Command, name string) (time.Time, error) {
+	value, _ := cmd.Flags().GetString(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, cfg.GetLocation())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s date: %s (use YYYY-MM-DD)", name, value)
+	}
+	return t, nil
+}
+
+func init() {
+	searchCmd.Flags().StringP("project", "p", "", "Only sessions for this project")
+	searchCmd.RegisterFlagCompletionFunc("project", completeProjectNames)
+	searchCmd.Flags().StringP("start", "s", "", "Only sessions from this date (YYYY-MM-DD)")
+	searchCmd.Flags().StringP("end", "e", "", "Only sessions up to this date (YYYY-MM-DD)")
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/kairos/internal/storage"
+	"github.com/kairos/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Work pattern statistics",
+	Long: `Statistics over a date range (default: last 30 days, or everything with --all-time).
+Without a subcommand, prints averages, the median session, work-day streaks,
+the most productive weekday and the earliest and latest clock-in.
+Every subcommand prints a readable summary by default, or structured values with --json or --csv.
+
+Examples:
+  kairos stats
+  kairos stats --since 2024-01-01
+  kairos stats schedule --all-time`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		start, end, sessions, err := statsSessions(cmd)
+		if err != nil {
+			return err
+		}
+		stats := trackerService.StatsFromSessions(sessions, start, end)
+
+		type statsExport struct {
+			Start                  string  `json:"start"`
+			End                    string  `json:"end"`
+			DaysWorked             int     `json:"days_worked"`
+			Sessions               int     `json:"sessions"`
+			TotalHours             float64 `json:"total_hours"`
+			AvgDailyHours          float64 `json:"avg_daily_hours"`
+			MedianSessionHours     float64 `json:"median_session_hours"`
+			LongestStreak          int     `json:"longest_streak"`
+			CurrentStreak          int     `json:"current_streak"`
+			MostProductiveDay      string  `json:"most_productive_day,omitempty"`
+			MostProductiveAvgHours float64 `json:"most_productive_avg_hours"`
+			EarliestClockIn        string  `json:"earliest_clock_in,omitempty"`
+			LatestClockIn          string  `json:"latest_clock_in,omitempty"`
+		}
+		export := statsExport{
+			Start:                  start.Format("2006-01-02"),
+			End:                    end.Format("2006-01-02"),
+			DaysWorked:             stats.DaysWorked,
+			Sessions:               stats.Sessions,
+			TotalHours:             stats.TotalHours,
+			AvgDailyHours:          stats.AvgDailyHours,
+			MedianSessionHours:     stats.MedianSessionHours,
+			LongestStreak:          stats.LongestStreak,
+			CurrentStreak:          stats.CurrentStreak,
+			MostProductiveAvgHours: stats.MostProductiveAvgHours,
+		}
+		if stats.Sessions > 0 {
+			export.MostProductiveDay = stats.MostProductiveDay.String()
+			export.EarliestClockIn = stats.EarliestClockIn.Format("2006-01-02 15:04")
+			export.LatestClockIn = stats.LatestClockIn.Format("2006-01-02 15:04")
+		}
+
+		header := []string{"Start", "End", "Days Worked", "Sessions", "Total Hours", "Avg Daily Hours", "Median Session Hours",
+			"Longest Streak", "Current Streak", "Most Productive Day", "Most Productive Avg Hours", "Earliest Clock-in", "Latest Clock-in"}
+		rows := [][]string{{export.Start, export.End, strconv.Itoa(export.DaysWorked), strconv.Itoa(export.Sessions),
+			formatHours(export.TotalHours), formatHours(export.AvgDailyHours), formatHours(export.MedianSessionHours),
+			strconv.Itoa(export.LongestStreak), strconv.Itoa(export.CurrentStreak), export.MostProductiveDay,
+			formatHours(export.MostProductiveAvgHours), export.EarliestClockIn, export.LatestClockIn}}
+		if done, err := writeStats(cmd, export, header, rows); done || err != nil {
+			return err
+		}
+
+		if stats.Sessions == 0 {
+			fmt.Printf("No completed sessions between %s and %s\n", export.Start, export.End)
+			return nil
+		}
+		fmt.Printf("Stats %s - %s | Days worked: %d | Sessions: %d | Total: %sh\n",
+			start.Format("Jan 2"), end.Format("Jan 2"), stats.DaysWorked, stats.Sessions, formatHours(stats.TotalHours))
+		fmt.Printf("  Avg day: %sh | Median session: %sh\n", formatHours(stats.AvgDailyHours), formatHours(stats.MedianSessionHours))
+		fmt.Printf("  Streak: %d work days (longest %d)\n", stats.CurrentStreak, stats.LongestStreak)
+		fmt.Printf("  Most productive: %s (avg %sh)\n", stats.MostProductiveDay, formatHours(stats.MostProductiveAvgHours))
+		fmt.Printf("  Earliest clock-in: %s | Latest clock-in: %s\n",
+			stats.EarliestClockIn.Format("15:04 (Jan 2)"), stats.LatestClockIn.Format("15:04 (Jan 2)"))
+		return nil
+	},
+}
+
+var statsScheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Average start, end and day length",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		start, end, sessions, err := statsSessions(cmd)
+		if err != nil {
+			return err
+		}
+		stats := tracker.ComputeScheduleStats(sessions)
+
+		type scheduleExport struct {
+			Start       string  `json:"start"`
+			End         string  `json:"end"`
+			DaysCounted int     `json:"days_counted"`
+			AvgStart    string  `json:"avg_start"`
+			AvgEnd      string  `json:"avg_end"`
+			AvgDayHours float64 `json:"avg_day_hours"`
+		}
+		export := scheduleExport{
+			Start:       start.Format("2006-01-02"),
+			End:         end.Format("2006-01-02"),
+			DaysCounted: stats.DaysCounted,
+			AvgDayHours: stats.AvgDayHours,
+		}
+		if stats.DaysCounted > 0 {
+			export.AvgStart = tracker.FormatClock(stats.AvgStart)
+			export.AvgEnd = tracker.FormatClock(stats.AvgEnd)
+		}
+
+		header := []string{"Start", "End", "Days", "Avg Start", "Avg End", "Avg Day Hours"}
+		rows := [][]string{{export.Start, export.End, strconv.Itoa(export.DaysCounted), export.AvgStart, export.AvgEnd, formatHours(export.AvgDayHours)}}
+		if done, err := writeStats(cmd, export, header, rows); done || err != nil {
+			return err
+		}
+
+		if stats.DaysCounted == 0 {
+			fmt.Printf("No completed sessions between %s and %s\n", export.Start, export.End)
+			return nil
+		}
+		fmt.Printf("Schedule %s - %s | Days: %d | Avg start: %s | Avg end: %s | Avg day: %sh\n",
+			start.Format("Jan 2"), end.Format("Jan 2"), stats.DaysCounted, export.AvgStart, export.AvgEnd, formatHours(stats.AvgDayHours))
+		return nil
+	},
+}
+
+var statsWeekdaysCmd = &cobra.Command{
+	Use:   "weekdays",
+	Short: "Hours broken down by day of week",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		start, end, sessions, err := statsSessions(cmd)
+		if err != nil {
+			return err
+		}
+		stats := tracker.ComputeWeekdayStats(sessions)
+
+		type weekdayExport struct {
+			Weekday  string  `json:"weekday"`
+			Days     int     `json:"days"`
+			Hours    float64 `json:"hours"`
+			AvgHours float64 `json:"avg_hours"`
+		}
+		exports := make([]weekdayExport, 0, len(stats))
+		rows := make([][]string, 0, len(stats))
+		for _, s := range stats {
+			exports = append(exports, weekdayExport{Weekday: s.Weekday.String(), Days: s.Days, Hours: s.Hours, AvgHours: s.AvgHours})
+			rows = append(rows, []string{s.Weekday.String(), strconv.Itoa(s.Days), formatHours(s.Hours), formatHours(s.AvgHours)})
+		}
+		if done, err := writeStats(cmd, exports, []string{"Weekday", "Days", "Hours", "Avg Hours"}, rows); done || err != nil {
+			return err
+		}
+
+		fmt.Printf("Weekdays %s - %s:\n", start.Format("Jan 2"), end.Format("Jan 2"))
+		for _, s := range stats {
+			fmt.Printf("  %s: %sh over %d days (avg %sh)\n", s.Weekday.String()[:3], formatHours(s.Hours), s.Days, formatHours(s.AvgHours))
+		}
+		return nil
+	},
+}
+
+// statsRange parses --start/--end, defaulting to the last 30 days like export.
+// --all-time runs from the first tracked day, live or archived, to now.
+func statsRange(cmd *cobra.Command) (time.Time, time.Time, error) {
+	if allTime, _ := cmd.Flags().GetBool("all-time"); allTime {
+		if cmd.Flags().Changed("start") || cmd.Flags().Changed("end") || cmd.Flags().Changed("since") {
+			return time.Time{}, time.Time{}, fmt.Errorf("--all-time can't be combined with --start, --end or --since")
+		}
+		return allTimeRange()
+	}
+
+	startStr, _ := cmd.Flags().GetString("start")
+	endStr, _ := cmd.Flags().GetString("end")
+
+	now := cfg.Now()
+	loc := cfg.GetLocation()
+	startDate := now.AddDate(0, 0, -30)
+	endDate := now
+	if since, _ := cmd.Flags().GetString("since"); since != "" {
+		if startStr != "" {
+			return time.Time{}, time.Time{}, fmt.Errorf("use only one of --since and --start")
+		}
+		t, err := tracker.ParseDateInput(since, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		startDate = t
+	}
+	if startStr != "" {
+		t, err := time.ParseInLocation("2006-01-02", startStr, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start date: %s (use YYYY-MM-DD)", startStr)
+		}
+		startDate = t
+	}
+	if endStr != "" {
+		t, err := time.ParseInLocation("2006-01-02", endStr, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end date: %s (use YYYY-MM-DD)", endStr)
+		}
+		endDate = t
+	}
+	return startDate, endDate, nil
+}
+
+var statsOvertimeCmd = &cobra.Command{
+	Use:   "overtime",
+	Short: "Overtime per week against the weekly goal",
+	Long: `Each week's hours minus the weekly goal, with a running total.
+Weeks are whole weeks from the configured week start that overlap the range.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		start, end, err := statsRange(cmd)
+		if err != nil {
+			return err
+		}
+		var weeks []tracker.WeekOvertime
+		if allTime, _ := cmd.Flags().GetBool("all-time"); allTime {
+			var sessions []storage.WorkSession
+			if sessions, err = dataQuerier.SessionsWithArchive(start, end); err != nil {
+				return err
+			}
+			weeks, err = trackerService.OvertimeFromSessions(sessions, start, end)
+		} else {
+			weeks, err = trackerService.GetOvertime(start, end)
+		}
+		if err != nil {
+			return err
+		}
+
+		type overtimeExport struct {
+			WeekStart    string  `json:"week_start"`
+			WeekEnd      string  `json:"week_end"`
+			Hours        float64 `json:"hours"`
+			Goal         float64 `json:"goal"`
+			Overtime     float64 `json:"overtime"`
+			RunningTotal float64 `json:"running_total"`
+		}
+		exports := make([]overtimeExport, 0, len(weeks))
+		rows := make([][]string, 0, len(weeks))
+		for _, w := range weeks {
+			e := overtimeExport{
+				WeekStart:    w.WeekStart.Format("2006-01-02"),
+				WeekEnd:      w.WeekEnd.Format("2006-01-02"),
+				Hours:        w.Hours,
+				Goal:         w.Goal,
+				Overtime:     w.Overtime,
+				RunningTotal: w.RunningTotal,
+			}
+			exports = append(exports, e)
+			rows = append(rows, []string{e.WeekStart, e.WeekEnd, formatHours(w.Hours), formatHours(w.Goal), formatHours(w.Overtime), formatHours(w.RunningTotal)})
+		}
+		if done, err := writeStats(cmd, exports, []string{"Week Start", "Week End", "Hours", "Goal", "Overtime", "Running Total"}, rows); done || err != nil {
+			return err
+		}
+
+		fmt.Printf("Overtime %s - %s:\n", start.Format("Jan 2"), end.Format("Jan 2"))
+		for _, w := range weeks {
+			fmt.Printf("  %s - %s: %sh / %sh | %sh | total %sh\n",
+				w.WeekStart.Format("Jan 02"), w.WeekEnd.Format("Jan 02"), formatHours(w.Hours), formatHours(w.Goal),
+				signedHours(w.Overtime), signedHours(w.RunningTotal))
+		}
+		if len(weeks) > 0 {
+			fmt.Printf("Total overtime: %sh over %d weeks\n", signedHours(weeks[len(weeks)-1].RunningTotal), len(weeks))
+		}
+		return nil
+	},
+}
+
+// statsSessions loads the sessions for the --start/--end range. With
+// --all-time, months kept only in the archive are read back from it.
+func statsSessions(cmd *cobra.Command) (time.Time, time.Time, []storage.WorkSession, error) {
+	startDate, endDate, err := statsRange(cmd)
+	if err != nil {
+		return time.Time{}, time.Time{}, nil, err
+	}
+	if allTime, _ := cmd.Flags().GetBool("all-time"); allTime {
+		sessions, err := dataQuerier.SessionsWithArchive(startDate, endDate)
+		return startDate, endDate, sessions, err
+	}
+	sessions, err := trackerService.GetSessionsInRange(startDate, endDate)
+	return startDate, endDate, sessions, err
+}
+
+// allTimeRange spans the first tracked day (database or archive) to now
+func allTimeRange() (time.Time, time.Time, error) {
+	now := cfg.Now()
+	first, ok, err := dataQuerier.FirstTrackedDate()
+	if err != nil || !ok {
+		return now, now, err
+	}
+	return first, now, nil
+}
+
+// signedHours formats hours with an explicit + for positive values
+func signedHours(h float64) string {
+	if h > 0 {
+		return "+" + formatHours(h)
+	}
+	return formatHours(h)
+}
+
+// writeStats emits value as JSON or rows as CSV when --json or --csv is set.
+// It reports false when the caller should print its readable summary.
+func writeStats(cmd *cobra.Command, value interface{}, header []string, rows [][]string) (bool, error) {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	asCSV, _ := cmd.Flags().GetBool("csv")
+
+	switch {
+	case asJSON && asCSV:
+		return true, fmt.Errorf("use only one of --json and --csv")
+	case asJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return true, encoder.Encode(value)
+	case asCSV:
+		writer := csv.NewWriter(os.Stdout)
+		writer.Write(header)
+		writer.WriteAll(rows)
+		return true, writer.Error()
+	}
+	return false, nil
+}
+
+func init() {
+	statsCmd.AddCommand(statsScheduleCmd)
+	statsCmd.AddCommand(statsWeekdaysCmd)
+	statsCmd.AddCommand(statsOvertimeCmd)
+
+	statsCmd.PersistentFlags().StringP("start", "s", "", "Start date (YYYY-MM-DD)")
+	statsCmd.PersistentFlags().StringP("end", "e", "", "End date (YYYY-MM-DD)")
+	statsCmd.PersistentFlags().String("since", "", "Start date, up to today (YYYY-MM-DD, \"Jan 2\" or \"1/2\")")
+	statsCmd.PersistentFlags().Bool("all-time", false, "Cover everything tracked, including archived months")
+	statsCmd.PersistentFlags().Bool("json", false, "Output as JSON")
+	statsCmd.PersistentFlags().Bool("csv", false, "Output as CSV")
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// lightAnnotation marks commands that only need config, the database and the
+// tracker; the root pre-run skips AI setup and auto-archiving for them
+const lightAnnotation = "kairos/light"
+
+// defaultSummaryFormat is the --oneline layout, e.g. "⏱ 6.2h today | 28.0/38.5 wk"
+const defaultSummaryFormat = "⏱ {today}h today | {week}/{goal} wk"
+
+var summaryCmd = &cobra.Command{
+	Use:         "summary",
+	Aliases:     []string{"tldr"},
+	Short:       "Compact today/week summary for shell prompts",
+	Annotations: map[string]string{lightAnnotation: "true"},
+	Long: `Print today's and this week's hours in a few words. The running session is
+counted. Skips the AI provider and auto-archive so it is fast enough for a
+shell prompt.
+
+--oneline prints a single line; --format sets its layout with placeholders:
+  {today}      hours today
+  {week}       hours this week
+  {goal}       weekly goal
+  {remaining}  hours left this week
+  {percent}    week % of goal
+  {status}     "in" or "out"
+  {since}      clock-in time or "-"
+Hours always use one decimal place so the output is stable to parse.
+
+Examples:
+  kairos summary --oneline
+  kairos summary --format '{status} {today}h ({percent}%)'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		trackerService.SetIncludeActive(true)
+
+		today, err := trackerService.GetTodayProgress()
+		if err != nil {
+			return err
+		}
+		week, err := trackerService.GetWeeklyProgress()
+		if err != nil {
+			return err
+		}
+		active, err := trackerService.GetActiveSession()
+		if err != nil {
+			return err
+		}
+
+		goal := week.Goal
+		remaining := goal - week.TotalHours
+		if remaining < 0 {
+			remaining = 0
+		}
+		percent := 0
+		if goal > 0 {
+			percent = int(week.TotalHours / goal * 100)
+		}
+		status, since := "out", "-"
+		if active != nil {
+			status, since = "in", active.StartTime.Format("15:04")
+		}
+
+		oneDecimal := func(h float64) string { return strconv.FormatFloat(h, 'f', 1, 64) }
+		values := strings.NewReplacer(
+			"{today}", oneDecimal(today.TotalHours),
+			"{week}", oneDecimal(week.TotalHours),
+			"{goal}", oneDecimal(goal),
+			"{remaining}", oneDecimal(remaining),
+			"{percent}", strconv.Itoa(percent),
+			"{status}", status,
+			"{since}", since,
+		)
+
+		format, _ := cmd.Flags().GetString("format")
+		oneline, _ := cmd.Flags().GetBool("oneline")
+		if oneline || cmd.Flags().Changed("format") {
+			fmt.Println(values.Replace(format))
+			return nil
+		}
+
+		fmt.Println(values.Replace("Today: {today}h | Week: {week}/{goal}h ({percent}%) | {remaining}h left"))
+		if active != nil {
+			fmt.Println(values.Replace("Clocked in since {since}"))
+		} else {
+			fmt.Println("Not clocked in")
+		}
+		return nil
+	},
+}
+
+func init() {
+	summaryCmd.Flags().Bool("oneline", false, "Print a single compact line")
+	summaryCmd.Flags().String("format", defaultSummaryFormat, "Layout for the one-line summary (implies --oneline)")
+}
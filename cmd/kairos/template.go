@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kairos/internal/storage"
+	"github.com/kairos/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+var templateCmd = &cobra.Command{
+	Use:     "template",
+	Aliases: []string{"tpl"},
+	Short:   "Manage session templates for recurring work blocks",
+	Long: `Save recurring work blocks (e.g. a daily standup) as named templates and
+create the session for a day in one command.`,
+}
+
+var templateAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add or replace a template",
+	Long:  `Add a template, e.g.: kairos template add standup --start 09:00 --end 09:30 --note "standup"`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		start, _ := cmd.Flags().GetString("start")
+		end, _ := cmd.Flags().GetString("end")
+		breakMinutes, _ := cmd.Flags().GetInt("break")
+		note, _ := cmd.Flags().GetString("note")
+
+		tpl := &storage.SessionTemplate{
+			Name:         args[0],
+			StartTime:    start,
+			EndTime:      end,
+			BreakMinutes: breakMinutes,
+			Note:         note,
+		}
+		if err := tracker.ValidateTemplate(tpl); err != nil {
+			return err
+		}
+		if err := db.SaveTemplate(tpl); err != nil {
+			return err
+		}
+
+		fmt.Printf("Saved template %s: %s-%s | Break: %dmin\n", tpl.Name, tpl.StartTime, tpl.EndTime, tpl.BreakMinutes)
+		return nil
+	},
+}
+
+var templateApplyCmd = &cobra.Command{
+	Use:   "apply <name> [date]",
+	Short: "Create a session from a template",
+	Long:  `Create the template's session for today or the given date (YYYY-MM-DD). Fails if it would overlap an existing session.`,
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tpl, err := db.GetTemplate(args[0])
+		if err != nil {
+			return err
+		}
+		if tpl == nil {
+			return fmt.Errorf("template not found: %s", args[0])
+		}
+
+		date := cfg.Now()
+		if len(args) > 1 {
+			date, err = tracker.ParseDateInput(args[1], cfg.GetLocation())
+			if err != nil {
+				return err
+			}
+		}
+
+		session, err := trackerService.ApplyTemplate(tpl, date)
+		if err != nil {
+			return err
+		}
+
+		hours, _ := sessionHours(*session)
+		fmt.Printf("Applied %s: %s %s-%s | Duration: %sh | ID: %s\n", tpl.Name,
+			session.StartTime.Format("Jan 2"), session.StartTime.Format("15:04"), session.EndTime.Format("15:04"),
+			formatHours(hours), session.ID[:8])
+		return nil
+	},
+}
+
+var templateListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List templates",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		templates, err := db.ListTemplates()
+		if err != nil {
+			return err
+		}
+
+		if len(templates) == 0 {
+			fmt.Println("No templates. Add one with: kairos template add <name> --start HH:MM --end HH:MM")
+			return nil
+		}
+
+		for _, tpl := range templates {
+			note := ""
+			if tpl.Note != "" {
+				note = " - " + tpl.Note
+			}
+			fmt.Printf("  %s: %s-%s | Break: %dmin%s\n", tpl.Name, tpl.StartTime, tpl.EndTime, tpl.BreakMinutes, note)
+		}
+		return nil
+	},
+}
+
+var templateRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a template",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tpl, err := db.GetTemplate(args[0])
+		if err != nil {
+			return err
+		}
+		if tpl == nil {
+			return fmt.Errorf("template not found: %s", args[0])
+		}
+		if err := db.DeleteTemplate(tpl.Name); err != nil {
+			return err
+		}
+
+		fmt.Printf("Removed template %s\n", tpl.Name)
+		return nil
+	},
+}
+
+func init() {
+	templateCmd.AddCommand(templateAddCmd)
+	templateCmd.AddCommand(templateApplyCmd)
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateRemoveCmd)
+
+	templateAddCmd.Flags().String("start", "", "Start time (HH:MM)")
+	templateAddCmd.Flags().String("end", "", "End time (HH:MM)")
+	templateAddCmd.Flags().IntP("break", "b", 0, "Break time in minutes")
+	templateAddCmd.Flags().StringP("note", "n", "", "Note for created sessions")
+	templateAddCmd.MarkFlagRequired("start")
+	templateAddCmd.MarkFlagRequired("end")
+}
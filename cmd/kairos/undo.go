@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/kairos/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:         "undo",
+	Short:       "Revert the last edit, clock-out or delete",
+	Annotations: map[string]string{lightAnnotation: "true"},
+	Long: fmt.Sprintf(`Revert the most recent change to a session: a deleted session comes back
+with its original ID, and an edited or clocked-out session gets its previous
+times, break and note back. An import with --replace-range is reverted as a
+whole. Run it again to step further back; the last %d
+changes are kept.
+
+Examples:
+  kairos undo
+  kairos undo --list`, storage.UndoLogSize),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if list, _ := cmd.Flags().GetBool("list"); list {
+			entries, err := trackerService.UndoLog()
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				fmt.Println("Nothing to undo")
+				return nil
+			}
+			for _, e := range entries {
+				fmt.Printf("%s %-7s %s\n", e.CreatedAt.In(cfg.GetLocation()).Format("2006-01-02 15:04"), e.Action, describeUndoEntry(e))
+			}
+			return nil
+		}
+
+		entry, err := trackerService.Undo()
+		if err != nil {
+			return err
+		}
+		if entry == nil {
+			fmt.Println("Nothing to undo")
+			return nil
+		}
+		switch entry.Action {
+		case storage.UndoDelete:
+			fmt.Printf("Restored deleted session %s\n", describeUndoSession(entry.Session))
+		case storage.UndoReplace:
+			fmt.Printf("Reverted import: %s\n", describeUndoEntry(*entry))
+		default:
+			fmt.Printf("Reverted session %s\n", describeUndoSession(entry.Session))
+		}
+		return nil
+	},
+}
+
+// describeUndoEntry shows the session an entry brings back, or for a
+// replaced range how many sessions come back and go
+func describeUndoEntry(e storage.UndoEntry) string {
+	if e.Action == storage.UndoReplace {
+		return fmt.Sprintf("%d replaced session(s) restored, %d imported removed", len(e.Replaced), len(e.Imported))
+	}
+	return describeUndoSession(e.Session)
+}
+
+// describeUndoSession shows a session as it will be after the undo
+func describeUndoSession(s storage.WorkSession) string {
+	desc := fmt.Sprintf("%s %s %s", s.ID[:8], s.Date.Format("2006-01-02"), s.StartTime.Format("15:04"))
+	if s.EndTime != nil {
+		desc += "-" + s.EndTime.Format("15:04")
+	} else {
+		desc += " (open)"
+	}
+	if s.BreakMinutes > 0 {
+		desc += fmt.Sprintf(", %dm break", s.BreakMinutes)
+	}
+	if s.Note != "" {
+		desc += " - " + s.Note
+	}
+	return desc
+}
+
+func init() {
+	undoCmd.Flags().BoolP("list", "l", false, "Show the changes that can be undone")
+}
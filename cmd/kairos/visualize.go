@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/kairos/internal/visualization"
 	"github.com/spf13/cobra"
@@ -11,23 +12,41 @@ import (
 var visualizer *visualization.Visualizer
 
 var visualizeCmd = &cobra.Command{
-	Use:   "visualize [week|month|html]",
+	Use:   "visualize [week|month|heatmap|html]",
 	Short: "Generate visual reports",
-	Long:  `Generate SVG or HTML visualizations of your work hours.`,
-	Args:  cobra.MinimumNArgs(1),
+	Long: `Generate SVG or HTML visualizations of your work hours.
+
+heatmap draws a calendar of daily hours, by default for the current month;
+use --start and --end (YYYY-MM-DD) for another range such as a quarter.`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(c *cobra.Command, args []string) error {
 		visualizer = visualization.New()
+		visualizer.SetDecimalPlaces(cfg.DecimalPlaces)
+		visualizer.SetWeeklyGoal(trackerService.WeeklyGoal())
+		visualizer.SetWeekStart(cfg.WeekStartsOn)
+
+		buckets := visualization.ColorBuckets{
+			MinHours: cfg.ChartMinHours,
+			LongDay:  cfg.ChartLongDayHours,
+			Overwork: cfg.ChartOverworkHours,
+		}
+		if c.Flags().Changed("min-hours") {
+			buckets.MinHours, _ = c.Flags().GetFloat64("min-hours")
+		}
+		visualizer.SetColorBuckets(buckets)
 
 		switch args[0] {
 		case "week":
 			return generateWeekSVG()
 		case "month":
 			return generateMonthSVG()
+		case "heatmap":
+			return generateHeatmapSVG(c)
 		case "html":
 			output, _ := c.Flags().GetString("output")
 			return generateHTMLReport(output)
 		default:
-			return fmt.Errorf("unknown visualization type: %s (use: week, month, or html)", args[0])
+			return fmt.Errorf("unknown visualization type: %s (use: week, month, heatmap, or html)", args[0])
 		}
 	},
 }
@@ -54,6 +73,38 @@ func generateMonthSVG() error {
 	return nil
 }
 
+func generateHeatmapSVG(cmd *cobra.Command) error {
+	start, err := optionalDateFlag(cmd, "start")
+	if err != nil {
+		return err
+	}
+	end, err := optionalDateFlag(cmd, "end")
+	if err != nil {
+		return err
+	}
+	now := cfg.Now()
+	if start.IsZero() {
+		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, cfg.GetLocation())
+	}
+	if end.IsZero() {
+		end = time.Date(start.Year(), start.Month()+1, 0, 0, 0, 0, 0, cfg.GetLocation())
+	}
+	if end.Before(start) {
+		return fmt.Errorf("end date %s is before start date %s", end.Format("2006-01-02"), start.Format("2006-01-02"))
+	}
+
+	// Archived months still show up over long ranges
+	sessions, err := dataQuerier.SessionsWithArchive(start, end)
+	if err != nil {
+		return err
+	}
+	sessions = trackerService.SessionsOnDays(sessions, start, end)
+
+	svg := visualizer.GenerateHeatmapSVG(sessions, start, end)
+	fmt.Println(svg)
+	return nil
+}
+
 func generateHTMLReport(output string) error {
 	dayProgress, err := trackerService.GetTodayProgress()
 	if err != nil {
@@ -78,4 +129,7 @@ func generateHTMLReport(output string) error {
 func init() {
 	rootCmd.AddCommand(visualizeCmd)
 	visualizeCmd.Flags().StringP("output", "o", "", "Output file path")
+	visualizeCmd.Flags().StringP("start", "s", "", "Heatmap start date (YYYY-MM-DD, default: first of this month)")
+	visualizeCmd.Flags().StringP("end", "e", "", "Heatmap end date (YYYY-MM-DD, default: end of the start month)")
+	visualizeCmd.Flags().Float64("min-hours", 0, "Grey out days with fewer hours (default from ChartMinHours config)")
 }
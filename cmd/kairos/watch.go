@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/kairos/internal/logger"
+	"github.com/kairos/internal/notify"
+	"github.com/kairos/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:         "watch",
+	Short:       "Notify when today's target or auto clock-out is near",
+	Annotations: map[string]string{lightAnnotation: "true"},
+	Long: `Keep running and check the active session every minute, showing a desktop
+notification 30 minutes before today's target, once it is reached, and 30
+minutes before the auto_clockout_minutes limit, clocking the session out when
+the limit passes. Notices are printed here too. Stop with Ctrl+C.
+
+Examples:
+  kairos watch
+  kairos watch --interval 30s`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		interval, _ := cmd.Flags().GetDuration("interval")
+		if interval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		fmt.Printf("Watching every %s (Ctrl+C to stop)\n", interval)
+		for {
+			now := trackerService.Now()
+			for _, s := range autoClockout() {
+				watchNotice(now, fmt.Sprintf("Clocked out automatically at %s after %d minutes",
+					s.EndTime.Format("15:04"), cfg.AutoClockoutMinutes))
+			}
+			if reason, ok := trackerService.ShouldNotify(now); ok {
+				watchNotice(now, reason)
+				// More notices may be due at once; don't wait a tick for them
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+// watchNotice prints reason and shows it as a desktop notification
+func watchNotice(now time.Time, reason string) {
+	fmt.Printf("%s %s\n", now.Format("15:04"), reason)
+	if err := notify.Send("Kairos", reason); err != nil {
+		logger.Warn("desktop notification failed", "err", err)
+	}
+}
+
+// autoClockout closes a session left running past auto_clockout_minutes,
+// logging a warning for each, and returns the sessions it closed
+func autoClockout() []storage.WorkSession {
+	closed, err := trackerService.AutoCloseStaleSessions(cfg.AutoClockoutMinutes)
+	if err != nil {
+		logger.Warn("auto clock-out failed", "err", err)
+	}
+	for _, s := range closed {
+		logger.Warn("clocked out a session left running past auto_clockout_minutes",
+			"id", s.ID[:8], "start", s.StartTime.Format("2006-01-02 15:04"), "end", s.EndTime.Format("2006-01-02 15:04"))
+	}
+	return closed
+}
+
+func init() {
+	watchCmd.Flags().Duration("interval", time.Minute, "How often to check the active session")
+}
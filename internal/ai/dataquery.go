@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/kairos/internal/archive"
 	"github.com/kairos/internal/storage"
 	"github.com/kairos/internal/tracker"
 	"github.com/kairos/internal/work"
@@ -14,20 +17,28 @@ import (
 
 // DataQuerier wraps storage for AI-friendly data queries
 type DataQuerier struct {
-	db          *storage.Database
-	tracker     *tracker.Tracker
-	historyPath string
+	db             *storage.Database
+	tracker        *tracker.Tracker
+	historyPath    string
+	historyMonths  int
+	summaries      *archive.SummaryCache
+	compareHistory bool
 }
 
+// defaultHistoryMonths is how many archived months BuildDataContext lists
+const defaultHistoryMonths = 3
+
 // NewDataQuerier creates a new data querier
 func NewDataQuerier(db *storage.Database, tr *tracker.Tracker) *DataQuerier {
 	if tr == nil {
 		tr = tracker.NewWithLocation(db, work.WeeklyGoalHours, db.Location())
 	}
 	return &DataQuerier{
-		db:          db,
-		tracker:     tr,
-		historyPath: "", // Set via SetHistoryPath if needed
+		db:            db,
+		tracker:       tr,
+		historyPath:   "", // Set via SetHistoryPath if needed
+		historyMonths: defaultHistoryMonths,
+		summaries:     archive.NewSummaryCache(),
 	}
 }
 
@@ -37,9 +48,11 @@ func NewDataQuerierWithHistory(db *storage.Database, tr *tracker.Tracker, histor
 		tr = tracker.NewWithLocation(db, work.WeeklyGoalHours, db.Location())
 	}
 	return &DataQuerier{
-		db:          db,
-		tracker:     tr,
-		historyPath: historyPath,
+		db:            db,
+		tracker:       tr,
+		historyPath:   historyPath,
+		historyMonths: defaultHistoryMonths,
+		summaries:     archive.NewSummaryCache(),
 	}
 }
 
@@ -48,15 +61,26 @@ func (dq *DataQuerier) SetHistoryPath(path string) {
 	dq.historyPath = path
 }
 
-func (dq *DataQuerier) now() time.Time {
-	return time.Now().In(dq.db.Location())
+// SetHistoryMonths sets how many archived months BuildDataContext lists
+// (0 leaves history out)
+func (dq *DataQuerier) SetHistoryMonths(n int) {
+	if n < 0 {
+		n = 0
+	}
+	dq.historyMonths = n
 }
 
-func (dq *DataQuerier) weeklyGoal() float64 {
-	if dq.tracker != nil {
-		return dq.tracker.WeeklyGoal()
-	}
-	return work.WeeklyGoalHours
+// SetCompareHistory makes BuildDataContext and the offline analysis compare
+// this month against the trailing average of archived months
+func (dq *DataQuerier) SetCompareHistory(compare bool) {
+	dq.compareHistory = compare
+}
+
+// comparisonMonths is how many archived months form the trailing average
+const comparisonMonths = 3
+
+func (dq *DataQuerier) now() time.Time {
+	return time.Now().In(dq.db.Location())
 }
 
 // QueryResult contains structured data for AI consumption
@@ -110,13 +134,13 @@ func (dq *DataQuerier) GetWeekSummary() (*QueryResult, error) {
 		return nil, err
 	}
 
-	remainingDays := work.RemainingWorkDaysInWeek(dq.now())
+	remainingDays := work.RemainingWorkDaysInWeekFrom(dq.tracker.Rules(), dq.now(), dq.tracker.WeekStartDay())
 	dailyTarget := 0.0
 	if remainingDays > 0 && progress.RemainingHours > 0 {
 		dailyTarget = progress.RemainingHours / float64(remainingDays)
 	}
 
-	goal := dq.weeklyGoal()
+	goal := progress.Goal
 	progressPct := 0.0
 	if goal > 0 {
 		progressPct = (progress.TotalHours / goal) * 100
@@ -198,14 +222,11 @@ func (dq *DataQuerier) GetRecentSessions(limit int) (*QueryResult, error) {
 			"break_min":  s.BreakMinutes,
 			"note":       s.Note,
 		}
-		if s.EndTime != nil {
+		hours, complete := storage.SessionNetHours(s, dq.now())
+		sess["hours"] = hours
+		sess["is_active"] = !complete
+		if complete {
 			sess["end_time"] = s.EndTime.Format("15:04")
-			hours := s.EndTime.Sub(s.StartTime).Hours() - float64(s.BreakMinutes)/60.0
-			sess["hours"] = hours
-			sess["is_active"] = false
-		} else {
-			sess["is_active"] = true
-			sess["hours"] = time.Since(s.StartTime).Hours()
 		}
 		sessionData = append(sessionData, sess)
 	}
@@ -242,17 +263,17 @@ func (dq *DataQuerier) GetWorkStatus() (*QueryResult, error) {
 		return nil, err
 	}
 
-	goal := dq.weeklyGoal()
+	goal := weekProgress.Goal
 	data := map[string]interface{}{
 		"is_working":      active != nil,
 		"today_hours":     dayProgress.TotalHours,
 		"week_hours":      weekProgress.TotalHours,
 		"weekly_goal":     goal,
 		"remaining_hours": weekProgress.RemainingHours,
-		"remaining_days":  work.RemainingWorkDaysInWeek(dq.now()),
+		"remaining_days":  work.RemainingWorkDaysInWeekFrom(dq.tracker.Rules(), dq.now(), dq.tracker.WeekStartDay()),
 		"time_now":        dq.now().Format("15:04"),
 		"day_of_week":     dq.now().Weekday().String(),
-		"default_break":   work.GetBreakMinutesForDay(dq.now()),
+		"default_break":   work.GetBreakMinutesForDay(dq.tracker.Rules(), dq.now()),
 	}
 
 	if active != nil {
@@ -296,7 +317,7 @@ func (dq *DataQuerier) BuildDataContext() (string, error) {
 	sb.WriteString("CURRENT WORK DATA:\n")
 	sb.WriteString(fmt.Sprintf("- %s\n", status.Summary))
 	sb.WriteString(fmt.Sprintf("- %s\n", week.Summary))
-	sb.WriteString(fmt.Sprintf("- Standard break: %d min (0 on Fridays)\n", work.DefaultBreakMinutes))
+	sb.WriteString(fmt.Sprintf("- Standard break: %s\n", dq.tracker.Rules().BreakSummary()))
 
 	if data, ok := week.Data["daily_breakdown"].(map[string]float64); ok && len(data) > 0 {
 		sb.WriteString("- Daily breakdown: ")
@@ -308,9 +329,21 @@ func (dq *DataQuerier) BuildDataContext() (string, error) {
 		sb.WriteString("\n")
 	}
 
+	if dq.compareHistory {
+		comparison, err := dq.GetHistoryComparison()
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString("\nHISTORY COMPARISON:\n")
+		sb.WriteString(fmt.Sprintf("- %s\n", comparison.Summary()))
+		if comparison.MonthsCounted > 0 {
+			sb.WriteString("- Compare this month against the trailing average and say whether the pace is higher or lower than usual.\n")
+		}
+	}
+
 	// Include historical context if available
-	if dq.historyPath != "" {
-		history := dq.getHistorySummary(3)
+	if dq.historyPath != "" && dq.historyMonths > 0 {
+		history := dq.getHistorySummary(dq.historyMonths)
 		if history != "" {
 			sb.WriteString("\n")
 			sb.WriteString(history)
@@ -320,26 +353,136 @@ func (dq *DataQuerier) BuildDataContext() (string, error) {
 	return sb.String(), nil
 }
 
-// getHistorySummary reads archived months and returns a summary
-func (dq *DataQuerier) getHistorySummary(monthsBack int) string {
+// HistoryComparison sets this month against the trailing archived average
+type HistoryComparison struct {
+	MonthHours    float64
+	MonthDays     int
+	AvgHours      float64
+	AvgDays       float64
+	MonthsCounted int
+}
+
+// MonthDailyAverage returns hours per worked day this month
+func (h *HistoryComparison) MonthDailyAverage() float64 {
+	if h.MonthDays == 0 {
+		return 0
+	}
+	return h.MonthHours / float64(h.MonthDays)
+}
+
+// AvgDailyAverage returns hours per worked day over the trailing months
+func (h *HistoryComparison) AvgDailyAverage() float64 {
+	if h.AvgDays == 0 {
+		return 0
+	}
+	return h.AvgHours / h.AvgDays
+}
+
+// Summary states the comparison in one sentence
+func (h *HistoryComparison) Summary() string {
+	current := fmt.Sprintf("This month: %.2f hours over %d days (%.2f h/day)", h.MonthHours, h.MonthDays, h.MonthDailyAverage())
+	if h.MonthsCounted == 0 {
+		return current + "; no archived months to compare against"
+	}
+
+	diff := h.MonthDailyAverage() - h.AvgDailyAverage()
+	direction := "above"
+	if diff < 0 {
+		direction = "below"
+		diff = -diff
+	}
+	return fmt.Sprintf("%s vs trailing %d-month average of %.2f hours (%.2f h/day), %.2f h/day %s usual",
+		current, h.MonthsCounted, h.AvgHours, h.AvgDailyAverage(), diff, direction)
+}
+
+// GetHistoryComparison compares this month with the average of the archived
+// months before it
+func (dq *DataQuerier) GetHistoryComparison() (*HistoryComparison, error) {
+	progress, err := dq.tracker.GetMonthlyProgress()
+	if err != nil {
+		return nil, err
+	}
+
+	days := make(map[string]bool)
+	for _, s := range progress.Sessions {
+		if s.EndTime != nil {
+			days[s.Date.Format("2006-01-02")] = true
+		}
+	}
+
+	comparison := &HistoryComparison{
+		MonthHours: progress.TotalHours,
+		MonthDays:  len(days),
+	}
+
 	if dq.historyPath == "" {
+		return comparison, nil
+	}
+
+	months, err := archive.TrailingMonths(dq.historyPath, progress.Month, comparisonMonths)
+	if err != nil {
+		return nil, err
+	}
+	if len(months) == 0 {
+		return comparison, nil
+	}
+
+	for _, m := range months {
+		comparison.AvgHours += m.TotalHours
+		comparison.AvgDays += float64(m.DaysWorked)
+	}
+	comparison.MonthsCounted = len(months)
+	comparison.AvgHours /= float64(len(months))
+	comparison.AvgDays /= float64(len(months))
+
+	return comparison, nil
+}
+
+// HistoryLines returns one line of key metrics per archived month, oldest
+// first, limited to the configured number of history months
+func (dq *DataQuerier) HistoryLines() []string {
+	return dq.historyLines(dq.historyMonths)
+}
+
+// getHistorySummary lists the last monthsBack archived months, one line of
+// key metrics each
+func (dq *DataQuerier) getHistorySummary(monthsBack int) string {
+	lines := dq.historyLines(monthsBack)
+	if len(lines) == 0 {
 		return ""
 	}
 
+	var sb strings.Builder
+	sb.WriteString("HISTORICAL DATA (archived months):\n")
+	for _, line := range lines {
+		sb.WriteString(fmt.Sprintf("- %s\n", line))
+	}
+	return sb.String()
+}
+
+// historyLines summarizes the last monthsBack archived months. Parsed
+// summaries are cached until their file changes.
+func (dq *DataQuerier) historyLines(monthsBack int) []string {
+	if dq.historyPath == "" || monthsBack <= 0 {
+		return nil
+	}
+
 	entries, err := os.ReadDir(dq.historyPath)
 	if err != nil {
-		return ""
+		return nil
 	}
 
+	// ReadDir sorts by name, so YYYY-MM files are oldest first
 	var archives []string
 	for _, e := range entries {
-		if !e.IsDir() && strings.HasSuffix(e.Name(), ".md") {
-			archives = append(archives, e.Name())
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".md") {
+			continue
 		}
-	}
-
-	if len(archives) == 0 {
-		return ""
+		if _, err := time.Parse("2006-01", strings.TrimSuffix(name, ".md")); err != nil {
+			continue
+		}
+		archives = append(archives, name)
 	}
 
 	// Get last N archives
@@ -348,31 +491,15 @@ func (dq *DataQuerier) getHistorySummary(monthsBack int) string {
 		start = 0
 	}
 
-	var sb strings.Builder
-	sb.WriteString("HISTORICAL DATA (archived months):\n")
-
-	for _, archive := range archives[start:] {
-		content, err := os.ReadFile(filepath.Join(dq.historyPath, archive))
+	var lines []string
+	for _, name := range archives[start:] {
+		month, err := dq.summaries.Load(filepath.Join(dq.historyPath, name))
 		if err != nil {
 			continue
 		}
-
-		// Extract summary from markdown
-		lines := strings.Split(string(content), "\n")
-		for _, line := range lines {
-			if strings.HasPrefix(line, "# ") {
-				sb.WriteString(fmt.Sprintf("- %s: ", strings.TrimPrefix(line, "# ")))
-			}
-			if strings.HasPrefix(line, "| Total Hours |") {
-				parts := strings.Split(line, "|")
-				if len(parts) >= 3 {
-					sb.WriteString(fmt.Sprintf("%s hours\n", strings.TrimSpace(parts[2])))
-				}
-			}
-		}
+		lines = append(lines, month.Line())
 	}
-
-	return sb.String()
+	return lines
 }
 
 // GetTodayHours returns hours worked today (for offline fallback)
@@ -393,7 +520,10 @@ func (dq *DataQuerier) GetWeekHours() (float64, error) {
 	return progress.TotalHours, nil
 }
 
-// GetHoursInRange returns total hours worked in a date range
+// GetHoursInRange returns total hours worked in a date range. Months that
+// have an archive file but no sessions left in the database (archived with
+// --clean) are counted from the archive, so ranges can reach back past the
+// retained data or straddle it.
 func (dq *DataQuerier) GetHoursInRange(start, end time.Time) (float64, error) {
 	sessions, err := dq.db.GetSessionsInRange(start, end)
 	if err != nil {
@@ -402,15 +532,155 @@ func (dq *DataQuerier) GetHoursInRange(start, end time.Time) (float64, error) {
 
 	total := 0.0
 	for _, s := range sessions {
-		if s.EndTime != nil {
-			hours := s.EndTime.Sub(s.StartTime).Hours()
-			hours -= float64(s.BreakMinutes) / 60.0
+		if hours, complete := storage.SessionNetHours(s, dq.now()); complete {
 			total += hours
 		}
 	}
+
+	records, err := dq.archivedRecords(start, end)
+	if err != nil {
+		return 0, err
+	}
+	for _, r := range records {
+		total += r.Hours
+	}
 	return total, nil
 }
 
+// SessionsWithArchive returns the sessions in start..end, rebuilding archived
+// sessions for months that only survive in the archive (see GetHoursInRange).
+// A month with any session left in the database is read from the database
+// alone, so a month that is both archived and retained is never counted twice.
+func (dq *DataQuerier) SessionsWithArchive(start, end time.Time) ([]storage.WorkSession, error) {
+	sessions, err := dq.db.GetSessionsInRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	records, err := dq.archivedRecords(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range records {
+		if s, ok := r.WorkSession(dq.db.Location()); ok {
+			sessions = append(sessions, s)
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].StartTime.Before(sessions[j].StartTime)
+	})
+	return sessions, nil
+}
+
+// archivedRecords returns the archived sessions dated start..end from months
+// that have no sessions left in the database
+func (dq *DataQuerier) archivedRecords(start, end time.Time) ([]archive.SessionRecord, error) {
+	if dq.historyPath == "" {
+		return nil, nil
+	}
+
+	loc := dq.db.Location()
+	first := start.In(loc).Format("2006-01-02")
+	last := end.In(loc).Format("2006-01-02")
+	var records []archive.SessionRecord
+	month := time.Date(start.In(loc).Year(), start.In(loc).Month(), 1, 0, 0, 0, 0, loc)
+	for ; !month.After(end.In(loc)); month = month.AddDate(0, 1, 0) {
+		monthEnd := month.AddDate(0, 1, -1)
+		retained, err := dq.db.GetSessionsInRange(month, monthEnd)
+		if err != nil {
+			return nil, err
+		}
+		// A session from the month before that ran past midnight doesn't
+		// count as the month still being in the database
+		if len(storage.StartedBetween(retained, month, monthEnd)) > 0 {
+			continue
+		}
+		monthRecords, err := archive.MonthSessions(dq.historyPath, month.Year(), month.Month())
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range monthRecords {
+			if r.Date >= first && r.Date <= last {
+				records = append(records, r)
+			}
+		}
+	}
+	return records, nil
+}
+
+// FirstTrackedDate returns the earliest day with data, in the database or
+// the archive; false when nothing has been tracked yet
+func (dq *DataQuerier) FirstTrackedDate() (time.Time, bool, error) {
+	oldest, err := dq.db.GetOldestSessionDate()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	var first time.Time
+	found := oldest != nil
+	if found {
+		first = *oldest
+	}
+	if dq.historyPath != "" {
+		month, ok, err := archive.OldestMonth(dq.historyPath)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if ok {
+			month = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, dq.db.Location())
+			if !found || month.Before(first) {
+				first = month
+			}
+			found = true
+		}
+	}
+	return first, found, nil
+}
+
+// MonthInQuestion finds a month named in a question ("last March",
+// "january 2025") and returns its first day. Without a year, a month later
+// than now's, or the current month after "last", means the previous year.
+// "may" only counts after in/last/of/during or before a year.
+func MonthInQuestion(question string, now time.Time) (time.Time, bool) {
+	words := strings.FieldsFunc(strings.ToLower(question), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+
+	for i, word := range words {
+		month, err := time.Parse("January", strings.ToUpper(word[:1])+word[1:])
+		if err != nil {
+			continue
+		}
+
+		prev, next := "", ""
+		if i > 0 {
+			prev = words[i-1]
+		}
+		if i+1 < len(words) {
+			next = words[i+1]
+		}
+		year, yearErr := strconv.Atoi(next)
+		hasYear := yearErr == nil && len(next) == 4
+
+		if month.Month() == time.May && !hasYear {
+			switch prev {
+			case "in", "last", "of", "during":
+			default:
+				continue
+			}
+		}
+
+		if !hasYear {
+			year = now.Year()
+			if month.Month() > now.Month() || (prev == "last" && month.Month() == now.Month()) {
+				year--
+			}
+		}
+		return time.Date(year, month.Month(), 1, 0, 0, 0, 0, now.Location()), true
+	}
+	return time.Time{}, false
+}
+
 // GetSessionsInRange returns sessions in a date range
 func (dq *DataQuerier) GetSessionsInRange(start, end time.Time) ([]storage.WorkSession, error) {
 	return dq.db.GetSessionsInRange(start, end)
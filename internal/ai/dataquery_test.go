@@ -0,0 +1,248 @@
+package ai
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kairos/internal/archive"
+	"github.com/kairos/internal/storage"
+	"github.com/kairos/internal/tracker"
+)
+
+func TestGetHoursInRangeSpansArchive(t *testing.T) {
+	dir := t.TempDir()
+	db, err := storage.New(filepath.Join(dir, "test.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	defer db.Close()
+
+	// February was archived with --clean: only the archive file remains
+	historyPath := filepath.Join(dir, "history")
+	if err := os.MkdirAll(historyPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	february := "# February 2025\n\n## Summary\n\n| Metric | Value |\n|--------|-------|\n| Total Hours | 15.00 |\n\n" +
+		"## Sessions\n\n| Date | Start | End | Hours | Break | Note |\n|------|-------|-----|-------|-------|------|\n" +
+		"| 2025-02-10 | 09:00 | 17:30 | 8.00 | 30m | early |\n" +
+		"| 2025-02-20 | 09:00 | 16:30 | 7.00 | 30m | late |\n"
+	if err := os.WriteFile(filepath.Join(historyPath, "2025-02.md"), []byte(february), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// March is still live in the database
+	start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	end := start.Add(6 * time.Hour)
+	if err := db.InsertSession(&storage.WorkSession{StartTime: start, EndTime: &end}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+	// A January shift running into February 1 leaves February archived
+	lateStart := time.Date(2025, 1, 31, 22, 0, 0, 0, time.UTC)
+	lateEnd := lateStart.Add(4 * time.Hour)
+	if err := db.InsertSession(&storage.WorkSession{StartTime: lateStart, EndTime: &lateEnd}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+
+	dq := NewDataQuerierWithHistory(db, nil, historyPath)
+	hours, err := dq.GetHoursInRange(time.Date(2025, 2, 15, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("GetHoursInRange: %v", err)
+	}
+	if hours != 13 {
+		t.Errorf("hours = %.2f, want 13 (7 archived after Feb 15 + 6 live)", hours)
+	}
+
+	live := NewDataQuerier(db, nil)
+	if hours, _ := live.GetHoursInRange(time.Date(2025, 2, 15, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)); hours != 6 {
+		t.Errorf("without history: hours = %.2f, want 6", hours)
+	}
+}
+
+func TestSessionsWithArchiveAllTime(t *testing.T) {
+	dir := t.TempDir()
+	db, err := storage.New(filepath.Join(dir, "test.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	defer db.Close()
+
+	// January was archived with --clean; February was archived but kept, so
+	// its archive must not be added on top of the live sessions
+	historyPath := filepath.Join(dir, "history")
+	if err := os.MkdirAll(historyPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	archived := map[string]string{
+		"2025-01.md": "| 2025-01-13 | 22:00 | 06:30 | 8.00 | 30m | night shift |\n| 2025-01-14 | 09:00 | 13:00 | 4.00 | 0m | half day |\n",
+		"2025-02.md": "| 2025-02-10 | 09:00 | 17:30 | 8.00 | 30m | kept live |\n",
+	}
+	for name, rows := range archived {
+		content := "# Archive\n\n## Sessions\n\n| Date | Start | End | Hours | Break | Note |\n|------|-------|-----|-------|-------|------|\n" + rows
+		if err := os.WriteFile(filepath.Join(historyPath, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, start := range []time.Time{
+		time.Date(2025, 2, 10, 9, 0, 0, 0, time.UTC),
+		time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC),
+	} {
+		end := start.Add(6 * time.Hour)
+		if err := db.InsertSession(&storage.WorkSession{StartTime: start, EndTime: &end}); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+	}
+
+	dq := NewDataQuerierWithHistory(db, nil, historyPath)
+	first, ok, err := dq.FirstTrackedDate()
+	if err != nil || !ok {
+		t.Fatalf("FirstTrackedDate = %v, %v, %v", first, ok, err)
+	}
+	if want := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC); !first.Equal(want) {
+		t.Errorf("FirstTrackedDate = %s, want the oldest archived month %s", first, want)
+	}
+
+	sessions, err := dq.SessionsWithArchive(first, time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("SessionsWithArchive: %v", err)
+	}
+	var got []string
+	total := 0.0
+	for _, s := range sessions {
+		hours, _ := storage.SessionNetHours(s, time.Now())
+		total += hours
+		got = append(got, fmt.Sprintf("%s %.2f", s.StartTime.Format("2006-01-02 15:04"), hours))
+	}
+	want := []string{"2025-01-13 22:00 8.00", "2025-01-14 09:00 4.00", "2025-02-10 09:00 6.00", "2025-03-03 09:00 6.00"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("sessions = %v, want %v", got, want)
+	}
+	if total != 24 {
+		t.Errorf("total = %.2f, want 24 (12 archived + 12 live)", total)
+	}
+}
+
+func TestWeekGoalOverrideInSummaries(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	defer db.Close()
+
+	tr := tracker.NewWithLocation(db, 40, time.UTC)
+	if err := tr.SetWeekGoal(tr.Now(), 30); err != nil {
+		t.Fatalf("SetWeekGoal: %v", err)
+	}
+	dq := NewDataQuerier(db, tr)
+
+	week, err := dq.GetWeekSummary()
+	if err != nil {
+		t.Fatalf("GetWeekSummary: %v", err)
+	}
+	if week.Data["weekly_goal"] != 30.0 {
+		t.Errorf("week summary goal = %v, want 30", week.Data["weekly_goal"])
+	}
+	status, err := dq.GetWorkStatus()
+	if err != nil {
+		t.Fatalf("GetWorkStatus: %v", err)
+	}
+	if status.Data["weekly_goal"] != 30.0 {
+		t.Errorf("work status goal = %v, want 30", status.Data["weekly_goal"])
+	}
+	digest, err := dq.WeeklyDigest(tr.Now())
+	if err != nil {
+		t.Fatalf("WeeklyDigest: %v", err)
+	}
+	if !strings.Contains(digest.Text, "0.00/30.00h") {
+		t.Errorf("digest = %q, want hours against the 30h week goal", digest.Text)
+	}
+	if analysis := NewAIService(nil).offlineAnalyze(dq); !strings.Contains(analysis, "/30.00 hours") {
+		t.Errorf("offline analysis = %q, want the 30h week goal", analysis)
+	}
+}
+
+func TestOfflineAnalyzeComparesHistory(t *testing.T) {
+	dir := t.TempDir()
+	db, err := storage.New(filepath.Join(dir, "test.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	defer db.Close()
+
+	tr := tracker.NewWithLocation(db, 40, time.UTC)
+	historyPath := filepath.Join(dir, "history")
+	if err := os.MkdirAll(historyPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	lastMonth := time.Date(tr.Now().Year(), tr.Now().Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	archived := "# Last month\n\n## Summary\n\n| Metric | Value |\n|--------|-------|\n| Total Hours | 140.00 |\n| Days Worked | 20 |\n"
+	if err := os.WriteFile(filepath.Join(historyPath, lastMonth.Format("2006-01")+".md"), []byte(archived), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dq := NewDataQuerierWithHistory(db, tr, historyPath)
+	service := NewAIService(nil)
+	if analysis := service.offlineAnalyze(dq); strings.Contains(analysis, "average") {
+		t.Errorf("offline analysis = %q, want no comparison unless asked", analysis)
+	}
+	dq.SetCompareHistory(true)
+	if analysis := service.offlineAnalyze(dq); !strings.Contains(analysis, "trailing 1-month average of 140.00 hours (7.00 h/day)") {
+		t.Errorf("offline analysis = %q, want last month's average", analysis)
+	}
+}
+
+func TestMonthInQuestion(t *testing.T) {
+	now := time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		question string
+		want     string
+	}{
+		{"how many hours did I work last March", "2025-03"},
+		{"hours in December?", "2024-12"},
+		{"what about last april", "2024-04"},
+		{"hours in january 2023", "2023-01"},
+		{"hours in may", "2024-05"},
+		{"may I leave early today", ""},
+		{"how am I doing this week", ""},
+	}
+	for _, tt := range tests {
+		month, ok := MonthInQuestion(tt.question, now)
+		got := ""
+		if ok {
+			got = month.Format("2006-01")
+		}
+		if got != tt.want {
+			t.Errorf("MonthInQuestion(%q) = %q, want %q", tt.question, got, tt.want)
+		}
+	}
+}
+
+func BenchmarkHistorySummary(b *testing.B) {
+	historyPath := b.TempDir()
+	for i := 0; i < 120; i++ {
+		month := time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, i, 0)
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "# %s\n\n## Summary\n\n| Metric | Value |\n|--------|-------|\n| Total Hours | 150.00 |\n| Days Worked | 20 |\n\n", month.Format("January 2006"))
+		sb.WriteString("## Sessions\n\n| Date | Start | End | Hours | Break | Note |\n|------|-------|-----|-------|-------|------|\n")
+		for day := 1; day <= 28; day++ {
+			fmt.Fprintf(&sb, "| %s | 09:00 | 17:30 | 8.00 | 30m | Feature work and reviews |\n", month.AddDate(0, 0, day-1).Format("2006-01-02"))
+		}
+		if err := os.WriteFile(filepath.Join(historyPath, month.Format("2006-01")+".md"), []byte(sb.String()), 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	dq := &DataQuerier{historyPath: historyPath, historyMonths: 12, summaries: archive.NewSummaryCache()}
+	if got := dq.getHistorySummary(12); strings.Count(got, "\n") != 13 {
+		b.Fatalf("summary has %d lines, want a header and 12 months:\n%s", strings.Count(got, "\n"), got)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dq.getHistorySummary(12)
+	}
+}
@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kairos/internal/tracker"
+)
+
+// Digest is a short, stored summary of one week for later AI context
+type Digest struct {
+	WeekStart time.Time
+	Text      string
+}
+
+// WeeklyDigest summarizes the week containing date in one line: hours
+// against the week's goal, days worked and the week's notable patterns (longest
+// day, usual hours, top note)
+func (dq *DataQuerier) WeeklyDigest(date time.Time) (*Digest, error) {
+	progress, err := dq.tracker.GetWeekProgressForDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	goal := progress.Goal
+	parts := []string{fmt.Sprintf("Week of %s: %.2f/%.2fh", progress.WeekStart.Format("2006-01-02"), progress.TotalHours, goal)}
+	if goal > 0 {
+		parts[0] += fmt.Sprintf(" (%.0f%%)", progress.TotalHours/goal*100)
+	}
+	switch {
+	case progress.TotalHours >= goal:
+		parts = append(parts, fmt.Sprintf("goal met, +%.2fh", progress.TotalHours-goal))
+	case progress.RemainingWorkDays > 0:
+		parts = append(parts, fmt.Sprintf("%.2fh to go over %d days", goal-progress.TotalHours, progress.RemainingWorkDays))
+	default:
+		parts = append(parts, fmt.Sprintf("goal missed by %.2fh", goal-progress.TotalHours))
+	}
+
+	if progress.DaysWorkedCount == 0 {
+		parts = append(parts, "no days worked")
+		return &Digest{WeekStart: progress.WeekStart, Text: strings.Join(parts, " | ")}, nil
+	}
+	parts = append(parts, fmt.Sprintf("%d days worked", progress.DaysWorkedCount))
+
+	longestDay, longest := "", 0.0
+	for day, hours := range progress.DaysWorked {
+		if hours > longest || (hours == longest && day < longestDay) {
+			longestDay, longest = day, hours
+		}
+	}
+	if day, err := time.Parse("2006-01-02", longestDay); err == nil {
+		parts = append(parts, fmt.Sprintf("longest %s %.2fh", day.Format("Mon"), longest))
+	}
+
+	if schedule := tracker.ComputeScheduleStats(progress.Sessions); schedule.DaysCounted > 0 {
+		parts = append(parts, fmt.Sprintf("usual hours %s-%s", tracker.FormatClock(schedule.AvgStart), tracker.FormatClock(schedule.AvgEnd)))
+	}
+
+	notes := make(map[string]int)
+	topNote := ""
+	for _, s := range progress.Sessions {
+		if s.Note == "" {
+			continue
+		}
+		notes[s.Note]++
+		if notes[s.Note] > notes[topNote] || (notes[s.Note] == notes[topNote] && s.Note < topNote) {
+			topNote = s.Note
+		}
+	}
+	if topNote != "" {
+		parts = append(parts, fmt.Sprintf("mostly %q", topNote))
+	}
+
+	return &Digest{WeekStart: progress.WeekStart, Text: strings.Join(parts, " | ")}, nil
+}
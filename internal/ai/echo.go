@@ -0,0 +1,53 @@
+package ai
+
+import (
+	"fmt"
+
+	"github.com/kairos/internal/tracker"
+)
+
+// EchoProvider is an offline provider for development and tests. It is
+// always available and answers deterministically from its inputs.
+type EchoProvider struct{}
+
+func NewEchoProvider() *EchoProvider {
+	return &EchoProvider{}
+}
+
+func (e *EchoProvider) Name() string {
+	return "Echo"
+}
+
+func (e *EchoProvider) IsAvailable() bool {
+	return true
+}
+
+func (e *EchoProvider) Ask(question string, ctx *WorkContext) (string, error) {
+	if ctx == nil {
+		return fmt.Sprintf("echo: %s", question), nil
+	}
+	answer := fmt.Sprintf("echo: %s | today=%.2fh week=%.2f/%.2fh month=%.2fh remaining=%.2fh",
+		question, ctx.TodayHours, ctx.WeekHours, ctx.WeeklyGoal, ctx.MonthHours, ctx.RemainingHours)
+	if ctx.PeriodLabel != "" {
+		answer += fmt.Sprintf(" period[%s]=%.2fh", ctx.PeriodLabel, ctx.PeriodHours)
+	}
+	return answer, nil
+}
+
+// LastUsage is always zero; echo answers cost no tokens
+func (e *EchoProvider) LastUsage() Usage {
+	return Usage{}
+}
+
+func (e *EchoProvider) Predict(weekProgress *tracker.WeekProgress) (string, error) {
+	return fmt.Sprintf("echo: predict | week=%.2fh remaining=%.2fh days=%d",
+		weekProgress.TotalHours, weekProgress.RemainingHours, weekProgress.DaysWorkedCount), nil
+}
+
+func (e *EchoProvider) Analyze(dq *DataQuerier) (string, error) {
+	data, err := dq.BuildDataContext()
+	if err != nil {
+		return "", err
+	}
+	return "echo: analyze\n" + data, nil
+}
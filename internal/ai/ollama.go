@@ -76,7 +76,7 @@ func (o *Ollama) AskWithContext(question string, ctx *WorkContext) (string, erro
 
 // Predict generates predictions based on weekly progress
 func (o *Ollama) Predict(weekProgress *tracker.WeekProgress) (string, error) {
-	remainingDays := work.RemainingWorkDaysInWeek(o.now())
+	remainingDays := weekProgress.RemainingWorkDays
 	dailyTarget := 0.0
 	if remainingDays > 0 && weekProgress.RemainingHours > 0 {
 		dailyTarget = weekProgress.RemainingHours / float64(remainingDays)
@@ -202,8 +202,8 @@ Current Status:
 - Remaining to weekly goal: %.2f hours
 - Remaining work days: %d
 - Required daily average: %.2f hours
-- Standard break: %d minutes (no break on Fridays)
-
+- Standard break: %s
+%s
 User question: "%s"
 
 Answer based on the data above. Be concise and helpful.`,
@@ -216,7 +216,8 @@ Answer based on the data above. Be concise and helpful.`,
 		ctx.RemainingHours,
 		ctx.RemainingDays,
 		ctx.DailyTarget,
-		work.DefaultBreakMinutes,
+		ctx.breakRules(),
+		ctx.scheduleDetails(),
 		question)
 }
 
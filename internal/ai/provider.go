@@ -7,10 +7,14 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/kairos/internal/config"
+	"github.com/kairos/internal/logger"
+	"github.com/kairos/internal/storage"
 	"github.com/kairos/internal/tracker"
 	"github.com/kairos/internal/work"
 )
@@ -20,14 +24,86 @@ type Provider interface {
 	Name() string
 	IsAvailable() bool
 	Ask(question string, ctx *WorkContext) (string, error)
+	// AskStream is Ask writing the answer to w as it is generated and
+	// stopping when ctx is cancelled. Providers without a streaming API write
+	// the whole answer at once.
+	AskStream(ctx context.Context, question string, wc *WorkContext, w io.Writer) error
 	Predict(weekProgress *tracker.WeekProgress) (string, error)
 	Analyze(dq *DataQuerier) (string, error)
+	// LastUsage reports the tokens spent by the most recent request; zero
+	// when the provider does not report them
+	LastUsage() Usage
+}
+
+// Usage is the token count of one provider request
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// Total is the prompt and completion tokens together
+func (u Usage) Total() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// usageMeter keeps a provider's last Usage; the MCP server may run
+// requests concurrently
+type usageMeter struct {
+	mu   sync.Mutex
+	last Usage
+}
+
+func (m *usageMeter) LastUsage() Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.last
+}
+
+func (m *usageMeter) record(u Usage) {
+	m.mu.Lock()
+	m.last = u
+	m.mu.Unlock()
+}
+
+// availabilityChecker is a Provider that can say why it is unavailable
+type availabilityChecker interface {
+	CheckAvailable() error
+}
+
+// availabilityTTL is how long AIService trusts a provider probe, so one
+// command probes once and a long-running MCP server still notices a
+// provider coming up or going down
+const availabilityTTL = time.Minute
+
+// Timeouts bound provider requests: Request for a whole answer (and the
+// silence allowed mid-stream), Probe for an availability check
+type Timeouts struct {
+	Request time.Duration
+	Probe   time.Duration
+}
+
+// DefaultTimeouts fill in zero Timeouts fields
+var DefaultTimeouts = Timeouts{Request: 60 * time.Second, Probe: 5 * time.Second}
+
+func (t Timeouts) withDefaults() Timeouts {
+	if t.Request <= 0 {
+		t.Request = DefaultTimeouts.Request
+	}
+	if t.Probe <= 0 {
+		t.Probe = DefaultTimeouts.Probe
+	}
+	return t
 }
 
 // AIService manages AI providers
 type AIService struct {
 	provider Provider
 	cfg      *config.Config
+	initErr  error // why Initialize left no provider
+
+	availMu  sync.Mutex
+	availErr error     // last probe result
+	availAt  time.Time // when the last probe ran; zero = never
 }
 
 func (s *AIService) now() time.Time {
@@ -44,29 +120,119 @@ func NewAIService(cfg *config.Config) *AIService {
 	}
 }
 
-// Initialize sets up the provider based on config
+// Initialize sets up the provider based on config. A provider missing its
+// model or API key is not created; the validation error is returned and kept
+// for CheckAvailable.
 func (s *AIService) Initialize() error {
+	s.provider = nil
+	s.initErr = s.cfg.ValidateProvider()
+	if s.initErr != nil {
+		return s.initErr
+	}
+
+	timeouts := Timeouts{
+		Request: time.Duration(s.cfg.AITimeoutSeconds) * time.Second,
+		Probe:   time.Duration(s.cfg.AIProbeTimeoutSeconds) * time.Second,
+	}
+	systemPrompt := strings.TrimSpace(s.cfg.SystemPromptOverride)
+	retry := Retry{MaxRetries: s.cfg.AIMaxRetries}
 	switch s.cfg.AIProvider {
 	case config.ProviderOllama:
-		s.provider = NewOllamaProvider(s.cfg.OllamaURL, s.cfg.OllamaModel, s.cfg.GetLocation())
+		p := NewOllamaProvider(s.cfg.OllamaURL, s.cfg.OllamaModel, s.cfg.GetLocation(), timeouts)
+		p.systemPrompt = systemPrompt
+		p.retry = retry
+		s.provider = p
 	case config.ProviderOpenAI:
-		s.provider = NewOpenAIProvider(s.cfg.OpenAIModel, s.cfg.OpenAIAPIKey, s.cfg.GetLocation())
+		p := NewOpenAIProvider(s.cfg.OpenAIModel, s.cfg.OpenAIAPIKey, s.cfg.GetLocation(), timeouts)
+		if base := strings.TrimRight(s.cfg.OpenAIBaseURL, "/"); base != "" {
+			p.baseURL = base
+		}
+		p.systemPrompt = systemPrompt
+		p.retry = retry
+		s.provider = p
 	case config.ProviderClaude:
-		s.provider = NewClaudeProvider(s.cfg.ClaudeModel, s.cfg.ClaudeAPIKey, s.cfg.GetLocation())
+		p := NewClaudeProvider(s.cfg.ClaudeModel, s.cfg.ClaudeAPIKey, s.cfg.GetLocation(), timeouts)
+		p.systemPrompt = systemPrompt
+		p.retry = retry
+		s.provider = p
 	case config.ProviderGemini:
-		s.provider = NewGeminiProvider(s.cfg.GeminiModel, s.cfg.GeminiAPIKey, s.cfg.GetLocation())
+		p := NewGeminiProvider(s.cfg.GeminiModel, s.cfg.GeminiAPIKey, s.cfg.GetLocation(), timeouts)
+		p.systemPrompt = systemPrompt
+		p.retry = retry
+		s.provider = p
+	case config.ProviderEcho:
+		s.provider = NewEchoProvider()
 	default:
-		return fmt.Errorf("unknown AI provider: %s", s.cfg.AIProvider)
+		s.initErr = fmt.Errorf("unknown AI provider: %s", s.cfg.AIProvider)
+		return s.initErr
+	}
+	return nil
+}
+
+// CheckAvailable returns nil when the provider can take queries, otherwise an
+// error saying what to fix
+func (s *AIService) CheckAvailable() error {
+	if s.initErr != nil {
+		return fmt.Errorf("%s AI provider is not configured: %w", s.cfg.AIProvider, s.initErr)
+	}
+	if s.provider == nil {
+		return fmt.Errorf("no AI provider. Configure with: kairos config --provider")
+	}
+	if err := s.probe(); err != nil {
+		return fmt.Errorf("%s is not available: %w. Configure with: kairos config", s.Name(), err)
 	}
 	return nil
 }
 
 // IsAvailable checks if the current provider is available
 func (s *AIService) IsAvailable() bool {
-	if s.provider == nil {
-		return false
+	return s.provider != nil && s.probe() == nil
+}
+
+// probe checks the provider, reusing a result younger than availabilityTTL
+// so CheckAvailable and the query that follows agree
+func (s *AIService) probe() error {
+	s.availMu.Lock()
+	defer s.availMu.Unlock()
+	if !s.availAt.IsZero() && time.Since(s.availAt) < availabilityTTL {
+		return s.availErr
+	}
+	if checker, ok := s.provider.(availabilityChecker); ok {
+		s.availErr = checker.CheckAvailable()
+	} else if s.provider.IsAvailable() {
+		s.availErr = nil
+	} else {
+		s.availErr = fmt.Errorf("provider did not respond")
+	}
+	s.availAt = time.Now()
+	return s.availErr
+}
+
+// instructions returns the configured system prompt, or def when none is set
+func instructions(systemPrompt, def string) string {
+	if systemPrompt != "" {
+		return systemPrompt
+	}
+	return def
+}
+
+// probeStatus sends an availability request and returns nil only on 200 OK.
+// 401 and 403 are reported as a rejected API key.
+func probeStatus(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("API key rejected (HTTP %d)", resp.StatusCode)
+	default:
+		return fmt.Errorf("HTTP %d from %s", resp.StatusCode, req.URL.Host)
 	}
-	return s.provider.IsAvailable()
 }
 
 // Name returns the current provider name
@@ -77,33 +243,55 @@ func (s *AIService) Name() string {
 	return s.provider.Name()
 }
 
+// LastUsage reports the tokens spent by the provider's most recent request;
+// zero without a provider
+func (s *AIService) LastUsage() Usage {
+	if s.provider == nil {
+		return Usage{}
+	}
+	return s.provider.LastUsage()
+}
+
 // Ask sends a question to the AI
 func (s *AIService) Ask(question string, ctx *WorkContext) (string, error) {
 	if s.provider == nil {
 		return s.offlineAsk(question, ctx), nil
 	}
 
-	if !s.provider.IsAvailable() {
+	if err := s.probe(); err != nil {
+		logger.Debug("AI provider unavailable, answering offline", "provider", s.provider.Name(), "err", err)
 		return s.offlineAsk(question, ctx), nil
 	}
 
-	return s.provider.Ask(question, ctx)
+	answer, err := s.provider.Ask(question, ctx)
+	if err != nil {
+		logger.Error("AI ask failed", "provider", s.provider.Name(), "err", err)
+	}
+	return answer, err
 }
 
 // Predict generates predictions
 func (s *AIService) Predict(weekProgress *tracker.WeekProgress) (string, error) {
-	if s.provider == nil || !s.provider.IsAvailable() {
+	if !s.IsAvailable() {
 		return s.offlinePredict(weekProgress), nil
 	}
-	return s.provider.Predict(weekProgress)
+	prediction, err := s.provider.Predict(weekProgress)
+	if err != nil {
+		logger.Error("AI predict failed", "provider", s.provider.Name(), "err", err)
+	}
+	return prediction, err
 }
 
 // Analyze provides work pattern analysis
 func (s *AIService) Analyze(dq *DataQuerier) (string, error) {
-	if s.provider == nil || !s.provider.IsAvailable() {
+	if !s.IsAvailable() {
 		return s.offlineAnalyze(dq), nil
 	}
-	return s.provider.Analyze(dq)
+	analysis, err := s.provider.Analyze(dq)
+	if err != nil {
+		logger.Error("AI analyze failed", "provider", s.provider.Name(), "err", err)
+	}
+	return analysis, err
 }
 
 // offlineAsk provides rule-based responses when AI is unavailable
@@ -111,11 +299,25 @@ func (s *AIService) offlineAsk(question string, ctx *WorkContext) string {
 	question = strings.ToLower(question)
 
 	// Check for common questions and provide helpful responses
-	if strings.Contains(question, "leave") || strings.Contains(question, "done") || strings.Contains(question, "go home") {
-		if ctx.RemainingHours > 0 {
-			return fmt.Sprintf("You need %.2f more hours to reach your weekly goal. At your current pace, plan to work about %.2f hours per remaining day.", ctx.RemainingHours, ctx.DailyTarget)
+	if ctx.PeriodLabel != "" && strings.Contains(question, "hour") {
+		return fmt.Sprintf("You worked %.2f hours in %s.", ctx.PeriodHours, ctx.PeriodLabel)
+	}
+
+	if containsAny(question, "overtime", "extra hours", "over my goal", "over goal") {
+		if ctx.RemainingHours < 0 {
+			return fmt.Sprintf("You're %.2f hours over your %.2fh weekly goal (%.2f hours worked this week).", -ctx.RemainingHours, ctx.WeeklyGoal, ctx.WeekHours)
 		}
-		return "You've exceeded your weekly goal! You're done for the week. Great work!"
+		return fmt.Sprintf("No overtime this week yet: %.2f hours to go to reach your %.2fh goal.", ctx.RemainingHours, ctx.WeeklyGoal)
+	}
+
+	if containsAny(question, "leave", "done", "go home", "clock out", "stop working") {
+		if ctx.RemainingHours <= 0 {
+			return "You've exceeded your weekly goal! You're done for the week. Great work!"
+		}
+		if ctx.LeaveTime != "" {
+			return fmt.Sprintf("Leave at about %s: that covers today's %.2f hours and keeps you on pace for the remaining %.2f hours over %d days (default break included).", ctx.LeaveTime, ctx.DailyTarget, ctx.RemainingHours, ctx.RemainingDays)
+		}
+		return fmt.Sprintf("You need %.2f more hours to reach your weekly goal. At your current pace, plan to work about %.2f hours per remaining day.", ctx.RemainingHours, ctx.DailyTarget)
 	}
 
 	if strings.Contains(question, "hours today") || strings.Contains(question, "worked today") {
@@ -129,6 +331,27 @@ func (s *AIService) offlineAsk(question string, ctx *WorkContext) string {
 		return "You've reached your weekly goal! No more hours needed."
 	}
 
+	if containsAny(question, "average", "avg", "typical day") {
+		answer := "You haven't logged a full day this week yet."
+		if ctx.DaysWorked > 0 {
+			answer = fmt.Sprintf("This week you've averaged %.2f hours per day over %d days.", ctx.WeekHours/float64(ctx.DaysWorked), ctx.DaysWorked)
+		}
+		if ctx.AvgDayHours > 0 {
+			answer += fmt.Sprintf(" This month your average day is %.2f hours, usually %s to %s.", ctx.AvgDayHours, ctx.AvgStart, ctx.AvgEnd)
+		}
+		return answer
+	}
+
+	if strings.Contains(question, "month") {
+		if ctx.MonthGoal > 0 {
+			if left := ctx.MonthGoal - ctx.MonthHours; left > 0 {
+				return fmt.Sprintf("You've worked %.2f hours this month of a %.2fh goal (%.2f to go).", ctx.MonthHours, ctx.MonthGoal, left)
+			}
+			return fmt.Sprintf("You've worked %.2f hours this month, past your %.2fh goal.", ctx.MonthHours, ctx.MonthGoal)
+		}
+		return fmt.Sprintf("You've worked %.2f hours this month.", ctx.MonthHours)
+	}
+
 	if strings.Contains(question, "behind") || strings.Contains(question, "track") {
 		if ctx.RemainingDays > 0 && ctx.DailyTarget > 0 {
 			return fmt.Sprintf("You need %.2f hours/day to hit your goal. You have %d days left.", ctx.DailyTarget, ctx.RemainingDays)
@@ -140,9 +363,19 @@ func (s *AIService) offlineAsk(question string, ctx *WorkContext) string {
 	return fmt.Sprintf("Current status: %.2f/%.2f hours this week (%.2f remaining). You need %.2f hours/day over %d days. AI is unavailable - install Ollama for smart insights!", ctx.WeekHours, ctx.WeeklyGoal, ctx.RemainingHours, ctx.DailyTarget, ctx.RemainingDays)
 }
 
+// containsAny reports whether s contains any of the phrases
+func containsAny(s string, phrases ...string) bool {
+	for _, phrase := range phrases {
+		if strings.Contains(s, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
 // offlinePredict provides rule-based predictions
 func (s *AIService) offlinePredict(weekProgress *tracker.WeekProgress) string {
-	remainingDays := work.RemainingWorkDaysInWeek(s.now())
+	remainingDays := weekProgress.RemainingWorkDays
 	goal := weeklyGoalFromProgress(weekProgress)
 
 	if weekProgress.RemainingHours <= 0 {
@@ -158,25 +391,38 @@ func (s *AIService) offlinePredict(weekProgress *tracker.WeekProgress) string {
 	return fmt.Sprintf("Prediction: You need %.2f more hours to reach your %.2fh weekly goal. That's %.2f hours/day over %d remaining work days. AI unavailable - install Ollama for detailed analysis!", weekProgress.RemainingHours, goal, dailyTarget, remainingDays)
 }
 
-// offlineAnalyze provides basic analysis without AI
+// offlineAnalyze provides basic analysis without AI, with the history
+// comparison when the querier asks for it
 func (s *AIService) offlineAnalyze(dq *DataQuerier) string {
 	// Get basic stats
-	week, _ := dq.GetWeekHours()
-	goal := dq.weeklyGoal()
+	progress, err := dq.tracker.GetWeeklyProgress()
+	if err != nil {
+		return fmt.Sprintf("Analysis unavailable: %v", err)
+	}
+	week, goal := progress.TotalHours, progress.Goal
 
+	var analysis string
 	if week >= goal {
-		return "You've already hit your weekly goal! Great consistency this week."
+		analysis = "You've already hit your weekly goal! Great consistency this week."
+	} else {
+		remaining := goal - week
+		daysLeft := work.RemainingWorkDaysInWeekFrom(dq.tracker.Rules(), s.now(), dq.tracker.WeekStartDay())
+		if daysLeft > 0 {
+			daily := remaining / float64(daysLeft)
+			analysis = fmt.Sprintf("Analysis: %.2f/%.2f hours this week (%.2f remaining). At %.2fh/day over %d days, you can still reach your goal.", week, goal, remaining, daily, daysLeft)
+		} else {
+			analysis = fmt.Sprintf("Analysis: You've logged %.2f/%.2f hours this week with no days left. Install Ollama for smarter insights!", week, goal)
+		}
 	}
 
-	remaining := goal - week
-	daysLeft := work.RemainingWorkDaysInWeek(s.now())
-
-	if daysLeft > 0 {
-		daily := remaining / float64(daysLeft)
-		return fmt.Sprintf("Analysis: %.2f/%.2f hours this week (%.2f remaining). At %.2fh/day over %d days, you can still reach your goal.", week, goal, remaining, daily, daysLeft)
+	if dq.compareHistory {
+		comparison, err := dq.GetHistoryComparison()
+		if err != nil {
+			return analysis + fmt.Sprintf("\nHistory comparison unavailable: %v", err)
+		}
+		analysis += "\n" + comparison.Summary() + "."
 	}
-
-	return fmt.Sprintf("Analysis: You've logged %.2f/%.2f hours this week with no days left. Install Ollama for smarter insights!", week, goal)
+	return analysis
 }
 
 // WorkContext contains all the work data for AI queries
@@ -192,23 +438,120 @@ type WorkContext struct {
 	IsWorking           bool
 	CurrentSessionStart string
 	DailyBreakdown      map[string]float64
+	// BreakRules describes the configured breaks, e.g. "30min (Fri: 0min)"
+	BreakRules string
+	// LeaveTime is when the running session reaches DailyTarget (after the
+	// default break), e.g. "17:05"; empty when not working or the goal is met
+	LeaveTime string
+
+	// Month goal and typical schedule (from the month's completed sessions)
+	MonthGoal   float64
+	AvgStart    string
+	AvgEnd      string
+	AvgDayHours float64
+
+	// ProjectHours maps project name to completed hours this week
+	ProjectHours map[string]float64
+
+	// PeriodLabel/PeriodHours answer a question about a specific month,
+	// including archived months (see MonthInQuestion)
+	PeriodLabel string
+	PeriodHours float64
+
+	// Unavailable lists sections (today, week, month) whose query failed and
+	// were zero-filled
+	Unavailable []string
 }
 
-// BuildWorkContext creates a comprehensive context from tracker data
-func BuildWorkContext(t *tracker.Tracker) (*WorkContext, error) {
-	dayProgress, err := t.GetTodayProgress()
-	if err != nil {
-		return nil, err
+// breakRules returns BreakRules, or the default rules for hand-built contexts
+func (c *WorkContext) breakRules() string {
+	if c.BreakRules != "" {
+		return c.BreakRules
 	}
+	return work.Rules{}.BreakSummary()
+}
 
-	weekProgress, err := t.GetWeeklyProgress()
-	if err != nil {
-		return nil, err
+// scheduleDetails renders the optional month/schedule/project data and any
+// unavailable sections as prompt lines, or "" when there is nothing to add
+func (c *WorkContext) scheduleDetails() string {
+	var sb strings.Builder
+	if c.MonthGoal > 0 {
+		sb.WriteString(fmt.Sprintf("- Month goal: %.2f hours (%.2f worked)\n", c.MonthGoal, c.MonthHours))
+	}
+	if c.LeaveTime != "" {
+		sb.WriteString(fmt.Sprintf("- Clocking out at %s today keeps pace for the weekly goal\n", c.LeaveTime))
+	}
+	if c.AvgStart != "" && c.AvgEnd != "" {
+		sb.WriteString(fmt.Sprintf("- Typical day this month: %s-%s, %.2f hours average\n", c.AvgStart, c.AvgEnd, c.AvgDayHours))
+	}
+	if len(c.ProjectHours) > 0 {
+		names := make([]string, 0, len(c.ProjectHours))
+		for name := range c.ProjectHours {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		parts := make([]string, 0, len(names))
+		for _, name := range names {
+			parts = append(parts, fmt.Sprintf("%s=%.2fh", name, c.ProjectHours[name]))
+		}
+		sb.WriteString("- Projects this week: " + strings.Join(parts, ", ") + "\n")
 	}
+	if c.PeriodLabel != "" {
+		sb.WriteString(fmt.Sprintf("- Hours worked in %s: %.2f\n", c.PeriodLabel, c.PeriodHours))
+	}
+	if len(c.Unavailable) > 0 {
+		sb.WriteString("- Unavailable (query failed, shown as 0): " + strings.Join(c.Unavailable, ", ") + "\n")
+	}
+	return sb.String()
+}
 
-	monthProgress, err := t.GetMonthlyProgress()
-	if err != nil {
-		return nil, err
+// ProgressSource is the tracker data BuildWorkContext reads; *tracker.Tracker
+// satisfies it
+type ProgressSource interface {
+	GetTodayProgress() (*tracker.DayProgress, error)
+	GetWeeklyProgress() (*tracker.WeekProgress, error)
+	GetMonthlyProgress() (*tracker.MonthProgress, error)
+	GetActiveSession() (*storage.WorkSession, error)
+	WeeklyGoal() float64
+	MonthlyGoal(date time.Time) float64
+	Rules() work.Rules
+	WeekStartDay() time.Weekday
+	Now() time.Time
+}
+
+// BuildWorkContext creates a comprehensive context from tracker data. A
+// failing progress query leaves its section zeroed and listed in Unavailable;
+// an error is returned only when every query fails.
+func BuildWorkContext(t ProgressSource) (*WorkContext, error) {
+	var unavailable []string
+
+	dayProgress, dayErr := t.GetTodayProgress()
+	if dayErr != nil {
+		dayProgress = &tracker.DayProgress{}
+		unavailable = append(unavailable, "today")
+	}
+
+	weekProgress, weekErr := t.GetWeeklyProgress()
+	if weekErr != nil {
+		weekProgress = &tracker.WeekProgress{
+			DaysWorked:     make(map[string]float64),
+			RemainingHours: t.WeeklyGoal(),
+		}
+		unavailable = append(unavailable, "week")
+	}
+
+	monthProgress, monthErr := t.GetMonthlyProgress()
+	if monthErr != nil {
+		now := t.Now()
+		monthProgress = &tracker.MonthProgress{
+			Month:     time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()),
+			WeekHours: make(map[int]float64),
+		}
+		unavailable = append(unavailable, "month")
+	}
+
+	if dayErr != nil && weekErr != nil && monthErr != nil {
+		return nil, fmt.Errorf("no progress data available: %w", dayErr)
 	}
 
 	activeSession, _ := t.GetActiveSession()
@@ -220,9 +563,33 @@ func BuildWorkContext(t *tracker.Tracker) (*WorkContext, error) {
 		WeeklyGoal:     t.WeeklyGoal(),
 		RemainingHours: weekProgress.RemainingHours,
 		DaysWorked:     weekProgress.DaysWorkedCount,
-		RemainingDays:  work.RemainingWorkDaysInWeek(t.Now()),
+		RemainingDays:  work.RemainingWorkDaysInWeekFrom(t.Rules(), t.Now(), t.WeekStartDay()),
 		DailyBreakdown: weekProgress.DaysWorked,
+		BreakRules:     t.Rules().BreakSummary(),
 		IsWorking:      activeSession != nil,
+		MonthGoal:      t.MonthlyGoal(monthProgress.Month),
+		Unavailable:    unavailable,
+	}
+	if weekProgress.DayTargets != nil {
+		// The week's own goal, less any holidays
+		ctx.WeeklyGoal = weekProgress.Goal
+	}
+
+	for _, session := range weekProgress.Sessions {
+		hours, complete := storage.SessionNetHours(session, t.Now())
+		if session.Project == "" || !complete {
+			continue
+		}
+		if ctx.ProjectHours == nil {
+			ctx.ProjectHours = make(map[string]float64)
+		}
+		ctx.ProjectHours[session.Project] += hours
+	}
+
+	if schedule := tracker.ComputeScheduleStats(monthProgress.Sessions); schedule.DaysCounted > 0 {
+		ctx.AvgStart = tracker.FormatClock(schedule.AvgStart)
+		ctx.AvgEnd = tracker.FormatClock(schedule.AvgEnd)
+		ctx.AvgDayHours = schedule.AvgDayHours
 	}
 
 	if ctx.RemainingDays > 0 && ctx.RemainingHours > 0 {
@@ -231,6 +598,14 @@ func BuildWorkContext(t *tracker.Tracker) (*WorkContext, error) {
 
 	if activeSession != nil {
 		ctx.CurrentSessionStart = activeSession.StartTime.Format("15:04")
+		if ctx.DailyTarget > 0 {
+			// Running time already counted in the week (include_active) is
+			// part of what the session has to cover
+			net := ctx.DailyTarget + weekProgress.ActiveHours
+			end := activeSession.StartTime.Add(time.Duration(net * float64(time.Hour)))
+			end = end.Add(time.Duration(work.GetBreakMinutesForShift(t.Rules(), activeSession.StartTime, end)) * time.Minute)
+			ctx.LeaveTime = end.Format("15:04")
+		}
 	}
 
 	return ctx, nil
@@ -239,18 +614,24 @@ func BuildWorkContext(t *tracker.Tracker) (*WorkContext, error) {
 // ==================== Ollama Provider ====================
 
 type OllamaProvider struct {
-	baseURL string
-	model   string
-	client  *http.Client
-	loc     *time.Location
-}
-
-func NewOllamaProvider(baseURL, model string, loc *time.Location) *OllamaProvider {
+	baseURL      string
+	model        string
+	systemPrompt string // replaces the default instructions in ask prompts
+	timeouts     Timeouts
+	client       *http.Client
+	retry        Retry
+	loc          *time.Location
+	usageMeter
+}
+
+func NewOllamaProvider(baseURL, model string, loc *time.Location, timeouts Timeouts) *OllamaProvider {
+	timeouts = timeouts.withDefaults()
 	return &OllamaProvider{
-		baseURL: baseURL,
-		model:   model,
+		baseURL:  baseURL,
+		model:    model,
+		timeouts: timeouts,
 		client: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout: timeouts.Request,
 		},
 		loc: loc,
 	}
@@ -268,21 +649,19 @@ func (o *OllamaProvider) Name() string {
 }
 
 func (o *OllamaProvider) IsAvailable() bool {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	return o.CheckAvailable() == nil
+}
+
+// CheckAvailable lists the server's models to see that Ollama is running
+func (o *OllamaProvider) CheckAvailable() error {
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeouts.Probe)
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(ctx, "GET", o.baseURL+"/api/tags", nil)
 	if err != nil {
-		return false
+		return err
 	}
-
-	resp, err := o.client.Do(req)
-	if err != nil {
-		return false
-	}
-	defer resp.Body.Close()
-
-	return resp.StatusCode == 200
+	return probeStatus(o.client, req)
 }
 
 func (o *OllamaProvider) Ask(question string, ctx *WorkContext) (string, error) {
@@ -291,7 +670,7 @@ func (o *OllamaProvider) Ask(question string, ctx *WorkContext) (string, error)
 }
 
 func (o *OllamaProvider) Predict(weekProgress *tracker.WeekProgress) (string, error) {
-	remainingDays := work.RemainingWorkDaysInWeek(o.now())
+	remainingDays := weekProgress.RemainingWorkDays
 	dailyTarget := 0.0
 	if remainingDays > 0 && weekProgress.RemainingHours > 0 {
 		dailyTarget = weekProgress.RemainingHours / float64(remainingDays)
@@ -342,7 +721,7 @@ Keep it concise (3-4 sentences max).`, dataContext)
 }
 
 func (o *OllamaProvider) query(prompt string) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeouts.Request)
 	defer cancel()
 
 	reqBody := map[string]interface{}{
@@ -362,7 +741,7 @@ func (o *OllamaProvider) query(prompt string) (string, error) {
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := o.client.Do(req)
+	resp, err := o.retry.do(o.client, req)
 	if err != nil {
 		return "", fmt.Errorf("failed to connect to Ollama: %w", err)
 	}
@@ -382,6 +761,7 @@ func (o *OllamaProvider) query(prompt string) (string, error) {
 		return "", fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	o.record(result.usage())
 	return result.Response, nil
 }
 
@@ -391,7 +771,16 @@ func (o *OllamaProvider) buildPrompt(question string, ctx *WorkContext) string {
 		workingStatus = fmt.Sprintf("Currently working (started at %s)", ctx.CurrentSessionStart)
 	}
 
-	return fmt.Sprintf(`You are a helpful work hours assistant with access to the user's time tracking data.
+	// A custom system prompt replaces both the opening and the closing
+	// instruction; Ollama's generate API has no system role, so it leads
+	// the prompt
+	intro := "You are a helpful work hours assistant with access to the user's time tracking data."
+	outro := "\n\nAnswer based on the data above. Be concise and helpful."
+	if o.systemPrompt != "" {
+		intro, outro = o.systemPrompt, ""
+	}
+
+	return fmt.Sprintf(`%s
 
 Current Status:
 - %s
@@ -402,11 +791,10 @@ Current Status:
 - Remaining to weekly goal: %.2f hours
 - Remaining work days: %d
 - Required daily average: %.2f hours
-- Standard break: %d minutes (no break on Fridays)
-
-User question: "%s"
-
-Answer based on the data above. Be concise and helpful.`,
+- Standard break: %s
+%s
+User question: "%s"%s`,
+		intro,
 		workingStatus,
 		ctx.TodayHours,
 		ctx.WeekHours,
@@ -416,30 +804,45 @@ Answer based on the data above. Be concise and helpful.`,
 		ctx.RemainingHours,
 		ctx.RemainingDays,
 		ctx.DailyTarget,
-		work.DefaultBreakMinutes,
-		question)
+		ctx.breakRules(),
+		ctx.scheduleDetails(),
+		question, outro)
 }
 
 type OllamaResponse struct {
-	Response string `json:"response"`
-	Done     bool   `json:"done"`
+	Response        string `json:"response"`
+	Done            bool   `json:"done"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+}
+
+func (r OllamaResponse) usage() Usage {
+	return Usage{PromptTokens: r.PromptEvalCount, CompletionTokens: r.EvalCount}
 }
 
 // ==================== OpenAI Provider ====================
 
 type OpenAIProvider struct {
-	model  string
-	apiKey string
-	client *http.Client
-	loc    *time.Location
-}
-
-func NewOpenAIProvider(model, apiKey string, loc *time.Location) *OpenAIProvider {
+	model        string
+	apiKey       string
+	baseURL      string // API root; chat and models paths are appended
+	systemPrompt string // replaces the default instructions in ask prompts
+	timeouts     Timeouts
+	client       *http.Client
+	retry        Retry
+	loc          *time.Location
+	usageMeter
+}
+
+func NewOpenAIProvider(model, apiKey string, loc *time.Location, timeouts Timeouts) *OpenAIProvider {
+	timeouts = timeouts.withDefaults()
 	return &OpenAIProvider{
-		model:  model,
-		apiKey: apiKey,
+		model:    model,
+		apiKey:   apiKey,
+		baseURL:  config.DefaultOpenAIBaseURL,
+		timeouts: timeouts,
 		client: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout: timeouts.Request,
 		},
 		loc: loc,
 	}
@@ -457,25 +860,23 @@ func (o *OpenAIProvider) Name() string {
 }
 
 func (o *OpenAIProvider) IsAvailable() bool {
+	return o.CheckAvailable() == nil
+}
+
+// CheckAvailable lists the account's models, which needs a valid key
+func (o *OpenAIProvider) CheckAvailable() error {
 	if o.apiKey == "" {
-		return false
+		return fmt.Errorf("API key not set")
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeouts.Probe)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.openai.com/v1/models", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", o.baseURL+"/models", nil)
 	if err != nil {
-		return false
+		return err
 	}
 	req.Header.Set("Authorization", "Bearer "+o.apiKey)
-
-	resp, err := o.client.Do(req)
-	if err != nil {
-		return false
-	}
-	defer resp.Body.Close()
-
-	return resp.StatusCode == 200
+	return probeStatus(o.client, req)
 }
 
 func (o *OpenAIProvider) Ask(question string, ctx *WorkContext) (string, error) {
@@ -483,7 +884,7 @@ func (o *OpenAIProvider) Ask(question string, ctx *WorkContext) (string, error)
 }
 
 func (o *OpenAIProvider) Predict(weekProgress *tracker.WeekProgress) (string, error) {
-	remainingDays := work.RemainingWorkDaysInWeek(o.now())
+	remainingDays := weekProgress.RemainingWorkDays
 	dailyTarget := 0.0
 	if remainingDays > 0 && weekProgress.RemainingHours > 0 {
 		dailyTarget = weekProgress.RemainingHours / float64(remainingDays)
@@ -530,15 +931,19 @@ func (o *OpenAIProvider) buildMessages(question string, ctx *WorkContext) []Open
 		status = fmt.Sprintf("Working since %s", ctx.CurrentSessionStart)
 	}
 
-	systemMsg := fmt.Sprintf(`You are a helpful work hours assistant. Current user data:
+	systemMsg := fmt.Sprintf(`%s
+Current user data:
 - Today: %.2f hours
 - This week: %.2f hours (goal: %.2f hours)
 - This month: %.2f hours
 - Status: %s
 - Days worked: %d
-- Remaining: %.2f hours over %d days (%.2f h/day)`,
+- Remaining: %.2f hours over %d days (%.2f h/day)
+%s`,
+		instructions(o.systemPrompt, "You are a helpful work hours assistant."),
 		ctx.TodayHours, ctx.WeekHours, ctx.WeeklyGoal,
-		ctx.MonthHours, status, ctx.DaysWorked, ctx.RemainingHours, ctx.RemainingDays, ctx.DailyTarget)
+		ctx.MonthHours, status, ctx.DaysWorked, ctx.RemainingHours, ctx.RemainingDays, ctx.DailyTarget,
+		ctx.scheduleDetails())
 
 	return []OpenAIMessage{
 		{Role: "system", Content: systemMsg},
@@ -561,17 +966,17 @@ func (o *OpenAIProvider) chatCompletion(messages []OpenAIMessage) (string, error
 		return "", err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeouts.Request)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/chat/completions", bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", o.baseURL+"/chat/completions", bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return "", err
 	}
 	req.Header.Set("Authorization", "Bearer "+o.apiKey)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := o.client.Do(req)
+	resp, err := o.retry.do(o.client, req)
 	if err != nil {
 		return "", fmt.Errorf("OpenAI request failed: %w", err)
 	}
@@ -591,6 +996,7 @@ func (o *OpenAIProvider) chatCompletion(messages []OpenAIMessage) (string, error
 		return "", fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	o.record(result.Usage.usage())
 	if len(result.Choices) == 0 {
 		return "", fmt.Errorf("no response from OpenAI")
 	}
@@ -604,31 +1010,59 @@ type OpenAIMessage struct {
 }
 
 type OpenAIRequest struct {
-	Model    string          `json:"model"`
-	Messages []OpenAIMessage `json:"messages"`
+	Model         string               `json:"model"`
+	Messages      []OpenAIMessage      `json:"messages"`
+	Stream        bool                 `json:"stream,omitempty"`
+	StreamOptions *OpenAIStreamOptions `json:"stream_options,omitempty"`
+}
+
+// OpenAIStreamOptions asks for a final stream event carrying the usage
+type OpenAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 type OpenAIResponse struct {
 	Choices []struct {
 		Message OpenAIMessage `json:"message"`
 	} `json:"choices"`
+	Usage OpenAIUsage `json:"usage"`
 }
 
-// ==================== Claude Provider ====================
+type OpenAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
 
-type ClaudeProvider struct {
-	model  string
-	apiKey string
-	client *http.Client
-	loc    *time.Location
+func (u OpenAIUsage) usage() Usage {
+	return Usage{PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens}
 }
 
-func NewClaudeProvider(model, apiKey string, loc *time.Location) *ClaudeProvider {
+// ==================== Claude Provider ====================
+
+// claudeBaseURL is the Anthropic API root
+const claudeBaseURL = "https://api.anthropic.com"
+
+type ClaudeProvider struct {
+	model        string
+	apiKey       string
+	baseURL      string
+	systemPrompt string // replaces the default instructions in ask prompts
+	timeouts     Timeouts
+	client       *http.Client
+	retry        Retry
+	loc          *time.Location
+	usageMeter
+}
+
+func NewClaudeProvider(model, apiKey string, loc *time.Location, timeouts Timeouts) *ClaudeProvider {
+	timeouts = timeouts.withDefaults()
 	return &ClaudeProvider{
-		model:  model,
-		apiKey: apiKey,
+		model:    model,
+		apiKey:   apiKey,
+		baseURL:  claudeBaseURL,
+		timeouts: timeouts,
 		client: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout: timeouts.Request,
 		},
 		loc: loc,
 	}
@@ -646,26 +1080,25 @@ func (c *ClaudeProvider) Name() string {
 }
 
 func (c *ClaudeProvider) IsAvailable() bool {
+	return c.CheckAvailable() == nil
+}
+
+// CheckAvailable lists one model, a free call that fails with 401 for a
+// wrong key
+func (c *ClaudeProvider) CheckAvailable() error {
 	if c.apiKey == "" {
-		return false
+		return fmt.Errorf("API key not set")
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Probe)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.anthropic.com/v1/messages", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/v1/models?limit=1", nil)
 	if err != nil {
-		return false
+		return err
 	}
 	req.Header.Set("x-api-key", c.apiKey)
 	req.Header.Set("anthropic-version", "2023-06-01")
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return false
-	}
-	defer resp.Body.Close()
-
-	return resp.StatusCode == 200 || resp.StatusCode == 401 // 401 = valid key, wrong endpoint
+	return probeStatus(c.client, req)
 }
 
 func (c *ClaudeProvider) Ask(question string, ctx *WorkContext) (string, error) {
@@ -673,7 +1106,7 @@ func (c *ClaudeProvider) Ask(question string, ctx *WorkContext) (string, error)
 }
 
 func (c *ClaudeProvider) Predict(weekProgress *tracker.WeekProgress) (string, error) {
-	remainingDays := work.RemainingWorkDaysInWeek(c.now())
+	remainingDays := weekProgress.RemainingWorkDays
 	dailyTarget := 0.0
 	if remainingDays > 0 && weekProgress.RemainingHours > 0 {
 		dailyTarget = weekProgress.RemainingHours / float64(remainingDays)
@@ -708,13 +1141,16 @@ func (c *ClaudeProvider) buildPrompt(question string, ctx *WorkContext) string {
 		status = fmt.Sprintf("Working since %s", ctx.CurrentSessionStart)
 	}
 
-	return fmt.Sprintf(`You are a helpful work hours assistant. Current data:
+	return fmt.Sprintf(`%s
+Current data:
 - Today: %.2f hours | Week: %.2f/%.2f | Month: %.2f hours
 - Status: %s | Days: %d | Remaining: %.2fh (%d days, %.2fh/day)
-
+%s
 Question: %s`,
+		instructions(c.systemPrompt, "You are a helpful work hours assistant."),
 		ctx.TodayHours, ctx.WeekHours, ctx.WeeklyGoal, ctx.MonthHours,
 		status, ctx.DaysWorked, ctx.RemainingHours, ctx.RemainingDays, ctx.DailyTarget,
+		ctx.scheduleDetails(),
 		question)
 }
 
@@ -736,10 +1172,10 @@ func (c *ClaudeProvider) claudeMessage(prompt string) (string, error) {
 		return "", err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeouts.Request)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/v1/messages", bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return "", err
 	}
@@ -747,7 +1183,7 @@ func (c *ClaudeProvider) claudeMessage(prompt string) (string, error) {
 	req.Header.Set("anthropic-version", "2023-06-01")
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.client.Do(req)
+	resp, err := c.retry.do(c.client, req)
 	if err != nil {
 		return "", fmt.Errorf("Claude request failed: %w", err)
 	}
@@ -767,6 +1203,7 @@ func (c *ClaudeProvider) claudeMessage(prompt string) (string, error) {
 		return "", fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	c.record(Usage{PromptTokens: result.Usage.InputTokens, CompletionTokens: result.Usage.OutputTokens})
 	return result.Content[0].Text, nil
 }
 
@@ -785,23 +1222,33 @@ type ClaudeResponse struct {
 	Content []struct {
 		Text string `json:"text"`
 	} `json:"content"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
 }
 
 // ==================== Gemini Provider ====================
 
 type GeminiProvider struct {
-	model  string
-	apiKey string
-	client *http.Client
-	loc    *time.Location
-}
-
-func NewGeminiProvider(model, apiKey string, loc *time.Location) *GeminiProvider {
+	model        string
+	apiKey       string
+	systemPrompt string // replaces the default instructions in ask prompts
+	timeouts     Timeouts
+	client       *http.Client
+	retry        Retry
+	loc          *time.Location
+	usageMeter
+}
+
+func NewGeminiProvider(model, apiKey string, loc *time.Location, timeouts Timeouts) *GeminiProvider {
+	timeouts = timeouts.withDefaults()
 	return &GeminiProvider{
-		model:  model,
-		apiKey: apiKey,
+		model:    model,
+		apiKey:   apiKey,
+		timeouts: timeouts,
 		client: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout: timeouts.Request,
 		},
 		loc: loc,
 	}
@@ -819,25 +1266,24 @@ func (g *GeminiProvider) Name() string {
 }
 
 func (g *GeminiProvider) IsAvailable() bool {
+	return g.CheckAvailable() == nil
+}
+
+// CheckAvailable looks up the configured model, which needs a valid key
+func (g *GeminiProvider) CheckAvailable() error {
 	if g.apiKey == "" {
-		return false
+		return fmt.Errorf("API key not set")
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), g.timeouts.Probe)
 	defer cancel()
 
 	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s?key=%s", g.model, g.apiKey)
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return false
-	}
-
-	resp, err := g.client.Do(req)
-	if err != nil {
-		return false
+		return err
 	}
-	defer resp.Body.Close()
-
-	return resp.StatusCode == 200
+	// Gemini answers a bad key with 400 rather than 401
+	return probeStatus(g.client, req)
 }
 
 func (g *GeminiProvider) Ask(question string, ctx *WorkContext) (string, error) {
@@ -845,7 +1291,7 @@ func (g *GeminiProvider) Ask(question string, ctx *WorkContext) (string, error)
 }
 
 func (g *GeminiProvider) Predict(weekProgress *tracker.WeekProgress) (string, error) {
-	remainingDays := work.RemainingWorkDaysInWeek(g.now())
+	remainingDays := weekProgress.RemainingWorkDays
 	weeklyGoal := weeklyGoalFromProgress(weekProgress)
 
 	prompt := fmt.Sprintf(`Work hours prediction: %.2f/%.2f hours, %d days worked, %.2f remaining over %d days.
@@ -872,10 +1318,14 @@ func (g *GeminiProvider) buildPrompt(question string, ctx *WorkContext) string {
 		status = fmt.Sprintf("Working since %s", ctx.CurrentSessionStart)
 	}
 
-	return fmt.Sprintf(`Work hours assistant. Current: Today=%.2fh, Week=%.2f/%.2f, Month=%.2f, Status=%s, Days=%d, Remaining=%.2fh/%dd@%.2fh/day. Question: %s`,
+	prompt := fmt.Sprintf(`%s Current: Today=%.2fh, Week=%.2f/%.2f, Month=%.2f, Status=%s, Days=%d, Remaining=%.2fh/%dd@%.2fh/day.`,
+		instructions(g.systemPrompt, "Work hours assistant."),
 		ctx.TodayHours, ctx.WeekHours, ctx.WeeklyGoal, ctx.MonthHours,
-		status, ctx.DaysWorked, ctx.RemainingHours, ctx.RemainingDays, ctx.DailyTarget,
-		question)
+		status, ctx.DaysWorked, ctx.RemainingHours, ctx.RemainingDays, ctx.DailyTarget)
+	if details := ctx.scheduleDetails(); details != "" {
+		prompt += "\n" + details
+	}
+	return prompt + " Question: " + question
 }
 
 func (g *GeminiProvider) geminiGenerate(prompt string) (string, error) {
@@ -900,7 +1350,7 @@ func (g *GeminiProvider) geminiGenerate(prompt string) (string, error) {
 		return "", err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), g.timeouts.Request)
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBody))
@@ -909,7 +1359,7 @@ func (g *GeminiProvider) geminiGenerate(prompt string) (string, error) {
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := g.client.Do(req)
+	resp, err := g.retry.do(g.client, req)
 	if err != nil {
 		return "", fmt.Errorf("Gemini request failed: %w", err)
 	}
@@ -929,6 +1379,7 @@ func (g *GeminiProvider) geminiGenerate(prompt string) (string, error) {
 		return "", fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	g.record(Usage{PromptTokens: result.UsageMetadata.PromptTokenCount, CompletionTokens: result.UsageMetadata.CandidatesTokenCount})
 	if len(result.Candidates) == 0 || len(result.Candidates[0].Content.Parts) == 0 {
 		return "", fmt.Errorf("no response from Gemini")
 	}
@@ -952,6 +1403,10 @@ type GeminiResponse struct {
 	Candidates []struct {
 		Content GeminiContent `json:"content"`
 	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
 }
 
 func weeklyGoalFromProgress(weekProgress *tracker.WeekProgress) float64 {
@@ -0,0 +1,263 @@
+package ai
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kairos/internal/config"
+	"github.com/kairos/internal/storage"
+	"github.com/kairos/internal/tracker"
+	"github.com/kairos/internal/work"
+)
+
+type stubProgress struct {
+	now      time.Time
+	monthErr error
+	active   *storage.WorkSession
+	sessions []storage.WorkSession // this month's
+}
+
+func (s *stubProgress) GetTodayProgress() (*tracker.DayProgress, error) {
+	return &tracker.DayProgress{Date: s.now, TotalHours: 3}, nil
+}
+
+func (s *stubProgress) GetWeeklyProgress() (*tracker.WeekProgress, error) {
+	return &tracker.WeekProgress{
+		TotalHours:      12,
+		DaysWorked:      map[string]float64{"2024-01-15": 12},
+		DaysWorkedCount: 1,
+		RemainingHours:  26.5,
+	}, nil
+}
+
+func (s *stubProgress) GetMonthlyProgress() (*tracker.MonthProgress, error) {
+	if s.monthErr != nil {
+		return nil, s.monthErr
+	}
+	return &tracker.MonthProgress{Month: s.now, TotalHours: 40, WeekHours: map[int]float64{}, Sessions: s.sessions}, nil
+}
+
+func (s *stubProgress) GetActiveSession() (*storage.WorkSession, error) { return s.active, nil }
+func (s *stubProgress) WeeklyGoal() float64                             { return 38.5 }
+func (s *stubProgress) MonthlyGoal(date time.Time) float64              { return 160 }
+func (s *stubProgress) Rules() work.Rules                               { return work.Rules{} }
+func (s *stubProgress) WeekStartDay() time.Weekday                      { return time.Monday }
+func (s *stubProgress) Now() time.Time                                  { return s.now }
+
+func TestBuildWorkContextMonthFailure(t *testing.T) {
+	src := &stubProgress{
+		now:      time.Date(2024, 1, 17, 10, 0, 0, 0, time.UTC),
+		monthErr: errors.New("database is locked"),
+	}
+
+	ctx, err := BuildWorkContext(src)
+	if err != nil {
+		t.Fatalf("expected partial context, got error: %v", err)
+	}
+	if ctx.TodayHours != 3 || ctx.WeekHours != 12 {
+		t.Errorf("today/week = %.1f/%.1f, want 3/12", ctx.TodayHours, ctx.WeekHours)
+	}
+	if ctx.MonthHours != 0 {
+		t.Errorf("MonthHours = %.1f, want 0", ctx.MonthHours)
+	}
+	if len(ctx.Unavailable) != 1 || ctx.Unavailable[0] != "month" {
+		t.Errorf("Unavailable = %v, want [month]", ctx.Unavailable)
+	}
+	if !strings.Contains(ctx.scheduleDetails(), "Unavailable (query failed, shown as 0): month") {
+		t.Errorf("prompt details missing unavailable note:\n%s", ctx.scheduleDetails())
+	}
+}
+
+func TestBuildWorkContextSchedule(t *testing.T) {
+	session := func(day, startHour, startMin, endHour, endMin, breakMinutes int) storage.WorkSession {
+		start := time.Date(2024, 1, day, startHour, startMin, 0, 0, time.UTC)
+		end := time.Date(2024, 1, day, endHour, endMin, 0, 0, time.UTC)
+		return storage.WorkSession{Date: start, StartTime: start, EndTime: &end, BreakMinutes: breakMinutes}
+	}
+	src := &stubProgress{
+		now: time.Date(2024, 1, 17, 10, 0, 0, 0, time.UTC),
+		sessions: []storage.WorkSession{
+			session(15, 8, 30, 12, 0, 0), // one day in two sessions: 08:30-17:00, 7.5h
+			session(15, 13, 0, 17, 0, 0),
+			session(16, 9, 0, 17, 30, 30), // 8h
+			{Date: time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC), StartTime: time.Date(2024, 1, 17, 7, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	ctx, err := BuildWorkContext(src)
+	if err != nil {
+		t.Fatalf("BuildWorkContext: %v", err)
+	}
+	// The open session leaves the averages alone
+	if ctx.MonthGoal != 160 || ctx.AvgStart != "08:45" || ctx.AvgEnd != "17:15" || ctx.AvgDayHours != 7.75 {
+		t.Errorf("month goal %.2f, typical day %s-%s %.2fh; want 160, 08:45-17:15 7.75h",
+			ctx.MonthGoal, ctx.AvgStart, ctx.AvgEnd, ctx.AvgDayHours)
+	}
+	details := ctx.scheduleDetails()
+	for _, want := range []string{"Month goal: 160.00 hours (40.00 worked)", "Typical day this month: 08:45-17:15, 7.75 hours average"} {
+		if !strings.Contains(details, want) {
+			t.Errorf("prompt details missing %q:\n%s", want, details)
+		}
+	}
+}
+
+func TestEchoProviderService(t *testing.T) {
+	svc := NewAIService(&config.Config{AIProvider: config.ProviderEcho})
+	if err := svc.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if !svc.IsAvailable() || svc.Name() != "Echo" {
+		t.Fatalf("echo provider not active: %s", svc.Name())
+	}
+
+	ctx := &WorkContext{TodayHours: 3, WeekHours: 12, WeeklyGoal: 38.5, RemainingHours: 26.5}
+	answer, err := svc.Ask("Can I leave?", ctx)
+	if err != nil {
+		t.Fatalf("Ask: %v", err)
+	}
+	want := "echo: Can I leave? | today=3.00h week=12.00/38.50h month=0.00h remaining=26.50h"
+	if answer != want {
+		t.Errorf("Ask = %q, want %q", answer, want)
+	}
+
+	prediction, _ := svc.Predict(&tracker.WeekProgress{TotalHours: 12, RemainingHours: 26.5, DaysWorkedCount: 2})
+	if prediction != "echo: predict | week=12.00h remaining=26.50h days=2" {
+		t.Errorf("Predict = %q", prediction)
+	}
+}
+
+func TestInitializeRejectsMissingAPIKey(t *testing.T) {
+	svc := NewAIService(&config.Config{AIProvider: config.ProviderOpenAI, OpenAIModel: "gpt-4"})
+
+	err := svc.Initialize()
+	var verr *config.ValidationError
+	if !errors.As(err, &verr) || verr.Field != "OpenAIAPIKey" {
+		t.Fatalf("Initialize error = %v, want OpenAIAPIKey validation error", err)
+	}
+	if svc.IsAvailable() {
+		t.Error("provider with no API key should not be available")
+	}
+	if err := svc.CheckAvailable(); err == nil || !strings.Contains(err.Error(), "OPENAI_API_KEY") {
+		t.Errorf("CheckAvailable = %v, want guidance naming OPENAI_API_KEY", err)
+	}
+}
+
+func TestOfflineAskIntents(t *testing.T) {
+	s := &AIService{}
+	ctx := &WorkContext{
+		TodayHours:     3,
+		WeekHours:      24,
+		MonthHours:     96,
+		MonthGoal:      161.7,
+		WeeklyGoal:     38.5,
+		RemainingHours: 14.5,
+		DaysWorked:     3,
+		RemainingDays:  2,
+		DailyTarget:    7.25,
+		IsWorking:      true,
+		LeaveTime:      "16:45",
+		AvgDayHours:    7.8,
+		AvgStart:       "08:45",
+		AvgEnd:         "17:05",
+	}
+
+	tests := []struct {
+		name     string
+		question string
+		want     string
+	}{
+		{"month", "How much have I worked this month?", "96.00 hours this month of a 161.70h goal (65.70 to go)"},
+		{"average", "What's my average per day?", "averaged 8.00 hours per day over 3 days"},
+		{"average month", "average day length", "your average day is 7.80 hours, usually 08:45 to 17:05"},
+		{"no overtime", "Do I have any overtime?", "No overtime this week yet: 14.50 hours to go"},
+		{"leave time", "What time should I leave?", "Leave at about 16:45"},
+		{"today", "How many hours today?", "You've worked 3.00 hours today."},
+		{"remaining", "How many hours left?", "14.50 hours remaining"},
+		{"behind", "Am I behind?", "7.25 hours/day"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.offlineAsk(tt.question, ctx); !strings.Contains(got, tt.want) {
+				t.Errorf("offlineAsk(%q) = %q, want it to contain %q", tt.question, got, tt.want)
+			}
+		})
+	}
+
+	over := *ctx
+	over.RemainingHours, over.WeekHours, over.DailyTarget, over.LeaveTime = -2.5, 41, 0, ""
+	if got := s.offlineAsk("how much overtime?", &over); !strings.Contains(got, "2.50 hours over your 38.50h weekly goal") {
+		t.Errorf("overtime answer = %q", got)
+	}
+	if got := s.offlineAsk("when can I go home?", &over); !strings.Contains(got, "done for the week") {
+		t.Errorf("leave answer past the goal = %q", got)
+	}
+
+	idle := *ctx
+	idle.IsWorking, idle.LeaveTime = false, ""
+	if got := s.offlineAsk("when should I leave?", &idle); !strings.Contains(got, "about 7.25 hours per remaining day") {
+		t.Errorf("leave answer when not working = %q", got)
+	}
+	idle.MonthGoal = 0
+	if got := s.offlineAsk("hours this month", &idle); got != "You've worked 96.00 hours this month." {
+		t.Errorf("month answer without a goal = %q", got)
+	}
+}
+
+func TestBuildWorkContextLeaveTime(t *testing.T) {
+	// Monday 10:00, clocked in at 09:00 with 26.5 hours left over 5 days
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	start := now.Add(-time.Hour)
+	src := &stubProgress{now: now, active: &storage.WorkSession{ID: "active", StartTime: start}}
+
+	ctx, err := BuildWorkContext(src)
+	if err != nil {
+		t.Fatalf("BuildWorkContext: %v", err)
+	}
+	// 5.30 hours plus the 30 minute default break
+	if ctx.LeaveTime != "14:48" {
+		t.Errorf("LeaveTime = %q, want 14:48", ctx.LeaveTime)
+	}
+	if !strings.Contains(ctx.scheduleDetails(), "Clocking out at 14:48") {
+		t.Errorf("prompt details missing the leave time:\n%s", ctx.scheduleDetails())
+	}
+}
+
+func TestSystemPromptOverride(t *testing.T) {
+	ctx := &WorkContext{TodayHours: 3.5, WeekHours: 20, WeeklyGoal: 38.5}
+	const persona = "Answer with numbers only."
+
+	ollama := NewOllamaProvider("http://localhost:11434", "test", time.UTC, Timeouts{})
+	openai := NewOpenAIProvider("gpt-test", "key", time.UTC, Timeouts{})
+	claude := NewClaudeProvider("claude-test", "key", time.UTC, Timeouts{})
+	gemini := NewGeminiProvider("gemini-test", "key", time.UTC, Timeouts{})
+	prompts := func() map[string]string {
+		return map[string]string{
+			"ollama": ollama.buildPrompt("How long today?", ctx),
+			"openai": openai.buildMessages("How long today?", ctx)[0].Content,
+			"claude": claude.buildPrompt("How long today?", ctx),
+			"gemini": gemini.buildPrompt("How long today?", ctx),
+		}
+	}
+
+	for name, prompt := range prompts() {
+		if !strings.Contains(strings.ToLower(prompt), "assistant") || strings.Contains(prompt, persona) {
+			t.Errorf("%s default prompt should keep the built-in instructions:\n%s", name, prompt)
+		}
+	}
+
+	ollama.systemPrompt, openai.systemPrompt, claude.systemPrompt, gemini.systemPrompt = persona, persona, persona, persona
+	for name, prompt := range prompts() {
+		if !strings.HasPrefix(prompt, persona) {
+			t.Errorf("%s prompt should open with the override:\n%s", name, prompt)
+		}
+		if strings.Contains(strings.ToLower(prompt), "assistant") || strings.Contains(prompt, "Be concise") {
+			t.Errorf("%s prompt kept the default instructions:\n%s", name, prompt)
+		}
+		// The work data is untouched
+		if !strings.Contains(prompt, "3.50") || !strings.Contains(prompt, "38.50") {
+			t.Errorf("%s prompt lost the work data:\n%s", name, prompt)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package ai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultRetryBase is the wait before the first retry of a failed provider
+// request; each further retry waits twice as long
+const DefaultRetryBase = 500 * time.Millisecond
+
+// Retry re-sends provider requests that failed in a way that may pass on
+// its own: a connection error, 429 Too Many Requests or a 5xx status. The
+// wait doubles from Base each attempt unless the server asks for a
+// Retry-After delay. The zero value sends each request once.
+type Retry struct {
+	MaxRetries int
+	Base       time.Duration // DefaultRetryBase when zero
+	// Sleep waits between attempts, returning early with the context's
+	// error when it ends; tests replace it to skip the waits
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// do sends req with client, retrying as configured. The request's context
+// bounds all attempts together: a retry that can't start before its deadline
+// isn't made, and the last response or error is returned as is.
+func (r Retry) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Do(req)
+		if attempt >= r.MaxRetries || ctx.Err() != nil || req.GetBody == nil && req.Body != nil {
+			return resp, err
+		}
+
+		var wait time.Duration
+		switch {
+		case err != nil:
+			wait = r.backoff(attempt)
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			wait = retryAfter(resp.Header.Get("Retry-After"), time.Now())
+			if wait <= 0 {
+				wait = r.backoff(attempt)
+			}
+		default:
+			return resp, nil
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := r.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// backoff is the wait before retry number attempt+1
+func (r Retry) backoff(attempt int) time.Duration {
+	base := r.Base
+	if base <= 0 {
+		base = DefaultRetryBase
+	}
+	return base << attempt
+}
+
+func (r Retry) sleep(ctx context.Context, d time.Duration) error {
+	if r.Sleep != nil {
+		return r.Sleep(ctx, d)
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryAfter reads a Retry-After header, given in seconds or as an HTTP
+// date; 0 when it is missing or unreadable
+func retryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
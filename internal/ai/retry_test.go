@@ -0,0 +1,149 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// flakyServer answers with each status in turn, then with a chat completion
+func flakyServer(t *testing.T, statuses []int, header http.Header) (*httptest.Server, *int) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if body, _ := io.ReadAll(r.Body); !strings.Contains(string(body), "How long?") {
+			t.Errorf("attempt %d sent body %q, want the question replayed", calls, body)
+		}
+		if calls <= len(statuses) {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(statuses[calls-1])
+			fmt.Fprintf(w, `{"error":"attempt %d"}`, calls)
+			return
+		}
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"40 hours."}}]}`)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+// retryingProvider is an OpenAI provider for server that records its waits
+// instead of sleeping
+func retryingProvider(server *httptest.Server, maxRetries int, waits *[]time.Duration) *OpenAIProvider {
+	p := NewOpenAIProvider("gpt-test", "key", time.UTC, Timeouts{})
+	p.baseURL = server.URL
+	p.retry = Retry{
+		MaxRetries: maxRetries,
+		Base:       100 * time.Millisecond,
+		Sleep: func(ctx context.Context, d time.Duration) error {
+			*waits = append(*waits, d)
+			return nil
+		},
+	}
+	return p
+}
+
+func TestRetryBacksOffOnServerErrors(t *testing.T) {
+	server, calls := flakyServer(t, []int{http.StatusServiceUnavailable, http.StatusBadGateway}, nil)
+	var waits []time.Duration
+	answer, err := retryingProvider(server, 2, &waits).Ask("How long?", &WorkContext{})
+	if err != nil {
+		t.Fatalf("Ask: %v", err)
+	}
+	if answer != "40 hours." || *calls != 3 {
+		t.Errorf("got %q after %d calls, want the answer on the third", answer, *calls)
+	}
+	if fmt.Sprint(waits) != "[100ms 200ms]" {
+		t.Errorf("waits = %v, want [100ms 200ms]", waits)
+	}
+}
+
+func TestRetryStreamRequest(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if body := readBody(r); !strings.Contains(body, "How long?") {
+			t.Errorf("attempt %d sent body %q, want the question replayed", calls, body)
+		}
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"40 hours.\"}}]}\n\ndata: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	out := &chunkWriter{}
+	if err := retryingProvider(server, 1, &waits).AskStream(context.Background(), "How long?", &WorkContext{}, out); err != nil {
+		t.Fatalf("AskStream: %v", err)
+	}
+	if out.String() != "40 hours." || calls != 2 || fmt.Sprint(waits) != "[100ms]" {
+		t.Errorf("got %q after %d calls and waits %v, want the answer on the second after 100ms", out.String(), calls, waits)
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	server, calls := flakyServer(t, []int{http.StatusTooManyRequests}, http.Header{"Retry-After": {"3"}})
+	var waits []time.Duration
+	if _, err := retryingProvider(server, 2, &waits).Ask("How long?", &WorkContext{}); err != nil {
+		t.Fatalf("Ask: %v", err)
+	}
+	if *calls != 2 || fmt.Sprint(waits) != "[3s]" {
+		t.Errorf("%d calls with waits %v, want 2 calls and a 3s wait", *calls, waits)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	// Client errors aren't retried
+	server, calls := flakyServer(t, []int{http.StatusBadRequest}, nil)
+	var waits []time.Duration
+	if _, err := retryingProvider(server, 2, &waits).Ask("How long?", &WorkContext{}); err == nil {
+		t.Error("expected an error for 400")
+	}
+	if *calls != 1 || len(waits) != 0 {
+		t.Errorf("400: %d calls with waits %v, want one call", *calls, waits)
+	}
+
+	// Once the retries run out, the last response is the error
+	server, calls = flakyServer(t, []int{500, 500, 500}, nil)
+	waits = nil
+	_, err := retryingProvider(server, 2, &waits).Ask("How long?", &WorkContext{})
+	if err == nil || !strings.Contains(err.Error(), "attempt 3") {
+		t.Errorf("err = %v, want the third attempt's error", err)
+	}
+	if *calls != 3 {
+		t.Errorf("made %d calls, want 3", *calls)
+	}
+
+	// A refused connection is retried too
+	server.Close()
+	waits = nil
+	if _, err := retryingProvider(server, 1, &waits).Ask("How long?", &WorkContext{}); err == nil {
+		t.Error("expected an error from a closed server")
+	}
+	if len(waits) != 1 {
+		t.Errorf("waits = %v, want one retry after the connection error", waits)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	for header, want := range map[string]time.Duration{
+		"":                              0,
+		"7":                             7 * time.Second,
+		"soon":                          0,
+		"Mon, 15 Jan 2024 09:00:30 GMT": 30 * time.Second,
+		"Mon, 15 Jan 2024 08:59:00 GMT": 0,
+	} {
+		if got := retryAfter(header, now); got != want {
+			t.Errorf("retryAfter(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kairos/internal/logger"
+)
+
+// AskStream is Ask writing the answer to w as it arrives. Without an
+// available provider the offline answer is written.
+func (s *AIService) AskStream(ctx context.Context, question string, wc *WorkContext, w io.Writer) error {
+	if !s.IsAvailable() {
+		if s.provider != nil {
+			logger.Debug("AI provider unavailable, answering offline", "provider", s.provider.Name(), "err", s.probe())
+		}
+		_, err := io.WriteString(w, s.offlineAsk(question, wc))
+		return err
+	}
+
+	err := s.provider.AskStream(ctx, question, wc, w)
+	if err != nil && ctx.Err() == nil {
+		logger.Error("AI ask failed", "provider", s.provider.Name(), "err", err)
+	}
+	return err
+}
+
+// askOnce is AskStream for providers without a streaming API. Cancelling ctx
+// returns at once; the request itself is left to finish or time out.
+func askOnce(ctx context.Context, p Provider, question string, wc *WorkContext, w io.Writer) error {
+	type result struct {
+		answer string
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		answer, err := p.Ask(question, wc)
+		done <- result{answer, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case r := <-done:
+		if r.err != nil {
+			return r.err
+		}
+		_, err := io.WriteString(w, r.answer)
+		return err
+	}
+}
+
+func (c *ClaudeProvider) AskStream(ctx context.Context, question string, wc *WorkContext, w io.Writer) error {
+	return askOnce(ctx, c, question, wc, w)
+}
+
+func (g *GeminiProvider) AskStream(ctx context.Context, question string, wc *WorkContext, w io.Writer) error {
+	return askOnce(ctx, g, question, wc, w)
+}
+
+func (e *EchoProvider) AskStream(ctx context.Context, question string, wc *WorkContext, w io.Writer) error {
+	return askOnce(ctx, e, question, wc, w)
+}
+
+// postStream sends a JSON request and returns the response for incremental
+// reading. Sending it is retried like any other provider request; once the
+// response is returned, nothing is resent. The request is cancelled when
+// idle passes without a keepAlive call; stop releases it once the caller is
+// done. The client's timeout covers the whole response, which a long answer
+// may legitimately exceed, so streams use the idle timer instead.
+func postStream(ctx context.Context, client *http.Client, retry Retry, url string, body interface{}, header http.Header, idleTimeout time.Duration) (resp *http.Response, keepAlive func(), stop func(), err error) {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	idle := time.AfterFunc(idleTimeout, cancel)
+	stop = func() {
+		idle.Stop()
+		cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
+	if err != nil {
+		stop()
+		return nil, nil, nil, err
+	}
+	req.Header = header.Clone()
+	req.Header.Set("Content-Type", "application/json")
+
+	// The idle timer replaces the client's whole-response timeout
+	streaming := *client
+	streaming.Timeout = 0
+	resp, err = retry.do(&streaming, req)
+	if err != nil {
+		stop()
+		return nil, nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		stop()
+		return nil, nil, nil, fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return resp, func() { idle.Reset(idleTimeout) }, stop, nil
+}
+
+// AskStream streams /api/generate, which sends one JSON object per line
+func (o *OllamaProvider) AskStream(ctx context.Context, question string, wc *WorkContext, w io.Writer) error {
+	reqBody := map[string]interface{}{
+		"model":  o.model,
+		"prompt": o.buildPrompt(question, wc),
+		"stream": true,
+	}
+	resp, keepAlive, stop, err := postStream(ctx, o.client, o.retry, o.baseURL+"/api/generate", reqBody, http.Header{}, o.timeouts.Request)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("failed to connect to Ollama: %w", err)
+	}
+	defer stop()
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		keepAlive()
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var chunk OllamaResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return fmt.Errorf("failed to parse stream: %w", err)
+		}
+		if _, err := io.WriteString(w, chunk.Response); err != nil {
+			return err
+		}
+		if chunk.Done {
+			o.record(chunk.usage())
+			return nil
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("ollama stream interrupted: %w", err)
+	}
+	return fmt.Errorf("ollama stream ended early")
+}
+
+// openAIStreamChunk is one server-sent event of a streamed chat completion
+type openAIStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *OpenAIUsage `json:"usage"` // only on the last event
+}
+
+// AskStream streams chat completions, sent as "data: {...}" events ending
+// with "data: [DONE]"
+func (o *OpenAIProvider) AskStream(ctx context.Context, question string, wc *WorkContext, w io.Writer) error {
+	if o.apiKey == "" {
+		return fmt.Errorf("OpenAI API key not set. Use: kairos config --openai-key YOUR_KEY")
+	}
+
+	reqBody := OpenAIRequest{
+		Model:         o.model,
+		Messages:      o.buildMessages(question, wc),
+		Stream:        true,
+		StreamOptions: &OpenAIStreamOptions{IncludeUsage: true},
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+o.apiKey)
+	resp, keepAlive, stop, err := postStream(ctx, o.client, o.retry, o.baseURL+"/chat/completions", reqBody, header, o.timeouts.Request)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("OpenAI request failed: %w", err)
+	}
+	defer stop()
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		keepAlive()
+		data, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			return nil
+		}
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to parse stream: %w", err)
+		}
+		if chunk.Usage != nil {
+			o.record(chunk.Usage.usage())
+		}
+		for _, choice := range chunk.Choices {
+			if _, err := io.WriteString(w, choice.Delta.Content); err != nil {
+				return err
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("OpenAI stream interrupted: %w", err)
+	}
+	return fmt.Errorf("OpenAI stream ended early")
+}
@@ -0,0 +1,282 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kairos/internal/config"
+)
+
+// chunkWriter records each write so tests can see the answer arrive in pieces
+type chunkWriter struct {
+	chunks []string
+	onData func()
+}
+
+func (c *chunkWriter) Write(p []byte) (int, error) {
+	c.chunks = append(c.chunks, string(p))
+	if c.onData != nil {
+		c.onData()
+	}
+	return len(p), nil
+}
+
+func (c *chunkWriter) String() string {
+	return strings.Join(c.chunks, "")
+}
+
+func readBody(r *http.Request) string {
+	body, _ := io.ReadAll(r.Body)
+	return string(body)
+}
+
+func TestOllamaAskStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/generate" {
+			http.NotFound(w, r)
+			return
+		}
+		for _, token := range []string{"You ", "can ", "leave."} {
+			fmt.Fprintf(w, "{\"response\":%q,\"done\":false}\n", token)
+			w.(http.Flusher).Flush()
+		}
+		fmt.Fprintln(w, `{"response":"","done":true,"prompt_eval_count":40,"eval_count":3}`)
+	}))
+	defer server.Close()
+
+	provider := NewOllamaProvider(server.URL, "test", time.UTC, Timeouts{})
+	out := &chunkWriter{}
+	if err := provider.AskStream(context.Background(), "Can I leave?", &WorkContext{}, out); err != nil {
+		t.Fatalf("AskStream: %v", err)
+	}
+	if out.String() != "You can leave." {
+		t.Errorf("answer = %q", out.String())
+	}
+	if len(out.chunks) < 3 {
+		t.Errorf("got %d writes, want one per token", len(out.chunks))
+	}
+	if got := provider.LastUsage(); got != (Usage{PromptTokens: 40, CompletionTokens: 3}) {
+		t.Errorf("LastUsage = %+v, want the counts from the final chunk", got)
+	}
+}
+
+func TestOpenAIAskStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		body := readBody(r)
+		for _, token := range []string{"Two ", "hours ", "left."} {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", token)
+			w.(http.Flusher).Flush()
+		}
+		if strings.Contains(body, `"include_usage":true`) {
+			fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":120,\"completion_tokens\":3}}\n\n")
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider("gpt-test", "key", time.UTC, Timeouts{})
+	provider.baseURL = server.URL
+	out := &chunkWriter{}
+	if err := provider.AskStream(context.Background(), "How long?", &WorkContext{}, out); err != nil {
+		t.Fatalf("AskStream: %v", err)
+	}
+	if out.String() != "Two hours left." {
+		t.Errorf("answer = %q", out.String())
+	}
+	if got := provider.LastUsage(); got != (Usage{PromptTokens: 120, CompletionTokens: 3}) {
+		t.Errorf("LastUsage = %+v, want the usage event's counts", got)
+	}
+
+	provider.apiKey = "wrong"
+	if err := provider.AskStream(context.Background(), "How long?", &WorkContext{}, &chunkWriter{}); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("err = %v, want the 401 status", err)
+	}
+}
+
+func TestAskStreamCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"response":"Thinking","done":false}`)
+		w.(http.Flusher).Flush()
+		// Hold the stream open until the client goes away
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := &chunkWriter{onData: cancel}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- NewOllamaProvider(server.URL, "test", time.UTC, Timeouts{}).AskStream(ctx, "?", &WorkContext{}, out)
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream kept running after cancel")
+	}
+	if out.String() != "Thinking" {
+		t.Errorf("answer = %q, want the tokens before the cancel", out.String())
+	}
+}
+
+func TestAskStreamFallback(t *testing.T) {
+	svc := NewAIService(nil)
+	svc.provider = NewEchoProvider()
+
+	out := &chunkWriter{}
+	if err := svc.AskStream(context.Background(), "hi", nil, out); err != nil {
+		t.Fatalf("AskStream: %v", err)
+	}
+	if out.String() != "echo: hi" {
+		t.Errorf("answer = %q, want the whole echo answer", out.String())
+	}
+}
+
+func TestClaudeAvailability(t *testing.T) {
+	var probes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/models" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		atomic.AddInt32(&probes, 1)
+		if r.Header.Get("x-api-key") != "key" {
+			http.Error(w, `{"type":"error","error":{"type":"authentication_error"}}`, http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"data":[{"id":"claude-test"}]}`)
+	}))
+	defer server.Close()
+
+	provider := NewClaudeProvider("claude-test", "key", time.UTC, Timeouts{})
+	provider.baseURL = server.URL
+	if err := provider.CheckAvailable(); err != nil {
+		t.Errorf("valid key: CheckAvailable = %v", err)
+	}
+
+	// A wrong key is unavailable, not mistaken for a working one
+	provider.apiKey = "wrong"
+	if provider.IsAvailable() {
+		t.Error("wrong key reported as available")
+	}
+
+	// The service probes once and explains the failure
+	svc := NewAIService(nil)
+	svc.provider = provider
+	atomic.StoreInt32(&probes, 0)
+	err := svc.CheckAvailable()
+	if err == nil || !strings.Contains(err.Error(), "API key rejected (HTTP 401)") {
+		t.Errorf("CheckAvailable = %v, want the rejected key", err)
+	}
+	if svc.IsAvailable() || atomic.LoadInt32(&probes) != 1 {
+		t.Errorf("IsAvailable after CheckAvailable probed %d times, want the cached result", atomic.LoadInt32(&probes))
+	}
+}
+
+func TestOpenAIBaseURL(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/gateway/v1/models":
+			fmt.Fprint(w, `{"data":[]}`)
+		case "/gateway/v1/chat/completions":
+			fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Via the gateway."}}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	// A trailing slash on the configured root is tolerated
+	svc := NewAIService(&config.Config{
+		AIProvider:    config.ProviderOpenAI,
+		OpenAIModel:   "gpt-test",
+		OpenAIAPIKey:  "key",
+		OpenAIBaseURL: server.URL + "/gateway/v1/",
+	})
+	if err := svc.Initialize(); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if err := svc.CheckAvailable(); err != nil {
+		t.Fatalf("CheckAvailable: %v", err)
+	}
+	answer, err := svc.Ask("Where?", &WorkContext{})
+	if err != nil || answer != "Via the gateway." {
+		t.Errorf("Ask = %q, %v", answer, err)
+	}
+	want := []string{"GET /gateway/v1/models", "POST /gateway/v1/chat/completions"}
+	if strings.Join(paths, ", ") != strings.Join(want, ", ") {
+		t.Errorf("requests = %v, want %v", paths, want)
+	}
+}
+
+func TestClaudeUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"40 hours."}],"usage":{"input_tokens":123,"output_tokens":45}}`)
+	}))
+	defer server.Close()
+
+	provider := NewClaudeProvider("claude-test", "key", time.UTC, Timeouts{})
+	provider.baseURL = server.URL
+	svc := NewAIService(nil)
+	svc.provider = provider
+	if got := svc.LastUsage(); got.Total() != 0 {
+		t.Errorf("LastUsage before any request = %+v, want zero", got)
+	}
+	if _, err := provider.Ask("How long?", &WorkContext{}); err != nil {
+		t.Fatalf("Ask: %v", err)
+	}
+	if got := svc.LastUsage(); got != (Usage{PromptTokens: 123, CompletionTokens: 45}) {
+		t.Errorf("LastUsage = %+v, want 123 prompt + 45 completion", got)
+	}
+}
+
+func TestProviderTimeouts(t *testing.T) {
+	if got := NewGeminiProvider("m", "k", time.UTC, Timeouts{}).timeouts; got != DefaultTimeouts {
+		t.Errorf("zero Timeouts = %+v, want the defaults %+v", got, DefaultTimeouts)
+	}
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	provider := NewOllamaProvider(server.URL, "test", time.UTC, Timeouts{Request: 5 * time.Second, Probe: 50 * time.Millisecond})
+	if provider.client.Timeout != 5*time.Second {
+		t.Errorf("client timeout = %s, want the request timeout", provider.client.Timeout)
+	}
+	start := time.Now()
+	if err := provider.CheckAvailable(); err == nil {
+		t.Error("CheckAvailable succeeded against a server that never answers")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("probe took %s, want it cut off by the 50ms probe timeout", elapsed)
+	}
+}
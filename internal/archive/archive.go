@@ -1,10 +1,12 @@
 package archive
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,11 +14,22 @@ import (
 	"github.com/kairos/internal/work"
 )
 
+// Archive file formats written by ArchiveMonth
+const (
+	FormatMarkdown = "markdown" // YYYY-MM.md only; notes shortened, no IDs
+	FormatJSON     = "json"     // YYYY-MM.json only; lossless
+	FormatBoth     = "both"
+)
+
 // Archiver handles monthly data archival to markdown
 type Archiver struct {
-	db          *storage.Database
-	historyPath string
-	weeklyGoal  float64
+	db            *storage.Database
+	historyPath   string
+	weeklyGoal    float64
+	weekNumbering string
+	weekStart     time.Weekday
+	rounding      int
+	format        string
 }
 
 // New creates a new Archiver
@@ -28,31 +41,108 @@ func New(db *storage.Database, historyPath string, weeklyGoal float64) *Archiver
 		db:          db,
 		historyPath: historyPath,
 		weeklyGoal:  weeklyGoal,
+		weekStart:   time.Monday,
+		format:      FormatBoth,
 	}
 }
 
+// SetFormat selects which archive files ArchiveMonth writes: FormatMarkdown,
+// FormatJSON or FormatBoth (the default, also used for unknown values)
+func (a *Archiver) SetFormat(format string) {
+	switch format {
+	case FormatMarkdown, FormatJSON:
+		a.format = format
+	default:
+		a.format = FormatBoth
+	}
+}
+
+// ArchivePaths returns the files ArchiveMonth writes for a month in the
+// configured format
+func (a *Archiver) ArchivePaths(year int, month time.Month) []string {
+	mdPath := filepath.Join(a.historyPath, fmt.Sprintf("%d-%02d.md", year, month))
+	switch a.format {
+	case FormatMarkdown:
+		return []string{mdPath}
+	case FormatJSON:
+		return []string{jsonPath(mdPath)}
+	}
+	return []string{mdPath, jsonPath(mdPath)}
+}
+
+// archived reports whether a month already has an archive file in either
+// format
+func (a *Archiver) archived(year int, month time.Month) bool {
+	mdPath := filepath.Join(a.historyPath, fmt.Sprintf("%d-%02d.md", year, month))
+	for _, path := range []string{mdPath, jsonPath(mdPath)} {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// SetWeekNumbering selects ISO weeks (default) or weeks of the month for
+// the Weekly Breakdown table
+func (a *Archiver) SetWeekNumbering(numbering string) {
+	a.weekNumbering = numbering
+}
+
+// SetWeekStart sets the first day of the week used by month week numbering
+// (Monday by default)
+func (a *Archiver) SetWeekStart(day time.Weekday) {
+	a.weekStart = day
+}
+
+// SetRoundingMinutes rounds each archived session to the nearest minutes
+// (e.g. 15) before it is summed; 0 turns rounding off
+func (a *Archiver) SetRoundingMinutes(minutes int) {
+	a.rounding = minutes
+}
+
 // MonthSummary contains archived month data
 type MonthSummary struct {
-	Month         time.Time
-	TotalHours    float64
-	DaysWorked    int
-	WeeklyGoal    float64
-	Sessions      []SessionRecord
-	WeekBreakdown map[int]float64
+	Month         time.Time       `json:"month"`
+	TotalHours    float64         `json:"total_hours"`
+	DaysWorked    int             `json:"days_worked"`
+	WeeklyGoal    float64         `json:"weekly_goal"`
+	Sessions      []SessionRecord `json:"sessions"`
+	WeekBreakdown map[int]float64 `json:"week_breakdown"`
+	Weeks         []int           `json:"weeks"` // WeekBreakdown keys in calendar order
+	WeekNumbering string          `json:"week_numbering,omitempty"`
+	// WorkSessions are the archived sessions exactly as stored (IDs,
+	// projects, tags, full times) so RestoreMonth can put them back as
+	// they were; archives written before it was added lack it
+	WorkSessions []storage.WorkSession `json:"work_sessions,omitempty"`
 }
 
-// SessionRecord is a simplified session for archive
+// SessionRecord is a simplified session for archive. The markdown table
+// shortens long notes; the JSON copy keeps them whole.
 type SessionRecord struct {
-	Date         string
-	StartTime    string
-	EndTime      string
-	Hours        float64
-	BreakMinutes int
-	Note         string
+	Date         string  `json:"date"`
+	StartTime    string  `json:"start_time"`
+	EndTime      string  `json:"end_time"`
+	Hours        float64 `json:"hours"`
+	BreakMinutes int     `json:"break_minutes"`
+	Note         string  `json:"note"`
 }
 
-// ArchiveMonth exports a month's data to markdown and optionally cleans DB
-func (a *Archiver) ArchiveMonth(year int, month time.Month, cleanDB bool) error {
+// markdownNoteLength is the longest note shown in an archive table
+const markdownNoteLength = 30
+
+// ArchiveMonth exports a month's data to markdown and/or JSON, per the
+// archiver's format, and optionally cleans DB. An existing archive file is
+// only replaced when force is set.
+func (a *Archiver) ArchiveMonth(year int, month time.Month, cleanDB, force bool) error {
+	paths := a.ArchivePaths(year, month)
+	if !force {
+		for _, path := range paths {
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("archive %s already exists; use --force to overwrite", path)
+			}
+		}
+	}
+
 	// Get month boundaries
 	loc := a.db.Location()
 	monthStart := time.Date(year, month, 1, 0, 0, 0, 0, loc)
@@ -71,20 +161,24 @@ func (a *Archiver) ArchiveMonth(year int, month time.Month, cleanDB bool) error
 	// Build summary
 	summary := a.buildSummary(monthStart, sessions)
 
-	// Generate markdown
-	markdown := a.generateMarkdown(summary)
-
 	// Ensure history directory exists
 	if err := os.MkdirAll(a.historyPath, 0755); err != nil {
 		return fmt.Errorf("failed to create history directory: %w", err)
 	}
 
-	// Write to file
-	filename := fmt.Sprintf("%d-%02d.md", year, month)
-	filePath := filepath.Join(a.historyPath, filename)
-
-	if err := os.WriteFile(filePath, []byte(markdown), 0644); err != nil {
-		return fmt.Errorf("failed to write archive: %w", err)
+	for _, path := range paths {
+		var data []byte
+		if strings.HasSuffix(path, ".json") {
+			// The JSON copy keeps full sessions for restoring them exactly
+			if data, err = json.MarshalIndent(summary, "", "  "); err != nil {
+				return fmt.Errorf("failed to encode archive: %w", err)
+			}
+		} else {
+			data = []byte(a.generateMarkdown(summary))
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
 	}
 
 	// Clean DB if requested
@@ -98,27 +192,35 @@ func (a *Archiver) ArchiveMonth(year int, month time.Month, cleanDB bool) error
 }
 
 func (a *Archiver) buildSummary(monthStart time.Time, sessions []storage.WorkSession) *MonthSummary {
+	// A session running past midnight into the month belongs to the month
+	// it started in
+	sessions = storage.StartedBetween(sessions, monthStart, monthStart.AddDate(0, 1, -1))
 	summary := &MonthSummary{
 		Month:         monthStart,
 		WeeklyGoal:    a.weeklyGoal,
 		Sessions:      make([]SessionRecord, 0, len(sessions)),
 		WeekBreakdown: make(map[int]float64),
+		WeekNumbering: a.weekNumbering,
 	}
 
 	daysWorked := make(map[string]bool)
 
 	for _, s := range sessions {
-		if s.EndTime == nil {
+		hours, complete := storage.SessionNetHours(s, time.Now())
+		if !complete {
 			continue // Skip incomplete sessions
 		}
+		hours = work.RoundSessionHours(hours, a.rounding)
 
-		hours := s.EndTime.Sub(s.StartTime).Hours() - float64(s.BreakMinutes)/60.0
 		summary.TotalHours += hours
 
 		dayKey := s.Date.Format("2006-01-02")
 		daysWorked[dayKey] = true
 
-		_, week := s.Date.ISOWeek()
+		week := work.WeekNumber(s.Date, a.weekNumbering, a.weekStart)
+		if _, seen := summary.WeekBreakdown[week]; !seen {
+			summary.Weeks = append(summary.Weeks, week)
+		}
 		summary.WeekBreakdown[week] += hours
 
 		summary.Sessions = append(summary.Sessions, SessionRecord{
@@ -129,6 +231,7 @@ func (a *Archiver) buildSummary(monthStart time.Time, sessions []storage.WorkSes
 			BreakMinutes: s.BreakMinutes,
 			Note:         s.Note,
 		})
+		summary.WorkSessions = append(summary.WorkSessions, s)
 	}
 
 	summary.DaysWorked = len(daysWorked)
@@ -143,43 +246,34 @@ func (a *Archiver) generateMarkdown(summary *MonthSummary) string {
 
 	// Summary stats
 	sb.WriteString("## Summary\n\n")
-	sb.WriteString(fmt.Sprintf("| Metric | Value |\n"))
-	sb.WriteString(fmt.Sprintf("|--------|-------|\n"))
-	sb.WriteString(fmt.Sprintf("| Total Hours | %.2f |\n", summary.TotalHours))
-	sb.WriteString(fmt.Sprintf("| Days Worked | %d |\n", summary.DaysWorked))
-	sb.WriteString(fmt.Sprintf("| Daily Average | %.2f |\n", summary.TotalHours/float64(max(summary.DaysWorked, 1))))
-	sb.WriteString(fmt.Sprintf("| Weekly Goal | %.2f |\n", summary.WeeklyGoal))
+	sb.WriteString(MarkdownTable([]string{"Metric", "Value"}, [][]string{
+		{"Total Hours", fmt.Sprintf("%.2f", summary.TotalHours)},
+		{"Days Worked", strconv.Itoa(summary.DaysWorked)},
+		{"Daily Average", fmt.Sprintf("%.2f", summary.TotalHours/float64(max(summary.DaysWorked, 1)))},
+		{"Weekly Goal", fmt.Sprintf("%.2f", summary.WeeklyGoal)},
+	}))
 	sb.WriteString("\n")
 
 	// Week breakdown
 	sb.WriteString("## Weekly Breakdown\n\n")
-	sb.WriteString("| Week | Hours |\n")
-	sb.WriteString("|------|-------|\n")
-
-	weeks := make([]int, 0, len(summary.WeekBreakdown))
-	for w := range summary.WeekBreakdown {
-		weeks = append(weeks, w)
-	}
-	sort.Ints(weeks)
-
-	for _, w := range weeks {
-		sb.WriteString(fmt.Sprintf("| W%d | %.2f |\n", w, summary.WeekBreakdown[w]))
+	weeks := make([][]string, 0, len(summary.Weeks))
+	for _, w := range summary.Weeks {
+		weeks = append(weeks, []string{work.WeekLabel(w, summary.WeekNumbering), fmt.Sprintf("%.2f", summary.WeekBreakdown[w])})
 	}
+	sb.WriteString(MarkdownTable([]string{"Week", "Hours"}, weeks))
 	sb.WriteString("\n")
 
 	// Session details
 	sb.WriteString("## Sessions\n\n")
-	sb.WriteString("| Date | Start | End | Hours | Break | Note |\n")
-	sb.WriteString("|------|-------|-----|-------|-------|------|\n")
-
+	sessions := make([][]string, 0, len(summary.Sessions))
 	for _, s := range summary.Sessions {
 		note := s.Note
-		if len(note) > 30 {
-			note = note[:27] + "..."
+		if r := []rune(note); len(r) > markdownNoteLength {
+			note = string(r[:markdownNoteLength-3]) + "..."
 		}
-		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %.2f | %dm | %s |\n",
-			s.Date, s.StartTime, s.EndTime, s.Hours, s.BreakMinutes, note))
+		sessions = append(sessions, []string{s.Date, s.StartTime, s.EndTime, fmt.Sprintf("%.2f", s.Hours), fmt.Sprintf("%dm", s.BreakMinutes), note})
 	}
+	sb.WriteString(MarkdownTable([]string{"Date", "Start", "End", "Hours", "Break", "Note"}, sessions))
 	sb.WriteString("\n")
 
 	// Footer
@@ -188,6 +282,11 @@ func (a *Archiver) generateMarkdown(summary *MonthSummary) string {
 	return sb.String()
 }
 
+// jsonPath returns the JSON copy's path for a YYYY-MM.md archive file
+func jsonPath(markdownPath string) string {
+	return strings.TrimSuffix(markdownPath, ".md") + ".json"
+}
+
 func (a *Archiver) cleanMonth(year int, month time.Month) error {
 	monthStart := time.Date(year, month, 1, 0, 0, 0, 0, a.db.Location())
 	monthEnd := monthStart.AddDate(0, 1, 0).Add(-time.Second)
@@ -195,6 +294,69 @@ func (a *Archiver) cleanMonth(year int, month time.Month) error {
 	return a.db.DeleteSessionsInRange(monthStart, monthEnd)
 }
 
+// RestoreMonth inserts the archived sessions of a month back into the
+// database and returns how many it added. The JSON copy's stored sessions
+// are restored exactly, IDs included; older JSON copies and markdown-only
+// archives are rebuilt from their session rows (markdown notes may be
+// shortened). Sessions whose date and start time are already in the
+// database are skipped, so restoring twice adds nothing. The archive files
+// are left in place.
+func (a *Archiver) RestoreMonth(year int, month time.Month) (int, error) {
+	loc := a.db.Location()
+	sessions, err := monthWorkSessions(a.historyPath, year, month)
+	if err != nil {
+		return 0, err
+	}
+	if sessions == nil {
+		records, err := MonthSessions(a.historyPath, year, month)
+		if err != nil {
+			return 0, err
+		}
+		if records == nil {
+			return 0, fmt.Errorf("no archive found for %d-%02d", year, month)
+		}
+		for _, r := range records {
+			if session, ok := r.WorkSession(loc); ok {
+				sessions = append(sessions, session)
+			}
+		}
+	}
+
+	monthStart := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	existing, err := a.db.GetSessionsInRange(monthStart, monthStart.AddDate(0, 1, 0).Add(-time.Second))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	present := make(map[string]bool, len(existing))
+	for _, s := range existing {
+		present[s.StartTime.In(loc).Format("2006-01-02 15:04")] = true
+	}
+
+	restored := 0
+	for _, session := range sessions {
+		key := session.StartTime.In(loc).Format("2006-01-02 15:04")
+		if present[key] {
+			continue
+		}
+		// A session moved since it was archived keeps its ID; leave it be
+		if session.ID != "" {
+			exists, err := a.db.SessionExists(session.ID)
+			if err != nil {
+				return restored, err
+			}
+			if exists {
+				continue
+			}
+		}
+		if err := a.db.InsertSession(&session); err != nil {
+			return restored, fmt.Errorf("failed to restore session of %s: %w", key, err)
+		}
+		present[key] = true
+		restored++
+	}
+	return restored, nil
+}
+
 // AutoArchivePastMonths archives all complete months older than current
 func (a *Archiver) AutoArchivePastMonths() ([]string, error) {
 	loc := a.db.Location()
@@ -215,16 +377,15 @@ func (a *Archiver) AutoArchivePastMonths() ([]string, error) {
 
 	// Archive each month before current
 	for monthStart.Before(currentMonth) {
-		filename := fmt.Sprintf("%d-%02d.md", monthStart.Year(), monthStart.Month())
-		filePath := filepath.Join(a.historyPath, filename)
+		filename := filepath.Base(a.ArchivePaths(monthStart.Year(), monthStart.Month())[0])
 
 		// Skip if already archived
-		if _, err := os.Stat(filePath); err == nil {
+		if a.archived(monthStart.Year(), monthStart.Month()) {
 			monthStart = monthStart.AddDate(0, 1, 0)
 			continue
 		}
 
-		err := a.ArchiveMonth(monthStart.Year(), monthStart.Month(), false)
+		err := a.ArchiveMonth(monthStart.Year(), monthStart.Month(), false, false)
 		if err != nil {
 			// Skip months with no data
 			if strings.Contains(err.Error(), "no sessions found") {
@@ -241,7 +402,8 @@ func (a *Archiver) AutoArchivePastMonths() ([]string, error) {
 	return archived, nil
 }
 
-// ListArchives returns list of archived months
+// ListArchives returns the monthly archive files (YYYY-MM.md), oldest first;
+// year summaries are left out
 func (a *Archiver) ListArchives() ([]string, error) {
 	entries, err := os.ReadDir(a.historyPath)
 	if err != nil {
@@ -253,7 +415,10 @@ func (a *Archiver) ListArchives() ([]string, error) {
 
 	var archives []string
 	for _, e := range entries {
-		if !e.IsDir() && strings.HasSuffix(e.Name(), ".md") {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".md") {
+			continue
+		}
+		if _, err := time.Parse("2006-01", strings.TrimSuffix(e.Name(), ".md")); err == nil {
 			archives = append(archives, e.Name())
 		}
 	}
@@ -262,6 +427,111 @@ func (a *Archiver) ListArchives() ([]string, error) {
 	return archives, nil
 }
 
+// YearMonth is one month's line in a year summary
+type YearMonth struct {
+	Month      time.Time
+	TotalHours float64
+	DaysWorked int
+	Source     string // "archive" or "database"
+}
+
+// ArchiveYear writes YYYY.md to the history folder: a summary of the year
+// and a table of each month's hours. Months come from their monthly
+// archives, or from the database for months not archived yet; months with
+// neither are left out. The file is rewritten on every call. It returns the
+// file's path.
+func (a *Archiver) ArchiveYear(year int) (string, error) {
+	archives, err := a.ListArchives()
+	if err != nil {
+		return "", err
+	}
+	archivedFiles := make(map[string]bool, len(archives))
+	for _, name := range archives {
+		archivedFiles[name] = true
+	}
+
+	loc := a.db.Location()
+	var months []YearMonth
+	for m := time.January; m <= time.December; m++ {
+		monthStart := time.Date(year, m, 1, 0, 0, 0, 0, loc)
+		name := fmt.Sprintf("%d-%02d.md", year, m)
+		if archivedFiles[name] {
+			content, err := os.ReadFile(filepath.Join(a.historyPath, name))
+			if err != nil {
+				return "", err
+			}
+			summary, err := ParseSummary(string(content))
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", name, err)
+			}
+			months = append(months, YearMonth{Month: monthStart, TotalHours: summary.TotalHours, DaysWorked: summary.DaysWorked, Source: "archive"})
+			continue
+		}
+		// A JSON-only archive carries the same totals
+		if data, err := os.ReadFile(jsonPath(filepath.Join(a.historyPath, name))); err == nil {
+			var summary MonthSummary
+			if err := json.Unmarshal(data, &summary); err != nil {
+				return "", fmt.Errorf("failed to read %s: %w", filepath.Base(jsonPath(name)), err)
+			}
+			months = append(months, YearMonth{Month: monthStart, TotalHours: summary.TotalHours, DaysWorked: summary.DaysWorked, Source: "archive"})
+			continue
+		}
+
+		sessions, err := a.db.GetSessionsInRange(monthStart, monthStart.AddDate(0, 1, 0).Add(-time.Second))
+		if err != nil {
+			return "", fmt.Errorf("failed to get sessions: %w", err)
+		}
+		summary := a.buildSummary(monthStart, sessions)
+		if len(summary.Sessions) > 0 {
+			months = append(months, YearMonth{Month: monthStart, TotalHours: summary.TotalHours, DaysWorked: summary.DaysWorked, Source: "database"})
+		}
+	}
+
+	if len(months) == 0 {
+		return "", fmt.Errorf("no archived or recorded months found for %d", year)
+	}
+
+	if err := os.MkdirAll(a.historyPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to create history directory: %w", err)
+	}
+	path := filepath.Join(a.historyPath, fmt.Sprintf("%d.md", year))
+	if err := os.WriteFile(path, []byte(a.generateYearMarkdown(year, months)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write year summary: %w", err)
+	}
+	return path, nil
+}
+
+func (a *Archiver) generateYearMarkdown(year int, months []YearMonth) string {
+	var sb strings.Builder
+	total := 0.0
+	days := 0
+	rows := make([][]string, 0, len(months)+1)
+	for _, m := range months {
+		total += m.TotalHours
+		days += m.DaysWorked
+		rows = append(rows, []string{m.Month.Format("January"), fmt.Sprintf("%.2f", m.TotalHours), strconv.Itoa(m.DaysWorked), m.Source})
+	}
+	rows = append(rows, []string{"**Total**", fmt.Sprintf("**%.2f**", total), fmt.Sprintf("**%d**", days), ""})
+
+	sb.WriteString(fmt.Sprintf("# %d\n\n", year))
+
+	sb.WriteString("## Summary\n\n")
+	sb.WriteString(MarkdownTable([]string{"Metric", "Value"}, [][]string{
+		{"Total Hours", fmt.Sprintf("%.2f", total)},
+		{"Days Worked", strconv.Itoa(days)},
+		{"Months", strconv.Itoa(len(months))},
+		{"Monthly Average", fmt.Sprintf("%.2f", total/float64(len(months)))},
+	}))
+	sb.WriteString("\n")
+
+	sb.WriteString("## Months\n\n")
+	sb.WriteString(MarkdownTable([]string{"Month", "Hours", "Days", "Source"}, rows))
+	sb.WriteString("\n")
+
+	sb.WriteString(fmt.Sprintf("---\n*Generated: %s*\n", time.Now().In(a.db.Location()).Format("2006-01-02 15:04")))
+	return sb.String()
+}
+
 // ReadArchive reads a specific month's archive
 func (a *Archiver) ReadArchive(year int, month time.Month) (string, error) {
 	filename := fmt.Sprintf("%d-%02d.md", year, month)
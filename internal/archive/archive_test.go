@@ -0,0 +1,340 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kairos/internal/storage"
+	"github.com/kairos/internal/work"
+)
+
+func TestArchiveMonthRequiresForceToOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	db, err := storage.New(filepath.Join(dir, "test.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	defer db.Close()
+
+	start := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	end := start.Add(8 * time.Hour)
+	if err := db.InsertSession(&storage.WorkSession{Date: start, StartTime: start, EndTime: &end}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+
+	historyPath := filepath.Join(dir, "history")
+	archiver := New(db, historyPath, 38.5)
+	if err := archiver.ArchiveMonth(2025, time.January, false, false); err != nil {
+		t.Fatalf("first ArchiveMonth: %v", err)
+	}
+
+	filePath := filepath.Join(historyPath, "2025-01.md")
+	if err := os.WriteFile(filePath, []byte("manual edits"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err = archiver.ArchiveMonth(2025, time.January, false, false)
+	if err == nil || !strings.Contains(err.Error(), "2025-01.md") {
+		t.Fatalf("second ArchiveMonth error = %v, want one naming the file", err)
+	}
+	if data, _ := os.ReadFile(filePath); string(data) != "manual edits" {
+		t.Error("archive was overwritten without force")
+	}
+
+	if err := archiver.ArchiveMonth(2025, time.January, false, true); err != nil {
+		t.Fatalf("forced ArchiveMonth: %v", err)
+	}
+	if data, _ := os.ReadFile(filePath); string(data) == "manual edits" {
+		t.Error("forced archive did not overwrite")
+	}
+}
+
+func TestArchiveWeekNumbering(t *testing.T) {
+	dir := t.TempDir()
+	db, err := storage.New(filepath.Join(dir, "test.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	defer db.Close()
+
+	// Jan 1, 2027 (Friday) is in ISO week 53; Jan 4 starts week 1
+	for _, day := range []int{1, 4} {
+		start := time.Date(2027, 1, day, 9, 0, 0, 0, time.UTC)
+		end := start.Add(8 * time.Hour)
+		if err := db.InsertSession(&storage.WorkSession{StartTime: start, EndTime: &end}); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+	}
+
+	historyPath := filepath.Join(dir, "history")
+	archiver := New(db, historyPath, 38.5)
+	if err := archiver.ArchiveMonth(2027, time.January, false, false); err != nil {
+		t.Fatalf("ArchiveMonth: %v", err)
+	}
+	content, _ := archiver.ReadArchive(2027, time.January)
+	if w53, w1 := strings.Index(content, "| W53 |"), strings.Index(content, "| W1 |"); w53 < 0 || w1 < w53 {
+		t.Errorf("expected W53 before W1 in:\n%s", content)
+	}
+
+	archiver.SetWeekNumbering(work.WeekNumberingMonth)
+	if err := archiver.ArchiveMonth(2027, time.January, false, true); err != nil {
+		t.Fatalf("ArchiveMonth: %v", err)
+	}
+	content, _ = archiver.ReadArchive(2027, time.January)
+	if !strings.Contains(content, "| Wk 1 | 8.00 |") || !strings.Contains(content, "| Wk 2 | 8.00 |") {
+		t.Errorf("expected Wk 1 and Wk 2 rows in:\n%s", content)
+	}
+}
+
+func TestArchiveKeepsFullNoteInJSON(t *testing.T) {
+	dir := t.TempDir()
+	db, err := storage.New(filepath.Join(dir, "test.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	defer db.Close()
+
+	note := "Migrated the billing service to the new queue and wrote the runbook"
+	start := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	end := start.Add(8 * time.Hour)
+	if err := db.InsertSession(&storage.WorkSession{Date: start, StartTime: start, EndTime: &end, Note: note}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+
+	historyPath := filepath.Join(dir, "history")
+	if err := New(db, historyPath, 38.5).ArchiveMonth(2025, time.January, false, false); err != nil {
+		t.Fatalf("ArchiveMonth: %v", err)
+	}
+
+	markdown, err := os.ReadFile(filepath.Join(historyPath, "2025-01.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(markdown), note) || !strings.Contains(string(markdown), "| Migrated the billing servic... |") {
+		t.Errorf("markdown table should shorten the note to %d characters:\n%s", markdownNoteLength, markdown)
+	}
+
+	records, err := MonthSessions(historyPath, 2025, time.January)
+	if err != nil {
+		t.Fatalf("MonthSessions: %v", err)
+	}
+	if len(records) != 1 || records[0].Note != note || records[0].Hours != 8 {
+		t.Errorf("records = %+v, want one 8h session with the full note", records)
+	}
+
+	// Archives written before the JSON copy still read from the markdown
+	if err := os.Remove(filepath.Join(historyPath, "2025-01.json")); err != nil {
+		t.Fatal(err)
+	}
+	records, err = MonthSessions(historyPath, 2025, time.January)
+	if err != nil || len(records) != 1 || records[0].Note != "Migrated the billing servic..." {
+		t.Errorf("markdown fallback = %+v, %v", records, err)
+	}
+}
+
+func TestMarkdownTable(t *testing.T) {
+	got := MarkdownTable([]string{"Week", "Hours"}, [][]string{{"W2", "38.50"}, {"W3", "40.00"}})
+	want := "| Week | Hours |\n|------|-------|\n| W2 | 38.50 |\n| W3 | 40.00 |\n"
+	if got != want {
+		t.Errorf("MarkdownTable =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRestoreMonth(t *testing.T) {
+	dir := t.TempDir()
+	db, err := storage.New(filepath.Join(dir, "test.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	defer db.Close()
+
+	for i, note := range []string{"planning | kickoff", "a note long enough to be shortened in the markdown table"} {
+		start := time.Date(2025, 1, 6+i, 9, 0, 0, 0, time.UTC)
+		end := start.Add(8*time.Hour + 30*time.Minute)
+		if err := db.InsertSession(&storage.WorkSession{Date: start, StartTime: start, EndTime: &end, BreakMinutes: 30, Note: note}); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+	}
+
+	historyPath := filepath.Join(dir, "history")
+	archiver := New(db, historyPath, 38.5)
+	if err := archiver.ArchiveMonth(2025, time.January, true, false); err != nil {
+		t.Fatalf("ArchiveMonth: %v", err)
+	}
+
+	restored, err := archiver.RestoreMonth(2025, time.January)
+	if err != nil || restored != 2 {
+		t.Fatalf("RestoreMonth = %d, %v; want 2 sessions", restored, err)
+	}
+	sessions, err := db.GetSessionsInRange(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("GetSessionsInRange: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("%d sessions after restore, want 2", len(sessions))
+	}
+	for _, s := range sessions {
+		if hours, _ := storage.SessionNetHours(s, time.Now()); hours != 8 || s.BreakMinutes != 30 || s.StartTime.Hour() != 9 {
+			t.Errorf("restored session %s: %.2fh, %dm break", s.StartTime.Format("2006-01-02 15:04"), hours, s.BreakMinutes)
+		}
+	}
+	if sessions[1].Note != "a note long enough to be shortened in the markdown table" {
+		t.Errorf("note = %q, want the full note from the JSON copy", sessions[1].Note)
+	}
+
+	// Restoring again finds every session present
+	if again, err := archiver.RestoreMonth(2025, time.January); err != nil || again != 0 {
+		t.Errorf("second RestoreMonth = %d, %v; want 0", again, err)
+	}
+
+	// Without the JSON copy the markdown table is read instead
+	if err := db.DeleteSessionsInRange(time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 7, 23, 59, 59, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(historyPath, "2025-01.json")); err != nil {
+		t.Fatal(err)
+	}
+	if restored, err := archiver.RestoreMonth(2025, time.January); err != nil || restored != 1 {
+		t.Errorf("RestoreMonth from markdown = %d, %v; want 1", restored, err)
+	}
+
+	if _, err := archiver.RestoreMonth(2024, time.March); err == nil {
+		t.Error("expected an error for a month without an archive")
+	}
+}
+
+func TestArchiveFormatAndExactRestore(t *testing.T) {
+	dir := t.TempDir()
+	db, err := storage.New(filepath.Join(dir, "test.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	defer db.Close()
+
+	start := time.Date(2025, 1, 6, 9, 0, 17, 0, time.UTC)
+	end := start.Add(8*time.Hour + 41*time.Second)
+	original := &storage.WorkSession{Date: start, StartTime: start, EndTime: &end, BreakMinutes: 30,
+		Note: "a note long enough to be shortened in the markdown table", Project: "alpha", Tags: []string{"review"}}
+	if err := db.InsertSession(original); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+
+	historyPath := filepath.Join(dir, "history")
+	archiver := New(db, historyPath, 38.5)
+	for _, tt := range []struct {
+		format string
+		files  []string
+	}{
+		{FormatMarkdown, []string{"2025-01.md"}},
+		{FormatJSON, []string{"2025-01.json"}},
+		{"", []string{"2025-01.md", "2025-01.json"}},
+	} {
+		os.RemoveAll(historyPath)
+		archiver.SetFormat(tt.format)
+		if err := archiver.ArchiveMonth(2025, time.January, false, false); err != nil {
+			t.Fatalf("ArchiveMonth(%q): %v", tt.format, err)
+		}
+		entries, _ := os.ReadDir(historyPath)
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		if len(names) != len(tt.files) {
+			t.Errorf("format %q wrote %v, want %v", tt.format, names, tt.files)
+		}
+		for _, name := range tt.files {
+			if _, err := os.Stat(filepath.Join(historyPath, name)); err != nil {
+				t.Errorf("format %q: %s missing", tt.format, name)
+			}
+		}
+	}
+
+	// Auto-archive counts a JSON-only month as archived
+	archiver.SetFormat(FormatJSON)
+	if err := os.Remove(filepath.Join(historyPath, "2025-01.md")); err != nil {
+		t.Fatal(err)
+	}
+	if archived, err := archiver.AutoArchivePastMonths(); err != nil || len(archived) != 0 {
+		t.Errorf("AutoArchivePastMonths = %v, %v; want January left alone", archived, err)
+	}
+
+	if err := db.DeleteSessionsInRange(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	if restored, err := archiver.RestoreMonth(2025, time.January); err != nil || restored != 1 {
+		t.Fatalf("RestoreMonth = %d, %v; want 1", restored, err)
+	}
+	got, err := db.GetSessionByID(original.ID)
+	if err != nil || got == nil {
+		t.Fatalf("restored session not found by its original ID: %v", err)
+	}
+	if !got.StartTime.Equal(start) || got.EndTime == nil || !got.EndTime.Equal(end) || got.Note != original.Note ||
+		got.Project != "alpha" || len(got.Tags) != 1 || got.Tags[0] != "review" || got.BreakMinutes != 30 {
+		t.Errorf("restored session = %+v, want %+v", got, original)
+	}
+}
+
+func TestArchiveYear(t *testing.T) {
+	dir := t.TempDir()
+	db, err := storage.New(filepath.Join(dir, "test.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	defer db.Close()
+
+	// January is archived and cleaned, February is only in the database,
+	// the rest of the year has nothing
+	for _, day := range []time.Time{
+		time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 7, 9, 0, 0, 0, time.UTC),
+		time.Date(2025, 2, 3, 9, 0, 0, 0, time.UTC),
+	} {
+		end := day.Add(8 * time.Hour)
+		if err := db.InsertSession(&storage.WorkSession{Date: day, StartTime: day, EndTime: &end}); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+	}
+	archiver := New(db, filepath.Join(dir, "history"), 38.5)
+	if err := archiver.ArchiveMonth(2025, time.January, true, false); err != nil {
+		t.Fatalf("ArchiveMonth: %v", err)
+	}
+
+	path, err := archiver.ArchiveYear(2025)
+	if err != nil {
+		t.Fatalf("ArchiveYear: %v", err)
+	}
+	if filepath.Base(path) != "2025.md" {
+		t.Errorf("path = %s, want 2025.md", path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	summary, err := ParseSummary(string(content))
+	if err != nil {
+		t.Fatalf("ParseSummary: %v", err)
+	}
+	if summary.TotalHours != 24 || summary.DaysWorked != 3 {
+		t.Errorf("year summary = %.2fh over %d days, want 24h over 3", summary.TotalHours, summary.DaysWorked)
+	}
+	for _, want := range []string{"| January | 16.00 | 2 | archive |", "| February | 8.00 | 1 | database |", "**24.00**"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("year summary missing %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(string(content), "March") {
+		t.Errorf("year summary lists a month without data:\n%s", content)
+	}
+
+	archives, err := archiver.ListArchives()
+	if err != nil || len(archives) != 1 || archives[0] != "2025-01.md" {
+		t.Errorf("ListArchives = %v, %v; want only 2025-01.md", archives, err)
+	}
+
+	if _, err := archiver.ArchiveYear(2019); err == nil {
+		t.Error("ArchiveYear of a year without data succeeded")
+	}
+}
@@ -0,0 +1,75 @@
+package archive
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SummaryCache keeps parsed archive summaries in memory, re-reading a file
+// only when its modification time or size changes. It is safe for
+// concurrent use.
+type SummaryCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedSummary
+}
+
+type cachedSummary struct {
+	modTime time.Time
+	size    int64
+	month   ArchivedMonth
+}
+
+// NewSummaryCache creates an empty cache
+func NewSummaryCache() *SummaryCache {
+	return &SummaryCache{entries: make(map[string]cachedSummary)}
+}
+
+// Load returns the summary of the archive file at path. Its Month comes from
+// the YYYY-MM file name, in UTC.
+func (c *SummaryCache) Load(path string) (ArchivedMonth, error) {
+	monthStart, err := time.Parse("2006-01", strings.TrimSuffix(filepath.Base(path), ".md"))
+	if err != nil {
+		return ArchivedMonth{}, fmt.Errorf("%s is not a monthly archive", filepath.Base(path))
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return ArchivedMonth{}, err
+	}
+
+	c.mu.Lock()
+	cached, ok := c.entries[path]
+	c.mu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.month, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ArchivedMonth{}, err
+	}
+	month, err := ParseSummary(string(content))
+	if err != nil {
+		return ArchivedMonth{}, err
+	}
+	month.Month = monthStart
+
+	c.mu.Lock()
+	c.entries[path] = cachedSummary{modTime: info.ModTime(), size: info.Size(), month: *month}
+	c.mu.Unlock()
+	return *month, nil
+}
+
+// Line is the month as a single line of key metrics, e.g.
+// "January 2025: 150.00h over 20 days (7.50h/day)"
+func (m ArchivedMonth) Line() string {
+	line := fmt.Sprintf("%s: %.2fh", m.Month.Format("January 2006"), m.TotalHours)
+	if m.DaysWorked > 0 {
+		line += fmt.Sprintf(" over %d days (%.2fh/day)", m.DaysWorked, m.TotalHours/float64(m.DaysWorked))
+	}
+	return line
+}
@@ -0,0 +1,28 @@
+package archive
+
+import "strings"
+
+// MarkdownTable renders a pipe table with a dash rule under the header, the
+// layout archive files use and ParseSummary/ParseSessions read back. Cells
+// are written as given, so callers escape any "|" they need kept literal.
+func MarkdownTable(header []string, rows [][]string) string {
+	var sb strings.Builder
+	writeRow := func(cells []string) {
+		sb.WriteString("|")
+		for _, c := range cells {
+			sb.WriteString(" " + c + " |")
+		}
+		sb.WriteString("\n")
+	}
+
+	writeRow(header)
+	sb.WriteString("|")
+	for _, h := range header {
+		sb.WriteString(strings.Repeat("-", len(h)+2) + "|")
+	}
+	sb.WriteString("\n")
+	for _, row := range rows {
+		writeRow(row)
+	}
+	return sb.String()
+}
@@ -0,0 +1,120 @@
+package archive
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArchivedMonth is the summary read back from an archive file
+type ArchivedMonth struct {
+	Month      time.Time
+	TotalHours float64
+	DaysWorked int
+}
+
+// ParseSummary extracts the totals from the Summary table of archive markdown
+func ParseSummary(content string) (*ArchivedMonth, error) {
+	month := &ArchivedMonth{}
+	found := false
+
+	for _, line := range strings.Split(content, "\n") {
+		parts := strings.Split(line, "|")
+		if len(parts) < 3 {
+			continue
+		}
+		value := strings.TrimSpace(parts[2])
+		switch strings.TrimSpace(parts[1]) {
+		case "Total Hours":
+			hours, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid total hours %q: %w", value, err)
+			}
+			month.TotalHours = hours
+			found = true
+		case "Days Worked":
+			days, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid days worked %q: %w", value, err)
+			}
+			month.DaysWorked = days
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no summary table found")
+	}
+	return month, nil
+}
+
+// TrailingMonths returns up to n archived months strictly before the month of
+// before, newest first. Unreadable or malformed archives are skipped.
+func TrailingMonths(historyPath string, before time.Time, n int) ([]ArchivedMonth, error) {
+	entries, err := os.ReadDir(historyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	cutoff := time.Date(before.Year(), before.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".md") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+
+	var months []ArchivedMonth
+	for _, name := range names {
+		if len(months) >= n {
+			break
+		}
+		monthStart, err := time.Parse("2006-01", strings.TrimSuffix(name, ".md"))
+		if err != nil || !monthStart.Before(cutoff) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(historyPath, name))
+		if err != nil {
+			continue
+		}
+		month, err := ParseSummary(string(content))
+		if err != nil {
+			continue
+		}
+		month.Month = time.Date(monthStart.Year(), monthStart.Month(), 1, 0, 0, 0, 0, before.Location())
+		months = append(months, *month)
+	}
+
+	return months, nil
+}
+
+// OldestMonth returns the first day (UTC) of the earliest archived month;
+// false when there are no archives
+func OldestMonth(historyPath string) (time.Time, bool, error) {
+	entries, err := os.ReadDir(historyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, err
+	}
+
+	// ReadDir sorts by name, so the first YYYY-MM file is the oldest
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".md") {
+			continue
+		}
+		if month, err := time.Parse("2006-01", strings.TrimSuffix(e.Name(), ".md")); err == nil {
+			return month, true, nil
+		}
+	}
+	return time.Time{}, false, nil
+}
@@ -0,0 +1,100 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseSummary(t *testing.T) {
+	content := "# March 2025\n\n## Summary\n\n| Metric | Value |\n|--------|-------|\n| Total Hours | 130.25 |\n| Days Worked | 18 |\n"
+
+	month, err := ParseSummary(content)
+	if err != nil {
+		t.Fatalf("ParseSummary: %v", err)
+	}
+	if month.TotalHours != 130.25 {
+		t.Errorf("TotalHours = %.2f, want 130.25", month.TotalHours)
+	}
+	if month.DaysWorked != 18 {
+		t.Errorf("DaysWorked = %d, want 18", month.DaysWorked)
+	}
+
+	if _, err := ParseSummary("# Notes\n"); err == nil {
+		t.Error("expected error for content without a summary table")
+	}
+}
+
+func TestTrailingMonths(t *testing.T) {
+	before := time.Date(2025, time.April, 15, 12, 0, 0, 0, time.UTC)
+
+	months, err := TrailingMonths("testdata/history", before, 3)
+	if err != nil {
+		t.Fatalf("TrailingMonths: %v", err)
+	}
+	if len(months) != 3 {
+		t.Fatalf("got %d months, want 3", len(months))
+	}
+
+	// Newest first, and the current month (April) is excluded
+	want := []struct {
+		month time.Month
+		hours float64
+	}{
+		{time.March, 130},
+		{time.February, 140},
+		{time.January, 120.5},
+	}
+	for i, w := range want {
+		if months[i].Month.Month() != w.month || months[i].TotalHours != w.hours {
+			t.Errorf("months[%d] = %s %.2f, want %s %.2f", i, months[i].Month.Month(), months[i].TotalHours, w.month, w.hours)
+		}
+	}
+
+	missing, err := TrailingMonths("testdata/missing", before, 3)
+	if err != nil || len(missing) != 0 {
+		t.Errorf("missing dir: got %v, %v; want no months and no error", missing, err)
+	}
+}
+
+func TestSummaryCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "2025-03.md")
+	write := func(total string, modTime time.Time) {
+		content := "# March 2025\n\n## Summary\n\n| Metric | Value |\n|--------|-------|\n| Total Hours | " + total + " |\n| Days Worked | 18 |\n"
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cache := NewSummaryCache()
+	modTime := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+	write("130.25", modTime)
+
+	month, err := cache.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if want := "March 2025: 130.25h over 18 days (7.24h/day)"; month.Line() != want {
+		t.Errorf("Line() = %q, want %q", month.Line(), want)
+	}
+
+	// A rewrite with the same mtime and size is served from the cache
+	write("999.99", modTime)
+	if month, _ := cache.Load(path); month.TotalHours != 130.25 {
+		t.Errorf("TotalHours = %.2f, want the cached 130.25", month.TotalHours)
+	}
+
+	// A newer mtime invalidates the entry
+	write("140.00", modTime.Add(time.Hour))
+	if month, _ := cache.Load(path); month.TotalHours != 140 {
+		t.Errorf("TotalHours = %.2f, want 140 after the file changed", month.TotalHours)
+	}
+
+	if _, err := cache.Load(filepath.Join(filepath.Dir(path), "notes.md")); err == nil {
+		t.Error("expected an error for a file that isn't a monthly archive")
+	}
+}
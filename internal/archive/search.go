@@ -0,0 +1,240 @@
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kairos/internal/storage"
+)
+
+// SearchQuery selects archived months. Term matches session notes
+// case-insensitively; MinHours/MaxHours bound the monthly total when > 0.
+type SearchQuery struct {
+	Term     string
+	MinHours float64
+	MaxHours float64
+}
+
+// SearchMatch is an archived month that satisfied a SearchQuery
+type SearchMatch struct {
+	Month      time.Time
+	File       string
+	TotalHours float64
+	Lines      []string // session rows whose note matched Term
+}
+
+// Search scans the archived months in historyPath, oldest first. A month's
+// JSON copy is read when it exists, since it keeps full notes; otherwise its
+// markdown. Files that can't be read or have no summary are skipped.
+func Search(historyPath string, q SearchQuery) ([]SearchMatch, error) {
+	if q.Term == "" && q.MinHours <= 0 && q.MaxHours <= 0 {
+		return nil, fmt.Errorf("a search term or an hours threshold is required")
+	}
+
+	entries, err := os.ReadDir(historyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var months []string
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		month := strings.TrimSuffix(strings.TrimSuffix(e.Name(), ".md"), ".json")
+		if e.IsDir() || month == e.Name() || seen[month] {
+			continue
+		}
+		seen[month] = true
+		months = append(months, month)
+	}
+	sort.Strings(months)
+
+	term := strings.ToLower(strings.TrimSpace(q.Term))
+
+	var matches []SearchMatch
+	for _, month := range months {
+		monthStart, err := time.Parse("2006-01", month)
+		if err != nil {
+			continue
+		}
+		file, totalHours, rows, err := readMonth(historyPath, month)
+		if err != nil {
+			continue
+		}
+		if q.MinHours > 0 && totalHours < q.MinHours {
+			continue
+		}
+		if q.MaxHours > 0 && totalHours > q.MaxHours {
+			continue
+		}
+
+		match := SearchMatch{Month: monthStart, File: file, TotalHours: totalHours}
+		if term != "" {
+			for _, row := range rows {
+				if strings.Contains(strings.ToLower(row.note), term) {
+					match.Lines = append(match.Lines, row.line)
+				}
+			}
+			if len(match.Lines) == 0 {
+				continue
+			}
+		}
+		matches = append(matches, match)
+	}
+
+	return matches, nil
+}
+
+// readMonth returns the file an archived YYYY-MM month is read from, its
+// total hours and its session rows. The JSON copy's rows are shown like the
+// markdown table's, with the whole note.
+func readMonth(historyPath, month string) (string, float64, []sessionRow, error) {
+	if data, err := os.ReadFile(filepath.Join(historyPath, month+".json")); err == nil {
+		var summary MonthSummary
+		if err := json.Unmarshal(data, &summary); err != nil {
+			return "", 0, nil, err
+		}
+		rows := make([]sessionRow, 0, len(summary.Sessions))
+		for _, r := range summary.Sessions {
+			cells := []string{r.Date, r.StartTime, r.EndTime, fmt.Sprintf("%.2f", r.Hours), fmt.Sprintf("%dm", r.BreakMinutes), r.Note}
+			line := "| " + strings.Join(cells, " | ") + " |"
+			rows = append(rows, sessionRow{line: line, cells: cells, note: r.Note})
+		}
+		return month + ".json", summary.TotalHours, rows, nil
+	}
+
+	content, err := os.ReadFile(filepath.Join(historyPath, month+".md"))
+	if err != nil {
+		return "", 0, nil, err
+	}
+	summary, err := ParseSummary(string(content))
+	if err != nil {
+		return "", 0, nil, err
+	}
+	return month + ".md", summary.TotalHours, sessionRows(string(content)), nil
+}
+
+type sessionRow struct {
+	line  string
+	cells []string // date, start, end, hours, break, note
+	note  string
+}
+
+// sessionRows returns the data rows of the Sessions table. The note is
+// everything after the fifth cell so notes containing "|" still match.
+func sessionRows(content string) []sessionRow {
+	var rows []sessionRow
+	inSessions := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, "## ") {
+			inSessions = strings.TrimSpace(strings.TrimPrefix(line, "## ")) == "Sessions"
+			continue
+		}
+		if !inSessions || !strings.HasPrefix(line, "|") || strings.HasPrefix(line, "|--") || strings.HasPrefix(line, "| Date ") {
+			continue
+		}
+		cells := strings.SplitN(strings.Trim(line, "|"), "|", 6)
+		if len(cells) < 6 {
+			continue
+		}
+		for i := range cells {
+			cells[i] = strings.TrimSpace(cells[i])
+		}
+		rows = append(rows, sessionRow{line: line, cells: cells, note: cells[5]})
+	}
+	return rows
+}
+
+// ParseSessions reads the Sessions table of archive markdown back into
+// records. Rows with an unparseable date or hours value are skipped; notes
+// may be truncated by the archiver.
+func ParseSessions(content string) []SessionRecord {
+	var records []SessionRecord
+	for _, row := range sessionRows(content) {
+		if _, err := time.Parse("2006-01-02", row.cells[0]); err != nil {
+			continue
+		}
+		hours, err := strconv.ParseFloat(row.cells[3], 64)
+		if err != nil {
+			continue
+		}
+		breakMinutes, _ := strconv.Atoi(strings.TrimSuffix(row.cells[4], "m"))
+		records = append(records, SessionRecord{
+			Date:         row.cells[0],
+			StartTime:    row.cells[1],
+			EndTime:      row.cells[2],
+			Hours:        hours,
+			BreakMinutes: breakMinutes,
+			Note:         row.note,
+		})
+	}
+	return records
+}
+
+// MonthSessions returns the archived sessions of the given month, or nil when
+// that month has no archive file. The JSON copy is preferred since it keeps
+// full notes; archives written before it existed are read from the markdown.
+func MonthSessions(historyPath string, year int, month time.Month) ([]SessionRecord, error) {
+	path := filepath.Join(historyPath, fmt.Sprintf("%d-%02d.md", year, month))
+	if data, err := os.ReadFile(jsonPath(path)); err == nil {
+		var summary MonthSummary
+		if err := json.Unmarshal(data, &summary); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(jsonPath(path)), err)
+		}
+		return summary.Sessions, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return ParseSessions(string(content)), nil
+}
+
+// monthWorkSessions returns the exact sessions kept in a month's JSON copy,
+// or nil when there is no JSON copy or it predates them
+func monthWorkSessions(historyPath string, year int, month time.Month) ([]storage.WorkSession, error) {
+	path := jsonPath(filepath.Join(historyPath, fmt.Sprintf("%d-%02d.md", year, month)))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var summary MonthSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	return summary.WorkSessions, nil
+}
+
+// WorkSession rebuilds a completed session from an archived row. The end is
+// set Hours plus the break after the start, so net hours match the archive
+// even for shifts that ran past midnight.
+func (r SessionRecord) WorkSession(loc *time.Location) (storage.WorkSession, bool) {
+	start, err := time.ParseInLocation("2006-01-02 15:04", r.Date+" "+r.StartTime, loc)
+	if err != nil {
+		return storage.WorkSession{}, false
+	}
+	end := start.Add(time.Duration(r.Hours*float64(time.Hour)) + time.Duration(r.BreakMinutes)*time.Minute)
+	return storage.WorkSession{
+		Date:         time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc),
+		StartTime:    start,
+		EndTime:      &end,
+		BreakMinutes: r.BreakMinutes,
+		Note:         r.Note,
+	}, true
+}
@@ -0,0 +1,90 @@
+package archive
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	matches, err := Search("testdata/history", SearchQuery{Term: "RELEASE"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("got %d matches, want 2", len(matches))
+	}
+	if matches[0].File != "2025-02.md" || matches[1].File != "2025-03.md" {
+		t.Errorf("files = %s, %s; want oldest first", matches[0].File, matches[1].File)
+	}
+	if len(matches[1].Lines) != 1 || matches[1].Lines[0] != "| 2025-03-04 | 09:00 | 16:30 | 7.00 | 30m | release prep |" {
+		t.Errorf("March lines = %q", matches[1].Lines)
+	}
+
+	over, err := Search("testdata/history", SearchQuery{MinHours: 135, MaxHours: 145})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(over) != 1 || over[0].TotalHours != 140 {
+		t.Errorf("months between 135h and 145h = %+v, want only February", over)
+	}
+
+	both, err := Search("testdata/history", SearchQuery{Term: "release", MaxHours: 135})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(both) != 1 || both[0].File != "2025-03.md" {
+		t.Errorf("release months under 135h = %+v, want only March", both)
+	}
+
+	if _, err := Search("testdata/history", SearchQuery{}); err == nil {
+		t.Error("expected error for an empty query")
+	}
+}
+
+func TestSearchReadsJSONOnlyArchive(t *testing.T) {
+	dir := t.TempDir()
+	note := "pairing on the billing service, then the quarterly release"
+	summary := MonthSummary{
+		TotalHours: 7.5,
+		Sessions:   []SessionRecord{{Date: "2025-05-06", StartTime: "09:00", EndTime: "17:00", Hours: 7.5, BreakMinutes: 30, Note: note}},
+	}
+	data, err := json.Marshal(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "2025-05.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The term is past the markdown table's 30-character note cut
+	matches, err := Search(dir, SearchQuery{Term: "quarterly"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(matches) != 1 || matches[0].File != "2025-05.json" || matches[0].TotalHours != 7.5 {
+		t.Fatalf("matches = %+v, want the JSON month", matches)
+	}
+	if want := "| 2025-05-06 | 09:00 | 17:00 | 7.50 | 30m | " + note + " |"; len(matches[0].Lines) != 1 || matches[0].Lines[0] != want {
+		t.Errorf("lines = %q, want [%q]", matches[0].Lines, want)
+	}
+}
+
+func TestMonthSessions(t *testing.T) {
+	records, err := MonthSessions("testdata/history", 2025, 3)
+	if err != nil {
+		t.Fatalf("MonthSessions: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if r := records[1]; r.Date != "2025-03-04" || r.Hours != 7 || r.BreakMinutes != 30 || r.Note != "release prep" {
+		t.Errorf("records[1] = %+v", r)
+	}
+
+	missing, err := MonthSessions("testdata/history", 2024, 12)
+	if err != nil || missing != nil {
+		t.Errorf("missing month: got %v, %v; want nil, nil", missing, err)
+	}
+}
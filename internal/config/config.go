@@ -2,12 +2,16 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/kairos/internal/archive"
+	"github.com/kairos/internal/work"
 	"gopkg.in/yaml.v3"
 )
 
@@ -19,8 +23,12 @@ const (
 	ProviderOpenAI AIProvider = "openai"
 	ProviderClaude AIProvider = "claude"
 	ProviderGemini AIProvider = "gemini"
+	ProviderEcho   AIProvider = "echo" // offline, deterministic; for development and tests
 )
 
+// DefaultOpenAIBaseURL is the OpenAI API root
+const DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+
 type Config struct {
 	DatabasePath string     `yaml:"DatabasePath"`
 	WeeklyGoal   float64    `yaml:"WeeklyGoal"`
@@ -34,6 +42,9 @@ type Config struct {
 	// OpenAI settings
 	OpenAIModel  string `yaml:"OpenAIModel"`
 	OpenAIAPIKey string `yaml:"OpenAIAPIKey"`
+	// OpenAIBaseURL is the API root, so any OpenAI-compatible server
+	// (a gateway, LM Studio, Groq) can stand in for OpenAI
+	OpenAIBaseURL string `yaml:"OpenAIBaseURL"`
 
 	// Claude settings
 	ClaudeModel  string `yaml:"ClaudeModel"`
@@ -46,6 +57,109 @@ type Config struct {
 	// Auto-clockout settings
 	AutoClockoutMinutes int  `yaml:"AutoClockoutMinutes"`
 	AutoArchive         bool `yaml:"AutoArchive"`
+
+	// ArchiveFormat is the files archiving writes: "markdown" (readable,
+	// used by history and AI context), "json" (lossless, for restore) or "both"
+	ArchiveFormat string `yaml:"ArchiveFormat"`
+
+	// Archived months listed in AI prompts, one line each (0 = none)
+	HistoryContextMonths int `yaml:"HistoryContextMonths"`
+
+	// Milliseconds a write waits for another process holding the database
+	// (the MCP server, a second CLI call) before failing with "database is locked"
+	BusyTimeout int `yaml:"BusyTimeout"`
+
+	// Longest session note in characters, checked on clockin and edit (0 = no limit)
+	MaxNoteLength int `yaml:"MaxNoteLength"`
+
+	// Open sessions running longer than this are shown capped with a
+	// forgotten clock-out warning (0 = never)
+	StaleSessionHours float64 `yaml:"StaleSessionHours"`
+
+	// Friday shifts of at least this many hours get the default break (0 = never)
+	FridayShortShiftHours float64 `yaml:"FridayShortShiftHours"`
+
+	// Work rules; empty keeps the defaults in internal/work (30 min breaks,
+	// none on Fridays, Mon-Fri). Break keys are lowercase weekday names.
+	DailyBreakMinutes map[string]int `yaml:"DailyBreakMinutes"`
+	WorkDays          []time.Weekday `yaml:"WorkDays"`
+	// Holidays are dates (YYYY-MM-DD) off work, like public holidays or PTO,
+	// in addition to the ones added with kairos holiday add
+	Holidays []string `yaml:"Holidays"`
+
+	// Display settings
+	DecimalPlaces int  `yaml:"DecimalPlaces"`
+	IncludeActive bool `yaml:"IncludeActive"` // count the running session in today/week totals
+	// DurationFormat is "decimal" (7.13h) or "hm" (7h 8m) for the status banner
+	DurationFormat string `yaml:"DurationFormat"`
+
+	// WeekNumbering labels month breakdowns by "iso" week (W52, W1) or by
+	// "month" week (Wk 1-6) in the month report, chart and archives
+	WeekNumbering string `yaml:"WeekNumbering"`
+	// WeekStartsOn is the first day of the week for week totals, the week
+	// view and charts (0 = Sunday, 1 = Monday)
+	WeekStartsOn time.Weekday `yaml:"WeekStartsOn"`
+	// RoundingMinutes rounds each session's hours to the nearest 15 (quarter
+	// hour), 6 (tenth of an hour), ... minutes in totals, exports and
+	// archives; 0 reports exact hours
+	RoundingMinutes int `yaml:"RoundingMinutes"`
+	// SplitAtMidnight counts a session spanning midnight on each day it
+	// covers instead of the day it started; sessions are stored whole
+	SplitAtMidnight bool `yaml:"SplitAtMidnight"`
+	// FlexStartDate (YYYY-MM-DD) is where the flex balance starts counting;
+	// empty counts from the first session in the database
+	FlexStartDate string `yaml:"FlexStartDate"`
+
+	// Chart color thresholds in hours per day (ChartMinHours 0 = no greying)
+	ChartMinHours      float64 `yaml:"ChartMinHours"`
+	ChartLongDayHours  float64 `yaml:"ChartLongDayHours"`
+	ChartOverworkHours float64 `yaml:"ChartOverworkHours"`
+
+	// Shell commands run around clock actions (session details in KAIROS_* env vars)
+	PreClockin   string `yaml:"PreClockin"`
+	PostClockout string `yaml:"PostClockout"`
+
+	// SystemPromptOverride replaces the assistant's default instructions in
+	// ask prompts (e.g. "Answer tersely with numbers only"); the work data
+	// is still included
+	SystemPromptOverride string `yaml:"SystemPromptOverride"`
+
+	// AI request timeouts in seconds: a whole answer (also the longest pause
+	// in a streamed one) and the availability check before it
+	AITimeoutSeconds      int `yaml:"AITimeoutSeconds"`
+	AIProbeTimeoutSeconds int `yaml:"AIProbeTimeoutSeconds"`
+	// AIMaxRetries is how often a request failing with a connection error,
+	// 429 or 5xx is retried, with growing waits (0 = no retries)
+	AIMaxRetries int `yaml:"AIMaxRetries"`
+
+	// Bearer token the MCP server requires on /mcp and /sse (empty = no auth)
+	MCPToken string `yaml:"MCPToken"`
+
+	// fileValues holds the config file's values that the environment
+	// overrode, by key, so Save writes the file's values (and never an API
+	// key from the environment) back
+	fileValues map[string]fileValue
+}
+
+// fileValue is a key's value in the config file and the one the
+// environment replaced it with
+type fileValue struct {
+	file, env interface{}
+}
+
+// envOverrides are the environment variables Load applies over the config
+// file, with the config key (and field) each one sets
+var envOverrides = []struct {
+	env string
+	key string
+}{
+	{"KAIROS_DB_PATH", "DatabasePath"},
+	{"KAIROS_WEEKLY_GOAL", "WeeklyGoal"},
+	{"KAIROS_PROVIDER", "AIProvider"},
+	{"KAIROS_TIMEZONE", "TimeZone"},
+	{"OPENAI_API_KEY", "OpenAIAPIKey"},
+	{"ANTHROPIC_API_KEY", "ClaudeAPIKey"},
+	{"GEMINI_API_KEY", "GeminiAPIKey"},
 }
 
 func Load() (*Config, error) {
@@ -64,6 +178,7 @@ func Load() (*Config, error) {
 		}
 		applyConfigMap(cfg, raw)
 	}
+	applyEnv(cfg)
 
 	// Apply defaults for missing values
 	if cfg.OllamaURL == "" {
@@ -75,6 +190,9 @@ func Load() (*Config, error) {
 	if cfg.OpenAIModel == "" {
 		cfg.OpenAIModel = "gpt-4"
 	}
+	if cfg.OpenAIBaseURL == "" {
+		cfg.OpenAIBaseURL = DefaultOpenAIBaseURL
+	}
 	if cfg.ClaudeModel == "" {
 		cfg.ClaudeModel = "claude-sonnet-4-20250514"
 	}
@@ -88,16 +206,70 @@ func Load() (*Config, error) {
 		cfg.DatabasePath = getDefaultConfig().DatabasePath
 	}
 
-	// Expand ~ in database path
-	if strings.HasPrefix(cfg.DatabasePath, "~/") {
+	return cfg, nil
+}
+
+// resolveDatabasePath expands ~ in path and makes a relative path relative
+// to the project root
+func resolveDatabasePath(path string) string {
+	if strings.HasPrefix(path, "~/") {
 		home, _ := os.UserHomeDir()
-		cfg.DatabasePath = filepath.Join(home, cfg.DatabasePath[2:])
+		path = filepath.Join(home, path[2:])
 	}
-	if !filepath.IsAbs(cfg.DatabasePath) {
-		cfg.DatabasePath = filepath.Join(getProjectRoot(), cfg.DatabasePath)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(getProjectRoot(), path)
 	}
+	return path
+}
 
-	return cfg, nil
+// applyEnv sets the config keys named in envOverrides from the environment.
+// Values go through applyConfigMap, so an invalid one (e.g. a goal that is
+// not a number) is ignored just like in the file.
+func applyEnv(cfg *Config) {
+	raw := make(map[string]interface{})
+	for _, o := range envOverrides {
+		if v := strings.TrimSpace(os.Getenv(o.env)); v != "" {
+			raw[o.key] = v
+		}
+	}
+	file := *cfg
+	applyConfigMap(cfg, raw)
+	for key := range raw {
+		if _, ok := cfg.fileValues[key]; ok {
+			continue
+		}
+		if cfg.fileValues == nil {
+			cfg.fileValues = make(map[string]fileValue)
+		}
+		cfg.fileValues[key] = fileValue{
+			file: file.field(key).Interface(),
+			env:  cfg.field(key).Interface(),
+		}
+	}
+}
+
+// field returns the settable field of c named key
+func (c *Config) field(key string) reflect.Value {
+	return reflect.ValueOf(c).Elem().FieldByName(key)
+}
+
+// SetAPIKey sets the API key for provider and returns false for providers
+// without one. The key is saved even if the environment overrides it.
+func (c *Config) SetAPIKey(provider AIProvider, key string) bool {
+	var field string
+	switch provider {
+	case ProviderOpenAI:
+		field = "OpenAIAPIKey"
+	case ProviderClaude:
+		field = "ClaudeAPIKey"
+	case ProviderGemini:
+		field = "GeminiAPIKey"
+	default:
+		return false
+	}
+	c.field(field).SetString(key)
+	delete(c.fileValues, field)
+	return true
 }
 
 func Save(cfg *Config) error {
@@ -105,13 +277,26 @@ func Save(cfg *Config) error {
 	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
 		return err
 	}
-	data, err := yaml.Marshal(cfg)
+	data, err := cfg.marshalFile()
 	if err != nil {
 		return err
 	}
 	return os.WriteFile(configPath, data, 0644)
 }
 
+// marshalFile renders cfg as config file YAML, with the file's own values in
+// place of those taken from the environment. A value changed since Load is
+// written as it is.
+func (c *Config) marshalFile() ([]byte, error) {
+	out := *c
+	for key, v := range c.fileValues {
+		if field := out.field(key); field.Interface() == v.env {
+			field.Set(reflect.ValueOf(v.file))
+		}
+	}
+	return yaml.Marshal(&out)
+}
+
 func getConfigPath() string {
 	return filepath.Join(getProjectRoot(), ".kairos", "config.yaml")
 }
@@ -119,17 +304,33 @@ func getConfigPath() string {
 func getDefaultConfig() *Config {
 	dataDir := filepath.Join(getProjectRoot(), ".kairos")
 	return &Config{
-		DatabasePath:        filepath.Join(dataDir, "data.db"),
-		WeeklyGoal:          38.5,
-		AIProvider:          ProviderOllama,
-		TimeZone:            time.Local.String(),
-		OllamaURL:           "http://localhost:11434",
-		OllamaModel:         "llama3.2",
-		OpenAIModel:         "gpt-4",
-		ClaudeModel:         "claude-sonnet-4-20250514",
-		GeminiModel:         "gemini-2.0-flash",
-		AutoClockoutMinutes: 0, // 0 = disabled
-		AutoArchive:         false,
+		DatabasePath:          filepath.Join(dataDir, "data.db"),
+		WeeklyGoal:            38.5,
+		AIProvider:            ProviderOllama,
+		TimeZone:              time.Local.String(),
+		OllamaURL:             "http://localhost:11434",
+		OllamaModel:           "llama3.2",
+		OpenAIModel:           "gpt-4",
+		OpenAIBaseURL:         DefaultOpenAIBaseURL,
+		ClaudeModel:           "claude-sonnet-4-20250514",
+		GeminiModel:           "gemini-2.0-flash",
+		AutoClockoutMinutes:   0, // 0 = disabled
+		AutoArchive:           false,
+		HistoryContextMonths:  3,
+		BusyTimeout:           5000,
+		StaleSessionHours:     16,
+		FridayShortShiftHours: work.FridayShortShiftHours,
+		DecimalPlaces:         work.DefaultDecimalPlaces,
+		DurationFormat:        work.DurationDecimal,
+		WeekNumbering:         work.WeekNumberingISO,
+		WeekStartsOn:          time.Monday,
+		ArchiveFormat:         archive.FormatBoth,
+		AITimeoutSeconds:      60,
+		AIProbeTimeoutSeconds: 5,
+		AIMaxRetries:          2,
+		ChartMinHours:         0,
+		ChartLongDayHours:     10,
+		ChartOverworkHours:    12,
 	}
 }
 
@@ -189,6 +390,26 @@ func (c *Config) Now() time.Time {
 	return time.Now().In(c.GetLocation())
 }
 
+// Rules returns the work rules from the config
+func (c *Config) Rules() work.Rules {
+	rules := work.Rules{
+		WorkDays:              c.WorkDays,
+		FridayShortShiftHours: c.FridayShortShiftHours,
+	}
+	for _, date := range c.Holidays {
+		rules.AddHoliday(date)
+	}
+	if len(c.DailyBreakMinutes) > 0 {
+		rules.DailyBreakMinutes = make(map[time.Weekday]int)
+		for name, minutes := range c.DailyBreakMinutes {
+			if day, ok := work.ParseWeekday(name); ok {
+				rules.DailyBreakMinutes[day] = minutes
+			}
+		}
+	}
+	return rules
+}
+
 // GetAPIKey returns the API key for the current provider
 func (c *Config) GetAPIKey() string {
 	switch c.AIProvider {
@@ -231,7 +452,42 @@ func (e *ValidationError) Error() string {
 
 // Validate checks the configuration for common issues
 func (c *Config) Validate() error {
-	// Check for missing required fields based on provider
+	switch c.AIProvider {
+	case ProviderOllama, ProviderOpenAI, ProviderClaude, ProviderGemini, ProviderEcho:
+	default:
+		return &ValidationError{Field: "AIProvider", Message: fmt.Sprintf("unknown AI provider %q (use ollama, openai, claude, gemini or echo)", c.AIProvider)}
+	}
+	if err := c.ValidateProvider(); err != nil {
+		return err
+	}
+
+	if c.OpenAIBaseURL != "" {
+		if u, err := url.Parse(c.OpenAIBaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return &ValidationError{Field: "OpenAIBaseURL", Message: fmt.Sprintf("invalid OpenAI base URL %q (use e.g. %s)", c.OpenAIBaseURL, DefaultOpenAIBaseURL)}
+		}
+	}
+
+	if c.TimeZone != "" && c.TimeZone != "local" {
+		if _, ok := parseTimezone(c.TimeZone); !ok {
+			return &ValidationError{Field: "TimeZone", Message: fmt.Sprintf("unknown timezone %q (use an IANA name like Europe/Vienna, UTC+01:00 or local)", c.TimeZone)}
+		}
+	}
+
+	// Validate weekly goal is positive
+	if c.WeeklyGoal <= 0 {
+		return &ValidationError{Field: "WeeklyGoal", Message: "Weekly goal must be positive"}
+	}
+
+	// Validate database path is set
+	if c.DatabasePath == "" {
+		return &ValidationError{Field: "DatabasePath", Message: "Database path is required"}
+	}
+
+	return nil
+}
+
+// ValidateProvider checks the fields the selected AI provider needs
+func (c *Config) ValidateProvider() error {
 	switch c.AIProvider {
 	case ProviderOllama:
 		if c.OllamaURL == "" {
@@ -259,17 +515,6 @@ func (c *Config) Validate() error {
 			return &ValidationError{Field: "GeminiAPIKey", Message: "Gemini API key is required (set GEMINI_API_KEY env var)"}
 		}
 	}
-
-	// Validate weekly goal is positive
-	if c.WeeklyGoal <= 0 {
-		return &ValidationError{Field: "WeeklyGoal", Message: "Weekly goal must be positive"}
-	}
-
-	// Validate database path is set
-	if c.DatabasePath == "" {
-		return &ValidationError{Field: "DatabasePath", Message: "Database path is required"}
-	}
-
 	return nil
 }
 
@@ -279,7 +524,7 @@ func applyConfigMap(cfg *Config, raw map[string]interface{}) {
 		switch normalized {
 		case "databasepath", "database", "db":
 			if s, ok := asString(value); ok && s != "" {
-				cfg.DatabasePath = s
+				cfg.DatabasePath = resolveDatabasePath(s)
 			}
 		case "weeklygoal", "weeklyhours":
 			if f, ok := asFloat(value); ok {
@@ -305,6 +550,10 @@ func applyConfigMap(cfg *Config, raw map[string]interface{}) {
 			if s, ok := asString(value); ok && s != "" {
 				cfg.OpenAIModel = s
 			}
+		case "openaibaseurl", "openaiurl":
+			if s, ok := asString(value); ok && s != "" {
+				cfg.OpenAIBaseURL = s
+			}
 		case "openaiapikey":
 			if s, ok := asString(value); ok && s != "" {
 				cfg.OpenAIAPIKey = s
@@ -333,6 +582,173 @@ func applyConfigMap(cfg *Config, raw map[string]interface{}) {
 			if b, ok := asBool(value); ok {
 				cfg.AutoArchive = b
 			}
+		case "dailybreakminutes", "breakminutes":
+			if m, ok := value.(map[string]interface{}); ok {
+				breaks := make(map[string]int)
+				for day, v := range m {
+					weekday, ok := work.ParseWeekday(day)
+					minutes, isInt := asInt(v)
+					if ok && isInt && minutes >= 0 {
+						breaks[strings.ToLower(weekday.String())] = minutes
+					}
+				}
+				cfg.DailyBreakMinutes = breaks
+			}
+		case "workdays":
+			var items []interface{}
+			switch v := value.(type) {
+			case []interface{}:
+				items = v
+			case string:
+				for _, part := range strings.Split(v, ",") {
+					items = append(items, part)
+				}
+			}
+			var days []time.Weekday
+			for _, item := range items {
+				s, ok := asString(item)
+				if !ok {
+					if i, isInt := asInt(item); isInt {
+						s = strconv.Itoa(i)
+					}
+				}
+				if day, ok := work.ParseWeekday(s); ok {
+					days = append(days, day)
+				}
+			}
+			if len(days) > 0 {
+				cfg.WorkDays = days
+			}
+		case "holidays":
+			var items []interface{}
+			switch v := value.(type) {
+			case []interface{}:
+				items = v
+			case string:
+				for _, part := range strings.Split(v, ",") {
+					items = append(items, part)
+				}
+			}
+			var dates []string
+			for _, item := range items {
+				if s, ok := asDate(item); ok {
+					dates = append(dates, s)
+				}
+			}
+			cfg.Holidays = dates
+		case "historycontextmonths", "historymonths":
+			if i, ok := asInt(value); ok && i >= 0 {
+				cfg.HistoryContextMonths = i
+			}
+		case "busytimeout":
+			if i, ok := asInt(value); ok && i >= 0 {
+				cfg.BusyTimeout = i
+			}
+		case "maxnotelength":
+			if i, ok := asInt(value); ok && i >= 0 {
+				cfg.MaxNoteLength = i
+			}
+		case "stalesessionhours":
+			if f, ok := asFloat(value); ok && f >= 0 {
+				cfg.StaleSessionHours = f
+			}
+		case "fridayshortshifthours":
+			if f, ok := asFloat(value); ok && f >= 0 {
+				cfg.FridayShortShiftHours = f
+			}
+		case "decimalplaces", "decimals", "precision":
+			if i, ok := asInt(value); ok && i >= 0 {
+				cfg.DecimalPlaces = i
+			}
+		case "durationformat":
+			if s, ok := asString(value); ok {
+				switch f := strings.ToLower(s); f {
+				case work.DurationDecimal, work.DurationHM:
+					cfg.DurationFormat = f
+				}
+			}
+		case "archiveformat":
+			if s, ok := asString(value); ok {
+				switch f := strings.ToLower(s); f {
+				case archive.FormatMarkdown, archive.FormatJSON, archive.FormatBoth:
+					cfg.ArchiveFormat = f
+				}
+			}
+		case "weeknumbering":
+			if s, ok := asString(value); ok {
+				switch n := strings.ToLower(s); n {
+				case work.WeekNumberingISO, work.WeekNumberingMonth:
+					cfg.WeekNumbering = n
+				}
+			}
+		case "weekstartson", "weekstart":
+			s, ok := asString(value)
+			if !ok {
+				if i, isInt := asInt(value); isInt {
+					s = strconv.Itoa(i)
+				}
+			}
+			if day, ok := work.ParseWeekday(s); ok {
+				cfg.WeekStartsOn = day
+			}
+		case "roundingminutes", "rounding":
+			if i, ok := asInt(value); ok && i >= 0 && i <= 60 {
+				cfg.RoundingMinutes = i
+			}
+		case "flexstartdate", "flexstart":
+			if s, ok := asDate(value); ok {
+				cfg.FlexStartDate = s
+			} else if s, _ := asString(value); s == "" {
+				cfg.FlexStartDate = ""
+			}
+		case "splitatmidnight", "splitsessions":
+			if b, ok := asBool(value); ok {
+				cfg.SplitAtMidnight = b
+			}
+		case "systempromptoverride", "systemprompt":
+			if s, ok := asString(value); ok {
+				cfg.SystemPromptOverride = s
+			}
+		case "aitimeoutseconds", "aitimeout":
+			if i, ok := asInt(value); ok && i > 0 {
+				cfg.AITimeoutSeconds = i
+			}
+		case "aiprobetimeoutseconds", "aiprobetimeout":
+			if i, ok := asInt(value); ok && i > 0 {
+				cfg.AIProbeTimeoutSeconds = i
+			}
+		case "aimaxretries", "airetries":
+			if i, ok := asInt(value); ok && i >= 0 {
+				cfg.AIMaxRetries = i
+			}
+		case "includeactive":
+			if b, ok := asBool(value); ok {
+				cfg.IncludeActive = b
+			}
+		case "chartminhours", "minhours":
+			if f, ok := asFloat(value); ok && f >= 0 {
+				cfg.ChartMinHours = f
+			}
+		case "chartlongdayhours", "longdayhours":
+			if f, ok := asFloat(value); ok && f > 0 {
+				cfg.ChartLongDayHours = f
+			}
+		case "chartoverworkhours", "overworkhours":
+			if f, ok := asFloat(value); ok && f > 0 {
+				cfg.ChartOverworkHours = f
+			}
+		case "preclockin", "preclockinhook":
+			if s, ok := asString(value); ok {
+				cfg.PreClockin = s
+			}
+		case "postclockout", "postclockouthook":
+			if s, ok := asString(value); ok {
+				cfg.PostClockout = s
+			}
+		case "mcptoken":
+			if s, ok := asString(value); ok {
+				cfg.MCPToken = s
+			}
 		}
 	}
 }
@@ -360,6 +776,22 @@ func asString(value interface{}) (string, bool) {
 	}
 }
 
+// asDate reads a YYYY-MM-DD date, which YAML decodes as a time.Time unless
+// it is quoted
+func asDate(value interface{}) (string, bool) {
+	if t, ok := value.(time.Time); ok {
+		return t.Format("2006-01-02"), true
+	}
+	s, ok := asString(value)
+	if !ok {
+		return "", false
+	}
+	if _, err := time.Parse("2006-01-02", s); err != nil {
+		return "", false
+	}
+	return s, true
+}
+
 func asFloat(value interface{}) (float64, bool) {
 	switch v := value.(type) {
 	case int:
@@ -547,6 +979,8 @@ func (c *Config) IsConfigured() bool {
 		return c.ClaudeAPIKey != ""
 	case ProviderGemini:
 		return c.GeminiAPIKey != ""
+	case ProviderEcho:
+		return true
 	}
 	return false
 }
@@ -3,7 +3,11 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestGetAPIKey(t *testing.T) {
@@ -166,3 +170,310 @@ func TestConfigValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestMigrateLegacyDir(t *testing.T) {
+	root := t.TempDir()
+	legacy := filepath.Join(root, ".samaya")
+	target := filepath.Join(root, ".kairos")
+
+	os.MkdirAll(filepath.Join(legacy, "history"), 0755)
+	os.WriteFile(filepath.Join(legacy, "data.db"), []byte("legacy-db"), 0644)
+	os.WriteFile(filepath.Join(legacy, "history", "2024-01.md"), []byte("# January 2024"), 0644)
+	os.MkdirAll(target, 0755)
+	os.WriteFile(filepath.Join(target, "config.yaml"), []byte("WeeklyGoal: 40"), 0644)
+	os.WriteFile(filepath.Join(legacy, "config.yaml"), []byte("WeeklyGoal: 38.5"), 0644)
+
+	result, err := migrateLegacyDir(legacy, target)
+	if err != nil {
+		t.Fatalf("migrateLegacyDir: %v", err)
+	}
+	if len(result.Copied) != 2 {
+		t.Errorf("copied = %v, want data.db and history/2024-01.md", result.Copied)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0] != "config.yaml" {
+		t.Errorf("skipped = %v, want [config.yaml]", result.Skipped)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(target, "history", "2024-01.md"))
+	if string(data) != "# January 2024" {
+		t.Errorf("history not copied, got %q", data)
+	}
+	data, _ = os.ReadFile(filepath.Join(target, "config.yaml"))
+	if string(data) != "WeeklyGoal: 40" {
+		t.Errorf("existing config was overwritten: %q", data)
+	}
+	if databases := result.SkippedDatabases(); len(databases) != 0 {
+		t.Errorf("skipped databases = %v, want none", databases)
+	}
+
+	// Migrating again finds a database already in place
+	again, err := migrateLegacyDir(legacy, target)
+	if err != nil {
+		t.Fatalf("migrateLegacyDir: %v", err)
+	}
+	if databases := again.SkippedDatabases(); len(databases) != 1 || databases[0] != "data.db" {
+		t.Errorf("skipped databases = %v, want [data.db]", databases)
+	}
+}
+
+func TestRelativeTo(t *testing.T) {
+	if rel, ok := relativeTo("/home/u/.samaya", "/home/u/.samaya/data.db"); !ok || rel != "data.db" {
+		t.Errorf("relativeTo inside = %q, %v", rel, ok)
+	}
+	if _, ok := relativeTo("/home/u/.samaya", "/home/u/.kairos/data.db"); ok {
+		t.Error("relativeTo should reject paths outside the directory")
+	}
+}
+
+func TestWorkRulesFromConfigMap(t *testing.T) {
+	cfg := getDefaultConfig()
+	applyConfigMap(cfg, map[string]interface{}{
+		"work_days":           []interface{}{"sun", "Monday", 2, "3", "thu", "holiday"},
+		"daily_break_minutes": map[string]interface{}{"Mon": 45, "friday": "15", "someday": 10},
+	})
+
+	rules := cfg.Rules()
+	if len(rules.WorkDays) != 5 || rules.WorkDays[0] != time.Sunday || rules.WorkDays[4] != time.Thursday {
+		t.Errorf("WorkDays = %v, want Sunday-Thursday", rules.WorkDays)
+	}
+	if rules.DailyBreakMinutes[time.Monday] != 45 || rules.DailyBreakMinutes[time.Friday] != 15 || len(rules.DailyBreakMinutes) != 2 {
+		t.Errorf("DailyBreakMinutes = %v, want Monday 45 and Friday 15", rules.DailyBreakMinutes)
+	}
+
+	// Without the keys the rules stay empty, i.e. the work package defaults
+	if rules := getDefaultConfig().Rules(); rules.WorkDays != nil || rules.DailyBreakMinutes != nil {
+		t.Errorf("default rules = %+v, want zero values", rules)
+	}
+}
+
+func TestWeekStartsOnFromConfigMap(t *testing.T) {
+	if cfg := getDefaultConfig(); cfg.WeekStartsOn != time.Monday {
+		t.Errorf("default WeekStartsOn = %s, want Monday", cfg.WeekStartsOn)
+	}
+	for value, want := range map[interface{}]time.Weekday{"sunday": time.Sunday, "Sat": time.Saturday, 0: time.Sunday, "1": time.Monday} {
+		cfg := getDefaultConfig()
+		applyConfigMap(cfg, map[string]interface{}{"week_starts_on": value})
+		if cfg.WeekStartsOn != want {
+			t.Errorf("week_starts_on %v = %s, want %s", value, cfg.WeekStartsOn, want)
+		}
+	}
+	cfg := getDefaultConfig()
+	applyConfigMap(cfg, map[string]interface{}{"week_starts_on": "someday"})
+	if cfg.WeekStartsOn != time.Monday {
+		t.Errorf("invalid week_starts_on changed WeekStartsOn to %s", cfg.WeekStartsOn)
+	}
+}
+
+func TestLoadEnvOverrides(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "ci.db")
+	t.Setenv("KAIROS_DB_PATH", dbPath)
+	t.Setenv("KAIROS_WEEKLY_GOAL", "32.5")
+	t.Setenv("KAIROS_PROVIDER", "Claude")
+	t.Setenv("KAIROS_TIMEZONE", "America/New_York")
+	t.Setenv("OPENAI_API_KEY", "sk-openai")
+	t.Setenv("ANTHROPIC_API_KEY", "sk-anthropic")
+	t.Setenv("GEMINI_API_KEY", "gemini-key")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.DatabasePath != dbPath {
+		t.Errorf("DatabasePath = %s, want %s", cfg.DatabasePath, dbPath)
+	}
+	if cfg.WeeklyGoal != 32.5 {
+		t.Errorf("WeeklyGoal = %v, want 32.5", cfg.WeeklyGoal)
+	}
+	if cfg.AIProvider != ProviderClaude {
+		t.Errorf("AIProvider = %s, want claude", cfg.AIProvider)
+	}
+	if cfg.TimeZone != "America/New_York" {
+		t.Errorf("TimeZone = %s, want America/New_York", cfg.TimeZone)
+	}
+	if cfg.OpenAIAPIKey != "sk-openai" || cfg.ClaudeAPIKey != "sk-anthropic" || cfg.GeminiAPIKey != "gemini-key" {
+		t.Errorf("API keys = %q, %q, %q; want the environment's", cfg.OpenAIAPIKey, cfg.ClaudeAPIKey, cfg.GeminiAPIKey)
+	}
+	if cfg.GetAPIKey() != "sk-anthropic" {
+		t.Errorf("GetAPIKey = %q, want the Anthropic key", cfg.GetAPIKey())
+	}
+}
+
+func TestEnvOverridesWinOverFile(t *testing.T) {
+	cfg := getDefaultConfig()
+	applyConfigMap(cfg, map[string]interface{}{"weekly_goal": 40, "ai_provider": "openai", "openai_api_key": "from-file"})
+
+	t.Setenv("KAIROS_WEEKLY_GOAL", "30")
+	t.Setenv("OPENAI_API_KEY", "from-env")
+	// An unusable value is ignored, as it would be in the file
+	t.Setenv("KAIROS_PROVIDER", "")
+	applyEnv(cfg)
+
+	if cfg.WeeklyGoal != 30 || cfg.AIProvider != ProviderOpenAI || cfg.OpenAIAPIKey != "from-env" {
+		t.Errorf("after env: goal %v, provider %s, key %q; want 30, openai, from-env", cfg.WeeklyGoal, cfg.AIProvider, cfg.OpenAIAPIKey)
+	}
+	// Save writes the file's key back, not the environment's
+	data, err := cfg.marshalFile()
+	if err != nil {
+		t.Fatalf("marshalFile: %v", err)
+	}
+	if !strings.Contains(string(data), "from-file") || strings.Contains(string(data), "from-env") {
+		t.Errorf("saved config should keep the file's API key:\n%s", data)
+	}
+
+	t.Setenv("KAIROS_WEEKLY_GOAL", "lots")
+	applyEnv(cfg)
+	if cfg.WeeklyGoal != 30 {
+		t.Errorf("invalid KAIROS_WEEKLY_GOAL changed the goal to %v", cfg.WeeklyGoal)
+	}
+}
+
+func TestSaveKeepsFileValuesUnderEnvOverrides(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "file.db")
+	cfg := getDefaultConfig()
+	applyConfigMap(cfg, map[string]interface{}{"database_path": dbPath, "weekly_goal": 40, "ai_provider": "openai", "timezone": "Europe/Berlin"})
+
+	t.Setenv("KAIROS_DB_PATH", filepath.Join(t.TempDir(), "env.db"))
+	t.Setenv("KAIROS_WEEKLY_GOAL", "30")
+	t.Setenv("KAIROS_PROVIDER", "claude")
+	t.Setenv("KAIROS_TIMEZONE", "America/New_York")
+	applyEnv(cfg)
+
+	data, err := cfg.marshalFile()
+	if err != nil {
+		t.Fatalf("marshalFile: %v", err)
+	}
+	var saved Config
+	if err := yaml.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("yaml.Unmarshal: %v", err)
+	}
+	if saved.DatabasePath != dbPath || saved.WeeklyGoal != 40 || saved.AIProvider != ProviderOpenAI || saved.TimeZone != "Europe/Berlin" {
+		t.Errorf("saved %s, %v, %s, %s; want the file's values", saved.DatabasePath, saved.WeeklyGoal, saved.AIProvider, saved.TimeZone)
+	}
+
+	// A value changed after loading is saved, override or not
+	cfg.WeeklyGoal = 35
+	data, _ = cfg.marshalFile()
+	if err := yaml.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("yaml.Unmarshal: %v", err)
+	}
+	if saved.WeeklyGoal != 35 || saved.TimeZone != "Europe/Berlin" {
+		t.Errorf("saved goal %v, zone %s; want 35 and the file's zone", saved.WeeklyGoal, saved.TimeZone)
+	}
+}
+
+func TestValidateProviderAndTimezone(t *testing.T) {
+	cfg := getDefaultConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("default config: %v", err)
+	}
+
+	cfg.AIProvider = "mistral"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "AIProvider") {
+		t.Errorf("unknown provider: Validate = %v", err)
+	}
+
+	cfg = getDefaultConfig()
+	cfg.TimeZone = "Mars/Base"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "TimeZone") {
+		t.Errorf("unknown timezone: Validate = %v", err)
+	}
+	for _, tz := range []string{"local", "UTC+01:00", "Europe/Vienna"} {
+		cfg.TimeZone = tz
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("timezone %s: %v", tz, err)
+		}
+	}
+}
+
+func TestValidateOpenAIBaseURL(t *testing.T) {
+	cfg := getDefaultConfig()
+	if cfg.OpenAIBaseURL != DefaultOpenAIBaseURL {
+		t.Errorf("default OpenAIBaseURL = %q, want %q", cfg.OpenAIBaseURL, DefaultOpenAIBaseURL)
+	}
+
+	for _, u := range []string{"http://localhost:1234/v1", "https://api.groq.com/openai/v1"} {
+		cfg.OpenAIBaseURL = u
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("%s: %v", u, err)
+		}
+	}
+	for _, u := range []string{"api.openai.com/v1", "ftp://example.com", "http://", "https://exa mple.com"} {
+		cfg.OpenAIBaseURL = u
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "OpenAIBaseURL") {
+			t.Errorf("%s: Validate = %v, want an OpenAIBaseURL error", u, err)
+		}
+	}
+
+	applyConfigMap(cfg, map[string]interface{}{"openai_base_url": "http://localhost:1234/v1"})
+	if cfg.OpenAIBaseURL != "http://localhost:1234/v1" {
+		t.Errorf("openai_base_url = %q", cfg.OpenAIBaseURL)
+	}
+}
+
+func TestSetAPIKey(t *testing.T) {
+	cfg := getDefaultConfig()
+	cfg.OpenAIAPIKey = "from-file"
+	t.Setenv("OPENAI_API_KEY", "from-env")
+	applyEnv(cfg)
+
+	// A key set explicitly is saved even though the environment overrode the file
+	if !cfg.SetAPIKey(ProviderOpenAI, "from-flag") {
+		t.Fatal("SetAPIKey(openai) = false")
+	}
+	data, err := cfg.marshalFile()
+	if err != nil {
+		t.Fatalf("marshalFile: %v", err)
+	}
+	if !strings.Contains(string(data), "from-flag") {
+		t.Errorf("saved config should hold the new key:\n%s", data)
+	}
+
+	if !cfg.SetAPIKey(ProviderClaude, "claude-key") || cfg.ClaudeAPIKey != "claude-key" {
+		t.Errorf("SetAPIKey(claude) left ClaudeAPIKey = %q", cfg.ClaudeAPIKey)
+	}
+	if cfg.SetAPIKey(ProviderOllama, "x") {
+		t.Error("SetAPIKey(ollama) = true, want false")
+	}
+}
+
+func TestAITimeoutsFromConfigMap(t *testing.T) {
+	cfg := getDefaultConfig()
+	if cfg.AITimeoutSeconds != 60 || cfg.AIProbeTimeoutSeconds != 5 {
+		t.Errorf("default timeouts = %d/%d, want 60/5", cfg.AITimeoutSeconds, cfg.AIProbeTimeoutSeconds)
+	}
+	applyConfigMap(cfg, map[string]interface{}{"ai_timeout_seconds": 300, "ai_probe_timeout_seconds": "2"})
+	if cfg.AITimeoutSeconds != 300 || cfg.AIProbeTimeoutSeconds != 2 {
+		t.Errorf("timeouts = %d/%d, want 300/2", cfg.AITimeoutSeconds, cfg.AIProbeTimeoutSeconds)
+	}
+	applyConfigMap(cfg, map[string]interface{}{"ai_timeout_seconds": 0, "ai_probe_timeout_seconds": -1})
+	if cfg.AITimeoutSeconds != 300 || cfg.AIProbeTimeoutSeconds != 2 {
+		t.Errorf("non-positive timeouts were applied: %d/%d", cfg.AITimeoutSeconds, cfg.AIProbeTimeoutSeconds)
+	}
+}
+
+func TestHolidaysFromConfigMap(t *testing.T) {
+	cfg := getDefaultConfig()
+	applyConfigMap(cfg, map[string]interface{}{
+		"holidays": []interface{}{time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC), "2024-12-26", "Dec 31", 5},
+	})
+	if len(cfg.Holidays) != 2 || cfg.Holidays[0] != "2024-12-25" || cfg.Holidays[1] != "2024-12-26" {
+		t.Errorf("Holidays = %v, want the two valid dates", cfg.Holidays)
+	}
+	if rules := cfg.Rules(); !rules.Holidays["2024-12-25"] || len(rules.Holidays) != 2 {
+		t.Errorf("rules holidays = %v", rules.Holidays)
+	}
+}
+
+func TestFlexStartDateFromConfigMap(t *testing.T) {
+	for value, want := range map[interface{}]string{
+		"2024-01-08": "2024-01-08",
+		time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC): "2024-01-08", // unquoted in YAML
+		"Jan 8": "",
+	} {
+		cfg := getDefaultConfig()
+		applyConfigMap(cfg, map[string]interface{}{"flex_start_date": value})
+		if cfg.FlexStartDate != want {
+			t.Errorf("flex_start_date %v = %q, want %q", value, cfg.FlexStartDate, want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// legacyDirName is the data directory used before the samaya → kairos rename
+const legacyDirName = ".samaya"
+
+// MigrationResult describes what MigrateLegacy copied
+type MigrationResult struct {
+	From    string
+	To      string
+	Copied  []string
+	Skipped []string
+}
+
+// SkippedDatabases returns the skipped files that are databases: the legacy
+// data they hold was not migrated
+func (r *MigrationResult) SkippedDatabases() []string {
+	var databases []string
+	for _, rel := range r.Skipped {
+		if filepath.Ext(rel) == ".db" {
+			databases = append(databases, rel)
+		}
+	}
+	return databases
+}
+
+// MigrateLegacy copies a legacy .samaya directory (config, database and
+// history) into the current .kairos location and points DatabasePath at the
+// new database. Existing files in .kairos are never overwritten. Returns nil
+// when no legacy data exists.
+func MigrateLegacy() (*MigrationResult, error) {
+	legacyDir := findLegacyDir()
+	if legacyDir == "" {
+		return nil, nil
+	}
+
+	targetDir := filepath.Join(getProjectRoot(), ".kairos")
+	result, err := migrateLegacyDir(legacyDir, targetDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rewrite a DatabasePath that still points into the legacy directory
+	if _, err := os.Stat(getConfigPath()); err == nil {
+		cfg, err := Load()
+		if err != nil {
+			return result, err
+		}
+		if rel, ok := relativeTo(legacyDir, cfg.DatabasePath); ok {
+			cfg.DatabasePath = filepath.Join(targetDir, rel)
+			if err := Save(cfg); err != nil {
+				return result, fmt.Errorf("failed to update DatabasePath: %w", err)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func findLegacyDir() string {
+	candidates := []string{filepath.Join(getProjectRoot(), legacyDirName)}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, legacyDirName))
+	}
+	for _, dir := range candidates {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return ""
+}
+
+func migrateLegacyDir(legacyDir, targetDir string) (*MigrationResult, error) {
+	result := &MigrationResult{From: legacyDir, To: targetDir}
+
+	err := filepath.Walk(legacyDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(legacyDir, path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(targetDir, rel)
+		if info.IsDir() {
+			return os.MkdirAll(dst, 0755)
+		}
+		if _, err := os.Stat(dst); err == nil {
+			result.Skipped = append(result.Skipped, rel)
+			return nil
+		}
+		if err := copyFile(path, dst, info.Mode()); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", rel, err)
+		}
+		result.Copied = append(result.Copied, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode.Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// relativeTo reports path relative to dir when path lies inside dir
+func relativeTo(dir, path string) (string, bool) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	return rel, true
+}
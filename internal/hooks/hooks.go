@@ -0,0 +1,89 @@
+// Package hooks runs user-configured shell commands around clock actions.
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/kairos/internal/logger"
+	"github.com/kairos/internal/storage"
+)
+
+// Hook events, exported to the command as KAIROS_HOOK
+const (
+	PreClockin   = "pre-clockin"
+	PostClockout = "post-clockout"
+)
+
+// Executor runs a hook command with extra environment variables
+type Executor func(command string, env []string) error
+
+// Runner executes hooks; failures are logged and never returned
+type Runner struct {
+	exec Executor
+}
+
+// New creates a runner; a nil executor runs commands through the shell
+func New(exec Executor) *Runner {
+	if exec == nil {
+		exec = ShellExecutor
+	}
+	return &Runner{exec: exec}
+}
+
+// ShellExecutor runs command with sh -c (cmd /C on Windows). Hook output goes
+// to stderr so it never mixes with command results.
+func ShellExecutor(command string, env []string) error {
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.Command("cmd", "/C", command)
+	} else {
+		c = exec.Command("sh", "-c", command)
+	}
+	c.Env = append(os.Environ(), env...)
+	c.Stdout = os.Stderr
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+// Run executes command for event with the session's details in the
+// environment. An empty command is a no-op.
+func (r *Runner) Run(event, command string, s *storage.WorkSession) {
+	if command == "" {
+		return
+	}
+	logger.Debug("running hook", "event", event, "command", command)
+	if err := r.exec(command, SessionEnv(event, s)); err != nil {
+		logger.Warn("hook failed", "event", event, "err", err)
+	}
+}
+
+// SessionEnv describes a session as KAIROS_* variables. Fields not yet known
+// (the ID before clock-in, the end before clock-out) are left out.
+func SessionEnv(event string, s *storage.WorkSession) []string {
+	env := []string{"KAIROS_HOOK=" + event}
+	if s == nil {
+		return env
+	}
+	if s.ID != "" {
+		env = append(env, "KAIROS_SESSION_ID="+s.ID)
+	}
+	env = append(env,
+		"KAIROS_DATE="+s.Date.Format("2006-01-02"),
+		"KAIROS_START="+s.StartTime.Format("15:04"),
+		"KAIROS_NOTE="+s.Note,
+		"KAIROS_PROJECT="+s.Project,
+		"KAIROS_BREAK_MINUTES="+strconv.Itoa(s.BreakMinutes),
+	)
+	if hours, complete := storage.SessionNetHours(*s, time.Now()); complete {
+		env = append(env,
+			"KAIROS_END="+s.EndTime.Format("15:04"),
+			fmt.Sprintf("KAIROS_HOURS=%.2f", hours),
+		)
+	}
+	return env
+}
@@ -0,0 +1,63 @@
+package hooks
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kairos/internal/logger"
+	"github.com/kairos/internal/storage"
+)
+
+func TestRunPassesSessionEnv(t *testing.T) {
+	var gotCommand string
+	var gotEnv []string
+	r := New(func(command string, env []string) error {
+		gotCommand, gotEnv = command, env
+		return nil
+	})
+
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	end := start.Add(8 * time.Hour)
+	r.Run(PostClockout, "notify.sh", &storage.WorkSession{
+		ID: "abc", Date: start, StartTime: start, EndTime: &end, BreakMinutes: 30, Note: "deploy",
+	})
+
+	if gotCommand != "notify.sh" {
+		t.Fatalf("command = %q", gotCommand)
+	}
+	joined := strings.Join(gotEnv, "\n")
+	for _, want := range []string{
+		"KAIROS_HOOK=post-clockout", "KAIROS_SESSION_ID=abc", "KAIROS_DATE=2024-01-15",
+		"KAIROS_START=09:00", "KAIROS_END=17:00", "KAIROS_HOURS=7.50", "KAIROS_NOTE=deploy",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("env missing %s:\n%s", want, joined)
+		}
+	}
+}
+
+func TestRunFailureIsLoggedNotFatal(t *testing.T) {
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	defer logger.SetOutput(os.Stderr)
+
+	calls := 0
+	r := New(func(command string, env []string) error {
+		calls++
+		return errors.New("exit status 1")
+	})
+
+	r.Run(PreClockin, "", nil)
+	if calls != 0 {
+		t.Errorf("empty command should not run, got %d calls", calls)
+	}
+
+	r.Run(PreClockin, "false", &storage.WorkSession{})
+	if calls != 1 || !strings.Contains(buf.String(), "hook failed") {
+		t.Errorf("calls = %d, log = %q", calls, buf.String())
+	}
+}
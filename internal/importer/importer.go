@@ -0,0 +1,280 @@
+// Package importer reads sessions from files in the layouts kairos exports,
+// so a spreadsheet or an earlier export can seed or migrate a database.
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kairos/internal/storage"
+	"github.com/kairos/internal/tracker"
+)
+
+// Result holds the sessions read from a file and the rows that could not be
+// read as one
+type Result struct {
+	Sessions []storage.WorkSession
+	Invalid  []RowError
+
+	rows []int // row of each session, for Check
+}
+
+// add appends session, read from row
+func (r *Result) add(row int, session storage.WorkSession) {
+	r.Sessions = append(r.Sessions, session)
+	r.rows = append(r.rows, row)
+}
+
+// Check runs check on each session, which may adjust it, and moves the
+// sessions it rejects to Invalid under their row
+func (r *Result) Check(check func(*storage.WorkSession) error) {
+	kept, rows := r.Sessions[:0], r.rows[:0]
+	for i := range r.Sessions {
+		if err := check(&r.Sessions[i]); err != nil {
+			r.Invalid = append(r.Invalid, RowError{Row: r.rows[i], Err: err})
+			continue
+		}
+		kept, rows = append(kept, r.Sessions[i]), append(rows, r.rows[i])
+	}
+	r.Sessions, r.rows = kept, rows
+	sort.SliceStable(r.Invalid, func(i, j int) bool { return r.Invalid[i].Row < r.Invalid[j].Row })
+}
+
+// RowError is a row skipped as invalid; Row is its line in a CSV file or
+// its position in a JSON export's sessions
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+// ParseCSV reads sessions in loc from CSV with the export's columns: Date
+// (YYYY-MM-DD), Start and End (HH:MM), Break (min), Hours, Note, Project
+// and Tags (";"-separated). Columns are found by their header, in any order;
+// only Date and Start are required. A missing End is derived from Hours and
+// the break, and an End before Start is taken as the next day. Rows starting
+// with # are comments. Rows that don't make a completed session are returned
+// in Invalid; only an unreadable file or a missing header is an error.
+func ParseCSV(r io.Reader, loc *time.Location) (*Result, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	result := &Result{}
+	var columns map[string]int
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) == 0 || strings.HasPrefix(record[0], "#") || blank(record) {
+			continue
+		}
+		if columns == nil {
+			if columns = csvColumns(record); columns == nil {
+				return nil, fmt.Errorf("no header row with Date and Start columns")
+			}
+			continue
+		}
+
+		row, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		session, err := csvSession(field, loc)
+		if err != nil {
+			result.Invalid = append(result.Invalid, RowError{Row: row, Err: err})
+			continue
+		}
+		result.add(row, session)
+	}
+	if columns == nil {
+		return nil, fmt.Errorf("no header row with Date and Start columns")
+	}
+	return result, nil
+}
+
+// csvColumns maps the lowercased header names, without units such as
+// "(min)", to their index; nil when record is not a header
+func csvColumns(record []string) map[string]int {
+	columns := make(map[string]int)
+	for i, name := range record {
+		if paren := strings.Index(name, "("); paren >= 0 {
+			name = name[:paren]
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	_, date := columns["date"]
+	_, start := columns["start"]
+	if !date || !start {
+		return nil
+	}
+	return columns
+}
+
+func csvSession(field func(string) string, loc *time.Location) (storage.WorkSession, error) {
+	breakMinutes := 0
+	if s := field("break"); s != "" {
+		var err error
+		if breakMinutes, err = strconv.Atoi(s); err != nil {
+			return storage.WorkSession{}, fmt.Errorf("invalid break: %s", s)
+		}
+	}
+	var hours float64
+	if s := field("hours"); s != "" && field("end") == "" {
+		var err error
+		if hours, err = strconv.ParseFloat(s, 64); err != nil {
+			return storage.WorkSession{}, fmt.Errorf("invalid hours: %s", s)
+		}
+	}
+
+	session, err := newSession(field("date"), field("start"), field("end"), hours, breakMinutes, loc)
+	if err != nil {
+		return session, err
+	}
+	session.Note = field("note")
+	session.Project = field("project")
+	for _, tag := range strings.Split(field("tags"), ";") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			session.Tags = append(session.Tags, tag)
+		}
+	}
+	return session, nil
+}
+
+// newSession builds a completed session on date from start to end, or to
+// start plus hours and the break when end is empty
+func newSession(date, start, end string, hours float64, breakMinutes int, loc *time.Location) (storage.WorkSession, error) {
+	day, err := time.ParseInLocation("2006-01-02", date, loc)
+	if err != nil {
+		return storage.WorkSession{}, fmt.Errorf("invalid date: %q (use YYYY-MM-DD)", date)
+	}
+	startTime, err := clockOn(day, start)
+	if err != nil {
+		return storage.WorkSession{}, err
+	}
+
+	var endTime time.Time
+	switch {
+	case end != "":
+		if endTime, err = clockOn(day, end); err != nil {
+			return storage.WorkSession{}, err
+		}
+		if !endTime.After(startTime) {
+			endTime = endTime.AddDate(0, 0, 1)
+		}
+	case hours > 0:
+		endTime = startTime.Add(time.Duration(hours*float64(time.Hour)) + time.Duration(breakMinutes)*time.Minute)
+	default:
+		return storage.WorkSession{}, errors.New("no end time or hours")
+	}
+	if err := tracker.ValidateBreak(startTime, endTime, breakMinutes); err != nil {
+		return storage.WorkSession{}, err
+	}
+
+	return storage.WorkSession{
+		Date:         day,
+		StartTime:    startTime,
+		EndTime:      &endTime,
+		BreakMinutes: breakMinutes,
+	}, nil
+}
+
+// clockOn places an HH:MM (or HH:MM:SS) time on day
+func clockOn(day time.Time, s string) (time.Time, error) {
+	for _, format := range []string{"15:04", "15:04:05"} {
+		if clock, err := time.Parse(format, s); err == nil {
+			return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, day.Location()), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time: %q (use HH:MM)", s)
+}
+
+func blank(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Import inserts sessions into db with new IDs and returns how many it
+// added. Sessions whose date and start time are already in the database, or
+// earlier in sessions, are skipped and counted, like an archive restore.
+func Import(db *storage.Database, sessions []storage.WorkSession) (imported, skipped int, err error) {
+	if len(sessions) == 0 {
+		return 0, 0, nil
+	}
+	first, last := dateRange(sessions)
+	existing, err := db.GetSessionsInRange(first, last)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	add, skipped := newSessions(db.Location(), existing, sessions)
+	for i := range add {
+		if err := db.InsertSession(&add[i]); err != nil {
+			return imported, skipped, fmt.Errorf("failed to import session of %s: %w", startKey(add[i], db.Location()), err)
+		}
+		imported++
+	}
+	return imported, skipped, nil
+}
+
+// newSessions returns the sessions, without IDs, that start at a different
+// minute than any of existing and any earlier one, and how many were skipped
+func newSessions(loc *time.Location, existing, sessions []storage.WorkSession) ([]storage.WorkSession, int) {
+	present := make(map[string]bool, len(existing))
+	for _, s := range existing {
+		present[startKey(s, loc)] = true
+	}
+	var add []storage.WorkSession
+	skipped := 0
+	for _, session := range sessions {
+		key := startKey(session, loc)
+		if present[key] {
+			skipped++
+			continue
+		}
+		session.ID = ""
+		add = append(add, session)
+		present[key] = true
+	}
+	return add, skipped
+}
+
+// startKey is the minute session starts at in loc
+func startKey(session storage.WorkSession, loc *time.Location) string {
+	return session.StartTime.In(loc).Format("2006-01-02 15:04")
+}
+
+// dateRange returns the earliest and latest Date of sessions, which must not
+// be empty
+func dateRange(sessions []storage.WorkSession) (first, last time.Time) {
+	first, last = sessions[0].Date, sessions[0].Date
+	for _, s := range sessions {
+		if s.Date.Before(first) {
+			first = s.Date
+		}
+		if s.Date.After(last) {
+			last = s.Date
+		}
+	}
+	return first, last
+}
@@ -0,0 +1,225 @@
+package importer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kairos/internal/storage"
+)
+
+func TestParseCSV(t *testing.T) {
+	// As written by kairos export --format csv, plus rows a spreadsheet might have
+	input := `# Kairos Work Report
+# Author: Jane
+Date,Start,End,Break (min),Hours,Note,Project,Tags
+2024-01-15,09:00,17:30,30,8.00,"Deploy, then review",acme,ops;release
+2024-01-16,22:00,06:00,0,8.00,Night shift,,
+2024-01-17,08:00,,60,7.5,From hours,,
+2024-01-18,09:00,,0,0.00,Still running,,
+2024-13-01,09:00,17:00,30,,Bad date,,
+2024-01-19,9am,17:00,30,,Bad time,,
+2024-01-20,09:00,10:00,90,,Long break,,
+,,,,,,,
+`
+	result, err := ParseCSV(strings.NewReader(input), time.UTC)
+	if err != nil {
+		t.Fatalf("ParseCSV: %v", err)
+	}
+
+	if len(result.Sessions) != 3 {
+		t.Fatalf("got %d sessions, want 3: %+v", len(result.Sessions), result.Sessions)
+	}
+	first := result.Sessions[0]
+	if first.StartTime != time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC) || first.EndTime.Format("15:04") != "17:30" ||
+		first.BreakMinutes != 30 || first.Note != "Deploy, then review" || first.Project != "acme" ||
+		strings.Join(first.Tags, ",") != "ops,release" {
+		t.Errorf("first session = %+v", first)
+	}
+	if night := result.Sessions[1]; night.EndTime.Format("2006-01-02 15:04") != "2024-01-17 06:00" {
+		t.Errorf("night shift ends %s, want the next morning", night.EndTime)
+	}
+	if fromHours := result.Sessions[2]; fromHours.EndTime.Format("15:04") != "16:30" {
+		t.Errorf("session from hours ends %s, want 16:30 (7.5h + 60min break)", fromHours.EndTime.Format("15:04"))
+	}
+
+	var rows []int
+	for _, invalid := range result.Invalid {
+		rows = append(rows, invalid.Row)
+	}
+	if len(rows) != 4 || rows[0] != 7 || rows[3] != 10 {
+		t.Errorf("invalid rows = %v (%v), want 7-10", rows, result.Invalid)
+	}
+
+	if _, err := ParseCSV(strings.NewReader("when,what\n2024-01-15,work\n"), time.UTC); err == nil {
+		t.Error("expected an error without Date and Start columns")
+	}
+}
+
+func TestImportSkipsDuplicates(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	defer db.Close()
+
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	end := start.Add(8 * time.Hour)
+	if err := db.InsertSession(&storage.WorkSession{Date: start, StartTime: start, EndTime: &end}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+
+	result, err := ParseCSV(strings.NewReader(`Date,Start,End
+2024-01-15,09:00,17:00
+2024-01-16,09:00,17:00
+2024-01-16,09:00,12:00
+`), time.UTC)
+	if err != nil {
+		t.Fatalf("ParseCSV: %v", err)
+	}
+	imported, skipped, err := Import(db, result.Sessions)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if imported != 1 || skipped != 2 {
+		t.Errorf("imported %d, skipped %d; want 1 and 2", imported, skipped)
+	}
+
+	// Importing again adds nothing
+	if imported, _, _ := Import(db, result.Sessions); imported != 0 {
+		t.Errorf("second import added %d sessions", imported)
+	}
+	sessions, _ := db.GetSessionsInRange(start, start.AddDate(0, 0, 1))
+	if len(sessions) != 2 {
+		t.Errorf("database has %d sessions, want 2", len(sessions))
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	// As written by kairos export json
+	input := `{
+  "export_date": "2024-01-20",
+  "sessions": [
+    {"date": "2024-01-15", "start_time": "09:00", "end_time": "17:30", "break_minutes": 30,
+     "hours_worked": 8, "note": "Deploy", "project": "acme", "tags": ["ops"]},
+    {"date": "2024-01-16", "start_time": "09:00", "break_minutes": 0, "hours_worked": 0},
+    {"date": "2024-01-17", "start_time": 900}
+  ],
+  "title": "Kairos Work Report",
+  "total_sessions": 3
+}`
+	result, err := ParseJSON(strings.NewReader(input), time.UTC)
+	if err != nil {
+		t.Fatalf("ParseJSON: %v", err)
+	}
+	if len(result.Sessions) != 1 || len(result.Invalid) != 2 {
+		t.Fatalf("got %d sessions and %d invalid, want 1 and 2: %v", len(result.Sessions), len(result.Invalid), result.Invalid)
+	}
+	s := result.Sessions[0]
+	if s.StartTime != time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC) || s.EndTime.Format("15:04") != "17:30" ||
+		s.BreakMinutes != 30 || s.Note != "Deploy" || s.Project != "acme" || len(s.Tags) != 1 {
+		t.Errorf("session = %+v", s)
+	}
+	if result.Invalid[0].Row != 2 || result.Invalid[1].Row != 3 {
+		t.Errorf("invalid rows = %v, want 2 and 3", result.Invalid)
+	}
+
+	// tz places a session in the zone it was recorded in; a break must be
+	// shorter than the session, as on clock-out
+	input = `{"sessions": [
+  {"date": "2024-07-01", "start_time": "09:00", "end_time": "17:00", "break_minutes": 30, "tz": "Europe/Vienna"},
+  {"date": "2024-07-02", "start_time": "09:00", "end_time": "10:00", "break_minutes": 60},
+  {"date": "2024-07-03", "start_time": "09:00", "end_time": "17:00", "tz": "Mars/Olympus"},
+  {"date": "2024-07-04", "start_time": "09:00", "end_time": "17:00", "note": "far too long a note"}]}`
+	result, err = ParseJSON(strings.NewReader(input), time.UTC)
+	if err != nil {
+		t.Fatalf("ParseJSON: %v", err)
+	}
+	vienna, _ := time.LoadLocation("Europe/Vienna")
+	if len(result.Sessions) != 2 || !result.Sessions[0].StartTime.Equal(time.Date(2024, 7, 1, 9, 0, 0, 0, vienna)) ||
+		result.Sessions[0].TimeZone != "Europe/Vienna" {
+		t.Fatalf("sessions = %+v, want the first at 09:00 Vienna time", result.Sessions)
+	}
+	result.Check(func(s *storage.WorkSession) error {
+		if len(s.Note) > 10 {
+			return fmt.Errorf("note too long")
+		}
+		return nil
+	})
+	var rows []string
+	for _, invalid := range result.Invalid {
+		rows = append(rows, fmt.Sprint(invalid.Row))
+	}
+	if len(result.Sessions) != 1 || strings.Join(rows, " ") != "2 3 4" {
+		t.Errorf("after Check: %d sessions, invalid rows %v; want 1 and rows 2 3 4", len(result.Sessions), rows)
+	}
+
+	for name, input := range map[string]string{
+		"array":       `[{"date": "2024-01-15"}]`,
+		"no sessions": `{"export_date": "2024-01-20"}`,
+		"truncated":   `{"total_sessions": 2, "sessions": [{"date": "2024-01-15", "start_time": "09:00", "end_time": "17:00"}]}`,
+		"not json":    `Date,Start,End`,
+	} {
+		if _, err := ParseJSON(strings.NewReader(input), time.UTC); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestReplaceRangeMakesReimportIdempotent(t *testing.T) {
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	defer db.Close()
+
+	insert := func(day, hour int, open bool) {
+		start := time.Date(2024, 1, day, hour, 0, 0, 0, time.UTC)
+		s := &storage.WorkSession{Date: start, StartTime: start}
+		if !open {
+			end := start.Add(time.Hour)
+			s.EndTime = &end
+		}
+		if err := db.InsertSession(s); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+	}
+	insert(14, 9, false) // before the range, kept
+	insert(15, 8, false) // replaced
+	insert(16, 7, true)  // running, kept
+
+	input := `{"export_date": "2024-01-20", "total_sessions": 2, "sessions": [
+  {"date": "2024-01-15", "start_time": "09:00", "end_time": "17:00", "break_minutes": 30},
+  {"date": "2024-01-16", "start_time": "09:00", "end_time": "17:00", "break_minutes": 30}]}`
+	for i := 0; i < 2; i++ {
+		result, err := ParseJSON(strings.NewReader(input), time.UTC)
+		if err != nil {
+			t.Fatalf("ParseJSON: %v", err)
+		}
+		if _, imported, _, err := ReplaceRange(db, result.Sessions); err != nil || imported != 2 {
+			t.Fatalf("import %d: imported %d, %v; want 2", i+1, imported, err)
+		}
+	}
+
+	sessions, _ := db.GetSessionsInRange(time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC))
+	var starts []string
+	for _, s := range sessions {
+		starts = append(starts, s.StartTime.Format("02 15:04"))
+	}
+	if got := strings.Join(starts, ", "); got != "14 09:00, 15 09:00, 16 07:00, 16 09:00" {
+		t.Errorf("sessions = %s", got)
+	}
+
+	// Each import is one undo entry, reverted as a whole
+	if entries, _ := db.UndoLog(); len(entries) != 2 {
+		t.Fatalf("undo log has %d entries, want one per import", len(entries))
+	}
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if sessions, _ := db.GetSessionsInRange(time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)); len(sessions) != 4 {
+		t.Errorf("after undo %d sessions, want the first import's 4", len(sessions))
+	}
+}
@@ -0,0 +1,129 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kairos/internal/storage"
+)
+
+// exportEnvelope is the object kairos export json writes
+type exportEnvelope struct {
+	ExportDate    string            `json:"export_date"`
+	TotalSessions *int              `json:"total_sessions"`
+	Sessions      []json.RawMessage `json:"sessions"`
+}
+
+// exportedSession is one entry of the envelope's sessions
+type exportedSession struct {
+	Date         string   `json:"date"`
+	StartTime    string   `json:"start_time"`
+	EndTime      string   `json:"end_time"`
+	BreakMinutes int      `json:"break_minutes"`
+	HoursWorked  float64  `json:"hours_worked"`
+	Note         string   `json:"note"`
+	Project      string   `json:"project"`
+	Tags         []string `json:"tags"`
+	TimeZone     string   `json:"tz"`
+}
+
+// ParseJSON reads sessions from the {export_date, total_sessions, sessions}
+// object kairos export json writes, each in the zone of its tz field, or in
+// loc when it has none. Other shapes, or a
+// total_sessions that doesn't match the sessions listed, are an error.
+// Sessions that don't make a completed session, like ones still open when
+// exported, are returned in Invalid with their position in the list as Row.
+func ParseJSON(r io.Reader, loc *time.Location) (*Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, fmt.Errorf("unrecognized JSON: expected the object kairos export json writes, with export_date, total_sessions and sessions")
+	}
+
+	var envelope exportEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("unrecognized JSON: %w", err)
+	}
+	if envelope.Sessions == nil {
+		return nil, fmt.Errorf("unrecognized JSON: no sessions list (expected the object kairos export json writes)")
+	}
+	if envelope.ExportDate != "" {
+		if _, err := time.Parse("2006-01-02", envelope.ExportDate); err != nil {
+			return nil, fmt.Errorf("unrecognized JSON: invalid export_date %q", envelope.ExportDate)
+		}
+	}
+	if envelope.TotalSessions != nil && *envelope.TotalSessions != len(envelope.Sessions) {
+		return nil, fmt.Errorf("total_sessions is %d but %d sessions are listed; the file may be incomplete",
+			*envelope.TotalSessions, len(envelope.Sessions))
+	}
+
+	result := &Result{}
+	for i, raw := range envelope.Sessions {
+		var exported exportedSession
+		if err := json.Unmarshal(raw, &exported); err != nil {
+			result.Invalid = append(result.Invalid, RowError{Row: i + 1, Err: err})
+			continue
+		}
+		sessionLoc := loc
+		if exported.TimeZone != "" {
+			if sessionLoc = storage.LoadZone(exported.TimeZone); sessionLoc == nil {
+				result.Invalid = append(result.Invalid, RowError{Row: i + 1, Err: fmt.Errorf("unknown tz: %q", exported.TimeZone)})
+				continue
+			}
+		}
+		session, err := newSession(exported.Date, exported.StartTime, exported.EndTime,
+			exported.HoursWorked, exported.BreakMinutes, sessionLoc)
+		if err != nil {
+			result.Invalid = append(result.Invalid, RowError{Row: i + 1, Err: err})
+			continue
+		}
+		session.Note = exported.Note
+		session.Project = exported.Project
+		session.Tags = exported.Tags
+		session.TimeZone = exported.TimeZone
+		result.add(i+1, session)
+	}
+	return result, nil
+}
+
+// ReplaceRange imports sessions in place of the completed sessions in db
+// started on the days from the first to the last of them, so importing the
+// same file again replaces the range instead of adding to it. A running
+// session is kept, and a session starting at the same minute as it is
+// skipped. Everything happens in one transaction that a single kairos undo
+// reverts; if an insert fails, nothing is deleted.
+func ReplaceRange(db *storage.Database, sessions []storage.WorkSession) (deleted, imported, skipped int, err error) {
+	if len(sessions) == 0 {
+		return 0, 0, 0, nil
+	}
+	first, last := dateRange(sessions)
+	existing, err := db.GetSessionsInRange(first, last)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	var remove []string
+	removed := make(map[string]bool)
+	for _, s := range storage.StartedBetween(existing, first, last) {
+		if s.EndTime != nil {
+			remove = append(remove, s.ID)
+			removed[s.ID] = true
+		}
+	}
+	var kept []storage.WorkSession
+	for _, s := range existing {
+		if !removed[s.ID] {
+			kept = append(kept, s)
+		}
+	}
+	add, skipped := newSessions(db.Location(), kept, sessions)
+	if err := db.ReplaceSessions(remove, add); err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to replace the range: %w", err)
+	}
+	return len(remove), len(add), skipped, nil
+}
@@ -0,0 +1,77 @@
+// Package logger writes leveled diagnostics to stderr, keeping stdout for
+// command results.
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// EnvVar overrides the log level when no --log-level flag is given
+const EnvVar = "KAIROS_LOG"
+
+// DefaultLevel is used when neither the flag nor the environment sets a level
+const DefaultLevel = "info"
+
+var (
+	level = new(slog.LevelVar)
+	log   = newLogger(os.Stderr)
+)
+
+func newLogger(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			// Diagnostics are read by people at a terminal; drop the timestamp
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+// ParseLevel maps debug, info, warn (or warning) and error to a slog level
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("invalid log level: %s (use debug, info, warn or error)", s)
+}
+
+// SetLevel sets the minimum level from a name, falling back to KAIROS_LOG and
+// then DefaultLevel when name is empty
+func SetLevel(name string) error {
+	if name == "" {
+		name = os.Getenv(EnvVar)
+	}
+	if name == "" {
+		name = DefaultLevel
+	}
+	l, err := ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	level.Set(l)
+	return nil
+}
+
+// SetOutput redirects diagnostics, mainly for tests
+func SetOutput(w io.Writer) {
+	log = newLogger(w)
+}
+
+func Debug(msg string, args ...any) { log.Debug(msg, args...) }
+func Info(msg string, args ...any)  { log.Info(msg, args...) }
+func Warn(msg string, args ...any)  { log.Warn(msg, args...) }
+func Error(msg string, args ...any) { log.Error(msg, args...) }
@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSetLevelFiltersAndEnv(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+
+	t.Setenv(EnvVar, "warn")
+	if err := SetLevel(""); err != nil {
+		t.Fatalf("SetLevel: %v", err)
+	}
+	Info("hidden")
+	Warn("shown", "port", 8765)
+
+	out := buf.String()
+	if strings.Contains(out, "hidden") {
+		t.Errorf("info logged at warn level:\n%s", out)
+	}
+	if !strings.Contains(out, "level=WARN msg=shown port=8765") {
+		t.Errorf("missing warn line:\n%s", out)
+	}
+
+	buf.Reset()
+	if err := SetLevel("debug"); err != nil {
+		t.Fatalf("SetLevel: %v", err)
+	}
+	Debug("details")
+	if !strings.Contains(buf.String(), "msg=details") {
+		t.Errorf("flag should override env:\n%s", buf.String())
+	}
+
+	if err := SetLevel("loud"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+}
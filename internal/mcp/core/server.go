@@ -2,25 +2,34 @@ package core
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
+
+	"github.com/kairos/internal/logger"
 )
 
 // Server represents a reusable MCP server
 type Server struct {
-	Port       int
-	httpServer *http.Server
+	Port         int
+	httpServer   *http.Server
 	ToolRegistry *ToolRegistry
-	running    bool
-	mu         sync.Mutex
-	onShutdown []func() // Callbacks on shutdown
-	Hooks      map[string][]func(ctx context.Context, args map[string]interface{}) (interface{}, error)
+	running      bool
+	mu           sync.Mutex
+	onShutdown   []func()      // Callbacks on shutdown
+	sem          chan struct{} // Limits concurrent tool calls; nil means unlimited
+	Hooks        map[string][]func(ctx context.Context, args map[string]interface{}) (interface{}, error)
+	sseMu        sync.Mutex
+	sseSessions  map[string]*sseSession // Open /sse streams by session ID
+	heartbeat    time.Duration          // Keep-alive interval for /sse streams
+	token        string                 // Bearer token required on /mcp and /sse; empty means open
 }
 
 // ToolRegistry holds all available MCP tools
@@ -44,6 +53,7 @@ func NewServer(port int) *Server {
 		Port:         port,
 		ToolRegistry: NewToolRegistry(),
 		Hooks:        make(map[string][]func(ctx context.Context, args map[string]interface{}) (interface{}, error)),
+		heartbeat:    SSEHeartbeat,
 	}
 }
 
@@ -83,6 +93,22 @@ func (s *Server) AddHandler(name, description string, parameters map[string]inte
 	s.Hooks[name] = append(s.Hooks[name], handler)
 }
 
+// SetMaxConcurrency limits how many tool calls run at once. Calls beyond the
+// limit queue until a slot frees or their context ends. n <= 0 removes the limit.
+func (s *Server) SetMaxConcurrency(n int) {
+	if n <= 0 {
+		s.sem = nil
+		return
+	}
+	s.sem = make(chan struct{}, n)
+}
+
+// SetToken requires "Authorization: Bearer <token>" on the /mcp and SSE
+// endpoints. An empty token leaves them open; /health never needs it.
+func (s *Server) SetToken(token string) {
+	s.token = token
+}
+
 // OnShutdown adds a callback to run on shutdown
 func (s *Server) OnShutdown(callback func()) {
 	s.onShutdown = append(s.onShutdown, callback)
@@ -98,19 +124,15 @@ func (s *Server) Start(ctx context.Context) error {
 	s.running = true
 	s.mu.Unlock()
 
-	mux := http.NewServeMux()
-	mux.HandleFunc("/mcp", s.handleMCP)
-	mux.HandleFunc("/health", s.healthHandler)
-
 	s.httpServer = &http.Server{
 		Addr:    fmt.Sprintf(":%d", s.Port),
-		Handler: mux,
+		Handler: s.routes(),
 	}
 
 	go func() {
-		fmt.Printf("MCP Server running on http://localhost:%d/mcp\n", s.Port)
+		logger.Info("MCP server listening", "url", fmt.Sprintf("http://localhost:%d/mcp", s.Port), "sse", fmt.Sprintf("http://localhost:%d/sse", s.Port))
 		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			fmt.Printf("MCP server error: %v\n", err)
+			logger.Error("MCP server stopped", "err", err)
 		}
 	}()
 
@@ -119,6 +141,31 @@ func (s *Server) Start(ctx context.Context) error {
 	return s.Stop()
 }
 
+// routes serves POST /mcp, the SSE transport (/sse and /message) and /health
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mcp", s.requireToken(s.handleMCP))
+	mux.HandleFunc("/sse", s.requireToken(s.handleSSE))
+	mux.HandleFunc("/message", s.requireToken(s.handleSSEMessage))
+	mux.HandleFunc("/health", s.healthHandler)
+	return mux
+}
+
+// requireToken rejects requests without the server's bearer token with 401
+func (s *Server) requireToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" {
+			given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(s.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="kairos-mcp"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
 // Stop gracefully shuts down the server
 func (s *Server) Stop() error {
 	s.mu.Lock()
@@ -138,6 +185,7 @@ func (s *Server) Stop() error {
 		cb()
 	}
 
+	s.closeSSE()
 	return s.httpServer.Shutdown(ctx)
 }
 
@@ -153,12 +201,22 @@ func (s *Server) handleMCP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := s.handleRequest(r.Context(), req)
+	response := s.HandleRequest(r.Context(), req)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-func (s *Server) handleRequest(ctx context.Context, req MCPRequest) MCPResponse {
+// HandleRequest dispatches an MCP request exactly as the HTTP endpoint does.
+// The response carries the request's jsonrpc and id, so a client can match
+// it to its request when several are in flight (as over SSE).
+func (s *Server) HandleRequest(ctx context.Context, req MCPRequest) MCPResponse {
+	response := s.dispatch(ctx, req)
+	response.JSONRPC = req.JSONRPC
+	response.ID = req.ID
+	return response
+}
+
+func (s *Server) dispatch(ctx context.Context, req MCPRequest) MCPResponse {
 	switch req.Method {
 	case "initialize":
 		return MCPResponse{
@@ -192,31 +250,16 @@ func (s *Server) handleRequest(ctx context.Context, req MCPRequest) MCPResponse
 			toolArgs = make(map[string]interface{})
 		}
 
-		_, exists := s.ToolRegistry.Get(toolName)
-		if !exists {
-			return MCPResponse{Error: fmt.Sprintf("unknown tool: %s", toolName)}
-		}
-
-		// Execute all handlers for this tool
-		handlers := s.Hooks[toolName]
-		if len(handlers) == 0 {
-			return MCPResponse{Error: fmt.Sprintf("no handler for tool: %s", toolName)}
-		}
-
-		var finalResult interface{}
-		for _, handler := range handlers {
-			result, err := handler(ctx, toolArgs)
-			if err != nil {
-				return MCPResponse{Error: err.Error()}
-			}
-			finalResult = result
+		finalResult, err := s.CallTool(ctx, toolName, toolArgs)
+		if err != nil {
+			return MCPResponse{Error: err.Error()}
 		}
 
 		return MCPResponse{
 			Result: map[string]interface{}{
 				"content": []map[string]interface{}{
 					{
-						"type":  "text",
+						"type": "text",
 						"text": finalResult,
 					},
 				},
@@ -228,6 +271,38 @@ func (s *Server) handleRequest(ctx context.Context, req MCPRequest) MCPResponse
 	}
 }
 
+// CallTool runs every handler registered for a tool in-process and returns
+// the last handler's result
+func (s *Server) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	if _, exists := s.ToolRegistry.Get(name); !exists {
+		return nil, fmt.Errorf("unknown tool: %s", name)
+	}
+
+	handlers := s.Hooks[name]
+	if len(handlers) == 0 {
+		return nil, fmt.Errorf("no handler for tool: %s", name)
+	}
+
+	if s.sem != nil {
+		select {
+		case s.sem <- struct{}{}:
+			defer func() { <-s.sem }()
+		case <-ctx.Done():
+			return nil, fmt.Errorf("server busy: %w", ctx.Err())
+		}
+	}
+
+	var finalResult interface{}
+	for _, handler := range handlers {
+		result, err := handler(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		finalResult = result
+	}
+	return finalResult, nil
+}
+
 func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
@@ -238,14 +313,18 @@ func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 
 // MCPRequest represents an MCP request
 type MCPRequest struct {
-	Method string                 `json:"method"`
-	Params map[string]interface{} `json:"params"`
+	JSONRPC string                 `json:"jsonrpc,omitempty"`
+	ID      json.RawMessage        `json:"id,omitempty"`
+	Method  string                 `json:"method"`
+	Params  map[string]interface{} `json:"params"`
 }
 
 // MCPResponse represents an MCP response
 type MCPResponse struct {
-	Result interface{} `json:"result,omitempty"`
-	Error  string      `json:"error,omitempty"`
+	JSONRPC string          `json:"jsonrpc,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   string          `json:"error,omitempty"`
 }
 
 // RunServer is a convenience function to run the server
@@ -269,7 +348,7 @@ func RunServer(port int) error {
 // ToolParameters returns standard parameters for a tool
 func ToolParameters(fields map[string]map[string]interface{}) map[string]interface{} {
 	return map[string]interface{}{
-		"type": "object",
+		"type":       "object",
 		"properties": fields,
 	}
 }
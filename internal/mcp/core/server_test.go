@@ -0,0 +1,217 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMaxConcurrency(t *testing.T) {
+	s := NewServer(0)
+	s.SetMaxConcurrency(2)
+
+	var running, peak int32
+	release := make(chan struct{})
+	s.AddHandler("slow", "blocks until released", nil, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&running, -1)
+		return "done", nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.CallTool(context.Background(), "slow", nil); err != nil {
+				t.Errorf("CallTool: %v", err)
+			}
+		}()
+	}
+
+	// With two slots held, a call with a short deadline must give up busy
+	time.Sleep(50 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := s.CallTool(ctx, "slow", nil); err == nil {
+		t.Error("expected busy error while at the limit")
+	}
+
+	close(release)
+	wg.Wait()
+
+	if peak != 2 {
+		t.Errorf("peak concurrency = %d, want 2", peak)
+	}
+}
+
+func TestSSETransport(t *testing.T) {
+	s := NewServer(0)
+	s.heartbeat = 20 * time.Millisecond
+	s.AddHandler("echo", "returns its message", nil, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return args["msg"], nil
+	})
+	ts := httptest.NewServer(s.routes())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/sse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	// Collect the stream's lines in the background
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	next := func(prefix string) string {
+		t.Helper()
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					t.Fatalf("stream closed waiting for %q", prefix)
+				}
+				if strings.HasPrefix(line, prefix) {
+					return strings.TrimPrefix(line, prefix)
+				}
+			case <-timeout:
+				t.Fatalf("timed out waiting for %q", prefix)
+			}
+		}
+	}
+
+	next("event: endpoint")
+	endpoint := next("data: ")
+	if !strings.HasPrefix(endpoint, "/message?sessionId=") {
+		t.Fatalf("endpoint = %q", endpoint)
+	}
+
+	post := func(body string) {
+		t.Helper()
+		resp, err := http.Post(ts.URL+endpoint, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusAccepted {
+			t.Fatalf("POST status = %d, want 202", resp.StatusCode)
+		}
+	}
+
+	post(`{"method":"tools/call","params":{"name":"echo","arguments":{"msg":"hello"}}}`)
+	next("event: message")
+	if data := next("data: "); !strings.Contains(data, `"text":"hello"`) || strings.Contains(data, `"id"`) {
+		t.Errorf("message data = %s", data)
+	}
+
+	// Responses arrive in any order; each carries its request's id
+	post(`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"echo","arguments":{"msg":"seven"}}}`)
+	post(`{"jsonrpc":"2.0","id":"b","method":"tools/call","params":{"name":"echo","arguments":{"msg":"bee"}}}`)
+	for i := 0; i < 2; i++ {
+		next("event: message")
+		data := next("data: ")
+		if !strings.HasPrefix(data, `{"jsonrpc":"2.0","id":`) {
+			t.Errorf("message data = %s, want jsonrpc and id first", data)
+		}
+		if strings.Contains(data, `"id":7`) != strings.Contains(data, `"text":"seven"`) ||
+			strings.Contains(data, `"id":"b"`) != strings.Contains(data, `"text":"bee"`) {
+			t.Errorf("message data = %s, want the id of the request it answers", data)
+		}
+	}
+
+	next(": ping")
+
+	unknown, err := http.Post(ts.URL+"/message?sessionId=nope", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown.Body.Close()
+	if unknown.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown session status = %d, want 404", unknown.StatusCode)
+	}
+
+	// Shutting down must end the stream rather than leave it open
+	s.closeSSE()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-lines:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("stream still open after closeSSE")
+		}
+	}
+}
+
+func TestTokenAuth(t *testing.T) {
+	s := NewServer(0)
+	s.SetToken("s3cret")
+	ts := httptest.NewServer(s.routes())
+	defer ts.Close()
+
+	do := func(method, path, auth string) int {
+		t.Helper()
+		req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(`{"method":"tools/list"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	tests := []struct {
+		method, path, auth string
+		want               int
+	}{
+		{"POST", "/mcp", "", http.StatusUnauthorized},
+		{"POST", "/mcp", "Bearer wrong", http.StatusUnauthorized},
+		{"POST", "/mcp", "s3cret", http.StatusUnauthorized},
+		{"POST", "/mcp", "Bearer s3cret", http.StatusOK},
+		{"GET", "/sse", "", http.StatusUnauthorized},
+		{"POST", "/message?sessionId=x", "", http.StatusUnauthorized},
+		{"GET", "/health", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if got := do(tt.method, tt.path, tt.auth); got != tt.want {
+			t.Errorf("%s %s with %q = %d, want %d", tt.method, tt.path, tt.auth, got, tt.want)
+		}
+	}
+
+	// Without a token everything stays open
+	s.SetToken("")
+	if got := do("POST", "/mcp", ""); got != http.StatusOK {
+		t.Errorf("POST /mcp without token configured = %d, want 200", got)
+	}
+}
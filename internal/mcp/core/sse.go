@@ -0,0 +1,123 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SSEHeartbeat is how often an open SSE stream gets a keep-alive comment, so
+// proxies and clients don't drop an idle connection
+const SSEHeartbeat = 15 * time.Second
+
+// sseSession is one client connected to /sse. Responses to the requests it
+// posts to /message are queued on events; done is closed when the server
+// stops.
+type sseSession struct {
+	events chan []byte
+	done   chan struct{}
+}
+
+// handleSSE opens an event stream. The first event tells the client where to
+// post its requests; each response then arrives as a "message" event.
+func (s *Server) handleSSE(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	id := uuid.New().String()
+	session := &sseSession{
+		events: make(chan []byte, 16),
+		done:   make(chan struct{}),
+	}
+	s.sseMu.Lock()
+	if s.sseSessions == nil {
+		s.sseSessions = make(map[string]*sseSession)
+	}
+	s.sseSessions[id] = session
+	s.sseMu.Unlock()
+	defer func() {
+		s.sseMu.Lock()
+		delete(s.sseSessions, id)
+		s.sseMu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	fmt.Fprintf(w, "event: endpoint\ndata: /message?sessionId=%s\n\n", id)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(s.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-session.done:
+			return
+		case data := <-session.events:
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// handleSSEMessage dispatches a request posted by an SSE client and sends the
+// response down that client's stream
+func (s *Server) handleSSEMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.sseMu.Lock()
+	session, ok := s.sseSessions[r.URL.Query().Get("sessionId")]
+	s.sseMu.Unlock()
+	if !ok {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+
+	var req MCPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	data, err := json.Marshal(s.HandleRequest(r.Context(), req))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	select {
+	case session.events <- data:
+		w.WriteHeader(http.StatusAccepted)
+	case <-session.done:
+		http.Error(w, "session closed", http.StatusGone)
+	case <-r.Context().Done():
+	}
+}
+
+// closeSSE ends every open event stream. http.Server.Shutdown waits for
+// handlers to return, and a stream's handler otherwise never does.
+func (s *Server) closeSSE() {
+	s.sseMu.Lock()
+	defer s.sseMu.Unlock()
+	for id, session := range s.sseSessions {
+		close(session.done)
+		delete(s.sseSessions, id)
+	}
+}
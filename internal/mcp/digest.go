@@ -0,0 +1,27 @@
+package mcp
+
+import (
+	"time"
+
+	"github.com/kairos/internal/ai"
+	"github.com/kairos/internal/storage"
+)
+
+// DigestCategory is the memory category of weekly digests, so the persist
+// tool can list them with category=digest
+const DigestCategory = "digest"
+
+// DigestKey is the memory key of the digest for the week starting weekStart
+func DigestKey(weekStart time.Time) string {
+	return "digest-" + weekStart.Format("2006-01-02")
+}
+
+// StoreDigest saves a weekly digest as a memory, replacing an earlier digest
+// of the same week, and returns its key
+func StoreDigest(db *storage.Database, d *ai.Digest) (string, error) {
+	key := DigestKey(d.WeekStart)
+	if _, err := StoreMemory(db, key, d.Text, DigestCategory, []string{"weekly"}); err != nil {
+		return "", err
+	}
+	return key, nil
+}
@@ -0,0 +1,65 @@
+package mcp
+
+import "strings"
+
+// toolIntent maps question keywords to a local tool and its default arguments
+type toolIntent struct {
+	tool     string
+	keywords []string
+	args     func(question string) map[string]interface{}
+}
+
+var toolIntents = []toolIntent{
+	{
+		tool:     "evolve",
+		keywords: []string{"evolve", "improve", "habit", "suggestion"},
+		args: func(question string) map[string]interface{} {
+			args := map[string]interface{}{"timeframe": "week"}
+			for _, tf := range []string{"month", "quarter"} {
+				if strings.Contains(question, tf) {
+					args["timeframe"] = tf
+				}
+			}
+			for _, area := range []string{"productivity", "consistency", "balance", "goals"} {
+				if strings.Contains(question, area) {
+					args["focus_area"] = area
+				}
+			}
+			return args
+		},
+	},
+	{
+		tool:     "consciousness",
+		keywords: []string{"conscious", "aware", "current state", "where am i", "how am i doing"},
+		args: func(question string) map[string]interface{} {
+			aspect := "all"
+			for _, a := range []string{"history", "patterns", "goals"} {
+				if strings.Contains(question, a) {
+					aspect = a
+				}
+			}
+			return map[string]interface{}{"aspect": aspect}
+		},
+	},
+	{
+		tool:     "think",
+		keywords: []string{"think", "reason"},
+		args: func(question string) map[string]interface{} {
+			return map[string]interface{}{"question": question, "include_history": true}
+		},
+	},
+}
+
+// MatchTool returns the local tool whose intent matches question, with
+// arguments derived from it. ok is false when no tool applies.
+func MatchTool(question string) (tool string, args map[string]interface{}, ok bool) {
+	q := strings.ToLower(question)
+	for _, intent := range toolIntents {
+		for _, kw := range intent.keywords {
+			if strings.Contains(q, kw) {
+				return intent.tool, intent.args(q), true
+			}
+		}
+	}
+	return "", nil, false
+}
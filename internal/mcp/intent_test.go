@@ -0,0 +1,33 @@
+package mcp
+
+import "testing"
+
+func TestMatchTool(t *testing.T) {
+	tests := []struct {
+		question string
+		tool     string
+		arg      string
+		value    interface{}
+	}{
+		{"evolve my habits", "evolve", "timeframe", "week"},
+		{"How can I improve my balance this month?", "evolve", "focus_area", "balance"},
+		{"How am I doing?", "consciousness", "aspect", "all"},
+		{"Be aware of my goals", "consciousness", "aspect", "goals"},
+		{"Think about my schedule", "think", "include_history", true},
+	}
+
+	for _, tt := range tests {
+		tool, args, ok := MatchTool(tt.question)
+		if !ok || tool != tt.tool {
+			t.Errorf("MatchTool(%q) = %q, %v; want %q", tt.question, tool, ok, tt.tool)
+			continue
+		}
+		if args[tt.arg] != tt.value {
+			t.Errorf("MatchTool(%q) %s = %v, want %v", tt.question, tt.arg, args[tt.arg], tt.value)
+		}
+	}
+
+	if tool, _, ok := MatchTool("Can I leave now?"); ok {
+		t.Errorf("MatchTool matched %q for a plain question", tool)
+	}
+}
@@ -1,9 +1,9 @@
 package mcp
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/kairos/internal/storage"
@@ -11,33 +11,42 @@ import (
 
 // Memory represents a stored memory
 type Memory struct {
-	ID        int64     `json:"id"`
-	Key       string    `json:"key"`
-	Value     string    `json:"value"`
-	Category  string    `json:"category"`
-	Tags      []string  `json:"tags"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int64      `json:"id"`
+	Key       string     `json:"key"`
+	Value     string     `json:"value"`
+	Category  string     `json:"category"`
+	Tags      []string   `json:"tags"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // nil keeps the memory until deleted
 }
 
-func initMemoriesTable(db *storage.Database) {
-	query := `
-	CREATE TABLE IF NOT EXISTS memories (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		key TEXT NOT NULL UNIQUE,
-		value TEXT NOT NULL,
-		category TEXT,
-		tags TEXT,
-		created_at TEXT,
-		updated_at TEXT
-	)`
-	db.Exec(query)
+// memoryColumns is the column list scanMemory reads
+const memoryColumns = "key, value, category, tags, created_at, updated_at, expires_at"
+
+// purgeExpired deletes memories whose expiry has passed and returns how many
+// there were. Expiry times are stored as UTC RFC3339 so they compare as text.
+func purgeExpired(db *storage.Database) (int, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	var expired int
+	if err := db.QueryRow("SELECT COUNT(*) FROM memories WHERE expires_at IS NOT NULL AND expires_at <= ?", now).Scan(&expired); err != nil {
+		return 0, err
+	}
+	if expired == 0 {
+		return 0, nil
+	}
+	return expired, db.Exec("DELETE FROM memories WHERE expires_at IS NOT NULL AND expires_at <= ?", now)
 }
 
 func handlePersist(db *storage.Database, args map[string]interface{}) (interface{}, error) {
-	initMemoriesTable(db)
-
 	action, _ := args["action"].(string)
+	// Expired memories are dropped lazily, before any action sees them;
+	// cleanup does it itself to report the count
+	if action != "cleanup" {
+		if _, err := purgeExpired(db); err != nil {
+			return nil, err
+		}
+	}
 
 	switch action {
 	case "store":
@@ -72,133 +81,140 @@ func persistStore(db *storage.Database, args map[string]interface{}) (interface{
 		}
 	}
 
+	ttlDays, _ := intArg(args["ttl_days"])
+	if ttlDays < 0 {
+		return nil, fmt.Errorf("ttl_days cannot be negative: %d", ttlDays)
+	}
+
+	updated, err := StoreMemoryTTL(db, key, value, category, tags, ttlDays)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Format(time.RFC3339)
+
+	var result map[string]interface{}
+	if updated {
+		result = map[string]interface{}{
+			"action":     "update",
+			"key":        key,
+			"updated_at": now,
+		}
+	} else {
+		result = map[string]interface{}{
+			"action":    "store",
+			"key":       key,
+			"stored_at": now,
+			"category":  category,
+			"tags":      tags,
+		}
+	}
+	if ttlDays > 0 {
+		result["expires_at"] = expiryAfter(ttlDays)
+	}
+	return result, nil
+}
+
+// expiryAfter is the stored expiry time ttlDays from now
+func expiryAfter(ttlDays int) string {
+	return time.Now().UTC().AddDate(0, 0, ttlDays).Format(time.RFC3339)
+}
+
+// StoreMemory saves value under key, replacing an existing memory but
+// keeping its creation time. It reports whether the key already existed.
+func StoreMemory(db *storage.Database, key, value, category string, tags []string) (bool, error) {
+	return StoreMemoryTTL(db, key, value, category, tags, 0)
+}
+
+// StoreMemoryTTL is StoreMemory for a memory that expires ttlDays from now;
+// 0 keeps it until deleted. Storing again replaces the expiry too.
+func StoreMemoryTTL(db *storage.Database, key, value, category string, tags []string, ttlDays int) (bool, error) {
 	tagsJSON, _ := json.Marshal(tags)
 	now := time.Now().Format(time.RFC3339)
+	var expiresAt interface{}
+	if ttlDays > 0 {
+		expiresAt = expiryAfter(ttlDays)
+	}
 
-	// Check if exists
 	var existingCreated string
 	db.QueryRow("SELECT created_at FROM memories WHERE key = ?", key).Scan(&existingCreated)
 
 	if existingCreated != "" {
-		// Update
-		db.Exec("UPDATE memories SET value=?, category=?, tags=?, updated_at=? WHERE key=?",
-			value, category, string(tagsJSON), now, key)
-		return map[string]interface{}{
-			"action":     "update",
-			"key":        key,
-			"updated_at": now,
-		}, nil
+		return true, db.Exec("UPDATE memories SET value=?, category=?, tags=?, updated_at=?, expires_at=? WHERE key=?",
+			value, category, string(tagsJSON), now, expiresAt, key)
 	}
+	return false, db.Exec("INSERT INTO memories (key, value, category, tags, created_at, updated_at, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		key, value, category, string(tagsJSON), now, now, expiresAt)
+}
 
-	// Insert
-	db.Exec("INSERT INTO memories (key, value, category, tags, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
-		key, value, category, string(tagsJSON), now, now)
-
-	return map[string]interface{}{
-		"action":     "store",
-		"key":        key,
-		"stored_at":  now,
-		"category":   category,
-		"tags":       tags,
-	}, nil
+// GetMemory returns the memory stored under key, or nil when there is none
+// or it has expired
+func GetMemory(db *storage.Database, key string) (*Memory, error) {
+	memory, err := scanMemory(db.QueryRow(
+		"SELECT "+memoryColumns+" FROM memories WHERE key = ? AND (expires_at IS NULL OR expires_at > ?)",
+		key, time.Now().UTC().Format(time.RFC3339),
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return memory, err
 }
 
 func persistRetrieve(db *storage.Database, args map[string]interface{}) (interface{}, error) {
 	key, _ := args["key"].(string)
 
-	var memory Memory
-	var tags string
-
-	err := db.QueryRow(
-		"SELECT key, value, category, tags, created_at, updated_at FROM memories WHERE key = ?",
-		key,
-	).Scan(&memory.Key, &memory.Value, &memory.Category, &tags, &memory.CreatedAt, &memory.UpdatedAt)
-
-	if err != nil {
+	memory, err := GetMemory(db, key)
+	if err != nil || memory == nil {
 		return map[string]interface{}{
 			"found": false,
 			"key":   key,
 		}, nil
 	}
 
-	json.Unmarshal([]byte(tags), &memory.Tags)
 	return map[string]interface{}{
-		"found":     true,
-		"key":       memory.Key,
-		"value":     memory.Value,
-		"category":  memory.Category,
-		"tags":      memory.Tags,
-		"created":   memory.CreatedAt,
-		"updated":   memory.UpdatedAt,
+		"found":    true,
+		"key":      memory.Key,
+		"value":    memory.Value,
+		"category": memory.Category,
+		"tags":     memory.Tags,
+		"created":  memory.CreatedAt,
+		"updated":  memory.UpdatedAt,
+		"expires":  memory.ExpiresAt,
 	}, nil
 }
 
 func persistSearch(db *storage.Database, args map[string]interface{}) (interface{}, error) {
 	query, _ := args["query"].(string)
 	category, _ := args["category"].(string)
+	limit, offset := pageArgs(args)
 
-	memories, err := getAllMemories(db)
+	results, total, err := getMemoriesPaged(db, memoryFilter{Query: query, Category: category}, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 
-	var results []Memory
-	for _, m := range memories {
-		// Check query match
-		queryMatch := true // default true if no query
-		if query != "" {
-			queryMatch = false
-			queryLower := strings.ToLower(query)
-			if strings.Contains(strings.ToLower(m.Key), queryLower) ||
-				strings.Contains(strings.ToLower(m.Value), queryLower) {
-				queryMatch = true
-			}
-			for _, tag := range m.Tags {
-				if strings.Contains(strings.ToLower(tag), queryLower) {
-					queryMatch = true
-					break
-				}
-			}
-		}
-
-		// Check category match
-		categoryMatch := true // default true if no category
-		if category != "" {
-			categoryMatch = m.Category == category
-		}
-
-		// Both conditions must be true (AND logic)
-		if queryMatch && categoryMatch {
-			results = append(results, m)
-		}
-	}
-
 	return map[string]interface{}{
 		"count":   len(results),
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
 		"results": results,
 	}, nil
 }
 
 func persistList(db *storage.Database, args map[string]interface{}) (interface{}, error) {
 	category, _ := args["category"].(string)
+	limit, offset := pageArgs(args)
 
-	memories, err := getAllMemories(db)
+	memories, total, err := getMemoriesPaged(db, memoryFilter{Category: category}, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 
-	if category != "" {
-		var filtered []Memory
-		for _, m := range memories {
-			if m.Category == category {
-				filtered = append(filtered, m)
-			}
-		}
-		memories = filtered
-	}
-
 	return map[string]interface{}{
-		"count":     len(memories),
+		"count":    len(memories),
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
 		"memories": memories,
 	}, nil
 }
@@ -240,49 +256,137 @@ func persistUpdate(db *storage.Database, args map[string]interface{}) (interface
 	}, nil
 }
 
+// persistCleanup deletes memories created before older_than (YYYY-MM-DD),
+// or without it every expired memory and orphaned rows with an empty key
 func persistCleanup(db *storage.Database, args map[string]interface{}) (interface{}, error) {
 	olderThan, _ := args["older_than"].(string)
 
-	var query string
-	var params []interface{}
-
 	if olderThan != "" {
 		t, err := time.Parse("2006-01-02", olderThan)
-		if err == nil {
-			query = "DELETE FROM memories WHERE created_at < ?"
-			params = append(params, t.Format(time.RFC3339))
+		if err != nil {
+			return nil, fmt.Errorf("invalid older_than: %s (use YYYY-MM-DD)", olderThan)
 		}
+		if err := db.Exec("DELETE FROM memories WHERE created_at < ?", t.Format(time.RFC3339)); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"cleaned":    true,
+			"cleaned_at": time.Now().Format(time.RFC3339),
+		}, nil
 	}
 
-	if query == "" {
-		// Delete all without created_at (cleanup orphaned)
-		db.Exec("DELETE FROM memories WHERE key = ''")
+	expired, err := purgeExpired(db)
+	if err != nil {
+		return nil, err
 	}
-
-	_ = db.Exec(query, params...)
+	db.Exec("DELETE FROM memories WHERE key = ''")
 	return map[string]interface{}{
-		"cleaned":     true,
-		"cleaned_at":  time.Now().Format(time.RFC3339),
+		"cleaned":    true,
+		"expired":    expired,
+		"cleaned_at": time.Now().Format(time.RFC3339),
 	}, nil
 }
 
-func getAllMemories(db *storage.Database) ([]Memory, error) {
+// defaultPageSize caps list/search results when no limit is given
+const defaultPageSize = 50
+
+// memoryFilter narrows list/search queries
+type memoryFilter struct {
+	Query    string // case-insensitive match on key, value, or tags
+	Category string // exact category match
+}
+
+// pageArgs reads limit/offset tool arguments, applying defaults
+func pageArgs(args map[string]interface{}) (int, int) {
+	limit, ok := intArg(args["limit"])
+	if !ok || limit <= 0 {
+		limit = defaultPageSize
+	}
+	offset, _ := intArg(args["offset"])
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// intArg accepts both JSON numbers (float64) and CLI-parsed ints
+func intArg(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
+
+// getMemoriesPaged returns one page of memories ordered by updated_at
+// (newest first) together with the total number of matching rows
+func getMemoriesPaged(db *storage.Database, filter memoryFilter, limit, offset int) ([]Memory, int, error) {
+	where := "WHERE (expires_at IS NULL OR expires_at > ?)"
+	params := []interface{}{time.Now().UTC().Format(time.RFC3339)}
+	if filter.Category != "" {
+		where += " AND category = ?"
+		params = append(params, filter.Category)
+	}
+	if filter.Query != "" {
+		like := "%" + filter.Query + "%"
+		where += " AND (key LIKE ? OR value LIKE ? OR tags LIKE ?)"
+		params = append(params, like, like, like)
+	}
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM memories "+where, params...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
 	rows, err := db.Query(
-		"SELECT key, value, category, tags, created_at, updated_at FROM memories ORDER BY updated_at DESC",
+		"SELECT "+memoryColumns+" FROM memories "+where+
+			" ORDER BY updated_at DESC, id DESC LIMIT ? OFFSET ?",
+		append(params, limit, offset)...,
 	)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var memories []Memory
+	memories := make([]Memory, 0, limit)
 	for rows.Next() {
-		var memory Memory
-		var tags string
-		rows.Scan(&memory.Key, &memory.Value, &memory.Category, &tags, &memory.CreatedAt, &memory.UpdatedAt)
-		json.Unmarshal([]byte(tags), &memory.Tags)
-		memories = append(memories, memory)
+		memory, err := scanMemory(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		memories = append(memories, *memory)
 	}
 
-	return memories, rows.Err()
+	return memories, total, rows.Err()
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanMemory reads a memory row; timestamps are stored as RFC3339 text
+func scanMemory(row rowScanner) (*Memory, error) {
+	var memory Memory
+	var category, tags, createdAt, updatedAt, expiresAt sql.NullString
+	if err := row.Scan(&memory.Key, &memory.Value, &category, &tags, &createdAt, &updatedAt, &expiresAt); err != nil {
+		return nil, err
+	}
+	memory.Category = category.String
+	if tags.Valid {
+		json.Unmarshal([]byte(tags.String), &memory.Tags)
+	}
+	memory.CreatedAt, _ = time.Parse(time.RFC3339, createdAt.String)
+	memory.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt.String)
+	if expiresAt.Valid {
+		if t, err := time.Parse(time.RFC3339, expiresAt.String); err == nil {
+			memory.ExpiresAt = &t
+		}
+	}
+	return &memory, nil
 }
@@ -0,0 +1,227 @@
+package mcp
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kairos/internal/ai"
+	"github.com/kairos/internal/storage"
+	"github.com/kairos/internal/tracker"
+)
+
+func newTestDB(t *testing.T) *storage.Database {
+	t.Helper()
+	db, err := storage.New(filepath.Join(t.TempDir(), "test.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func seedMemories(t *testing.T, db *storage.Database, n int) {
+	t.Helper()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		category := "notes"
+		if i%2 == 1 {
+			category = "projects"
+		}
+		ts := base.Add(time.Duration(i) * time.Minute).Format(time.RFC3339)
+		err := db.Exec("INSERT INTO memories (key, value, category, tags, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+			fmt.Sprintf("key-%03d", i), fmt.Sprintf("value %d", i), category, `["tag"]`, ts, ts)
+		if err != nil {
+			t.Fatalf("insert memory %d: %v", i, err)
+		}
+	}
+}
+
+func TestPersistListPaged(t *testing.T) {
+	db := newTestDB(t)
+	seedMemories(t, db, 120)
+
+	result, err := handlePersist(db, map[string]interface{}{"action": "list"})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	page := result.(map[string]interface{})
+	if page["total"] != 120 {
+		t.Errorf("total = %v, want 120", page["total"])
+	}
+	memories := page["memories"].([]Memory)
+	if len(memories) != defaultPageSize {
+		t.Fatalf("default page size = %d, want %d", len(memories), defaultPageSize)
+	}
+	if memories[0].Key != "key-119" {
+		t.Errorf("first memory = %s, want newest key-119", memories[0].Key)
+	}
+
+	// JSON clients send numbers as float64
+	result, err = handlePersist(db, map[string]interface{}{"action": "list", "limit": float64(25), "offset": float64(100)})
+	if err != nil {
+		t.Fatalf("list page: %v", err)
+	}
+	memories = result.(map[string]interface{})["memories"].([]Memory)
+	if len(memories) != 20 {
+		t.Fatalf("last page size = %d, want 20", len(memories))
+	}
+	if memories[0].Key != "key-019" || memories[19].Key != "key-000" {
+		t.Errorf("last page = %s..%s, want key-019..key-000", memories[0].Key, memories[19].Key)
+	}
+	if memories[0].UpdatedAt.IsZero() {
+		t.Error("UpdatedAt should be parsed from stored timestamp")
+	}
+}
+
+func TestPersistSearchPaged(t *testing.T) {
+	db := newTestDB(t)
+	seedMemories(t, db, 60)
+
+	result, err := handlePersist(db, map[string]interface{}{
+		"action":   "search",
+		"category": "projects",
+		"limit":    10,
+		"offset":   25,
+	})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	page := result.(map[string]interface{})
+	if page["total"] != 30 {
+		t.Errorf("total = %v, want 30", page["total"])
+	}
+	results := page["results"].([]Memory)
+	if len(results) != 5 {
+		t.Fatalf("page size = %d, want 5", len(results))
+	}
+	for _, m := range results {
+		if m.Category != "projects" {
+			t.Errorf("unexpected category %q for %s", m.Category, m.Key)
+		}
+	}
+
+	result, _ = handlePersist(db, map[string]interface{}{"action": "search", "query": "VALUE 4"})
+	if total := result.(map[string]interface{})["total"]; total != 11 {
+		t.Errorf("query total = %v, want 11 (value 4, 40-49)", total)
+	}
+}
+
+func TestPersistRetrieve(t *testing.T) {
+	db := newTestDB(t)
+	handlePersist(db, map[string]interface{}{"action": "store", "key": "deadline", "value": "March 15"})
+
+	result, err := handlePersist(db, map[string]interface{}{"action": "retrieve", "key": "deadline"})
+	if err != nil {
+		t.Fatalf("retrieve: %v", err)
+	}
+	if found := result.(map[string]interface{})["found"]; found != true {
+		t.Fatalf("found = %v, want true", found)
+	}
+}
+
+func TestWeeklyDigestStoredAndRetrievable(t *testing.T) {
+	db := newTestDB(t)
+
+	monday := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	for i, hours := range []float64{8.5, 9.5, 8} {
+		start := monday.AddDate(0, 0, i)
+		end := start.Add(time.Duration(hours * float64(time.Hour)))
+		if err := db.InsertSession(&storage.WorkSession{StartTime: start, EndTime: &end, BreakMinutes: 30, Note: "feature X"}); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+	}
+
+	dq := ai.NewDataQuerier(db, tracker.NewWithLocation(db, 38.5, time.UTC))
+	digest, err := dq.WeeklyDigest(monday.AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatalf("WeeklyDigest: %v", err)
+	}
+	want := `Week of 2024-01-15: 24.50/38.50h (64%) | goal missed by 14.00h | 3 days worked | longest Tue 9.00h | usual hours 09:00-17:40 | mostly "feature X"`
+	if digest.Text != want {
+		t.Errorf("digest = %q\nwant     %q", digest.Text, want)
+	}
+
+	key, err := StoreDigest(db, digest)
+	if err != nil {
+		t.Fatalf("StoreDigest: %v", err)
+	}
+	if key != "digest-2024-01-15" {
+		t.Errorf("key = %q, want digest-2024-01-15", key)
+	}
+
+	// Storing the week again replaces the digest instead of adding one
+	digest.Text += " (revised)"
+	if _, err := StoreDigest(db, digest); err != nil {
+		t.Fatalf("StoreDigest again: %v", err)
+	}
+
+	result, err := handlePersist(db, map[string]interface{}{"action": "list", "category": DigestCategory})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	memories := result.(map[string]interface{})["memories"].([]Memory)
+	if len(memories) != 1 || memories[0].Key != key || memories[0].Value != want+" (revised)" {
+		t.Errorf("digest memories = %+v", memories)
+	}
+
+	stored, err := GetMemory(db, key)
+	if err != nil || stored == nil || stored.Category != DigestCategory {
+		t.Errorf("GetMemory = %+v, %v", stored, err)
+	}
+	if missing, err := GetMemory(db, "digest-1999-01-04"); missing != nil || err != nil {
+		t.Errorf("GetMemory(missing) = %+v, %v; want nil, nil", missing, err)
+	}
+}
+
+func TestPersistTTL(t *testing.T) {
+	db := newTestDB(t)
+
+	result, err := handlePersist(db, map[string]interface{}{"action": "store", "key": "standup", "value": "moved to 10:00", "ttl_days": 7})
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	if _, ok := result.(map[string]interface{})["expires_at"]; !ok {
+		t.Error("store with ttl_days should report expires_at")
+	}
+	handlePersist(db, map[string]interface{}{"action": "store", "key": "forever", "value": "keep"})
+
+	// Back-date an expiry as if a week had passed
+	past := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)
+	expire := func(key string) {
+		t.Helper()
+		if err := db.Exec("UPDATE memories SET expires_at = ? WHERE key = ?", past, key); err != nil {
+			t.Fatalf("expire %s: %v", key, err)
+		}
+	}
+	expire("standup")
+
+	if m, err := GetMemory(db, "standup"); m != nil || err != nil {
+		t.Errorf("GetMemory(expired) = %+v, %v; want nil, nil", m, err)
+	}
+	result, err = handlePersist(db, map[string]interface{}{"action": "cleanup"})
+	if err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	if expired := result.(map[string]interface{})["expired"]; expired != 1 {
+		t.Errorf("cleanup expired = %v, want 1", expired)
+	}
+
+	// Other actions drop expired memories before answering
+	handlePersist(db, map[string]interface{}{"action": "store", "key": "sprint", "value": "ends Friday", "ttl_days": 1})
+	expire("sprint")
+	result, err = handlePersist(db, map[string]interface{}{"action": "list"})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	memories := result.(map[string]interface{})["memories"].([]Memory)
+	if len(memories) != 1 || memories[0].Key != "forever" || memories[0].ExpiresAt != nil {
+		t.Errorf("memories = %+v, want only the one without expiry", memories)
+	}
+	var rows int
+	db.QueryRow("SELECT COUNT(*) FROM memories").Scan(&rows)
+	if rows != 1 {
+		t.Errorf("%d rows left, want expired ones purged", rows)
+	}
+}
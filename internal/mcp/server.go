@@ -19,15 +19,19 @@ type Server struct {
 	*core.Server
 	db        *storage.Database
 	aiService *ai.AIService
+	querier   *ai.DataQuerier
+	tracker   *tracker.Tracker
 	port      int
 }
 
-// NewServer creates a new Kairos MCP server
-func NewServer(db *storage.Database, aiSvc *ai.AIService, port int) *Server {
+// NewServer creates a new Kairos MCP server whose tools run on the given
+// tracker, so they honour the same settings and hooks as the CLI
+func NewServer(db *storage.Database, tr *tracker.Tracker, aiSvc *ai.AIService, port int) *Server {
 	server := &Server{
 		Server:    core.NewServer(port),
 		db:        db,
 		aiService: aiSvc,
+		tracker:   tr,
 		port:      port,
 	}
 
@@ -35,8 +39,17 @@ func NewServer(db *storage.Database, aiSvc *ai.AIService, port int) *Server {
 	return server
 }
 
+// SetDataQuerier replaces the querier used for archived history, so tools
+// see the same history path and month count as the CLI
+func (s *Server) SetDataQuerier(dq *ai.DataQuerier) {
+	if dq != nil {
+		s.querier = dq
+	}
+}
+
 func (s *Server) registerTools() {
-	t := tracker.NewWithDefaults(s.db)
+	t := s.tracker
+	s.querier = ai.NewDataQuerier(s.db, t)
 
 	// THINK - Reasoning and analysis
 	s.AddHandler(
@@ -71,6 +84,13 @@ func (s *Server) registerTools() {
 			if s.aiService != nil && s.aiService.IsAvailable() {
 				ctx, _ := ai.BuildWorkContext(t)
 				result["ai_response"], _ = s.aiService.Ask(question, ctx)
+			} else if includeHistory {
+				reasoning, history, err := s.historyReasoning(t)
+				if err != nil {
+					return nil, err
+				}
+				result["reasoning"] = reasoning
+				result["history"] = history
 			} else {
 				result["reasoning"] = "Analysis based on your work patterns"
 			}
@@ -93,6 +113,10 @@ func (s *Server) registerTools() {
 
 			weekProgress, _ := t.GetWeeklyProgress()
 			monthProgress, _ := t.GetMonthlyProgress()
+			stats, err := t.ComputeStats(timeframeStart(t, timeframe), t.Now())
+			if err != nil {
+				return nil, err
+			}
 
 			weeklyGoal := t.WeeklyGoal()
 			progressRatio := 0.0
@@ -116,6 +140,7 @@ func (s *Server) registerTools() {
 				"days_worked":     weekProgress.DaysWorkedCount,
 				"remaining_hours": weekProgress.RemainingHours,
 				"daily_target":    dailyTarget,
+				"stats":           statsResult(stats),
 				"suggestions": []string{
 					fmt.Sprintf("Aim for %.1f hours over %d remaining days", dailyTarget, remainingDays),
 					"Try time-blocking for focused work sessions",
@@ -172,6 +197,12 @@ func (s *Server) registerTools() {
 
 			if activeSession != nil {
 				consciousness["started_at"] = activeSession.StartTime.Format("15:04")
+				age := t.OpenSessionAge(activeSession)
+				consciousness["elapsed_hours"] = age.Elapsed.Hours()
+				if age.Stale {
+					consciousness["warning"] = age.Warning
+					consciousness["fix"] = age.Fix
+				}
 			}
 
 			if aspect == "all" || aspect == "current" {
@@ -191,21 +222,66 @@ func (s *Server) registerTools() {
 		"persist",
 		"Store and retrieve long-term memories and insights",
 		core.ToolParameters(map[string]map[string]interface{}{
-			"action":   core.StringParam("Action to perform", []string{"store", "retrieve", "search", "list", "delete"}),
-			"key":      core.StringParam("Memory key/identifier", nil),
-			"value":    core.StringParam("Value to store", nil),
-			"category": core.StringParam("Category for organization", nil),
-			"tags":     core.ArrayParam("Tags for search", core.StringParam("tag", nil)),
-			"query":    core.StringParam("Search query", nil),
+			"action":     core.StringParam("Action to perform", []string{"store", "retrieve", "search", "list", "update", "delete", "cleanup"}),
+			"key":        core.StringParam("Memory key/identifier", nil),
+			"value":      core.StringParam("Value to store", nil),
+			"category":   core.StringParam("Category for organization", nil),
+			"tags":       core.ArrayParam("Tags for search", core.StringParam("tag", nil)),
+			"query":      core.StringParam("Search query", nil),
+			"limit":      core.IntParam("Maximum results for list/search (default 50)"),
+			"offset":     core.IntParam("Number of results to skip for list/search"),
+			"ttl_days":   core.IntParam("Days until a stored memory expires (default: never)"),
+			"older_than": core.StringParam("For cleanup: delete memories created before this date, YYYY-MM-DD (default: delete expired ones)", nil),
 		}),
 		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 			return handlePersist(s.db, args)
 		},
 	)
+
+	// TRACK - Record time
+	s.AddHandler(
+		"track",
+		"Record work time: clock in, clock out of the open session, or add a finished session",
+		core.ToolParameters(map[string]map[string]interface{}{
+			"action":        core.StringParam("Action to perform", []string{"clockin", "clockout", "add"}),
+			"note":          core.StringParam("Session note", nil),
+			"time":          core.StringParam("Clock-in or clock-out time, HH:MM (default now)", nil),
+			"date":          core.StringParam("Day of the session for add, YYYY-MM-DD (default today)", nil),
+			"start":         core.StringParam("Start time for add, HH:MM", nil),
+			"end":           core.StringParam("End time for add, HH:MM", nil),
+			"break_minutes": core.IntParam("Break in minutes for clockout/add (default: the day's break rule)"),
+		}),
+		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return handleTrack(t, args)
+		},
+	)
+}
+
+// historyReasoning answers think offline from this week, this month against
+// the archived trailing average, and one line per archived month
+func (s *Server) historyReasoning(t *tracker.Tracker) (string, []string, error) {
+	weekProgress, err := t.GetWeeklyProgress()
+	if err != nil {
+		return "", nil, err
+	}
+	comparison, err := s.querier.GetHistoryComparison()
+	if err != nil {
+		return "", nil, err
+	}
+
+	reasoning := fmt.Sprintf("This week: %.2f/%.2f hours over %d days. %s.",
+		weekProgress.TotalHours, t.WeeklyGoal(), weekProgress.DaysWorkedCount, comparison.Summary())
+	history := s.querier.HistoryLines()
+	if history == nil {
+		history = []string{}
+	}
+	return reasoning, history, nil
 }
 
-// RunServer starts the MCP server
-func RunServer(db *storage.Database, aiSvc *ai.AIService, port int) error {
+// RunServer starts the MCP server, running at most maxConcurrency tool calls
+// at once (0 for no limit). A non-empty token is required as a bearer token.
+// Tools run on tr, so clock actions through the track tool run its hooks.
+func RunServer(db *storage.Database, tr *tracker.Tracker, aiSvc *ai.AIService, dq *ai.DataQuerier, port, maxConcurrency int, token string) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -217,6 +293,9 @@ func RunServer(db *storage.Database, aiSvc *ai.AIService, port int) error {
 		cancel()
 	}()
 
-	server := NewServer(db, aiSvc, port)
+	server := NewServer(db, tr, aiSvc, port)
+	server.SetDataQuerier(dq)
+	server.SetMaxConcurrency(maxConcurrency)
+	server.SetToken(token)
 	return server.Start(ctx)
 }
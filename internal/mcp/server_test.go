@@ -0,0 +1,151 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kairos/internal/ai"
+	"github.com/kairos/internal/hooks"
+	"github.com/kairos/internal/mcp/core"
+	"github.com/kairos/internal/tracker"
+)
+
+func TestHandleRequestWrapsToolResult(t *testing.T) {
+	db := newTestDB(t)
+	server := NewServer(db, tracker.NewWithDefaults(db), nil, 0)
+
+	resp := server.HandleRequest(context.Background(), core.MCPRequest{
+		Method: "tools/call",
+		Params: map[string]interface{}{
+			"name":      "persist",
+			"arguments": map[string]interface{}{"action": "list"},
+		},
+	})
+	if resp.Error != "" {
+		t.Fatalf("unexpected error: %s", resp.Error)
+	}
+
+	result, ok := resp.Result.(map[string]interface{})
+	if !ok {
+		t.Fatalf("result is %T, want map", resp.Result)
+	}
+	content, ok := result["content"].([]map[string]interface{})
+	if !ok || len(content) != 1 || content[0]["type"] != "text" {
+		t.Fatalf("content = %v, want one text block", result["content"])
+	}
+
+	resp = server.HandleRequest(context.Background(), core.MCPRequest{
+		Method: "tools/call",
+		Params: map[string]interface{}{"name": "missing"},
+	})
+	if resp.Error != "unknown tool: missing" {
+		t.Errorf("Error = %q, want unknown tool", resp.Error)
+	}
+}
+
+func TestThinkOfflineIncludesHistory(t *testing.T) {
+	db := newTestDB(t)
+	historyPath := t.TempDir()
+	lastMonth := time.Now().AddDate(0, 0, -time.Now().Day()+1).AddDate(0, -1, 0)
+	archived := fmt.Sprintf("# %s\n\n## Summary\n\n| Metric | Value |\n|--------|-------|\n| Total Hours | 120.00 |\n| Days Worked | 16 |\n", lastMonth.Format("January 2006"))
+	if err := os.WriteFile(filepath.Join(historyPath, lastMonth.Format("2006-01")+".md"), []byte(archived), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer(db, tracker.NewWithDefaults(db), nil, 0)
+	server.SetDataQuerier(ai.NewDataQuerierWithHistory(db, nil, historyPath))
+
+	out, err := server.CallTool(context.Background(), "think", map[string]interface{}{
+		"question":        "Am I on track?",
+		"include_history": true,
+	})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	result := out.(map[string]interface{})
+
+	if _, ok := result["month_hours"]; !ok {
+		t.Error("month_hours missing with include_history")
+	}
+	reasoning, _ := result["reasoning"].(string)
+	if !strings.Contains(reasoning, "trailing 1-month average of 120.00 hours") {
+		t.Errorf("reasoning = %q, want the archived average", reasoning)
+	}
+	history, _ := result["history"].([]string)
+	want := lastMonth.Format("January 2006") + ": 120.00h over 16 days (7.50h/day)"
+	if len(history) != 1 || history[0] != want {
+		t.Errorf("history = %v, want [%q]", history, want)
+	}
+}
+
+func TestTrackTool(t *testing.T) {
+	db := newTestDB(t)
+	tr := tracker.NewWithDefaults(db)
+	var ran []string
+	tr.SetHooks(tracker.Hooks{
+		Runner: hooks.New(func(command string, env []string) error {
+			ran = append(ran, command)
+			return nil
+		}),
+		PreClockin:   "pre",
+		PostClockout: "post",
+	})
+	tr.SetMaxNoteLength(20)
+	server := NewServer(db, tr, nil, 0)
+	call := func(args map[string]interface{}) (map[string]interface{}, error) {
+		t.Helper()
+		out, err := server.CallTool(context.Background(), "track", args)
+		if err != nil {
+			return nil, err
+		}
+		return out.(map[string]interface{}), nil
+	}
+
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	out, err := call(map[string]interface{}{"action": "add", "date": yesterday, "start": "09:00", "end": "17:00", "break_minutes": 30, "note": "backfill"})
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	session := out["session"].(map[string]interface{})
+	if session["date"] != yesterday || session["end"] != "17:00" || session["hours"] != 7.5 || session["note"] != "backfill" {
+		t.Errorf("added session = %v", session)
+	}
+	if _, err := call(map[string]interface{}{"action": "add", "date": yesterday, "start": "16:00", "end": "18:00"}); err == nil {
+		t.Error("expected an overlapping add to fail")
+	}
+
+	if _, err := call(map[string]interface{}{"action": "clockout"}); err == nil {
+		t.Error("expected clockout without an active session to fail")
+	}
+	if _, err := call(map[string]interface{}{"action": "clockin", "time": "25:99"}); err == nil {
+		t.Error("expected an invalid time to fail")
+	}
+	// The tool runs on the given tracker, so its note limit applies
+	if _, err := call(map[string]interface{}{"action": "clockin", "note": strings.Repeat("x", 21)}); err == nil {
+		t.Error("expected a note over the tracker's limit to fail")
+	}
+
+	start := time.Now().Add(-2 * time.Hour).Format("15:04")
+	if _, err := call(map[string]interface{}{"action": "clockin", "time": start, "note": "from chat"}); err != nil {
+		t.Fatalf("clockin: %v", err)
+	}
+	if _, err := call(map[string]interface{}{"action": "clockin"}); err == nil || !strings.Contains(err.Error(), "already clocked in") {
+		t.Errorf("second clockin error = %v, want already clocked in", err)
+	}
+	out, err = call(map[string]interface{}{"action": "clockout", "break_minutes": float64(0)})
+	if err != nil {
+		t.Fatalf("clockout: %v", err)
+	}
+	session = out["session"].(map[string]interface{})
+	if session["start"] != start || session["end"] == nil || session["note"] != "from chat" {
+		t.Errorf("clocked-out session = %v", session)
+	}
+	if strings.Join(ran, ",") != "pre,post" {
+		t.Errorf("hooks ran %v, want pre,post", ran)
+	}
+}
@@ -0,0 +1,42 @@
+package mcp
+
+import (
+	"time"
+
+	"github.com/kairos/internal/tracker"
+)
+
+// timeframeStart is the first day the evolve tool looks at: the current
+// week, the last month or the last three months
+func timeframeStart(t *tracker.Tracker, timeframe string) time.Time {
+	now := t.Now()
+	switch timeframe {
+	case "month":
+		return now.AddDate(0, -1, 0)
+	case "quarter":
+		return now.AddDate(0, -3, 0)
+	}
+	offset := (int(now.Weekday()) - int(t.WeekStartDay()) + 7) % 7
+	return now.AddDate(0, 0, -offset)
+}
+
+// statsResult is the JSON form of tracker.Stats
+func statsResult(stats *tracker.Stats) map[string]interface{} {
+	result := map[string]interface{}{
+		"start":                stats.Start.Format("2006-01-02"),
+		"end":                  stats.End.Format("2006-01-02"),
+		"days_worked":          stats.DaysWorked,
+		"sessions":             stats.Sessions,
+		"total_hours":          stats.TotalHours,
+		"avg_daily_hours":      stats.AvgDailyHours,
+		"median_session_hours": stats.MedianSessionHours,
+		"longest_streak":       stats.LongestStreak,
+		"current_streak":       stats.CurrentStreak,
+	}
+	if stats.Sessions > 0 {
+		result["most_productive_day"] = stats.MostProductiveDay.String()
+		result["earliest_clock_in"] = stats.EarliestClockIn.Format("15:04")
+		result["latest_clock_in"] = stats.LatestClockIn.Format("15:04")
+	}
+	return result
+}
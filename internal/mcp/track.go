@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kairos/internal/storage"
+	"github.com/kairos/internal/tracker"
+)
+
+// handleTrack records time for the track tool: clockin and clockout act on
+// the open session like the CLI commands, add records a finished session
+func handleTrack(t *tracker.Tracker, args map[string]interface{}) (interface{}, error) {
+	action, _ := args["action"].(string)
+	note, _ := args["note"].(string)
+	timeStr, _ := args["time"].(string)
+	if timeStr != "" {
+		if _, err := clockOnDate(t.Now(), timeStr); err != nil {
+			return nil, err
+		}
+	}
+
+	active, err := t.GetActiveSession()
+	if err != nil {
+		return nil, err
+	}
+
+	switch action {
+	case "clockin":
+		if active != nil {
+			return nil, fmt.Errorf("already clocked in since %s (session %s); clock out first",
+				active.StartTime.Format("2006-01-02 15:04"), active.ID[:8])
+		}
+		session, err := t.ClockInWithTime(note, timeStr)
+		if err != nil {
+			return nil, err
+		}
+		return trackResult(t, action, session, fmt.Sprintf("Clocked in at %s", session.StartTime.Format("15:04"))), nil
+
+	case "clockout":
+		if active == nil {
+			return nil, fmt.Errorf("no active session to clock out")
+		}
+		end := t.ResolveEndTime(active, timeStr)
+		breakMinutes, ok := intArg(args["break_minutes"])
+		if !ok {
+			breakMinutes = t.DefaultBreak(active.StartTime, end)
+		}
+		session, err := t.ClockOutAt(active.ID, breakMinutes, note, end)
+		if err != nil {
+			return nil, err
+		}
+		return trackResult(t, action, session, fmt.Sprintf("Clocked out at %s", end.Format("15:04"))), nil
+
+	case "add":
+		startStr, _ := args["start"].(string)
+		endStr, _ := args["end"].(string)
+		if startStr == "" || endStr == "" {
+			return nil, fmt.Errorf("add needs start and end times (HH:MM)")
+		}
+		date := t.Now()
+		if dateStr, _ := args["date"].(string); dateStr != "" {
+			if date, err = tracker.ParseDateInput(dateStr, t.Now().Location()); err != nil {
+				return nil, err
+			}
+		}
+		start, err := clockOnDate(date, startStr)
+		if err != nil {
+			return nil, err
+		}
+		end, err := clockOnDate(date, endStr)
+		if err != nil {
+			return nil, err
+		}
+		if !end.After(start) {
+			end = end.Add(24 * time.Hour)
+		}
+		breakMinutes, ok := intArg(args["break_minutes"])
+		if !ok {
+			breakMinutes = t.DefaultBreak(start, end)
+		}
+		session, err := t.AddSession(date, startStr, endStr, breakMinutes, note)
+		if err != nil {
+			return nil, err
+		}
+		return trackResult(t, action, session, fmt.Sprintf("Added session on %s", session.Date.Format("2006-01-02"))), nil
+
+	default:
+		return nil, fmt.Errorf("unknown action: %s (use clockin, clockout or add)", action)
+	}
+}
+
+// clockOnDate places an HH:MM time on date, rejecting times the tracker
+// would otherwise silently ignore
+func clockOnDate(date time.Time, s string) (time.Time, error) {
+	for _, format := range []string{"15:04", "3:04"} {
+		if clock, err := time.Parse(format, s); err == nil {
+			return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, date.Location()), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time: %s (use HH:MM)", s)
+}
+
+// trackResult summarizes the session a track action left behind
+func trackResult(t *tracker.Tracker, action string, s *storage.WorkSession, message string) map[string]interface{} {
+	session := map[string]interface{}{
+		"id":            s.ID,
+		"date":          s.Date.Format("2006-01-02"),
+		"start":         s.StartTime.Format("15:04"),
+		"break_minutes": s.BreakMinutes,
+		"note":          s.Note,
+	}
+	if s.EndTime != nil {
+		session["end"] = s.EndTime.Format("15:04")
+		session["hours"], _ = storage.SessionNetHours(*s, t.Now())
+	}
+	return map[string]interface{}{
+		"action":  action,
+		"message": message,
+		"session": session,
+	}
+}
@@ -0,0 +1,54 @@
+// Package notify shows desktop notifications through the tools each OS ships
+// with: notify-send on Linux and the BSDs, osascript on macOS and PowerShell
+// on Windows.
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Send shows a desktop notification with title and message
+func Send(title, message string) error {
+	name, args := command(runtime.GOOS, title, message)
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// command returns the program and arguments that show a notification on goos
+func command(goos, title, message string) (string, []string) {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return "osascript", []string{"-e", script}
+	case "windows":
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms
+$n = New-Object System.Windows.Forms.NotifyIcon
+$n.Icon = [System.Drawing.SystemIcons]::Information
+$n.Visible = $true
+$n.ShowBalloonTip(10000, %s, %s, 'Info')
+Start-Sleep -Seconds 10
+$n.Dispose()`, powerShellString(title), powerShellString(message))
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", script}
+	default:
+		return "notify-send", []string{"--app-name=kairos", title, message}
+	}
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// powerShellString quotes s as a single-quoted PowerShell string literal
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,24 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommandQuoting(t *testing.T) {
+	name, args := command("linux", "Kairos", `It's "done"`)
+	if name != "notify-send" || args[len(args)-1] != `It's "done"` {
+		t.Errorf("linux: %s %q", name, args)
+	}
+
+	name, args = command("darwin", "Kairos", `It's "done" \o/`)
+	want := `display notification "It's \"done\" \\o/" with title "Kairos"`
+	if name != "osascript" || args[1] != want {
+		t.Errorf("darwin: %s %q, want script %s", name, args, want)
+	}
+
+	name, args = command("windows", "Kairos", `It's "done"`)
+	if name != "powershell" || !strings.Contains(args[len(args)-1], `'Kairos', 'It''s "done"'`) {
+		t.Errorf("windows: %s %q", name, args)
+	}
+}
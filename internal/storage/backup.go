@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// BackupName returns the timestamped file name used for backups taken at t
+func BackupName(t time.Time) string {
+	return "kairos-" + t.Format("20060102-150405") + ".db"
+}
+
+// Backup writes a consistent copy of the open database to dst, creating its
+// directory. It uses VACUUM INTO, so other connections can keep reading and
+// the copy is compacted. An existing dst is never overwritten.
+func (d *Database) Backup(dst string) error {
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("backup file already exists: %s", dst)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	if _, err := d.db.Exec(`VACUUM INTO ?`, dst); err != nil {
+		return fmt.Errorf("backup failed: %w", err)
+	}
+	return nil
+}
+
+// Restore replaces the database file at dst with the backup at src. The
+// backup is checked to be a kairos database first, and the file is swapped
+// in with a rename so dst is never left half-written. Close any Database
+// open on dst before calling it.
+func Restore(src, dst string) error {
+	if err := VerifyBackup(src); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".restore-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// A write-ahead log left by the replaced database must not be replayed
+	// into the restored one
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dst + suffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// VerifyBackup opens path read-only and checks that it is a kairos database
+func VerifyBackup(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("backup not found: %s", path)
+	}
+	conn, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var sessions int
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM work_sessions`).Scan(&sessions); err != nil {
+		return fmt.Errorf("%s is not a kairos database: %w", path, err)
+	}
+	return nil
+}
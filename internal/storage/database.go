@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,6 +19,58 @@ type WorkSession struct {
 	EndTime      *time.Time `json:"end_time,omitempty"`
 	BreakMinutes int        `json:"break_minutes"`
 	Note         string     `json:"note,omitempty"`
+	Project      string     `json:"project,omitempty"`
+	Tags         []string   `json:"tags,omitempty"`
+	// TimeZone is the zone the session was recorded in ("" for sessions
+	// recorded before zones were stored, or under the machine-local zone);
+	// its times are shown in that zone rather than the configured one
+	TimeZone string `json:"time_zone,omitempty"`
+}
+
+// SessionNetHours returns a session's worked hours: end minus start minus
+// the break. An open session (complete is false) counts up to now and never
+// goes below zero while its break is still longer than the elapsed time.
+func SessionNetHours(s WorkSession, now time.Time) (hours float64, complete bool) {
+	breakHours := float64(s.BreakMinutes) / 60.0
+	if s.EndTime != nil {
+		return s.EndTime.Sub(s.StartTime).Hours() - breakHours, true
+	}
+	hours = now.Sub(s.StartTime).Hours() - breakHours
+	if hours < 0 {
+		hours = 0
+	}
+	return hours, false
+}
+
+// StartedBetween keeps the sessions whose local start day lies from start's
+// day to end's day, compared in their own zones. Range queries also return
+// sessions running into the range, such as one started at 23:30 the night
+// before; its hours belong to the day it started.
+func StartedBetween(sessions []WorkSession, start, end time.Time) []WorkSession {
+	first, last := start.Format("2006-01-02"), end.Format("2006-01-02")
+	kept := make([]WorkSession, 0, len(sessions))
+	for _, s := range sessions {
+		if day := s.Date.Format("2006-01-02"); day >= first && day <= last {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+// ProjectSummary is a project with its completed-session totals
+type ProjectSummary struct {
+	Name     string  `json:"name"`
+	Hours    float64 `json:"hours"`
+	Sessions int     `json:"sessions"`
+}
+
+// SessionTemplate is a named recurring work block (times are HH:MM)
+type SessionTemplate struct {
+	Name         string `json:"name"`
+	StartTime    string `json:"start_time"`
+	EndTime      string `json:"end_time"`
+	BreakMinutes int    `json:"break_minutes"`
+	Note         string `json:"note,omitempty"`
 }
 
 type DailySummary struct {
@@ -47,7 +100,22 @@ type Database struct {
 	loc *time.Location
 }
 
+// DefaultBusyTimeout is how long a write waits for another process (the MCP
+// server, a second CLI call) to release the database before failing with
+// "database is locked"
+const DefaultBusyTimeout = 5 * time.Second
+
+// New opens the database at path with DefaultBusyTimeout
 func New(path string, loc *time.Location) (*Database, error) {
+	return NewWithBusyTimeout(path, loc, DefaultBusyTimeout)
+}
+
+// NewWithBusyTimeout opens the database at path in WAL mode, so readers
+// never block the writer, with every connection waiting up to busyTimeout
+// for a lock. Transactions take the write lock when they begin, which keeps
+// a read-then-write transaction from failing when another process writes
+// between the two.
+func NewWithBusyTimeout(path string, loc *time.Location, busyTimeout time.Duration) (*Database, error) {
 	if loc == nil {
 		loc = time.Local
 	}
@@ -56,7 +124,8 @@ func New(path string, loc *time.Location) (*Database, error) {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
 
-	db, err := sql.Open("sqlite3", path)
+	dsn := fmt.Sprintf("%s?_journal_mode=WAL&_busy_timeout=%d&_txlock=immediate", path, busyTimeout.Milliseconds())
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, err
 	}
@@ -67,6 +136,7 @@ func New(path string, loc *time.Location) (*Database, error) {
 
 	database := &Database{db: db, loc: loc}
 	if err := database.createTables(); err != nil {
+		db.Close()
 		return nil, err
 	}
 
@@ -102,6 +172,42 @@ func (d *Database) createTables() error {
 			days_worked INTEGER,
 			week_count INTEGER
 		)`,
+		`CREATE TABLE IF NOT EXISTS session_templates (
+			name TEXT PRIMARY KEY,
+			start_time TEXT NOT NULL,
+			end_time TEXT NOT NULL,
+			break_minutes INTEGER DEFAULT 0,
+			note TEXT
+		)`,
+		`CREATE TABLE IF NOT EXISTS projects (
+			name TEXT PRIMARY KEY,
+			created_at TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS undo_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			action TEXT NOT NULL,
+			session_id TEXT NOT NULL,
+			session TEXT NOT NULL,
+			created_at TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS holidays (
+			date TEXT PRIMARY KEY,
+			name TEXT NOT NULL DEFAULT ''
+		)`,
+		`CREATE TABLE IF NOT EXISTS week_goals (
+			week_start TEXT PRIMARY KEY,
+			goal REAL NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS memories (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			key TEXT NOT NULL UNIQUE,
+			value TEXT NOT NULL,
+			category TEXT,
+			tags TEXT,
+			created_at TEXT,
+			updated_at TEXT,
+			expires_at TEXT
+		)`,
 		`CREATE INDEX IF NOT EXISTS idx_sessions_date ON work_sessions(date)`,
 		`CREATE INDEX IF NOT EXISTS idx_sessions_start ON work_sessions(start_time)`,
 	}
@@ -112,13 +218,19 @@ func (d *Database) createTables() error {
 		}
 	}
 
-	return nil
+	return d.migrate(migrations)
 }
 
 func (d *Database) Close() error {
 	return d.db.Close()
 }
 
+// SetMaxOpenConns limits the connections kept open to the database file
+// (0 = unlimited)
+func (d *Database) SetMaxOpenConns(n int) {
+	d.db.SetMaxOpenConns(n)
+}
+
 func (d *Database) Location() *time.Location {
 	if d.loc != nil {
 		return d.loc
@@ -126,10 +238,19 @@ func (d *Database) Location() *time.Location {
 	return time.Local
 }
 
+// InsertSession stores a new session, generating its ID unless one is
+// already set (as when an undo restores a deleted session)
 func (d *Database) InsertSession(session *WorkSession) error {
+	return d.insertSession(d.db, session)
+}
+
+func (d *Database) insertSession(ex execer, session *WorkSession) error {
 	if session.ID == "" {
 		session.ID = uuid.New().String()
 	}
+	if session.TimeZone == "" {
+		session.TimeZone = zoneName(d.Location())
+	}
 
 	var endTimeStr interface{}
 	if session.EndTime != nil {
@@ -140,20 +261,30 @@ func (d *Database) InsertSession(session *WorkSession) error {
 		dateValue = session.StartTime
 	}
 
-	_, err := d.db.Exec(
-		`INSERT INTO work_sessions (id, date, start_time, end_time, break_minutes, note)
-		 VALUES (?, ?, ?, ?, ?, ?)`,
+	_, err := ex.Exec(
+		`INSERT INTO work_sessions (id, date, start_time, end_time, break_minutes, note, project, tz, tags)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		session.ID,
-		dateValue.UTC().Format("2006-01-02"),
+		dateValue.In(d.sessionLocation(session.TimeZone)).Format("2006-01-02"),
 		session.StartTime.UTC().Format("2006-01-02T15:04:05"),
 		endTimeStr,
 		session.BreakMinutes,
 		session.Note,
+		session.Project,
+		session.TimeZone,
+		joinTags(session.Tags),
 	)
 	return err
 }
 
+// UpdateSession saves session, keeping its previous state in the undo log
 func (d *Database) UpdateSession(session *WorkSession) error {
+	return d.withUndo(UndoUpdate, session.ID, func(tx *sql.Tx) error {
+		return d.updateSession(tx, session)
+	})
+}
+
+func (d *Database) updateSession(ex execer, session *WorkSession) error {
 	var endTimeStr interface{}
 	if session.EndTime != nil {
 		endTimeStr = session.EndTime.UTC().Format("2006-01-02T15:04:05")
@@ -163,32 +294,43 @@ func (d *Database) UpdateSession(session *WorkSession) error {
 		dateValue = session.StartTime
 	}
 
-	_, err := d.db.Exec(
-		`UPDATE work_sessions SET date = ?, start_time = ?, end_time = ?, break_minutes = ?, note = ? WHERE id = ?`,
-		dateValue.UTC().Format("2006-01-02"),
+	_, err := ex.Exec(
+		`UPDATE work_sessions SET date = ?, start_time = ?, end_time = ?, break_minutes = ?, note = ?, project = ?, tz = ?, tags = ? WHERE id = ?`,
+		dateValue.In(d.sessionLocation(session.TimeZone)).Format("2006-01-02"),
 		session.StartTime.UTC().Format("2006-01-02T15:04:05"),
 		endTimeStr,
 		session.BreakMinutes,
 		session.Note,
+		session.Project,
+		session.TimeZone,
+		joinTags(session.Tags),
 		session.ID,
 	)
 	return err
 }
 
+// SessionExists reports whether a session has exactly the ID id
+func (d *Database) SessionExists(id string) (bool, error) {
+	var count int
+	err := d.db.QueryRow(`SELECT COUNT(*) FROM work_sessions WHERE id = ?`, id).Scan(&count)
+	return count > 0, err
+}
+
 func (d *Database) GetSessionByID(id string) (*WorkSession, error) {
 	var session WorkSession
 	var dateStr, startTimeStr, endTime sql.NullString
+	var tags string
 
 	// Try exact match first
 	err := d.db.QueryRow(
-		`SELECT id, date, start_time, end_time, break_minutes, note
+		`SELECT id, date, start_time, end_time, break_minutes, note, project, tz, tags
 		 FROM work_sessions WHERE id = ?`,
 		id,
-	).Scan(&session.ID, &dateStr, &startTimeStr, &endTime, &session.BreakMinutes, &session.Note)
+	).Scan(&session.ID, &dateStr, &startTimeStr, &endTime, &session.BreakMinutes, &session.Note, &session.Project, &session.TimeZone, &tags)
 
-	if err == sql.ErrNoRows && len(id) >= 8 {
+	if err == sql.ErrNoRows && len(id) >= MinSessionPrefix {
 		// Try prefix match
-		return d.GetSessionByPrefix(id[:8])
+		return d.GetSessionByPrefix(id)
 	}
 
 	if err != nil {
@@ -196,37 +338,53 @@ func (d *Database) GetSessionByID(id string) (*WorkSession, error) {
 	}
 
 	d.populateSessionTimes(&session, dateStr, startTimeStr, endTime)
+	session.Tags = splitTags(tags)
 
 	return &session, nil
 }
 
-func (d *Database) GetSessionByPrefix(prefix string) (*WorkSession, error) {
-	var session WorkSession
-	var dateStr, startTimeStr, endTime sql.NullString
-
-	err := d.db.QueryRow(
-		`SELECT id, date, start_time, end_time, break_minutes, note
-		 FROM work_sessions WHERE id LIKE ?`,
-		prefix+"%",
-	).Scan(&session.ID, &dateStr, &startTimeStr, &endTime, &session.BreakMinutes, &session.Note)
+// MinSessionPrefix is the shortest session ID prefix GetSessionByPrefix
+// accepts
+const MinSessionPrefix = 4
 
+// GetSessionByPrefix returns the one session whose ID starts with prefix, or
+// sql.ErrNoRows when none does. A prefix shorter than MinSessionPrefix or
+// shared by several sessions is an error.
+func (d *Database) GetSessionByPrefix(prefix string) (*WorkSession, error) {
+	if len(prefix) < MinSessionPrefix {
+		return nil, fmt.Errorf("session ID %q is too short: use at least %d characters", prefix, MinSessionPrefix)
+	}
+	rows, err := d.db.Query(
+		`SELECT id, date, start_time, end_time, break_minutes, note, project, tz, tags
+		 FROM work_sessions WHERE id LIKE ? ESCAPE '\' LIMIT 2`,
+		likeEscaper.Replace(prefix)+"%",
+	)
 	if err != nil {
 		return nil, err
 	}
-
-	d.populateSessionTimes(&session, dateStr, startTimeStr, endTime)
-
-	return &session, nil
+	sessions, err := d.scanSessions(rows)
+	if err != nil {
+		return nil, err
+	}
+	switch len(sessions) {
+	case 0:
+		return nil, sql.ErrNoRows
+	case 1:
+		return &sessions[0], nil
+	default:
+		return nil, fmt.Errorf("session ID %q matches more than one session: use more characters", prefix)
+	}
 }
 
 func (d *Database) GetActiveSession() (*WorkSession, error) {
 	var session WorkSession
 	var dateStr, startTimeStr sql.NullString
+	var tags string
 
 	err := d.db.QueryRow(
-		`SELECT id, date, start_time, break_minutes, note
+		`SELECT id, date, start_time, break_minutes, note, project, tz, tags
 		 FROM work_sessions WHERE end_time IS NULL ORDER BY start_time DESC LIMIT 1`,
-	).Scan(&session.ID, &dateStr, &startTimeStr, &session.BreakMinutes, &session.Note)
+	).Scan(&session.ID, &dateStr, &startTimeStr, &session.BreakMinutes, &session.Note, &session.Project, &session.TimeZone, &tags)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -236,6 +394,7 @@ func (d *Database) GetActiveSession() (*WorkSession, error) {
 	}
 
 	d.populateSessionTimes(&session, dateStr, startTimeStr, sql.NullString{})
+	session.Tags = splitTags(tags)
 
 	return &session, nil
 }
@@ -243,7 +402,7 @@ func (d *Database) GetActiveSession() (*WorkSession, error) {
 func (d *Database) GetSessionsInRange(start, end time.Time) ([]WorkSession, error) {
 	rangeStart, rangeEnd := d.normalizeRange(start, end)
 	rows, err := d.db.Query(
-		`SELECT id, date, start_time, end_time, break_minutes, note
+		`SELECT id, date, start_time, end_time, break_minutes, note, project, tz, tags
 		 FROM work_sessions WHERE start_time <= ? AND (end_time IS NULL OR end_time >= ?)
 		 ORDER BY start_time ASC`,
 		rangeEnd.Format("2006-01-02T15:04:05"),
@@ -252,18 +411,84 @@ func (d *Database) GetSessionsInRange(start, end time.Time) ([]WorkSession, erro
 	if err != nil {
 		return nil, err
 	}
+	return d.scanSessions(rows)
+}
+
+// SearchSessions returns sessions whose note matches query, newest first.
+// Matching is a case-insensitive LIKE; every word must appear somewhere in
+// the note, and "double quoted" words must appear together as a phrase. A
+// zero start or end leaves that side of the range open.
+func (d *Database) SearchSessions(query string, start, end time.Time) ([]WorkSession, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("search query is empty")
+	}
+
+	var where []string
+	var args []interface{}
+	for _, term := range terms {
+		where = append(where, `note LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+likeEscaper.Replace(term)+"%")
+	}
+	if !start.IsZero() || !end.IsZero() {
+		rangeStart, rangeEnd := d.normalizeRange(start, end)
+		if !end.IsZero() {
+			where = append(where, "start_time <= ?")
+			args = append(args, rangeEnd.Format("2006-01-02T15:04:05"))
+		}
+		if !start.IsZero() {
+			where = append(where, "(end_time IS NULL OR end_time >= ?)")
+			args = append(args, rangeStart.Format("2006-01-02T15:04:05"))
+		}
+	}
+
+	rows, err := d.db.Query(
+		`SELECT id, date, start_time, end_time, break_minutes, note, project, tz, tags
+		 FROM work_sessions WHERE `+strings.Join(where, " AND ")+`
+		 ORDER BY start_time DESC`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return d.scanSessions(rows)
+}
+
+// likeEscaper escapes LIKE wildcards so search terms match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// searchTerms splits a query into words, keeping "quoted phrases" whole
+func searchTerms(query string) []string {
+	var terms []string
+	for i, part := range strings.Split(query, `"`) {
+		if i%2 == 1 {
+			if phrase := strings.TrimSpace(part); phrase != "" {
+				terms = append(terms, phrase)
+			}
+			continue
+		}
+		terms = append(terms, strings.Fields(part)...)
+	}
+	return terms
+}
+
+// scanSessions reads work_sessions rows selected in the column order used
+// by GetSessionsInRange and closes rows
+func (d *Database) scanSessions(rows *sql.Rows) ([]WorkSession, error) {
 	defer rows.Close()
 
 	var sessions []WorkSession
 	for rows.Next() {
 		var session WorkSession
 		var dateStr, startTimeStr, endTime sql.NullString
+		var tags string
 
-		if err := rows.Scan(&session.ID, &dateStr, &startTimeStr, &endTime, &session.BreakMinutes, &session.Note); err != nil {
+		if err := rows.Scan(&session.ID, &dateStr, &startTimeStr, &endTime, &session.BreakMinutes, &session.Note, &session.Project, &session.TimeZone, &tags); err != nil {
 			return nil, err
 		}
 
 		d.populateSessionTimes(&session, dateStr, startTimeStr, endTime)
+		session.Tags = splitTags(tags)
 
 		sessions = append(sessions, session)
 	}
@@ -276,9 +501,12 @@ func (d *Database) GetTodaySessions() ([]WorkSession, error) {
 	return d.GetSessionsInRange(now, now)
 }
 
+// DeleteSession removes a session, keeping it in the undo log
 func (d *Database) DeleteSession(id string) error {
-	_, err := d.db.Exec("DELETE FROM work_sessions WHERE id = ?", id)
-	return err
+	return d.withUndo(UndoDelete, id, func(tx *sql.Tx) error {
+		_, err := tx.Exec("DELETE FROM work_sessions WHERE id = ?", id)
+		return err
+	})
 }
 
 // DeleteSessionsInRange removes all sessions within a date range
@@ -319,8 +547,10 @@ func (d *Database) normalizeRange(start, end time.Time) (time.Time, time.Time) {
 	return rangeStart.UTC(), rangeEnd.UTC()
 }
 
+// populateSessionTimes converts the stored UTC strings into the session's
+// recorded zone, or the database location when none was recorded
 func (d *Database) populateSessionTimes(session *WorkSession, dateStr, startTimeStr, endTime sql.NullString) {
-	loc := d.Location()
+	loc := d.sessionLocation(session.TimeZone)
 	if startTimeStr.Valid {
 		t, _ := time.ParseInLocation("2006-01-02T15:04:05", startTimeStr.String, time.UTC)
 		localStart := t.In(loc)
@@ -338,6 +568,277 @@ func (d *Database) populateSessionTimes(session *WorkSession, dateStr, startTime
 	}
 }
 
+// joinTags stores tags as one comma-separated column; blanks are dropped
+func joinTags(tags []string) string {
+	kept := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			kept = append(kept, tag)
+		}
+	}
+	return strings.Join(kept, ",")
+}
+
+// splitTags reads the tags column written by joinTags
+func splitTags(column string) []string {
+	if column == "" {
+		return nil
+	}
+	return strings.Split(column, ",")
+}
+
+// sessionLocation resolves a session's tz column, falling back to the
+// database location for legacy rows and unknown zone names
+func (d *Database) sessionLocation(tz string) *time.Location {
+	if loc := LoadZone(tz); loc != nil {
+		return loc
+	}
+	return d.Location()
+}
+
+// zoneName is the tz column value for loc: its IANA or UTC±hh:mm name, or ""
+// for the machine-local zone, which has no portable name
+func zoneName(loc *time.Location) string {
+	if loc == nil || loc == time.Local {
+		return ""
+	}
+	return loc.String()
+}
+
+// LoadZone resolves a tz column value written by zoneName; "" and unknown
+// names give nil
+func LoadZone(name string) *time.Location {
+	if name == "" {
+		return nil
+	}
+	var hours, minutes int
+	if n, _ := fmt.Sscanf(name, "UTC%d:%d", &hours, &minutes); n == 2 {
+		seconds := hours*3600 + minutes*60
+		if strings.HasPrefix(name, "UTC-") {
+			seconds = hours*3600 - minutes*60
+		}
+		return time.FixedZone(name, seconds)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || loc == time.Local {
+		return nil
+	}
+	return loc
+}
+
+// CountUnzonedSessions returns how many sessions have no recorded zone
+func (d *Database) CountUnzonedSessions() (int, error) {
+	var count int
+	err := d.db.QueryRow("SELECT COUNT(*) FROM work_sessions WHERE tz = ''").Scan(&count)
+	return count, err
+}
+
+// RecordSessionZones stamps sessions without a recorded zone with the
+// database location, so they keep their times if the configured zone later
+// changes. It returns how many rows changed.
+func (d *Database) RecordSessionZones() (int, error) {
+	name := zoneName(d.Location())
+	if name == "" {
+		return 0, fmt.Errorf("the machine-local zone can't be recorded; set an explicit timezone first")
+	}
+	result, err := d.db.Exec("UPDATE work_sessions SET tz = ? WHERE tz = ''", name)
+	if err != nil {
+		return 0, err
+	}
+	changed, err := result.RowsAffected()
+	return int(changed), err
+}
+
+// DateMismatch is a session whose stored date column disagrees with its
+// start_time in the configured location
+type DateMismatch struct {
+	ID       string
+	Stored   string
+	Expected string
+}
+
+// FindDateMismatches recomputes every session's date from start_time in its
+// recorded zone (the database location for legacy rows) and returns the rows
+// whose stored date differs
+func (d *Database) FindDateMismatches() ([]DateMismatch, error) {
+	rows, err := d.db.Query(`SELECT id, date, start_time, tz FROM work_sessions ORDER BY start_time ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mismatches []DateMismatch
+	for rows.Next() {
+		var id, dateStr, startTimeStr, tz string
+		if err := rows.Scan(&id, &dateStr, &startTimeStr, &tz); err != nil {
+			return nil, err
+		}
+		start, err := time.ParseInLocation("2006-01-02T15:04:05", startTimeStr, time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("session %s: invalid start_time %q", id, startTimeStr)
+		}
+		if expected := start.In(d.sessionLocation(tz)).Format("2006-01-02"); expected != dateStr {
+			mismatches = append(mismatches, DateMismatch{ID: id, Stored: dateStr, Expected: expected})
+		}
+	}
+	return mismatches, rows.Err()
+}
+
+// FixSessionDates rewrites mismatched date columns from start_time in the
+// database location and returns how many rows changed
+func (d *Database) FixSessionDates() (int, error) {
+	mismatches, err := d.FindDateMismatches()
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	for _, m := range mismatches {
+		if _, err := tx.Exec("UPDATE work_sessions SET date = ? WHERE id = ?", m.Expected, m.ID); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(mismatches), nil
+}
+
+// EnsureProject registers a project name; existing names are left as they are
+func (d *Database) EnsureProject(name string) error {
+	_, err := d.db.Exec(
+		"INSERT OR IGNORE INTO projects (name, created_at) VALUES (?, ?)",
+		name, time.Now().UTC().Format("2006-01-02T15:04:05"),
+	)
+	return err
+}
+
+// ProjectNames returns registered and in-use project names, sorted
+func (d *Database) ProjectNames() ([]string, error) {
+	rows, err := d.db.Query(`
+		SELECT project FROM work_sessions WHERE project != ''
+		UNION SELECT name FROM projects
+		ORDER BY 1`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// GetProjectSessions returns every session with a project, oldest first
+func (d *Database) GetProjectSessions() ([]WorkSession, error) {
+	rows, err := d.db.Query(
+		`SELECT id, date, start_time, end_time, break_minutes, note, project, tz, tags
+		 FROM work_sessions WHERE project != '' ORDER BY start_time ASC`)
+	if err != nil {
+		return nil, err
+	}
+	return d.scanSessions(rows)
+}
+
+// RenameProject renames a project on every session and in the projects table,
+// returning how many sessions changed
+func (d *Database) RenameProject(oldName, newName string) (int, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE work_sessions SET project = ? WHERE project = ?", newName, oldName)
+	if err != nil {
+		return 0, err
+	}
+	changed, _ := result.RowsAffected()
+
+	registered, err := tx.Exec("DELETE FROM projects WHERE name = ?", oldName)
+	if err != nil {
+		return 0, err
+	}
+	if n, _ := registered.RowsAffected(); n == 0 && changed == 0 {
+		return 0, fmt.Errorf("project not found: %s", oldName)
+	}
+	if _, err := tx.Exec(
+		"INSERT OR IGNORE INTO projects (name, created_at) VALUES (?, ?)",
+		newName, time.Now().UTC().Format("2006-01-02T15:04:05"),
+	); err != nil {
+		return 0, err
+	}
+
+	return int(changed), tx.Commit()
+}
+
+// SaveTemplate inserts or replaces a session template
+func (d *Database) SaveTemplate(tpl *SessionTemplate) error {
+	_, err := d.db.Exec(
+		`INSERT OR REPLACE INTO session_templates (name, start_time, end_time, break_minutes, note)
+		 VALUES (?, ?, ?, ?, ?)`,
+		tpl.Name, tpl.StartTime, tpl.EndTime, tpl.BreakMinutes, tpl.Note,
+	)
+	return err
+}
+
+// GetTemplate returns the named template, or nil if it doesn't exist
+func (d *Database) GetTemplate(name string) (*SessionTemplate, error) {
+	var tpl SessionTemplate
+	var note sql.NullString
+	err := d.db.QueryRow(
+		`SELECT name, start_time, end_time, break_minutes, note FROM session_templates WHERE name = ?`,
+		name,
+	).Scan(&tpl.Name, &tpl.StartTime, &tpl.EndTime, &tpl.BreakMinutes, &note)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	tpl.Note = note.String
+	return &tpl, nil
+}
+
+// ListTemplates returns all templates ordered by name
+func (d *Database) ListTemplates() ([]SessionTemplate, error) {
+	rows, err := d.db.Query(
+		`SELECT name, start_time, end_time, break_minutes, note FROM session_templates ORDER BY name`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []SessionTemplate
+	for rows.Next() {
+		var tpl SessionTemplate
+		var note sql.NullString
+		if err := rows.Scan(&tpl.Name, &tpl.StartTime, &tpl.EndTime, &tpl.BreakMinutes, &note); err != nil {
+			return nil, err
+		}
+		tpl.Note = note.String
+		templates = append(templates, tpl)
+	}
+	return templates, rows.Err()
+}
+
+// DeleteTemplate removes the named template
+func (d *Database) DeleteTemplate(name string) error {
+	_, err := d.db.Exec(`DELETE FROM session_templates WHERE name = ?`, name)
+	return err
+}
+
 // Exec executes a raw SQL query (for MCP tools)
 func (d *Database) Exec(query string, args ...interface{}) error {
 	_, err := d.db.Exec(query, args...)
@@ -0,0 +1,731 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFixSessionDatesAfterZoneChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	// 02:00 UTC on Mar 11 is 22:00 on Mar 10 in New York
+	start := time.Date(2024, 3, 11, 2, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+
+	utcDB, err := New(path, time.UTC)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := utcDB.InsertSession(&WorkSession{Date: start, StartTime: start, EndTime: &end}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+	// A legacy row: no recorded zone, so it follows the configured one
+	if err := utcDB.Exec("UPDATE work_sessions SET tz = ''"); err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+	utcDB.Close()
+
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	db, err := New(path, ny)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer db.Close()
+
+	mismatches, err := db.FindDateMismatches()
+	if err != nil {
+		t.Fatalf("FindDateMismatches: %v", err)
+	}
+	if len(mismatches) != 1 || mismatches[0].Stored != "2024-03-11" || mismatches[0].Expected != "2024-03-10" {
+		t.Fatalf("mismatches = %+v, want one 2024-03-11 -> 2024-03-10", mismatches)
+	}
+
+	changed, err := db.FixSessionDates()
+	if err != nil || changed != 1 {
+		t.Fatalf("FixSessionDates = %d, %v; want 1", changed, err)
+	}
+	if changed, _ := db.FixSessionDates(); changed != 0 {
+		t.Errorf("second FixSessionDates changed %d rows, want 0", changed)
+	}
+
+	// New rows take their date from the configured location
+	late := time.Date(2024, 3, 12, 23, 30, 0, 0, ny)
+	if err := db.InsertSession(&WorkSession{Date: late, StartTime: late}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+	if mismatches, _ := db.FindDateMismatches(); len(mismatches) != 0 {
+		t.Errorf("new session stored with mismatched date: %+v", mismatches)
+	}
+}
+
+func TestSessionTimeZones(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	vienna, err := time.LoadLocation("Europe/Vienna")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+
+	// 20:00 in Vienna on Mar 10 is 04:00 on Mar 11 in Tokyo
+	start := time.Date(2024, 3, 10, 20, 0, 0, 0, vienna)
+	end := start.Add(2 * time.Hour)
+
+	viennaDB, err := New(path, vienna)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	recorded := &WorkSession{ID: "recorded", StartTime: start, EndTime: &end}
+	if err := viennaDB.InsertSession(recorded); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+	if recorded.TimeZone != "Europe/Vienna" {
+		t.Errorf("TimeZone = %q, want Europe/Vienna", recorded.TimeZone)
+	}
+	if err := viennaDB.InsertSession(&WorkSession{ID: "legacy", StartTime: start, EndTime: &end}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+	if err := viennaDB.Exec("UPDATE work_sessions SET tz = '' WHERE id = 'legacy'"); err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+	viennaDB.Close()
+
+	// After moving to Tokyo the recorded session keeps its Vienna times...
+	db, err := New(path, tokyo)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer db.Close()
+
+	got, err := db.GetSessionByID("recorded")
+	if err != nil || got == nil {
+		t.Fatalf("GetSessionByID: %v", err)
+	}
+	if got.StartTime.Location().String() != "Europe/Vienna" || got.StartTime.Hour() != 20 || got.Date.Day() != 10 {
+		t.Errorf("recorded session = %s on %s, want 20:00 Vienna on the 10th", got.StartTime, got.Date.Format("2006-01-02"))
+	}
+
+	// ...while the legacy row is read as UTC and shown in the configured zone
+	legacy, err := db.GetSessionByID("legacy")
+	if err != nil || legacy == nil {
+		t.Fatalf("GetSessionByID: %v", err)
+	}
+	if legacy.TimeZone != "" || legacy.StartTime.Location() != tokyo || legacy.StartTime.Hour() != 4 || legacy.Date.Day() != 11 {
+		t.Errorf("legacy session = %s on %s, want 04:00 Tokyo on the 11th", legacy.StartTime, legacy.Date.Format("2006-01-02"))
+	}
+
+	mismatches, err := db.FindDateMismatches()
+	if err != nil {
+		t.Fatalf("FindDateMismatches: %v", err)
+	}
+	if len(mismatches) != 1 || mismatches[0].ID != "legacy" {
+		t.Errorf("mismatches = %+v, want only the legacy row", mismatches)
+	}
+
+	if n, _ := db.CountUnzonedSessions(); n != 1 {
+		t.Errorf("CountUnzonedSessions = %d, want 1", n)
+	}
+	if changed, err := db.RecordSessionZones(); err != nil || changed != 1 {
+		t.Errorf("RecordSessionZones = %d, %v; want 1", changed, err)
+	}
+	if legacy, _ := db.GetSessionByID("legacy"); legacy.TimeZone != "Asia/Tokyo" {
+		t.Errorf("legacy TimeZone after recording = %q, want Asia/Tokyo", legacy.TimeZone)
+	}
+}
+
+func TestLoadZoneFixedOffsets(t *testing.T) {
+	for name, want := range map[string]int{"UTC+05:30": 19800, "UTC-03:30": -12600, "UTC+00:00": 0} {
+		loc := LoadZone(name)
+		if loc == nil {
+			t.Errorf("LoadZone(%q) = nil", name)
+			continue
+		}
+		if _, offset := time.Date(2024, 1, 1, 0, 0, 0, 0, loc).Zone(); offset != want {
+			t.Errorf("LoadZone(%q) offset = %d, want %d", name, offset, want)
+		}
+	}
+	if LoadZone("") != nil || LoadZone("Not/AZone") != nil {
+		t.Error("empty and unknown names should give nil")
+	}
+}
+
+func TestProjectsListAndRename(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	// A database created before the project column existed
+	legacy, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := legacy.Exec(`CREATE TABLE work_sessions (
+		id TEXT PRIMARY KEY, date TEXT NOT NULL, start_time TEXT NOT NULL,
+		end_time TEXT, break_minutes INTEGER DEFAULT 0, note TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := legacy.Exec(`INSERT INTO work_sessions VALUES ('old', '2024-01-15', '2024-01-15T09:00:00', '2024-01-15T10:00:00', 0, '')`); err != nil {
+		t.Fatal(err)
+	}
+	legacy.Close()
+
+	db, err := New(path, time.UTC)
+	if err != nil {
+		t.Fatalf("New on legacy schema: %v", err)
+	}
+	defer db.Close()
+
+	if s, err := db.GetSessionByID("old"); err != nil || s.Project != "" {
+		t.Fatalf("legacy session = %+v, %v; want empty project", s, err)
+	}
+
+	start := time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)
+	end := start.Add(3 * time.Hour)
+	for _, s := range []*WorkSession{
+		{Date: start, StartTime: start, EndTime: &end, BreakMinutes: 30, Project: "api"},
+		{Date: start, StartTime: start, EndTime: &end, Project: "api"},
+	} {
+		if err := db.InsertSession(s); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+	}
+	if err := db.EnsureProject("docs"); err != nil {
+		t.Fatalf("EnsureProject: %v", err)
+	}
+
+	names, err := db.ProjectNames()
+	if err != nil {
+		t.Fatalf("ProjectNames: %v", err)
+	}
+	if strings.Join(names, " ") != "api docs" {
+		t.Fatalf("projects = %v, want api and docs", names)
+	}
+	if sessions, err := db.GetProjectSessions(); err != nil || len(sessions) != 2 {
+		t.Fatalf("GetProjectSessions = %d sessions, %v; want the 2 api sessions", len(sessions), err)
+	}
+
+	changed, err := db.RenameProject("api", "backend")
+	if err != nil || changed != 2 {
+		t.Fatalf("RenameProject = %d, %v; want 2", changed, err)
+	}
+	names, _ = db.ProjectNames()
+	if strings.Join(names, " ") != "backend docs" {
+		t.Errorf("after rename projects = %v", names)
+	}
+
+	if _, err := db.RenameProject("missing", "x"); err == nil {
+		t.Error("expected error renaming an unknown project")
+	}
+}
+
+func TestHolidays(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "test.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer db.Close()
+
+	for _, h := range []Holiday{{"2024-12-26", "Boxing Day"}, {"2024-12-25", "Xmas"}, {"2024-12-25", "Christmas"}} {
+		if err := db.AddHoliday(h.Date, h.Name); err != nil {
+			t.Fatalf("AddHoliday: %v", err)
+		}
+	}
+	holidays, err := db.ListHolidays()
+	if err != nil {
+		t.Fatalf("ListHolidays: %v", err)
+	}
+	if len(holidays) != 2 || holidays[0] != (Holiday{"2024-12-25", "Christmas"}) || holidays[1].Date != "2024-12-26" {
+		t.Errorf("holidays = %v, want Christmas renamed and in date order", holidays)
+	}
+
+	if removed, err := db.RemoveHoliday("2024-12-26"); err != nil || !removed {
+		t.Errorf("RemoveHoliday = %v, %v", removed, err)
+	}
+	if removed, _ := db.RemoveHoliday("2024-12-26"); removed {
+		t.Error("removing a missing holiday reported a removal")
+	}
+}
+
+func TestWeekGoals(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "test.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer db.Close()
+
+	if _, ok, err := db.GetWeekGoal("2024-01-15"); err != nil || ok {
+		t.Errorf("GetWeekGoal before setting = %v, %v; want none", ok, err)
+	}
+	for _, goal := range []float64{24, 30} {
+		if err := db.SetWeekGoal("2024-01-15", goal); err != nil {
+			t.Fatalf("SetWeekGoal: %v", err)
+		}
+	}
+	if goal, ok, err := db.GetWeekGoal("2024-01-15"); err != nil || !ok || goal != 30 {
+		t.Errorf("GetWeekGoal = %v, %v, %v; want the replaced 30", goal, ok, err)
+	}
+
+	if cleared, err := db.ClearWeekGoal("2024-01-15"); err != nil || !cleared {
+		t.Errorf("ClearWeekGoal = %v, %v", cleared, err)
+	}
+	if cleared, _ := db.ClearWeekGoal("2024-01-15"); cleared {
+		t.Error("clearing a missing week goal reported a removal")
+	}
+}
+
+func TestMigrationsUpgradeLegacyDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.db")
+
+	// The original schema, before project/tz/tags existed
+	raw, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	if _, err := raw.Exec(`CREATE TABLE work_sessions (
+		id TEXT PRIMARY KEY,
+		date TEXT NOT NULL,
+		start_time TEXT NOT NULL,
+		end_time TEXT,
+		break_minutes INTEGER DEFAULT 0,
+		note TEXT
+	)`); err != nil {
+		t.Fatalf("create legacy table: %v", err)
+	}
+	if _, err := raw.Exec(`INSERT INTO work_sessions VALUES ('legacy', '2024-01-15', '2024-01-15T09:00:00Z', '2024-01-15T17:00:00Z', 30, 'old')`); err != nil {
+		t.Fatalf("insert legacy row: %v", err)
+	}
+	// MCP memories from before they could expire
+	if _, err := raw.Exec(`CREATE TABLE memories (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		key TEXT NOT NULL UNIQUE,
+		value TEXT NOT NULL,
+		category TEXT,
+		tags TEXT,
+		created_at TEXT,
+		updated_at TEXT
+	)`); err != nil {
+		t.Fatalf("create legacy memories: %v", err)
+	}
+	raw.Close()
+
+	db, err := New(path, time.UTC)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatalf("SchemaVersion: %v", err)
+	}
+	if version != len(migrations) {
+		t.Errorf("schema version = %d, want %d", version, len(migrations))
+	}
+
+	if err := db.Exec(`INSERT INTO memories (key, value, expires_at) VALUES ('k', 'v', '2024-02-01T00:00:00Z')`); err != nil {
+		t.Errorf("memories lack expires_at after migrating: %v", err)
+	}
+
+	session, err := db.GetSessionByID("legacy")
+	if err != nil || session == nil {
+		t.Fatalf("GetSessionByID: %v, %v", session, err)
+	}
+	if session.Note != "old" || session.Project != "" || len(session.Tags) != 0 {
+		t.Errorf("legacy session = %+v", session)
+	}
+	session.Project = "core"
+	session.Tags = []string{"a"}
+	if err := db.UpdateSession(session); err != nil {
+		t.Fatalf("UpdateSession: %v", err)
+	}
+	db.Close()
+
+	// Reopening applies nothing new
+	db, err = New(path, time.UTC)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer db.Close()
+	if v, _ := db.SchemaVersion(); v != len(migrations) {
+		t.Errorf("schema version after reopen = %d, want %d", v, len(migrations))
+	}
+	if got, _ := db.GetSessionByID("legacy"); got.Project != "core" {
+		t.Errorf("project = %q after reopen", got.Project)
+	}
+}
+
+func TestFailedMigrationRollsBack(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "test.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer db.Close()
+
+	list := append(append([]migration{}, migrations...),
+		func(tx *sql.Tx) error {
+			if _, err := tx.Exec(`CREATE TABLE scratch (id TEXT)`); err != nil {
+				return err
+			}
+			return fmt.Errorf("boom")
+		})
+	if err := db.migrate(list); err == nil {
+		t.Fatal("migrate succeeded, want the failing migration's error")
+	}
+
+	if v, _ := db.SchemaVersion(); v != len(migrations) {
+		t.Errorf("schema version = %d, want %d", v, len(migrations))
+	}
+	var name string
+	if err := db.QueryRow(`SELECT name FROM sqlite_master WHERE name = 'scratch'`).Scan(&name); err != sql.ErrNoRows {
+		t.Errorf("scratch table survived the rollback (err=%v)", err)
+	}
+}
+
+func TestSessionNetHours(t *testing.T) {
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	end := start.Add(8*time.Hour + 30*time.Minute)
+	now := start.Add(2 * time.Hour)
+	lateEnd := start.Add(17 * time.Hour) // 02:00 the next day
+
+	tests := []struct {
+		name         string
+		session      WorkSession
+		wantHours    float64
+		wantComplete bool
+	}{
+		{"complete", WorkSession{StartTime: start, EndTime: &end, BreakMinutes: 30}, 8, true},
+		{"zero break", WorkSession{StartTime: start, EndTime: &end}, 8.5, true},
+		{"open", WorkSession{StartTime: start, BreakMinutes: 30}, 1.5, false},
+		{"open, break longer than elapsed", WorkSession{StartTime: start, BreakMinutes: 180}, 0, false},
+		{"past midnight", WorkSession{StartTime: start.Add(13 * time.Hour), EndTime: &lateEnd}, 4, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hours, complete := SessionNetHours(tt.session, now)
+			if hours != tt.wantHours || complete != tt.wantComplete {
+				t.Errorf("SessionNetHours = %.2f, %v; want %.2f, %v", hours, complete, tt.wantHours, tt.wantComplete)
+			}
+		})
+	}
+}
+
+func TestSearchSessions(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "test.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer db.Close()
+
+	for i, note := range []string{
+		"Planned the DB migration",
+		"standup",
+		"Ran the migration on the db replica",
+		"Cut billing to 100% of traffic",
+	} {
+		start := time.Date(2025, 3, 3+i, 9, 0, 0, 0, time.UTC)
+		end := start.Add(8 * time.Hour)
+		if err := db.InsertSession(&WorkSession{StartTime: start, EndTime: &end, Note: note}); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+	}
+
+	search := func(query string, start, end time.Time) []string {
+		t.Helper()
+		sessions, err := db.SearchSessions(query, start, end)
+		if err != nil {
+			t.Fatalf("SearchSessions(%q): %v", query, err)
+		}
+		var dates []string
+		for _, s := range sessions {
+			dates = append(dates, s.Date.Format("01-02"))
+		}
+		return dates
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"MIGRATION", "03-05 03-03"},        // case-insensitive, newest first
+		{"db migration", "03-05 03-03"},     // every word, in any order
+		{`"db migration"`, "03-03"},         // quoted phrase
+		{`migration "db replica"`, "03-05"}, // words and phrases combined
+		{"100%", "03-06"},                   // wildcards match literally
+		{"_", ""},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(search(tt.query, time.Time{}, time.Time{}), " "); got != tt.want {
+			t.Errorf("SearchSessions(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	if got := strings.Join(search("migration", time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC), time.Time{}), " "); got != "03-05" {
+		t.Errorf("from Mar 4 = %q, want 03-05", got)
+	}
+	if got := strings.Join(search("migration", time.Time{}, time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)), " "); got != "03-03" {
+		t.Errorf("up to Mar 4 = %q, want 03-03", got)
+	}
+	if _, err := db.SearchSessions(`  "" `, time.Time{}, time.Time{}); err == nil {
+		t.Error("empty query was accepted")
+	}
+}
+
+func TestGetSessionByPrefix(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "test.db"), time.UTC)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer db.Close()
+
+	start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	for _, id := range []string{"abcd1111", "abcd2222", "ef_91234"} {
+		if err := db.InsertSession(&WorkSession{ID: id, StartTime: start}); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+	}
+
+	// SessionExists takes whole IDs only
+	if ok, err := db.SessionExists("abcd1111"); err != nil || !ok {
+		t.Errorf("SessionExists(abcd1111) = %v, %v; want true", ok, err)
+	}
+	if ok, _ := db.SessionExists("abcd1"); ok {
+		t.Error("SessionExists(abcd1) = true, want a prefix not to count")
+	}
+
+	if s, err := db.GetSessionByPrefix("abcd1"); err != nil || s.ID != "abcd1111" {
+		t.Errorf("abcd1 = %v, %v, want abcd1111", s, err)
+	}
+	if _, err := db.GetSessionByPrefix("abcd"); err == nil || !strings.Contains(err.Error(), "more than one") {
+		t.Errorf("abcd error = %v, want it rejected as ambiguous", err)
+	}
+	if _, err := db.GetSessionByPrefix("abc"); err == nil || !strings.Contains(err.Error(), "too short") {
+		t.Errorf("abc error = %v, want it rejected as too short", err)
+	}
+	// _ matches itself, not any character
+	if _, err := db.GetSessionByPrefix("ef_8"); err != sql.ErrNoRows {
+		t.Errorf("ef_8 error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestUndoRestoresUpdatesAndDeletes(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "test.db"), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	start := time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)
+	session := &WorkSession{Date: start, StartTime: start, Note: "open", Tags: []string{"a"}}
+	if err := db.InsertSession(session); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+
+	// Clock out, then delete
+	end := start.Add(3 * time.Hour)
+	closed := *session
+	closed.EndTime = &end
+	closed.BreakMinutes = 30
+	if err := db.UpdateSession(&closed); err != nil {
+		t.Fatalf("UpdateSession: %v", err)
+	}
+	if err := db.DeleteSession(session.ID); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+
+	entry, err := db.Undo()
+	if err != nil || entry == nil || entry.Action != UndoDelete {
+		t.Fatalf("first Undo = %+v, %v; want the delete", entry, err)
+	}
+	got, err := db.GetSessionByID(session.ID)
+	if err != nil || got.EndTime == nil || !got.EndTime.Equal(end) || got.BreakMinutes != 30 {
+		t.Fatalf("restored session = %+v, %v; want it closed at 12:00 with its original ID", got, err)
+	}
+
+	entry, err = db.Undo()
+	if err != nil || entry == nil || entry.Action != UndoUpdate {
+		t.Fatalf("second Undo = %+v, %v; want the clock-out", entry, err)
+	}
+	got, _ = db.GetSessionByID(session.ID)
+	if got.EndTime != nil || got.BreakMinutes != 0 || got.Note != "open" || len(got.Tags) != 1 {
+		t.Errorf("after undoing the clock-out session = %+v, want it open again", got)
+	}
+
+	if entry, err := db.Undo(); err != nil || entry != nil {
+		t.Errorf("Undo on an empty log = %+v, %v; want nil", entry, err)
+	}
+
+	// Only the most recent changes are kept
+	for i := 0; i < UndoLogSize+5; i++ {
+		got.BreakMinutes = i
+		if err := db.UpdateSession(got); err != nil {
+			t.Fatalf("UpdateSession: %v", err)
+		}
+	}
+	entries, err := db.UndoLog()
+	if err != nil || len(entries) != UndoLogSize || entries[0].Session.BreakMinutes != UndoLogSize+3 {
+		t.Errorf("UndoLog = %d entries, %v; want the last %d, newest first", len(entries), err, UndoLogSize)
+	}
+}
+
+func TestReplaceSessionsIsAllOrNothing(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "test.db"), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	session := func(day int) WorkSession {
+		start := time.Date(2024, 1, day, 9, 0, 0, 0, time.UTC)
+		end := start.Add(8 * time.Hour)
+		return WorkSession{Date: start, StartTime: start, EndTime: &end}
+	}
+	old, kept := session(15), session(16)
+	for _, s := range []*WorkSession{&old, &kept} {
+		if err := db.InsertSession(s); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+	}
+
+	// The second insert fails after the delete: nothing changes
+	clash := session(17)
+	clash.ID = kept.ID
+	if err := db.ReplaceSessions([]string{old.ID}, []WorkSession{session(15), clash}); err == nil {
+		t.Fatal("expected an insert with a taken ID to fail")
+	}
+	if _, err := db.GetSessionByID(old.ID); err != nil {
+		t.Errorf("replaced session after a failed insert: %v, want it kept", err)
+	}
+	if entries, _ := db.UndoLog(); len(entries) != 0 {
+		t.Errorf("undo log after a failed replace = %+v, want it empty", entries)
+	}
+
+	added := []WorkSession{session(15), session(17)}
+	if err := db.ReplaceSessions([]string{old.ID}, added); err != nil {
+		t.Fatalf("ReplaceSessions: %v", err)
+	}
+	if _, err := db.GetSessionByID(old.ID); err != sql.ErrNoRows {
+		t.Errorf("replaced session lookup error = %v, want sql.ErrNoRows", err)
+	}
+	entries, _ := db.UndoLog()
+	if len(entries) != 1 || entries[0].Action != UndoReplace || len(entries[0].Replaced) != 1 || len(entries[0].Imported) != 2 {
+		t.Fatalf("undo log = %+v, want one replace entry", entries)
+	}
+
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if _, err := db.GetSessionByID(old.ID); err != nil {
+		t.Errorf("replaced session after undo: %v, want it back", err)
+	}
+	for _, s := range added {
+		if _, err := db.GetSessionByID(s.ID); err != sql.ErrNoRows {
+			t.Errorf("imported session %s after undo: error %v, want it gone", s.ID, err)
+		}
+	}
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.db")
+	db, err := New(path, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)
+	kept := &WorkSession{Date: start, StartTime: start, Note: "kept"}
+	if err := db.InsertSession(kept); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+
+	// The backup directory doesn't exist yet
+	backup := filepath.Join(dir, "backups", "nested", BackupName(start))
+	if err := db.Backup(backup); err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+	if err := db.Backup(backup); err == nil {
+		t.Error("Backup overwrote an existing file")
+	}
+
+	if err := db.DeleteSession(kept.ID); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+	db.Close()
+
+	if err := Restore(filepath.Join(dir, "missing.db"), path); err == nil {
+		t.Error("Restore accepted a missing backup")
+	}
+	notDB := filepath.Join(dir, "notes.txt")
+	os.WriteFile(notDB, []byte("not a database"), 0644)
+	if err := Restore(notDB, path); err == nil {
+		t.Error("Restore accepted a file that isn't a kairos database")
+	}
+
+	if err := Restore(backup, path); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	db, err = New(path, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if s, err := db.GetSessionByID(kept.ID); err != nil || s.Note != "kept" {
+		t.Errorf("restored session = %+v, %v; want the backed-up session", s, err)
+	}
+}
+
+func TestConcurrentWritersShareFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shared.db")
+	// Two handles stand in for the MCP server and a CLI call
+	first, err := New(path, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+	second, err := New(path, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+
+	const perHandle = 50
+	start := time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)
+	errs := make(chan error, 2*perHandle)
+	var wg sync.WaitGroup
+	for _, db := range []*Database{first, second} {
+		wg.Add(1)
+		go func(db *Database) {
+			defer wg.Done()
+			for i := 0; i < perHandle; i++ {
+				s := &WorkSession{Date: start, StartTime: start.Add(time.Duration(i) * time.Minute)}
+				if err := db.InsertSession(s); err != nil {
+					errs <- err
+					continue
+				}
+				// A logged update reads and writes in one transaction
+				s.Note = "edited"
+				if err := db.UpdateSession(s); err != nil {
+					errs <- err
+				}
+			}
+		}(db)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent write: %v", err)
+	}
+
+	sessions, err := first.GetSessionsInRange(start, start)
+	if err != nil || len(sessions) != 2*perHandle {
+		t.Errorf("sessions = %d, %v; want %d", len(sessions), err, 2*perHandle)
+	}
+}
@@ -0,0 +1,44 @@
+package storage
+
+// Holiday is a day off, such as a public holiday or PTO, that doesn't count
+// against the goal
+type Holiday struct {
+	Date string `json:"date"` // YYYY-MM-DD
+	Name string `json:"name,omitempty"`
+}
+
+// AddHoliday stores date (YYYY-MM-DD) as a holiday, renaming it if it is
+// already one
+func (d *Database) AddHoliday(date, name string) error {
+	_, err := d.db.Exec(`INSERT OR REPLACE INTO holidays (date, name) VALUES (?, ?)`, date, name)
+	return err
+}
+
+// RemoveHoliday deletes the holiday on date and reports whether there was one
+func (d *Database) RemoveHoliday(date string) (bool, error) {
+	result, err := d.db.Exec(`DELETE FROM holidays WHERE date = ?`, date)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// ListHolidays returns the stored holidays in date order
+func (d *Database) ListHolidays() ([]Holiday, error) {
+	rows, err := d.db.Query(`SELECT date, name FROM holidays ORDER BY date`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holidays []Holiday
+	for rows.Next() {
+		var h Holiday
+		if err := rows.Scan(&h.Date, &h.Name); err != nil {
+			return nil, err
+		}
+		holidays = append(holidays, h)
+	}
+	return holidays, rows.Err()
+}
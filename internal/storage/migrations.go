@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+)
+
+// migration changes the schema of an existing database. Migrations run in
+// order inside their own transaction; the one at index i brings the schema
+// to version i+1.
+type migration func(*sql.Tx) error
+
+// migrations must only be appended to: the schema_version stored in the meta
+// table is the number already applied
+var migrations = []migration{
+	// 1: sessions belong to a project
+	func(tx *sql.Tx) error {
+		return addColumn(tx, "work_sessions", "project", "TEXT NOT NULL DEFAULT ''")
+	},
+	// 2: sessions remember the zone they were recorded in
+	func(tx *sql.Tx) error {
+		return addColumn(tx, "work_sessions", "tz", "TEXT NOT NULL DEFAULT ''")
+	},
+	// 3: comma-joined session tags
+	func(tx *sql.Tx) error {
+		return addColumn(tx, "work_sessions", "tags", "TEXT NOT NULL DEFAULT ''")
+	},
+	// 4: MCP memories can expire
+	func(tx *sql.Tx) error {
+		return addColumn(tx, "memories", "expires_at", "TEXT")
+	},
+}
+
+// SchemaVersion returns the number of migrations applied to the database
+func (d *Database) SchemaVersion() (int, error) {
+	var value string
+	err := d.db.QueryRow(`SELECT value FROM meta WHERE key = 'schema_version'`).Scan(&value)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(value)
+}
+
+// migrate applies the migrations the database hasn't seen yet. A failing
+// migration is rolled back and leaves the version at the last one that
+// succeeded.
+func (d *Database) migrate(list []migration) error {
+	if _, err := d.db.Exec(`CREATE TABLE IF NOT EXISTS meta (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("failed to create meta table: %w", err)
+	}
+
+	version, err := d.SchemaVersion()
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version > len(list) {
+		return fmt.Errorf("database schema version %d is newer than this build supports (%d)", version, len(list))
+	}
+
+	for i := version; i < len(list); i++ {
+		tx, err := d.db.Begin()
+		if err != nil {
+			return err
+		}
+		if err := list[i](tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d failed: %w", i+1, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO meta (key, value) VALUES ('schema_version', ?)
+			ON CONFLICT(key) DO UPDATE SET value = excluded.value`, strconv.Itoa(i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d failed: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %d failed: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// addColumn adds a column to an existing table if it is missing. Databases
+// created before migrations were tracked may already have it.
+func addColumn(tx *sql.Tx, table, column, definition string) error {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add %s.%s: %w", table, column, err)
+	}
+	return nil
+}
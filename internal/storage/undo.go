@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Undo log actions: the change made to the session whose prior state is kept
+const (
+	UndoUpdate = "update"
+	UndoDelete = "delete"
+	// UndoReplace is a range replaced by an import: undoing it deletes the
+	// imported sessions and brings the replaced ones back
+	UndoReplace = "replace"
+)
+
+// UndoLogSize is how many changes the undo log keeps
+const UndoLogSize = 20
+
+// UndoEntry is one logged change and the session as it was before it
+type UndoEntry struct {
+	ID      int64
+	Action  string
+	Session WorkSession
+	// Replaced and Imported are set for UndoReplace instead of Session: the
+	// deleted sessions and the IDs of the sessions inserted in their place
+	Replaced  []WorkSession
+	Imported  []string
+	CreatedAt time.Time
+}
+
+// replacement is how an UndoReplace entry is stored
+type replacement struct {
+	Replaced []WorkSession `json:"replaced"`
+	Imported []string      `json:"imported"`
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// withUndo runs change in a transaction after logging the current state of
+// session id
+func (d *Database) withUndo(action, id string, change func(tx *sql.Tx) error) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := d.logCurrent(tx, action, id); err != nil {
+		return err
+	}
+	if err := change(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateSessions saves sessions in one transaction, each with its previous
+// state in the undo log. If any update fails, none is saved.
+func (d *Database) UpdateSessions(sessions []WorkSession) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i := range sessions {
+		if err := d.logCurrent(tx, UndoUpdate, sessions[i].ID); err != nil {
+			return err
+		}
+		if err := d.updateSession(tx, &sessions[i]); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ReplaceSessions deletes the sessions with the remove IDs and inserts add
+// in one transaction, logged as a single undo entry that reverts both. If
+// any step fails, nothing changes.
+func (d *Database) ReplaceSessions(remove []string, add []WorkSession) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var record replacement
+	for _, id := range remove {
+		session, err := d.loadSession(tx, id)
+		if err != nil {
+			return err
+		}
+		if session == nil {
+			continue
+		}
+		if _, err := tx.Exec("DELETE FROM work_sessions WHERE id = ?", id); err != nil {
+			return err
+		}
+		record.Replaced = append(record.Replaced, *session)
+	}
+	for i := range add {
+		if err := d.insertSession(tx, &add[i]); err != nil {
+			return err
+		}
+		record.Imported = append(record.Imported, add[i].ID)
+	}
+	if err := logUndo(tx, UndoReplace, "", record); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// logCurrent logs the stored state of session id before action. Nothing is
+// logged when the session doesn't exist.
+func (d *Database) logCurrent(tx *sql.Tx, action, id string) error {
+	session, err := d.loadSession(tx, id)
+	if err != nil || session == nil {
+		return err
+	}
+	return logUndo(tx, action, session.ID, session)
+}
+
+// loadSession reads session id within tx, or returns nil when it doesn't
+// exist
+func (d *Database) loadSession(tx *sql.Tx, id string) (*WorkSession, error) {
+	var session WorkSession
+	var dateStr, startTimeStr, endTime sql.NullString
+	var tags string
+	err := tx.QueryRow(
+		`SELECT id, date, start_time, end_time, break_minutes, note, project, tz, tags
+		 FROM work_sessions WHERE id = ?`,
+		id,
+	).Scan(&session.ID, &dateStr, &startTimeStr, &endTime, &session.BreakMinutes, &session.Note, &session.Project, &session.TimeZone, &tags)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	d.populateSessionTimes(&session, dateStr, startTimeStr, endTime)
+	session.Tags = splitTags(tags)
+	return &session, nil
+}
+
+// logUndo records the state before action on session id, a session or a
+// replacement, and drops entries past UndoLogSize
+func logUndo(tx *sql.Tx, action, id string, before interface{}) error {
+	data, err := json.Marshal(before)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO undo_log (action, session_id, session, created_at) VALUES (?, ?, ?, ?)`,
+		action, id, string(data), time.Now().UTC().Format("2006-01-02T15:04:05"),
+	); err != nil {
+		return err
+	}
+	_, err = tx.Exec(
+		`DELETE FROM undo_log WHERE id NOT IN (SELECT id FROM undo_log ORDER BY id DESC LIMIT ?)`,
+		UndoLogSize,
+	)
+	return err
+}
+
+// UndoLog returns the logged changes, most recent first
+func (d *Database) UndoLog() ([]UndoEntry, error) {
+	rows, err := d.db.Query(`SELECT id, action, session, created_at FROM undo_log ORDER BY id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []UndoEntry
+	for rows.Next() {
+		entry, err := scanUndoEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, rows.Err()
+}
+
+// Undo reverts the most recent logged change: a deleted session is inserted
+// again under its original ID and an updated one gets its old values back.
+// It returns the reverted entry, or nil when the log is empty.
+func (d *Database) Undo() (*UndoEntry, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	entry, err := scanUndoEntry(tx.QueryRow(
+		`SELECT id, action, session, created_at FROM undo_log ORDER BY id DESC LIMIT 1`,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch entry.Action {
+	case UndoDelete:
+		err = d.insertSession(tx, &entry.Session)
+	case UndoUpdate:
+		err = d.updateSession(tx, &entry.Session)
+	case UndoReplace:
+		err = d.revertReplace(tx, entry)
+	default:
+		err = fmt.Errorf("unknown undo action %q", entry.Action)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`DELETE FROM undo_log WHERE id = ?`, entry.ID); err != nil {
+		return nil, err
+	}
+	return entry, tx.Commit()
+}
+
+// revertReplace deletes the sessions an UndoReplace entry imported and
+// inserts the ones it replaced
+func (d *Database) revertReplace(tx *sql.Tx, entry *UndoEntry) error {
+	for _, id := range entry.Imported {
+		if _, err := tx.Exec("DELETE FROM work_sessions WHERE id = ?", id); err != nil {
+			return err
+		}
+	}
+	for i := range entry.Replaced {
+		if err := d.insertSession(tx, &entry.Replaced[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanUndoEntry(row interface{ Scan(...interface{}) error }) (*UndoEntry, error) {
+	var entry UndoEntry
+	var data, createdAt string
+	if err := row.Scan(&entry.ID, &entry.Action, &data, &createdAt); err != nil {
+		return nil, err
+	}
+	var err error
+	if entry.Action == UndoReplace {
+		var record replacement
+		err = json.Unmarshal([]byte(data), &record)
+		entry.Replaced, entry.Imported = record.Replaced, record.Imported
+	} else {
+		err = json.Unmarshal([]byte(data), &entry.Session)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("undo entry %d: %w", entry.ID, err)
+	}
+	entry.CreatedAt, _ = time.Parse("2006-01-02T15:04:05", createdAt)
+	return &entry, nil
+}
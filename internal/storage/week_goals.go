@@ -0,0 +1,35 @@
+package storage
+
+import "database/sql"
+
+// SetWeekGoal stores goal as the hours due in the week starting on
+// weekStart (YYYY-MM-DD), replacing any earlier goal for it
+func (d *Database) SetWeekGoal(weekStart string, goal float64) error {
+	_, err := d.db.Exec(`INSERT OR REPLACE INTO week_goals (week_start, goal) VALUES (?, ?)`, weekStart, goal)
+	return err
+}
+
+// GetWeekGoal returns the goal stored for the week starting on weekStart,
+// and false when there is none
+func (d *Database) GetWeekGoal(weekStart string) (float64, bool, error) {
+	var goal float64
+	err := d.db.QueryRow(`SELECT goal FROM week_goals WHERE week_start = ?`, weekStart).Scan(&goal)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return goal, true, nil
+}
+
+// ClearWeekGoal deletes the goal stored for the week starting on weekStart
+// and reports whether there was one
+func (d *Database) ClearWeekGoal(weekStart string) (bool, error) {
+	result, err := d.db.Exec(`DELETE FROM week_goals WHERE week_start = ?`, weekStart)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
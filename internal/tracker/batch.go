@@ -0,0 +1,35 @@
+package tracker
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/kairos/internal/storage"
+)
+
+// ResolveSessionIDs looks up each full ID or prefix (as shown by sessions,
+// usually 8 characters). Sessions matched more than once are returned once;
+// IDs that match nothing are returned in missing.
+func (t *Tracker) ResolveSessionIDs(ids []string) (found []storage.WorkSession, missing []string, err error) {
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		session, err := t.db.GetSessionByPrefix(id)
+		if errors.Is(err, sql.ErrNoRows) {
+			missing = append(missing, id)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if !seen[session.ID] {
+			seen[session.ID] = true
+			found = append(found, *session)
+		}
+	}
+	return found, missing, nil
+}
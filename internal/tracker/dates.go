@@ -0,0 +1,34 @@
+package tracker
+
+import (
+	"fmt"
+	"time"
+)
+
+// ParseDateInput parses a user-supplied date (YYYY-MM-DD, "Jan 2", "1/2")
+// in loc. Dates without a year are taken to be in the current year.
+func ParseDateInput(input string, loc *time.Location) (time.Time, error) {
+	return parseDateInputAt(input, loc, time.Now())
+}
+
+// parseDateInputAt is ParseDateInput with the year taken from now
+func parseDateInputAt(input string, loc *time.Location, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", input, loc); err == nil {
+		return t, nil
+	}
+	year := now.In(loc).Year()
+	for _, format := range []string{"Jan 2", "Jan 02", "1/2"} {
+		t, err := time.ParseInLocation(format, input, loc)
+		if err != nil {
+			continue
+		}
+		// Formats without a year parse to year 0; "Feb 29" only exists in
+		// leap years
+		dated := time.Date(year, t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		if dated.Day() != t.Day() {
+			return time.Time{}, fmt.Errorf("invalid date: %s is not a day in %d", input, year)
+		}
+		return dated, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date format: %s (use YYYY-MM-DD, \"Jan 2\" or \"1/2\")", input)
+}
@@ -0,0 +1,51 @@
+package tracker
+
+import "time"
+
+// SetFlexStart sets the day the flex balance starts counting from; a zero
+// time counts from the first session in the database
+func (t *Tracker) SetFlexStart(start time.Time) {
+	t.flexStart = start
+}
+
+// FlexStart returns the first day of the week the flex balance starts in,
+// and false when there is nothing to count from yet
+func (t *Tracker) FlexStart() (time.Time, bool, error) {
+	start := t.flexStart
+	if start.IsZero() {
+		oldest, err := t.db.GetOldestSessionDate()
+		if err != nil || oldest == nil {
+			return time.Time{}, false, err
+		}
+		start = *oldest
+	}
+	return weekStartDay(start.In(t.now().Location()), t.weekStartDay), true, nil
+}
+
+// weekStartDay is getWeekStartOn at midnight
+func weekStartDay(t time.Time, startDay time.Weekday) time.Time {
+	day := getWeekStartOn(t, startDay)
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+}
+
+// GetFlexBalance sums each completed week's hours minus its effective goal,
+// from the flex start to the last week ending before upTo's week. Overtime
+// raises the balance, short weeks draw it down. Sessions already cleaned
+// into the archive are not counted, so start after them.
+func (t *Tracker) GetFlexBalance(upTo time.Time) (float64, error) {
+	start, ok, err := t.FlexStart()
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	current := weekStartDay(upTo.In(start.Location()), t.weekStartDay)
+	var balance float64
+	for weekStart := start; weekStart.Before(current); weekStart = weekStart.AddDate(0, 0, 7) {
+		week, err := t.computeWeekProgress(weekStart)
+		if err != nil {
+			return 0, err
+		}
+		balance += week.TotalHours - week.Goal
+	}
+	return balance, nil
+}
@@ -0,0 +1,50 @@
+package tracker
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kairos/internal/storage"
+)
+
+// Gap is idle time between one session's end and the next session's start
+type Gap struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the length of the gap
+func (g Gap) Duration() time.Duration {
+	return g.End.Sub(g.Start)
+}
+
+// FindGaps returns the gaps between consecutive sessions ordered by start
+// time. Overlapping sessions and active sessions produce no gap.
+func FindGaps(sessions []storage.WorkSession) []Gap {
+	ordered := make([]storage.WorkSession, len(sessions))
+	copy(ordered, sessions)
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].StartTime.Before(ordered[j].StartTime)
+	})
+
+	var gaps []Gap
+	var lastEnd *time.Time
+	for _, s := range ordered {
+		if lastEnd != nil && s.StartTime.After(*lastEnd) {
+			gaps = append(gaps, Gap{Start: *lastEnd, End: s.StartTime})
+		}
+		if s.EndTime == nil {
+			break
+		}
+		if lastEnd == nil || s.EndTime.After(*lastEnd) {
+			end := *s.EndTime
+			lastEnd = &end
+		}
+	}
+	return gaps
+}
+
+// GetDaySessions returns the sessions for the day containing date
+func (t *Tracker) GetDaySessions(date time.Time) ([]storage.WorkSession, error) {
+	return t.db.GetSessionsInRange(date, date)
+}
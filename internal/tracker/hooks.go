@@ -0,0 +1,27 @@
+package tracker
+
+import (
+	"github.com/kairos/internal/hooks"
+	"github.com/kairos/internal/storage"
+)
+
+// Hooks are the commands run around clock actions (empty = none)
+type Hooks struct {
+	Runner       *hooks.Runner
+	PreClockin   string
+	PostClockout string
+}
+
+// SetHooks runs h.PreClockin before each clock-in is recorded and
+// h.PostClockout after each clock-out, whether it comes from a command, the
+// automatic clock-out or the MCP track tool
+func (t *Tracker) SetHooks(h Hooks) {
+	t.hooks = h
+}
+
+// runHook runs command for event with s, if a runner is set
+func (t *Tracker) runHook(event, command string, s *storage.WorkSession) {
+	if t.hooks.Runner != nil {
+		t.hooks.Runner.Run(event, command, s)
+	}
+}
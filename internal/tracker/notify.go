@@ -0,0 +1,85 @@
+package tracker
+
+import (
+	"fmt"
+	"time"
+)
+
+// NotifyLead is how long before the day's target or an auto clock-out
+// ShouldNotify gives notice
+const NotifyLead = 30 * time.Minute
+
+// SetAutoClockoutMinutes sets how long a session may run before it is due to
+// be clocked out automatically (0 = never), for ShouldNotify's warning
+func (t *Tracker) SetAutoClockoutMinutes(minutes int) {
+	t.autoClockout = minutes
+}
+
+// ShouldNotify reports whether a notification is due at now while clocked
+// in, and the message to show: the running session is within NotifyLead of
+// the auto clock-out limit, today's hours are within NotifyLead of the day's
+// target, or they reached it. Each notice is given once (per session for the
+// auto clock-out, per day for the target), so a watcher can poll freely.
+func (t *Tracker) ShouldNotify(now time.Time) (reason string, ok bool) {
+	active, err := t.GetActiveSession()
+	if err != nil || active == nil {
+		return "", false
+	}
+	if t.notified == nil {
+		t.notified = make(map[string]bool)
+	}
+	once := func(key string) bool {
+		if t.notified[key] {
+			return false
+		}
+		t.notified[key] = true
+		return true
+	}
+
+	if t.autoClockout > 0 {
+		left := active.StartTime.Add(time.Duration(t.autoClockout) * time.Minute).Sub(now)
+		if left <= NotifyLead && once("autoclockout:"+active.ID) {
+			if left <= 0 {
+				return fmt.Sprintf("Session has passed the %s auto clock-out limit", minutes(t.autoClockout)), true
+			}
+			return fmt.Sprintf("Auto clock-out in %s", minutes(int(left.Minutes()+0.5))), true
+		}
+	}
+
+	goal := t.DayTarget(now)
+	if goal <= 0 {
+		return "", false
+	}
+	sessions, err := t.db.GetSessionsInRange(now, now)
+	if err != nil {
+		return "", false
+	}
+	var hours float64
+	for _, s := range t.SessionsOnDays(sessions, now, now) {
+		h, _ := t.sessionHours(s, now)
+		hours += h
+	}
+
+	day := now.Format("2006-01-02")
+	switch left := time.Duration((goal - hours) * float64(time.Hour)); {
+	case left <= 0:
+		// Reaching the target covers the nearly-there notice too
+		t.notified["target-near:"+day] = true
+		if once("target:" + day) {
+			return fmt.Sprintf("Reached today's %.2fh target", goal), true
+		}
+	case left <= NotifyLead:
+		if once("target-near:" + day) {
+			return fmt.Sprintf("%s left to today's %.2fh target", minutes(int(left.Minutes()+0.5)), goal), true
+		}
+	}
+	return "", false
+}
+
+// minutes formats n as "1 minute" or "n minutes"
+func minutes(n int) string {
+	if n == 1 {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%d minutes", n)
+}
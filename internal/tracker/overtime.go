@@ -0,0 +1,64 @@
+package tracker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kairos/internal/storage"
+)
+
+// WeekOvertime is one week's hours against its goal: the weekly goal less
+// holidays, or the goal set for that week
+type WeekOvertime struct {
+	WeekStart    time.Time
+	WeekEnd      time.Time
+	Hours        float64
+	Goal         float64
+	Overtime     float64 // Hours - Goal; negative when short
+	RunningTotal float64 // sum of Overtime up to and including this week
+}
+
+// GetOvertime returns every week overlapping start..end with its overtime and
+// the running total. Weeks are whole weeks from the configured week start.
+func (t *Tracker) GetOvertime(start, end time.Time) ([]WeekOvertime, error) {
+	if end.Before(start) {
+		return nil, fmt.Errorf("end date %s is before start date %s", end.Format("2006-01-02"), start.Format("2006-01-02"))
+	}
+
+	first := getWeekStartOn(start, t.weekStartDay)
+	last := getWeekStartOn(end, t.weekStartDay).AddDate(0, 0, 6)
+	sessions, err := t.db.GetSessionsInRange(first, last)
+	if err != nil {
+		return nil, err
+	}
+	return t.OvertimeFromSessions(sessions, start, end)
+}
+
+// OvertimeFromSessions is GetOvertime over sessions the caller already
+// loaded, such as live sessions merged with archived ones. Each week is
+// totalled like the week view.
+func (t *Tracker) OvertimeFromSessions(sessions []storage.WorkSession, start, end time.Time) ([]WeekOvertime, error) {
+	if end.Before(start) {
+		return nil, fmt.Errorf("end date %s is before start date %s", end.Format("2006-01-02"), start.Format("2006-01-02"))
+	}
+
+	var weeks []WeekOvertime
+	var running float64
+	for weekStart := getWeekStartOn(start, t.weekStartDay); !weekStart.After(end); weekStart = weekStart.AddDate(0, 0, 7) {
+		progress, err := t.weekProgressFromSessions(weekStart, sessions)
+		if err != nil {
+			return nil, err
+		}
+		overtime := progress.TotalHours - progress.Goal
+		running += overtime
+		weeks = append(weeks, WeekOvertime{
+			WeekStart:    weekStart,
+			WeekEnd:      progress.WeekEnd,
+			Hours:        progress.TotalHours,
+			Goal:         progress.Goal,
+			Overtime:     overtime,
+			RunningTotal: running,
+		})
+	}
+	return weeks, nil
+}
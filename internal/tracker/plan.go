@@ -0,0 +1,33 @@
+package tracker
+
+// WeekPlan is a what-if projection of the week's total
+type WeekPlan struct {
+	CurrentHours   float64
+	DailyHours     float64
+	Days           int
+	ProjectedHours float64
+	Goal           float64
+	Surplus        float64 // ProjectedHours - Goal; negative is a deficit
+}
+
+// MeetsGoal reports whether the projected total reaches the goal
+func (p WeekPlan) MeetsGoal() bool {
+	return p.Surplus >= 0
+}
+
+// PlanWeek projects the week's total if dailyHours are worked on each of the
+// next days days
+func PlanWeek(currentHours, goal, dailyHours float64, days int) WeekPlan {
+	if days < 0 {
+		days = 0
+	}
+	projected := currentHours + dailyHours*float64(days)
+	return WeekPlan{
+		CurrentHours:   currentHours,
+		DailyHours:     dailyHours,
+		Days:           days,
+		ProjectedHours: projected,
+		Goal:           goal,
+		Surplus:        projected - goal,
+	}
+}
@@ -0,0 +1,78 @@
+package tracker
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kairos/internal/storage"
+)
+
+// GetProjectBreakdown returns completed hours per project for sessions in
+// start..end, most hours first. Sessions without a project are grouped under
+// the empty name.
+func (t *Tracker) GetProjectBreakdown(start, end time.Time) ([]storage.ProjectSummary, error) {
+	if end.Before(start) {
+		return nil, fmt.Errorf("end date %s is before start date %s", end.Format("2006-01-02"), start.Format("2006-01-02"))
+	}
+
+	sessions, err := t.db.GetSessionsInRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	sessions = t.SessionsOnDays(sessions, start, end)
+
+	byName := make(map[string]*storage.ProjectSummary)
+	var order []string
+	for _, s := range sessions {
+		hours, complete := t.sessionHours(s, t.now())
+		if !complete {
+			continue
+		}
+		summary, ok := byName[s.Project]
+		if !ok {
+			summary = &storage.ProjectSummary{Name: s.Project}
+			byName[s.Project] = summary
+			order = append(order, s.Project)
+		}
+		summary.Hours += hours
+		summary.Sessions++
+	}
+
+	breakdown := make([]storage.ProjectSummary, 0, len(order))
+	for _, name := range order {
+		breakdown = append(breakdown, *byName[name])
+	}
+	sort.SliceStable(breakdown, func(i, j int) bool {
+		return breakdown[i].Hours > breakdown[j].Hours
+	})
+	return breakdown, nil
+}
+
+// ListProjects returns registered and in-use projects with their all-time
+// completed hours, counted like every other total, sorted by name
+func (t *Tracker) ListProjects() ([]storage.ProjectSummary, error) {
+	names, err := t.db.ProjectNames()
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := t.db.GetProjectSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make([]storage.ProjectSummary, len(names))
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		projects[i].Name = name
+		index[name] = i
+	}
+	for _, s := range sessions {
+		hours, complete := t.sessionHours(s, t.now())
+		if i, ok := index[s.Project]; ok && complete {
+			projects[i].Hours += hours
+			projects[i].Sessions++
+		}
+	}
+	return projects, nil
+}
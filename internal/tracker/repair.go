@@ -0,0 +1,144 @@
+package tracker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kairos/internal/storage"
+	"github.com/kairos/internal/work"
+)
+
+// Repair kinds reported by FindRepairs
+const (
+	RepairStaleOpen    = "stale-open"    // open longer than the stale session threshold
+	RepairZeroDuration = "zero-duration" // end at or before start
+	RepairLongBreak    = "long-break"    // break negative or not shorter than the session
+	RepairDate         = "date"          // date column disagrees with start time
+)
+
+// Repair is one proposed fix to a stored session
+type Repair struct {
+	Kind      string
+	SessionID string
+	Before    string
+	After     string
+	Delete    bool
+
+	apply func(*storage.WorkSession)
+}
+
+// FindRepairs scans every stored session for common data issues. A session
+// that will be deleted gets no other repairs.
+func (t *Tracker) FindRepairs() ([]Repair, error) {
+	oldest, err := t.db.GetOldestSessionDate()
+	if err != nil {
+		return nil, err
+	}
+	if oldest == nil {
+		return nil, nil
+	}
+
+	now := t.now()
+	sessions, err := t.db.GetSessionsInRange(*oldest, now)
+	if err != nil {
+		return nil, err
+	}
+
+	dailyTarget := t.weeklyGoal / float64(t.rules.DaysPerWeek())
+
+	var repairs []Repair
+	deleted := make(map[string]bool)
+	for _, s := range sessions {
+		if s.EndTime == nil {
+			if !t.OpenSessionAge(&s).Stale {
+				continue
+			}
+			breakMinutes := work.GetBreakMinutesForDay(t.rules, s.StartTime)
+			end := s.StartTime.Add(time.Duration(dailyTarget*float64(time.Hour)) + time.Duration(breakMinutes)*time.Minute)
+			dayEnd := time.Date(s.StartTime.Year(), s.StartTime.Month(), s.StartTime.Day(), 23, 59, 0, 0, s.StartTime.Location())
+			if end.After(dayEnd) {
+				end = dayEnd
+			}
+			if ValidateBreak(s.StartTime, end, breakMinutes) != nil {
+				breakMinutes = 0
+			}
+			repairs = append(repairs, Repair{
+				Kind:      RepairStaleOpen,
+				SessionID: s.ID,
+				Before:    fmt.Sprintf("open since %s", s.StartTime.Format("2006-01-02 15:04")),
+				After:     fmt.Sprintf("closed at %s with %dmin break", end.Format("2006-01-02 15:04"), breakMinutes),
+				apply: func(ws *storage.WorkSession) {
+					ws.EndTime = &end
+					ws.BreakMinutes = breakMinutes
+				},
+			})
+			continue
+		}
+
+		if !s.EndTime.After(s.StartTime) {
+			deleted[s.ID] = true
+			repairs = append(repairs, Repair{
+				Kind:      RepairZeroDuration,
+				SessionID: s.ID,
+				Before:    fmt.Sprintf("%s %s-%s", s.StartTime.Format("2006-01-02"), s.StartTime.Format("15:04"), s.EndTime.Format("15:04")),
+				After:     "deleted",
+				Delete:    true,
+			})
+			continue
+		}
+
+		if ValidateBreak(s.StartTime, *s.EndTime, s.BreakMinutes) != nil {
+			clamped := work.GetBreakMinutesForDay(t.rules, s.StartTime)
+			if ValidateBreak(s.StartTime, *s.EndTime, clamped) != nil {
+				clamped = 0
+			}
+			repairs = append(repairs, Repair{
+				Kind:      RepairLongBreak,
+				SessionID: s.ID,
+				Before:    fmt.Sprintf("%dmin break in a %.0fmin session", s.BreakMinutes, s.EndTime.Sub(s.StartTime).Minutes()),
+				After:     fmt.Sprintf("%dmin break", clamped),
+				apply: func(ws *storage.WorkSession) {
+					ws.BreakMinutes = clamped
+				},
+			})
+		}
+	}
+
+	mismatches, err := t.db.FindDateMismatches()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mismatches {
+		if deleted[m.ID] {
+			continue
+		}
+		repairs = append(repairs, Repair{
+			Kind:      RepairDate,
+			SessionID: m.ID,
+			Before:    "date " + m.Stored,
+			After:     "date " + m.Expected,
+			// Saving the session rewrites its date from the start time
+			apply: func(*storage.WorkSession) {},
+		})
+	}
+
+	return repairs, nil
+}
+
+// ApplyRepair applies a repair returned by FindRepairs to the current state
+// of its session
+func (t *Tracker) ApplyRepair(r Repair) error {
+	if r.Delete {
+		return t.db.DeleteSession(r.SessionID)
+	}
+
+	session, err := t.db.GetSessionByID(r.SessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return fmt.Errorf("session %s not found", r.SessionID)
+	}
+	r.apply(session)
+	return t.db.UpdateSession(session)
+}
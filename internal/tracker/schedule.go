@@ -0,0 +1,121 @@
+package tracker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kairos/internal/storage"
+)
+
+// ScheduleStats summarizes when work typically happens
+type ScheduleStats struct {
+	DaysCounted int
+	AvgStart    time.Duration // offset from local midnight of the first clock-in
+	AvgEnd      time.Duration // offset from local midnight of the last clock-out
+	AvgDayHours float64       // average net hours per worked day
+}
+
+// ComputeScheduleStats derives average start/end times and day length from
+// completed sessions. Each day contributes its earliest start and latest end.
+func ComputeScheduleStats(sessions []storage.WorkSession) ScheduleStats {
+	type dayBounds struct {
+		start, end time.Duration
+		hours      float64
+	}
+	days := make(map[string]*dayBounds)
+	var order []string
+
+	for _, s := range sessions {
+		hours, complete := storage.SessionNetHours(s, time.Now())
+		if !complete {
+			continue
+		}
+		key := s.Date.Format("2006-01-02")
+		start := sinceMidnight(s.StartTime, s.Date)
+		end := sinceMidnight(*s.EndTime, s.Date)
+
+		d, ok := days[key]
+		if !ok {
+			days[key] = &dayBounds{start: start, end: end, hours: hours}
+			order = append(order, key)
+			continue
+		}
+		if start < d.start {
+			d.start = start
+		}
+		if end > d.end {
+			d.end = end
+		}
+		d.hours += hours
+	}
+
+	stats := ScheduleStats{DaysCounted: len(order)}
+	if stats.DaysCounted == 0 {
+		return stats
+	}
+
+	var startSum, endSum time.Duration
+	var hoursSum float64
+	for _, key := range order {
+		startSum += days[key].start
+		endSum += days[key].end
+		hoursSum += days[key].hours
+	}
+	n := time.Duration(stats.DaysCounted)
+	stats.AvgStart = startSum / n
+	stats.AvgEnd = endSum / n
+	stats.AvgDayHours = hoursSum / float64(stats.DaysCounted)
+	return stats
+}
+
+// sinceMidnight measures t from the start of day, so sessions ending after
+// midnight report offsets beyond 24h instead of wrapping
+func sinceMidnight(t, day time.Time) time.Duration {
+	midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, t.Location())
+	return t.Sub(midnight)
+}
+
+// FormatClock renders a midnight offset as HH:MM
+func FormatClock(d time.Duration) string {
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%02d:%02d", int(d.Hours())%24, int(d.Minutes())%60)
+}
+
+// WeekdayStats is the hours worked on one day of the week
+type WeekdayStats struct {
+	Weekday  time.Weekday
+	Days     int     // distinct dates worked on this weekday
+	Hours    float64 // total net hours
+	AvgHours float64 // Hours / Days
+}
+
+// ComputeWeekdayStats breaks completed sessions down by day of week, ordered
+// Monday to Sunday
+func ComputeWeekdayStats(sessions []storage.WorkSession) []WeekdayStats {
+	stats := make([]WeekdayStats, 7)
+	seen := make(map[string]bool)
+	for i := range stats {
+		stats[i].Weekday = time.Weekday((i + 1) % 7)
+	}
+
+	for _, s := range sessions {
+		hours, complete := storage.SessionNetHours(s, time.Now())
+		if !complete {
+			continue
+		}
+		idx := (int(s.Date.Weekday()) + 6) % 7
+		stats[idx].Hours += hours
+		key := s.Date.Format("2006-01-02")
+		if !seen[key] {
+			seen[key] = true
+			stats[idx].Days++
+		}
+	}
+
+	for i := range stats {
+		if stats[i].Days > 0 {
+			stats[i].AvgHours = stats[i].Hours / float64(stats[i].Days)
+		}
+	}
+	return stats
+}
@@ -0,0 +1,68 @@
+package tracker
+
+import (
+	"time"
+
+	"github.com/kairos/internal/storage"
+)
+
+// SplitSessionAcrossDays cuts a completed session at each local midnight so
+// every piece lies on one calendar day, e.g. a 22:00-06:00 night shift
+// becomes 22:00-00:00 and 00:00-06:00. The break is shared out in proportion
+// to each piece's length. Pieces keep the session's ID, note and tags; open
+// sessions and sessions within one day are returned whole.
+func SplitSessionAcrossDays(s storage.WorkSession) []storage.WorkSession {
+	if s.EndTime == nil {
+		return []storage.WorkSession{s}
+	}
+	loc := s.StartTime.Location()
+	end := s.EndTime.In(loc)
+	total := end.Sub(s.StartTime)
+
+	var pieces []storage.WorkSession
+	breakLeft := s.BreakMinutes
+	for start := s.StartTime; start.Before(end); {
+		day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+		pieceEnd := day.AddDate(0, 0, 1)
+		if !pieceEnd.Before(end) {
+			pieceEnd = end
+		}
+
+		piece := s
+		piece.Date = day
+		piece.StartTime = start
+		piece.EndTime = &pieceEnd
+		if pieceEnd.Equal(end) {
+			// The last piece takes what rounding left of the break
+			piece.BreakMinutes = breakLeft
+		} else {
+			piece.BreakMinutes = int(float64(s.BreakMinutes)*float64(pieceEnd.Sub(start))/float64(total) + 0.5)
+			breakLeft -= piece.BreakMinutes
+		}
+		pieces = append(pieces, piece)
+		start = pieceEnd
+	}
+	if len(pieces) == 0 {
+		return []storage.WorkSession{s}
+	}
+	return pieces
+}
+
+// SetSplitAtMidnight makes progress totals and range queries attribute a
+// session's hours to each day it spans instead of the day it started
+func (t *Tracker) SetSplitAtMidnight(split bool) {
+	t.splitAtMidnight = split
+}
+
+// SessionsOnDays keeps the sessions, or with SetSplitAtMidnight the pieces
+// of sessions, that start on the days from start to end
+func (t *Tracker) SessionsOnDays(sessions []storage.WorkSession, start, end time.Time) []storage.WorkSession {
+	if t.splitAtMidnight {
+		var pieces []storage.WorkSession
+		for _, s := range sessions {
+			pieces = append(pieces, SplitSessionAcrossDays(s)...)
+		}
+		sessions = pieces
+	}
+	return storage.StartedBetween(sessions, start, end)
+}
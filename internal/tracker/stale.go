@@ -0,0 +1,98 @@
+package tracker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kairos/internal/storage"
+	"github.com/kairos/internal/work"
+)
+
+// DefaultStaleSessionHours is how long a session may stay open before it is
+// treated as a forgotten clock-out
+const DefaultStaleSessionHours = 16
+
+// OpenSessionAge describes how long an open session has been running.
+// Elapsed is capped at the stale threshold so a forgotten session never
+// shows a runaway value; Stale reports whether the cap was hit.
+type OpenSessionAge struct {
+	Elapsed time.Duration
+	Actual  time.Duration
+	Stale   bool
+	Warning string
+	Fix     string
+}
+
+// SetStaleSessionHours sets the age after which an open session is flagged
+// as forgotten. Zero or less disables the check.
+func (t *Tracker) SetStaleSessionHours(hours float64) {
+	t.staleHours = hours
+}
+
+// OpenSessionAge reports the elapsed time of the open session s as of now
+func (t *Tracker) OpenSessionAge(s *storage.WorkSession) OpenSessionAge {
+	return CheckOpenSession(s, t.now(), t.staleHours)
+}
+
+// CheckOpenSession measures an open session against thresholdHours (zero or
+// less disables the cap) and, when it has run longer, suggests how to close it
+func CheckOpenSession(s *storage.WorkSession, now time.Time, thresholdHours float64) OpenSessionAge {
+	actual := now.Sub(s.StartTime)
+	if actual < 0 {
+		actual = 0
+	}
+	age := OpenSessionAge{Elapsed: actual, Actual: actual}
+	threshold := time.Duration(thresholdHours * float64(time.Hour))
+	if threshold <= 0 || actual <= threshold {
+		return age
+	}
+
+	age.Elapsed = threshold
+	age.Stale = true
+	if days := int(actual.Hours() / 24); days >= 1 {
+		unit := "days"
+		if days == 1 {
+			unit = "day"
+		}
+		age.Warning = fmt.Sprintf("session open for %d %s — did you forget to clock out?", days, unit)
+	} else {
+		age.Warning = fmt.Sprintf("session open for %dh — did you forget to clock out?", int(actual.Hours()))
+	}
+	id := s.ID
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	age.Fix = fmt.Sprintf("kairos edit %s --end HH:MM (started %s) or kairos doctor --fix",
+		id, s.StartTime.Format("Mon Jan 2 15:04"))
+	return age
+}
+
+// AutoCloseStaleSessions clocks out the running session once it has been
+// open more than maxMinutes, ending it at start plus maxMinutes with the
+// break due that day (none when the limit is no longer than the break), and
+// returns the sessions it closed. Zero or less disables it. Each close goes to the
+// undo log like a manual clock-out.
+func (t *Tracker) AutoCloseStaleSessions(maxMinutes int) ([]storage.WorkSession, error) {
+	if maxMinutes <= 0 {
+		return nil, nil
+	}
+	limit := time.Duration(maxMinutes) * time.Minute
+
+	var closed []storage.WorkSession
+	for {
+		active, err := t.db.GetActiveSession()
+		if err != nil || active == nil || t.now().Sub(active.StartTime) <= limit {
+			return closed, err
+		}
+
+		breakMinutes := work.GetBreakMinutesForDay(t.rules, active.StartTime)
+		if breakMinutes >= maxMinutes {
+			breakMinutes = 0
+		}
+		session, err := t.closeSession(active, breakMinutes, "", active.StartTime.Add(limit))
+		if err != nil {
+			return closed, err
+		}
+		closed = append(closed, *session)
+	}
+}
@@ -0,0 +1,131 @@
+package tracker
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kairos/internal/storage"
+	"github.com/kairos/internal/work"
+)
+
+// Stats summarizes work habits over a date range
+type Stats struct {
+	Start, End         time.Time
+	DaysWorked         int
+	Sessions           int     // completed sessions
+	TotalHours         float64 // net hours of completed sessions
+	AvgDailyHours      float64 // TotalHours / DaysWorked
+	MedianSessionHours float64
+	// Streaks count consecutive work days (per the work rules) with at
+	// least one session; days off neither extend nor break them. The current
+	// streak runs up to End, and an unworked today doesn't break it yet.
+	LongestStreak int
+	CurrentStreak int
+	// MostProductiveDay is the weekday with the highest average hours per
+	// day worked; only meaningful when DaysWorked > 0
+	MostProductiveDay      time.Weekday
+	MostProductiveAvgHours float64
+	// EarliestClockIn and LatestClockIn are the sessions starting earliest
+	// and latest in the day; zero without completed sessions
+	EarliestClockIn time.Time
+	LatestClockIn   time.Time
+}
+
+// ComputeStats computes Stats for the sessions started from start to end
+func (t *Tracker) ComputeStats(start, end time.Time) (*Stats, error) {
+	sessions, err := t.db.GetSessionsInRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return t.StatsFromSessions(sessions, start, end), nil
+}
+
+// StatsFromSessions is ComputeStats over sessions the caller already loaded,
+// such as live sessions merged with archived ones
+func (t *Tracker) StatsFromSessions(sessions []storage.WorkSession, start, end time.Time) *Stats {
+	stats := &Stats{Start: start, End: end}
+	// A running session counts toward streaks, not toward the totals
+	worked := make(map[string]bool)
+	completed := make(map[string]bool)
+	var lengths []float64
+	var earliest, latest time.Duration
+
+	sessions = t.SessionsOnDays(sessions, start, end)
+	for _, s := range sessions {
+		day := s.Date.Format("2006-01-02")
+		worked[day] = true
+		hours, complete := t.sessionHours(s, t.now())
+		if !complete {
+			continue
+		}
+		completed[day] = true
+		stats.Sessions++
+		stats.TotalHours += hours
+		lengths = append(lengths, hours)
+
+		clockIn := sinceMidnight(s.StartTime, s.Date)
+		if stats.EarliestClockIn.IsZero() || clockIn < earliest {
+			stats.EarliestClockIn, earliest = s.StartTime, clockIn
+		}
+		if stats.LatestClockIn.IsZero() || clockIn > latest {
+			stats.LatestClockIn, latest = s.StartTime, clockIn
+		}
+	}
+
+	stats.DaysWorked = len(completed)
+	if stats.DaysWorked > 0 {
+		stats.AvgDailyHours = stats.TotalHours / float64(stats.DaysWorked)
+	}
+	stats.MedianSessionHours = median(lengths)
+
+	for _, day := range ComputeWeekdayStats(sessions) {
+		if day.AvgHours > stats.MostProductiveAvgHours {
+			stats.MostProductiveDay, stats.MostProductiveAvgHours = day.Weekday, day.AvgHours
+		}
+	}
+
+	stats.LongestStreak, stats.CurrentStreak = t.streaks(worked, start, end)
+	return stats
+}
+
+// streaks walks the work days from start to end, counting runs of days
+// found in worked
+func (t *Tracker) streaks(worked map[string]bool, start, end time.Time) (longest, current int) {
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	last := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, start.Location())
+	today := t.now().Format("2006-01-02")
+
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		if !work.IsWorkDay(t.rules, d) {
+			continue
+		}
+		key := d.Format("2006-01-02")
+		switch {
+		case worked[key]:
+			current++
+			if current > longest {
+				longest = current
+			}
+		case key == today:
+			// Today may still be worked
+		default:
+			current = 0
+		}
+	}
+	return longest, current
+}
+
+// median returns the middle value of values, or the mean of the two middle
+// values for an even count; 0 for none
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,123 @@
+package tracker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kairos/internal/storage"
+)
+
+// ValidateTemplate checks that a template's times parse and describe a
+// non-empty block
+func ValidateTemplate(tpl *storage.SessionTemplate) error {
+	base := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	start, end, err := templateTimes(tpl, base)
+	if err != nil {
+		return err
+	}
+	if tpl.BreakMinutes < 0 {
+		return fmt.Errorf("break cannot be negative")
+	}
+	if float64(tpl.BreakMinutes) >= end.Sub(start).Minutes() {
+		return fmt.Errorf("break (%dmin) must be shorter than the block (%s-%s)", tpl.BreakMinutes, tpl.StartTime, tpl.EndTime)
+	}
+	return nil
+}
+
+// ApplyTemplate creates a completed session from tpl on date. It refuses to
+// create a session that overlaps an existing one.
+func (t *Tracker) ApplyTemplate(tpl *storage.SessionTemplate, date time.Time) (*storage.WorkSession, error) {
+	if err := ValidateTemplate(tpl); err != nil {
+		return nil, err
+	}
+
+	start, end, err := templateTimes(tpl, date)
+	if err != nil {
+		return nil, err
+	}
+	return t.insertCompleted(fmt.Sprintf("template %q", tpl.Name), start, end, tpl.BreakMinutes, tpl.Note)
+}
+
+// AddSession records a completed session on date from start and end times
+// (HH:MM; an end before the start rolls over to the next day). Like
+// ApplyTemplate it refuses to overlap an existing session.
+func (t *Tracker) AddSession(date time.Time, startStr, endStr string, breakMinutes int, note string) (*storage.WorkSession, error) {
+	note, err := t.FitNote(note)
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := blockTimes(date, startStr, endStr)
+	if err != nil {
+		return nil, err
+	}
+	if end.After(t.now()) {
+		return nil, fmt.Errorf("session end %s is in the future; clock in instead", end.Format("Jan 2 15:04"))
+	}
+	if err := ValidateBreak(start, end, breakMinutes); err != nil {
+		return nil, err
+	}
+	return t.insertCompleted("session", start, end, breakMinutes, note)
+}
+
+// insertCompleted stores a closed session from start to end unless it
+// overlaps another; what names the new session in the overlap error
+func (t *Tracker) insertCompleted(what string, start, end time.Time, breakMinutes int, note string) (*storage.WorkSession, error) {
+	existing, err := t.db.GetSessionsInRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	if other := findOverlap(existing, start, end); other != nil {
+		return nil, fmt.Errorf("%s overlaps session %s (%s)", what, other.ID[:8], other.StartTime.Format("Jan 2 15:04"))
+	}
+
+	session := &storage.WorkSession{
+		Date:         start,
+		StartTime:    start,
+		EndTime:      &end,
+		BreakMinutes: breakMinutes,
+		Note:         note,
+	}
+	if err := t.db.InsertSession(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// templateTimes places a template's start and end on date; an end before the
+// start rolls over to the next day
+func templateTimes(tpl *storage.SessionTemplate, date time.Time) (time.Time, time.Time, error) {
+	return blockTimes(date, tpl.StartTime, tpl.EndTime)
+}
+
+// blockTimes places HH:MM start and end times on date, rolling an end before
+// the start over to the next day
+func blockTimes(date time.Time, startStr, endStr string) (time.Time, time.Time, error) {
+	start, err := parseTimeOnDate(date, startStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := parseTimeOnDate(date, endStr)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !end.After(start) {
+		end = end.Add(24 * time.Hour)
+	}
+	return start, end, nil
+}
+
+// findOverlap returns the first session that overlaps [start, end). Active
+// sessions extend indefinitely.
+func findOverlap(sessions []storage.WorkSession, start, end time.Time) *storage.WorkSession {
+	for i := range sessions {
+		s := &sessions[i]
+		if !s.StartTime.Before(end) {
+			continue
+		}
+		if s.EndTime != nil && !s.EndTime.After(start) {
+			continue
+		}
+		return s
+	}
+	return nil
+}
@@ -4,14 +4,30 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/kairos/internal/hooks"
 	"github.com/kairos/internal/storage"
 	"github.com/kairos/internal/work"
 )
 
 type Tracker struct {
-	db         *storage.Database
-	weeklyGoal float64
-	nowFn      func() time.Time
+	db            *storage.Database
+	weeklyGoal    float64
+	weekStartDay  time.Weekday
+	weekNumbering string
+	includeActive bool
+	rules         work.Rules
+	staleHours    float64
+	maxNote       int
+	truncateNotes bool
+	rounding      int
+	// splitAtMidnight apportions sessions spanning midnight to each day
+	splitAtMidnight bool
+	flexStart       time.Time // zero = first session
+	autoClockout    int       // minutes, 0 = off
+	hooks           Hooks
+	// notified holds the ShouldNotify notices already given
+	notified map[string]bool
+	nowFn    func() time.Time
 }
 
 func New(db *storage.Database, weeklyGoal float64) *Tracker {
@@ -31,8 +47,10 @@ func NewWithLocation(db *storage.Database, weeklyGoal float64, loc *time.Locatio
 		loc = time.Local
 	}
 	return &Tracker{
-		db:         db,
-		weeklyGoal: weeklyGoal,
+		db:           db,
+		weeklyGoal:   weeklyGoal,
+		weekStartDay: time.Monday,
+		staleHours:   DefaultStaleSessionHours,
 		nowFn: func() time.Time {
 			return time.Now().In(loc)
 		},
@@ -54,29 +72,114 @@ func (t *Tracker) WeeklyGoal() float64 {
 	return t.weeklyGoal
 }
 
-func (t *Tracker) ClockIn(note string) (*storage.WorkSession, error) {
-	now := t.now()
-	session := &storage.WorkSession{
-		Date:         now,
-		StartTime:    now,
-		BreakMinutes: 0,
-		Note:         note,
+// SetWeekStart sets the first day of the week (Monday by default)
+func (t *Tracker) SetWeekStart(day time.Weekday) {
+	t.weekStartDay = day
+}
+
+// SetWeekNumbering selects how GetMonthlyProgress numbers weeks:
+// work.WeekNumberingISO (default) or work.WeekNumberingMonth
+func (t *Tracker) SetWeekNumbering(numbering string) {
+	t.weekNumbering = numbering
+}
+
+// SetRules sets the break and work day rules (work.Rules{} is the default)
+func (t *Tracker) SetRules(rules work.Rules) {
+	t.rules = rules
+}
+
+// Rules returns the configured work rules
+func (t *Tracker) Rules() work.Rules {
+	return t.rules
+}
+
+// SetMaxNoteLength limits session notes to n characters (0 = no limit).
+// Longer notes are rejected unless SetTruncateNotes is on.
+func (t *Tracker) SetMaxNoteLength(n int) {
+	t.maxNote = n
+}
+
+// SetTruncateNotes shortens notes over the limit instead of rejecting them
+func (t *Tracker) SetTruncateNotes(truncate bool) {
+	t.truncateNotes = truncate
+}
+
+// SetRoundingMinutes rounds each completed session to the nearest minutes
+// (e.g. 15) before it is added to progress totals; 0 turns rounding off
+func (t *Tracker) SetRoundingMinutes(minutes int) {
+	t.rounding = minutes
+}
+
+// sessionHours is storage.SessionNetHours with completed sessions rounded
+// to the configured minutes. Open sessions keep their exact elapsed time.
+func (t *Tracker) sessionHours(s storage.WorkSession, now time.Time) (float64, bool) {
+	hours, complete := storage.SessionNetHours(s, now)
+	if complete {
+		hours = work.RoundSessionHours(hours, t.rounding)
 	}
+	return hours, complete
+}
 
-	if err := t.db.InsertSession(session); err != nil {
-		return nil, err
+// SetIncludeActive makes today/week totals include the running session's
+// elapsed time (reported separately as ActiveHours)
+func (t *Tracker) SetIncludeActive(include bool) {
+	t.includeActive = include
+}
+
+// WeekStartDay returns the configured first day of the week
+func (t *Tracker) WeekStartDay() time.Weekday {
+	return t.weekStartDay
+}
+
+// MonthlyGoal returns the goal for the month containing date: the daily
+// target times the number of work days in that month, holidays excluded
+func (t *Tracker) MonthlyGoal(date time.Time) float64 {
+	dailyTarget := t.weeklyGoal / float64(t.rules.DaysPerWeek())
+	return dailyTarget * float64(work.WorkDaysInMonth(t.rules, date.Year(), date.Month()))
+}
+
+// DayTarget returns the share of the week's goal due on date's day, 0 on
+// days off and holidays
+func (t *Tracker) DayTarget(date time.Time) float64 {
+	if !work.IsWorkDay(t.rules, date) {
+		return 0
 	}
+	targets, _, err := t.weekTargets(getWeekStartOn(date, t.weekStartDay))
+	if err != nil {
+		return t.weeklyGoal / float64(t.rules.DaysPerWeek())
+	}
+	return targets[date.Format("2006-01-02")]
+}
 
-	return session, nil
+func (t *Tracker) ClockIn(note string) (*storage.WorkSession, error) {
+	return t.ClockInWithProject(note, "", "")
 }
 
 func (t *Tracker) ClockInWithTime(note, timeStr string) (*storage.WorkSession, error) {
+	return t.ClockInWithProject(note, timeStr, "")
+}
+
+// ClockInWithProject starts a session tagged with a project and optional
+// tags, registering the project name if it is new
+func (t *Tracker) ClockInWithProject(note, timeStr, project string, tags ...string) (*storage.WorkSession, error) {
+	note, err := t.FitNote(note)
+	if err != nil {
+		return nil, err
+	}
 	now := t.now()
 	session := &storage.WorkSession{
 		Date:         now,
 		StartTime:    now,
 		BreakMinutes: 0,
 		Note:         note,
+		Project:      project,
+		Tags:         tags,
+	}
+
+	if project != "" {
+		if err := t.db.EnsureProject(project); err != nil {
+			return nil, err
+		}
 	}
 
 	// Parse time override if provided
@@ -93,6 +196,7 @@ func (t *Tracker) ClockInWithTime(note, timeStr string) (*storage.WorkSession, e
 		}
 	}
 
+	t.runHook(hooks.PreClockin, t.hooks.PreClockin, session)
 	if err := t.db.InsertSession(session); err != nil {
 		return nil, err
 	}
@@ -113,15 +217,29 @@ func (t *Tracker) ClockOutWithTime(id string, breakMinutes int, note string, tim
 		return nil, fmt.Errorf("session not found")
 	}
 
-	endTime := t.now()
-	if timeStr != "" {
-		parsed, err := parseTimeOnDate(session.StartTime, timeStr)
-		if err == nil {
-			if parsed.Before(session.StartTime) {
-				parsed = parsed.Add(24 * time.Hour)
-			}
-			endTime = parsed
-		}
+	return t.closeSession(session, breakMinutes, note, t.ResolveEndTime(session, timeStr))
+}
+
+// ClockOutAt closes the session at an exact end time, e.g. one rounded with
+// work.RoundUpToQuarterHour
+func (t *Tracker) ClockOutAt(id string, breakMinutes int, note string, endTime time.Time) (*storage.WorkSession, error) {
+	session, err := t.db.GetSessionByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, fmt.Errorf("session not found")
+	}
+	return t.closeSession(session, breakMinutes, note, endTime)
+}
+
+func (t *Tracker) closeSession(session *storage.WorkSession, breakMinutes int, note string, endTime time.Time) (*storage.WorkSession, error) {
+	if err := ValidateBreak(session.StartTime, endTime, breakMinutes); err != nil {
+		return nil, err
+	}
+	note, err := t.FitNote(note)
+	if err != nil {
+		return nil, err
 	}
 
 	session.EndTime = &endTime
@@ -133,10 +251,27 @@ func (t *Tracker) ClockOutWithTime(id string, breakMinutes int, note string, tim
 	if err := t.db.UpdateSession(session); err != nil {
 		return nil, err
 	}
+	t.runHook(hooks.PostClockout, t.hooks.PostClockout, session)
 
 	return session, nil
 }
 
+// ResolveEndTime returns the end time a clockout with timeStr would record:
+// now when timeStr is empty or invalid, otherwise timeStr on the session's
+// start day (rolled to the next day when it falls before the start)
+func (t *Tracker) ResolveEndTime(session *storage.WorkSession, timeStr string) time.Time {
+	if timeStr != "" {
+		parsed, err := parseTimeOnDate(session.StartTime, timeStr)
+		if err == nil {
+			if parsed.Before(session.StartTime) {
+				parsed = parsed.Add(24 * time.Hour)
+			}
+			return parsed
+		}
+	}
+	return t.now()
+}
+
 func parseTimeOnDate(base time.Time, s string) (time.Time, error) {
 	for _, format := range []string{"15:04", "3:04", "15:04:05", "3:04:05"} {
 		if t, err := time.Parse(format, s); err == nil {
@@ -155,20 +290,27 @@ func (t *Tracker) GetTodayProgress() (*DayProgress, error) {
 
 	progress := &DayProgress{
 		Date:       now,
-		Sessions:   sessions,
+		Sessions:   t.SessionsOnDays(sessions, now, now),
 		TotalHours: 0,
+		Goal:       t.DayTarget(now),
 	}
 
+	// The open session is current even when it started yesterday, but its
+	// hours only count on the day it started
 	for _, s := range sessions {
-		if s.EndTime != nil {
-			hours := s.EndTime.Sub(s.StartTime).Hours()
-			hours -= float64(s.BreakMinutes) / 60.0
-			progress.TotalHours += hours
-		} else {
-			// This is the current open session
+		if s.EndTime == nil {
 			progress.CurrentSessionID = s.ID
 		}
 	}
+	for _, s := range progress.Sessions {
+		hours, complete := t.sessionHours(s, now)
+		if complete {
+			progress.TotalHours += hours
+		} else if t.includeActive {
+			progress.ActiveHours += hours
+		}
+	}
+	progress.TotalHours += progress.ActiveHours
 
 	return progress, nil
 }
@@ -178,22 +320,28 @@ func (t *Tracker) GetWeeklyProgress() (*WeekProgress, error) {
 }
 
 func (t *Tracker) GetLastWeekProgress() (*WeekProgress, error) {
-	lastWeekStart := getWeekStart(t.now()).AddDate(0, 0, -7)
+	lastWeekStart := getWeekStartOn(t.now(), t.weekStartDay).AddDate(0, 0, -7)
 	return t.computeWeekProgress(lastWeekStart)
 }
 
 func (t *Tracker) GetWeekProgressForDate(date time.Time) (*WeekProgress, error) {
-	weekStart := getWeekStart(date)
+	weekStart := getWeekStartOn(date, t.weekStartDay)
 	return t.computeWeekProgress(weekStart)
 }
 
 func (t *Tracker) computeWeekProgress(weekStart time.Time) (*WeekProgress, error) {
-	weekEnd := weekStart.AddDate(0, 0, 6)
-
-	sessions, err := t.db.GetSessionsInRange(weekStart, weekEnd)
+	sessions, err := t.db.GetSessionsInRange(weekStart, weekStart.AddDate(0, 0, 6))
 	if err != nil {
 		return nil, err
 	}
+	return t.weekProgressFromSessions(weekStart, sessions)
+}
+
+// weekProgressFromSessions computes the progress of the week starting on
+// weekStart from sessions, which may span other weeks too
+func (t *Tracker) weekProgressFromSessions(weekStart time.Time, sessions []storage.WorkSession) (*WeekProgress, error) {
+	weekEnd := weekStart.AddDate(0, 0, 6)
+	sessions = t.SessionsOnDays(sessions, weekStart, weekEnd)
 
 	progress := &WeekProgress{
 		WeekStart:  weekStart,
@@ -204,17 +352,38 @@ func (t *Tracker) computeWeekProgress(weekStart time.Time) (*WeekProgress, error
 	}
 
 	for _, s := range sessions {
-		if s.EndTime != nil {
-			hours := s.EndTime.Sub(s.StartTime).Hours()
-			hours -= float64(s.BreakMinutes) / 60.0
+		hours, complete := t.sessionHours(s, t.now())
+		if complete {
 			progress.TotalHours += hours
 			dayKey := s.Date.Format("2006-01-02")
 			progress.DaysWorked[dayKey] += hours
+		} else if t.includeActive {
+			progress.TotalHours += hours
+			progress.ActiveHours += hours
+			progress.DaysWorked[s.Date.Format("2006-01-02")] += hours
 		}
 	}
 
 	progress.DaysWorkedCount = len(progress.DaysWorked)
-	progress.RemainingHours = t.weeklyGoal - progress.TotalHours
+
+	var err error
+	progress.DayTargets, progress.GoalOverride, err = t.weekTargets(weekStart)
+	if err != nil {
+		return nil, err
+	}
+	today := t.now().Format("2006-01-02")
+	progress.Holidays = make(map[string]bool)
+	for d := weekStart; !d.After(weekEnd); d = d.AddDate(0, 0, 1) {
+		dayKey := d.Format("2006-01-02")
+		if work.IsHoliday(t.rules, d) {
+			progress.Holidays[dayKey] = true
+		}
+		progress.Goal += progress.DayTargets[dayKey]
+		if progress.DayTargets[dayKey] > 0 && dayKey >= today {
+			progress.RemainingWorkDays++
+		}
+	}
+	progress.RemainingHours = progress.Goal - progress.TotalHours
 
 	return progress, nil
 }
@@ -227,25 +396,35 @@ func (t *Tracker) GetMonthlyProgress() (*MonthProgress, error) {
 	if err != nil {
 		return nil, err
 	}
+	sessions = t.SessionsOnDays(sessions, monthStart, now)
 
+	numbering := t.weekNumbering
+	if numbering == "" {
+		numbering = work.WeekNumberingISO
+	}
 	progress := &MonthProgress{
-		Month:      monthStart,
-		TotalHours: 0,
-		WeekHours:  make(map[int]float64),
+		Month:         monthStart,
+		TotalHours:    0,
+		WeekHours:     make(map[int]float64),
+		WeekNumbering: numbering,
+		Sessions:      sessions,
 	}
 
 	for _, s := range sessions {
-		if s.EndTime != nil {
-			hours := s.EndTime.Sub(s.StartTime).Hours()
-			hours -= float64(s.BreakMinutes) / 60.0
+		if hours, complete := t.sessionHours(s, now); complete {
 			progress.TotalHours += hours
-			_, week := s.Date.ISOWeek()
+			week := work.WeekNumber(s.Date, numbering, t.weekStartDay)
+			if _, seen := progress.WeekHours[week]; !seen {
+				progress.Weeks = append(progress.Weeks, week)
+			}
 			progress.WeekHours[week] += hours
 		}
 	}
 
 	progress.WeekCount = len(progress.WeekHours)
 	progress.DailyAverage = progress.TotalHours / float64(now.Day())
+	progress.GoalHours = t.MonthlyGoal(monthStart)
+	progress.RemainingHours = progress.GoalHours - progress.TotalHours
 
 	return progress, nil
 }
@@ -254,12 +433,50 @@ func (t *Tracker) GetActiveSession() (*storage.WorkSession, error) {
 	return t.db.GetActiveSession()
 }
 
+// GetSessionsInRange returns the sessions started on the days from start to
+// end, split at midnight when SetSplitAtMidnight is on
+func (t *Tracker) GetSessionsInRange(start, end time.Time) ([]storage.WorkSession, error) {
+	sessions, err := t.db.GetSessionsInRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return t.SessionsOnDays(sessions, start, end), nil
+}
+
 func (t *Tracker) EditSession(id string, breakMinutes int, note string, timeStr string) error {
 	return t.EditSessionSelective(id, breakMinutes, true, note, true, timeStr, "")
 }
 
 // EditSessionSelective updates only the fields that are explicitly changed
 func (t *Tracker) EditSessionSelective(id string, breakMinutes int, breakChanged bool, note string, noteChanged bool, startTimeStr string, endTimeStr string) error {
+	edit := SessionEdit{StartTime: startTimeStr, EndTime: endTimeStr}
+	if breakChanged {
+		edit.BreakMinutes = &breakMinutes
+	}
+	if noteChanged {
+		edit.Note = &note
+	}
+	return t.ApplySessionEdit(id, edit)
+}
+
+// NoteSeparator joins an appended note to the existing one
+const NoteSeparator = "; "
+
+// SessionEdit lists the changes to make to a session; nil fields and empty
+// times are left as they are
+type SessionEdit struct {
+	BreakMinutes *int
+	Note         *string // replaces the note
+	AppendNote   string  // added after the note, separated by NoteSeparator
+	Project      *string
+	Tags         *[]string
+	StartTime    string // HH:MM on the session's day
+	EndTime      string // HH:MM, rolled past midnight when before the start
+}
+
+// ApplySessionEdit updates the fields set in edit, registering a new
+// project name
+func (t *Tracker) ApplySessionEdit(id string, edit SessionEdit) error {
 	session, err := t.db.GetSessionByID(id)
 	if err != nil {
 		return err
@@ -267,20 +484,79 @@ func (t *Tracker) EditSessionSelective(id string, breakMinutes int, breakChanged
 	if session == nil {
 		return fmt.Errorf("session not found: %s", id)
 	}
+	if err := t.editSession(session, edit); err != nil {
+		return err
+	}
+	if err := t.ensureEditProject(edit); err != nil {
+		return err
+	}
+	return t.db.UpdateSession(session)
+}
 
-	// Only update break if explicitly changed
-	if breakChanged {
-		session.BreakMinutes = breakMinutes
+// ApplySessionEdits applies edit to each session in ids. Every edited
+// session is checked before any is saved, and all are saved in one
+// transaction, so an invalid edit to one session changes none.
+func (t *Tracker) ApplySessionEdits(ids []string, edit SessionEdit) error {
+	sessions := make([]storage.WorkSession, 0, len(ids))
+	for _, id := range ids {
+		session, err := t.db.GetSessionByID(id)
+		if err != nil {
+			return err
+		}
+		if session == nil {
+			return fmt.Errorf("session not found: %s", id)
+		}
+		if err := t.editSession(session, edit); err != nil {
+			return fmt.Errorf("session %s: %w", session.ID[:8], err)
+		}
+		sessions = append(sessions, *session)
 	}
 
-	// Only update note if explicitly changed
-	if noteChanged {
-		session.Note = note
+	if err := t.ensureEditProject(edit); err != nil {
+		return err
+	}
+	return t.db.UpdateSessions(sessions)
+}
+
+// ensureEditProject registers the project name edit sets, if new
+func (t *Tracker) ensureEditProject(edit SessionEdit) error {
+	if edit.Project == nil || *edit.Project == "" {
+		return nil
+	}
+	return t.db.EnsureProject(*edit.Project)
+}
+
+// editSession applies edit to session and checks the result, without saving
+func (t *Tracker) editSession(session *storage.WorkSession, edit SessionEdit) error {
+	var err error
+	if edit.BreakMinutes != nil {
+		session.BreakMinutes = *edit.BreakMinutes
+	}
+	if edit.Note != nil {
+		session.Note = *edit.Note
+	}
+	if edit.AppendNote != "" {
+		if session.Note == "" {
+			session.Note = edit.AppendNote
+		} else {
+			session.Note += NoteSeparator + edit.AppendNote
+		}
+	}
+	if edit.Note != nil || edit.AppendNote != "" {
+		if session.Note, err = t.FitNote(session.Note); err != nil {
+			return err
+		}
+	}
+	if edit.Project != nil {
+		session.Project = *edit.Project
+	}
+	if edit.Tags != nil {
+		session.Tags = *edit.Tags
 	}
 
 	// Update start time if provided
-	if startTimeStr != "" {
-		newTime, err := parseTimeOnDate(session.StartTime, startTimeStr)
+	if edit.StartTime != "" {
+		newTime, err := parseTimeOnDate(session.StartTime, edit.StartTime)
 		if err == nil {
 			session.StartTime = newTime
 			session.Date = newTime
@@ -288,8 +564,8 @@ func (t *Tracker) EditSessionSelective(id string, breakMinutes int, breakChanged
 	}
 
 	// Update end time if provided
-	if endTimeStr != "" {
-		newTime, err := parseTimeOnDate(session.StartTime, endTimeStr)
+	if edit.EndTime != "" {
+		newTime, err := parseTimeOnDate(session.StartTime, edit.EndTime)
 		if err == nil {
 			if newTime.Before(session.StartTime) {
 				newTime = newTime.Add(24 * time.Hour)
@@ -298,26 +574,115 @@ func (t *Tracker) EditSessionSelective(id string, breakMinutes int, breakChanged
 		}
 	}
 
-	return t.db.UpdateSession(session)
+	if session.EndTime != nil {
+		// A new start alone is not rolled over like a new end is
+		if !session.EndTime.After(session.StartTime) {
+			return fmt.Errorf("session %s would end at %s, not after its start at %s",
+				session.ID[:8], session.EndTime.Format("2006-01-02 15:04"), session.StartTime.Format("2006-01-02 15:04"))
+		}
+		if err := ValidateBreak(session.StartTime, *session.EndTime, session.BreakMinutes); err != nil {
+			return err
+		}
+	} else if session.BreakMinutes < 0 {
+		// An open session's break is checked against its length at clock-out
+		return fmt.Errorf("break cannot be negative: %d minutes", session.BreakMinutes)
+	}
+	return nil
 }
 
 func (t *Tracker) DeleteSession(id string) error {
 	return t.db.DeleteSession(id)
 }
 
-func getWeekStart(t time.Time) time.Time {
-	weekday := int(t.Weekday())
-	if weekday == 0 {
-		weekday = 7
+// Undo reverts the most recent session edit, clock-out or delete, returning
+// nil when there is nothing left to undo
+func (t *Tracker) Undo() (*storage.UndoEntry, error) {
+	return t.db.Undo()
+}
+
+// UndoLog lists the changes Undo can revert, most recent first
+func (t *Tracker) UndoLog() ([]storage.UndoEntry, error) {
+	return t.db.UndoLog()
+}
+
+// FitNote applies the note length limit, counting characters rather than
+// bytes. Truncated notes end in "..." like archive tables.
+func (t *Tracker) FitNote(note string) (string, error) {
+	runes := []rune(note)
+	if t.maxNote <= 0 || len(runes) <= t.maxNote {
+		return note, nil
+	}
+	if !t.truncateNotes {
+		return "", fmt.Errorf("note is %d characters, over the limit of %d (shorten it or use --truncate-note)", len(runes), t.maxNote)
+	}
+	if t.maxNote <= 3 {
+		return string(runes[:t.maxNote]), nil
 	}
-	return t.AddDate(0, 0, -weekday+1)
+	return string(runes[:t.maxNote-3]) + "...", nil
+}
+
+// DefaultBreak returns the break a clock-out from start to end gets when none
+// is given: the day's break, or none when the session is no longer than it
+func (t *Tracker) DefaultBreak(start, end time.Time) int {
+	breakMinutes := work.GetBreakMinutesForShift(t.rules, start, end)
+	if ValidateBreak(start, end, breakMinutes) != nil {
+		return 0
+	}
+	return breakMinutes
+}
+
+// ValidateBreak rejects negative breaks and breaks that use up the whole
+// session, which would produce zero or negative worked hours
+func ValidateBreak(start, end time.Time, breakMinutes int) error {
+	if breakMinutes < 0 {
+		return fmt.Errorf("break cannot be negative: %d minutes", breakMinutes)
+	}
+	sessionMinutes := end.Sub(start).Minutes()
+	if float64(breakMinutes) >= sessionMinutes {
+		return fmt.Errorf("break of %d minutes must be shorter than the %.0f-minute session", breakMinutes, sessionMinutes)
+	}
+	return nil
+}
+
+// ResolveActive handles still-open sessions for exports and ranges: they are
+// dropped, or with include set, closed at now so their hours are the elapsed
+// time minus break. Completed sessions pass through unchanged.
+func ResolveActive(sessions []storage.WorkSession, now time.Time, include bool) []storage.WorkSession {
+	resolved := make([]storage.WorkSession, 0, len(sessions))
+	for _, s := range sessions {
+		if s.EndTime == nil {
+			if !include {
+				continue
+			}
+			end := now
+			if minEnd := s.StartTime.Add(time.Duration(s.BreakMinutes) * time.Minute); end.Before(minEnd) {
+				end = minEnd
+			}
+			s.EndTime = &end
+		}
+		resolved = append(resolved, s)
+	}
+	return resolved
+}
+
+func getWeekStart(t time.Time) time.Time {
+	return getWeekStartOn(t, time.Monday)
+}
+
+// getWeekStartOn returns the most recent startDay on or before t, keeping
+// t's time of day
+func getWeekStartOn(t time.Time, startDay time.Weekday) time.Time {
+	offset := (int(t.Weekday()) - int(startDay) + 7) % 7
+	return t.AddDate(0, 0, -offset)
 }
 
 type DayProgress struct {
 	Date             time.Time
 	Sessions         []storage.WorkSession
 	TotalHours       float64
+	ActiveHours      float64 // running session time included in TotalHours
 	CurrentSessionID string
+	Goal             float64 // the day's share of the weekly goal, 0 on days off
 }
 
 type WeekProgress struct {
@@ -326,14 +691,47 @@ type WeekProgress struct {
 	TotalHours      float64
 	DaysWorked      map[string]float64
 	DaysWorkedCount int
-	RemainingHours  float64
-	Sessions        []storage.WorkSession
+	// Goal is the week's effective goal, the sum of its DayTargets: the
+	// weekly goal less a day's share for each holiday, or the goal set for
+	// this week when GoalOverride is true
+	Goal           float64
+	GoalOverride   bool
+	RemainingHours float64
+	// RemainingWorkDays counts the work days from today (included) to the
+	// end of the week; 0 for past weeks
+	RemainingWorkDays int
+	ActiveHours       float64 // running session time included in TotalHours
+	// DayTargets holds each day's share of the weekly goal, keyed like
+	// DaysWorked; non-work days and holidays are 0
+	DayTargets map[string]float64
+	// Holidays marks the days of the week taken off as holidays, keyed like
+	// DaysWorked; Goal leaves them out
+	Holidays map[string]bool
+	Sessions []storage.WorkSession
+}
+
+// WorkDayTarget returns the hours due on each of the week's work days, 0
+// when it has none
+func (p *WeekProgress) WorkDayTarget() float64 {
+	for _, target := range p.DayTargets {
+		if target > 0 {
+			return target
+		}
+	}
+	return 0
 }
 
 type MonthProgress struct {
-	Month        time.Time
-	TotalHours   float64
-	DailyAverage float64
-	WeekHours    map[int]float64
-	WeekCount    int
+	Month         time.Time
+	TotalHours    float64
+	DailyAverage  float64
+	WeekHours     map[int]float64
+	Weeks         []int  // WeekHours keys in calendar order (ISO W52 before W1 in January)
+	WeekNumbering string // work.WeekNumberingISO or work.WeekNumberingMonth
+	WeekCount     int
+	// GoalHours is the monthly goal (see MonthlyGoal); RemainingHours is
+	// negative once it is exceeded
+	GoalHours      float64
+	RemainingHours float64
+	Sessions       []storage.WorkSession
 }
@@ -1,8 +1,14 @@
 package tracker
 
 import (
+	"math"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/kairos/internal/hooks"
+	"github.com/kairos/internal/storage"
+	"github.com/kairos/internal/work"
 )
 
 func TestGetWeekStart(t *testing.T) {
@@ -39,6 +45,36 @@ func TestGetWeekStart(t *testing.T) {
 	}
 }
 
+func TestGetWeekStartOnSunday(t *testing.T) {
+	// Sunday-start week spanning Sep/Oct 2024: Sun Sep 29 - Sat Oct 5
+	wantStart := time.Date(2024, 9, 29, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 7; i++ {
+		date := wantStart.AddDate(0, 0, i).Add(10 * time.Hour)
+		start := getWeekStartOn(date, time.Sunday)
+		if start.Weekday() != time.Sunday || start.YearDay() != wantStart.YearDay() {
+			t.Errorf("getWeekStartOn(%s, Sunday) = %s, want Sun Sep 29", date.Format("Mon Jan 2"), start.Format("Mon Jan 2"))
+		}
+
+		end := start.AddDate(0, 0, 6)
+		if end.Weekday() != time.Saturday || end.Month() != time.October || end.Day() != 5 {
+			t.Errorf("week end for %s = %s, want Sat Oct 5", date.Format("Mon Jan 2"), end.Format("Mon Jan 2"))
+		}
+	}
+
+	// The Sunday after belongs to the next week
+	next := getWeekStartOn(time.Date(2024, 10, 6, 9, 0, 0, 0, time.UTC), time.Sunday)
+	if next.Month() != time.October || next.Day() != 6 {
+		t.Errorf("getWeekStartOn(Sun Oct 6, Sunday) = %s, want Oct 6", next.Format("Mon Jan 2"))
+	}
+
+	// Monday start keeps Sunday as the last day
+	monday := getWeekStartOn(time.Date(2024, 9, 29, 9, 0, 0, 0, time.UTC), time.Monday)
+	if monday.Month() != time.September || monday.Day() != 23 {
+		t.Errorf("getWeekStartOn(Sun Sep 29, Monday) = %s, want Mon Sep 23", monday.Format("Mon Jan 2"))
+	}
+}
+
 func TestParseTimeOnDate(t *testing.T) {
 	now := time.Now()
 
@@ -152,3 +188,1474 @@ func TestMonthProgressFields(t *testing.T) {
 		t.Error("WeekHours should not be nil")
 	}
 }
+
+func TestFindGaps(t *testing.T) {
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) *time.Time {
+		v := day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute)
+		return &v
+	}
+
+	sessions := []storage.WorkSession{
+		{ID: "c", StartTime: *at(13, 0), EndTime: at(17, 0)},
+		{ID: "a", StartTime: *at(9, 0), EndTime: at(12, 0)},
+		{ID: "b", StartTime: *at(12, 0), EndTime: at(12, 30)},
+		{ID: "d", StartTime: *at(17, 15), EndTime: nil},
+	}
+
+	gaps := FindGaps(sessions)
+	want := []time.Duration{30 * time.Minute, 15 * time.Minute}
+	if len(gaps) != len(want) {
+		t.Fatalf("FindGaps returned %d gaps, want %d: %v", len(gaps), len(want), gaps)
+	}
+	for i, d := range want {
+		if gaps[i].Duration() != d {
+			t.Errorf("gap %d = %v, want %v", i, gaps[i].Duration(), d)
+		}
+	}
+	if !gaps[0].Start.Equal(*at(12, 30)) || !gaps[0].End.Equal(*at(13, 0)) {
+		t.Errorf("gap 0 = %v-%v, want 12:30-13:00", gaps[0].Start, gaps[0].End)
+	}
+
+	if gaps := FindGaps(nil); len(gaps) != 0 {
+		t.Errorf("FindGaps(nil) = %v, want none", gaps)
+	}
+}
+
+func TestFindOverlap(t *testing.T) {
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time {
+		return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute)
+	}
+	end := func(h, m int) *time.Time {
+		v := at(h, m)
+		return &v
+	}
+
+	sessions := []storage.WorkSession{
+		{ID: "morning", StartTime: at(8, 0), EndTime: end(9, 0)},
+		{ID: "afternoon", StartTime: at(13, 0), EndTime: end(17, 0)},
+	}
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       string
+	}{
+		{"touching end", at(9, 0), at(9, 30), ""},
+		{"between", at(10, 0), at(12, 0), ""},
+		{"touching start", at(12, 30), at(13, 0), ""},
+		{"inside", at(14, 0), at(15, 0), "afternoon"},
+		{"straddles", at(8, 30), at(9, 30), "morning"},
+	}
+	for _, tt := range tests {
+		got := findOverlap(sessions, tt.start, tt.end)
+		name := ""
+		if got != nil {
+			name = got.ID
+		}
+		if name != tt.want {
+			t.Errorf("%s: findOverlap = %q, want %q", tt.name, name, tt.want)
+		}
+	}
+
+	active := []storage.WorkSession{{ID: "active", StartTime: at(16, 0)}}
+	if got := findOverlap(active, at(18, 0), at(19, 0)); got == nil {
+		t.Error("active session should overlap any later block")
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		tpl     storage.SessionTemplate
+		wantErr bool
+	}{
+		{storage.SessionTemplate{Name: "standup", StartTime: "09:00", EndTime: "09:30"}, false},
+		{storage.SessionTemplate{Name: "night", StartTime: "22:00", EndTime: "02:00", BreakMinutes: 30}, false},
+		{storage.SessionTemplate{Name: "bad", StartTime: "9am", EndTime: "10:00"}, true},
+		{storage.SessionTemplate{Name: "long break", StartTime: "09:00", EndTime: "09:30", BreakMinutes: 30}, true},
+	}
+	for _, tt := range tests {
+		err := ValidateTemplate(&tt.tpl)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateTemplate(%s) error = %v, wantErr %v", tt.tpl.Name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestIncludeActiveSession(t *testing.T) {
+	// Wednesday: 09:00-12:00 done, a session running since 13:00 with a 15min break
+	now := time.Date(2024, 1, 17, 15, 0, 0, 0, time.UTC)
+	tr, db := newTestTracker(t, 38.5, func() time.Time { return now })
+	doneStart := time.Date(2024, 1, 17, 9, 0, 0, 0, time.UTC)
+	doneEnd := doneStart.Add(3 * time.Hour)
+	activeStart := time.Date(2024, 1, 17, 13, 0, 0, 0, time.UTC)
+	for _, s := range []*storage.WorkSession{
+		{Date: doneStart, StartTime: doneStart, EndTime: &doneEnd},
+		{Date: activeStart, StartTime: activeStart, BreakMinutes: 15},
+	} {
+		if err := db.InsertSession(s); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+	}
+
+	day, err := tr.GetTodayProgress()
+	if err != nil {
+		t.Fatalf("GetTodayProgress: %v", err)
+	}
+	if day.TotalHours != 3 || day.ActiveHours != 0 {
+		t.Errorf("default today = %.2f (active %.2f), want 3.00 (active 0)", day.TotalHours, day.ActiveHours)
+	}
+
+	tr.SetIncludeActive(true)
+
+	day, err = tr.GetTodayProgress()
+	if err != nil {
+		t.Fatalf("GetTodayProgress: %v", err)
+	}
+	if day.ActiveHours != 1.75 || day.TotalHours != 4.75 {
+		t.Errorf("today with active = %.2f (active %.2f), want 4.75 (active 1.75)", day.TotalHours, day.ActiveHours)
+	}
+
+	week, err := tr.GetWeeklyProgress()
+	if err != nil {
+		t.Fatalf("GetWeeklyProgress: %v", err)
+	}
+	if week.ActiveHours != 1.75 || week.TotalHours != 4.75 {
+		t.Errorf("week with active = %.2f (active %.2f), want 4.75 (active 1.75)", week.TotalHours, week.ActiveHours)
+	}
+	if week.RemainingHours != 38.5-4.75 {
+		t.Errorf("week remaining = %.2f, want %.2f", week.RemainingHours, 38.5-4.75)
+	}
+	if week.DaysWorked["2024-01-17"] != 4.75 {
+		t.Errorf("week day total = %.2f, want 4.75", week.DaysWorked["2024-01-17"])
+	}
+}
+
+func TestComputeWeekdayStats(t *testing.T) {
+	session := func(day, startHour, endHour int) storage.WorkSession {
+		date := time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)
+		end := date.Add(time.Duration(endHour) * time.Hour)
+		return storage.WorkSession{Date: date, StartTime: date.Add(time.Duration(startHour) * time.Hour), EndTime: &end}
+	}
+
+	sessions := []storage.WorkSession{
+		session(1, 9, 12),  // Mon Jan 1
+		session(1, 13, 17), // Mon Jan 1
+		session(8, 9, 15),  // Mon Jan 8
+		session(7, 10, 12), // Sun Jan 7
+		{Date: time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC), StartTime: time.Date(2024, 1, 9, 9, 0, 0, 0, time.UTC)}, // active, ignored
+	}
+
+	stats := ComputeWeekdayStats(sessions)
+	if len(stats) != 7 || stats[0].Weekday != time.Monday || stats[6].Weekday != time.Sunday {
+		t.Fatalf("expected Monday..Sunday, got %v", stats)
+	}
+	if stats[0].Days != 2 || stats[0].Hours != 13 || stats[0].AvgHours != 6.5 {
+		t.Errorf("Monday = %+v, want 2 days, 13h, 6.5 avg", stats[0])
+	}
+	if stats[6].Days != 1 || stats[6].Hours != 2 {
+		t.Errorf("Sunday = %+v, want 1 day, 2h", stats[6])
+	}
+	if stats[1].Days != 0 || stats[1].AvgHours != 0 {
+		t.Errorf("Tuesday = %+v, want empty", stats[1])
+	}
+}
+
+func TestGetOvertime(t *testing.T) {
+	tr, db := newTestTracker(t, 10, nil)
+
+	// 12h in the week of Jan 1 and 8h in the week of Jan 8 against a 10h goal
+	for _, s := range []struct{ day, hours int }{{1, 8}, {3, 4}, {9, 8}} {
+		start := time.Date(2024, 1, s.day, 9, 0, 0, 0, time.UTC)
+		end := start.Add(time.Duration(s.hours) * time.Hour)
+		if err := db.InsertSession(&storage.WorkSession{Date: start, StartTime: start, EndTime: &end}); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+	}
+
+	weeks, err := tr.GetOvertime(time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("GetOvertime: %v", err)
+	}
+	if len(weeks) != 3 {
+		t.Fatalf("got %d weeks, want 3 (Jan 1, 8, 15)", len(weeks))
+	}
+
+	want := []struct{ overtime, running float64 }{{2, 2}, {-2, 0}, {-10, -10}}
+	for i, w := range want {
+		if weeks[i].Overtime != w.overtime || weeks[i].RunningTotal != w.running {
+			t.Errorf("week %s: overtime %.1f running %.1f, want %.1f / %.1f",
+				weeks[i].WeekStart.Format("Jan 2"), weeks[i].Overtime, weeks[i].RunningTotal, w.overtime, w.running)
+		}
+	}
+	if weeks[0].WeekStart.Weekday() != time.Monday {
+		t.Errorf("first week starts %s, want Monday", weeks[0].WeekStart.Weekday())
+	}
+
+	if _, err := tr.GetOvertime(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Error("expected error for reversed range")
+	}
+
+	// A week with its own goal is measured against it
+	if err := tr.SetWeekGoal(time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), 8); err != nil {
+		t.Fatalf("SetWeekGoal: %v", err)
+	}
+	weeks, err = tr.GetOvertime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("GetOvertime: %v", err)
+	}
+	if weeks[1].Goal != 8 || weeks[1].Overtime != 0 || weeks[1].RunningTotal != 2 {
+		t.Errorf("overridden week: goal %.1f overtime %.1f running %.1f, want 8 / 0 / 2", weeks[1].Goal, weeks[1].Overtime, weeks[1].RunningTotal)
+	}
+}
+
+func TestBreakLongerThanSession(t *testing.T) {
+	now := time.Date(2024, 1, 17, 11, 0, 0, 0, time.UTC)
+	tr, _ := newTestTracker(t, 38.5, func() time.Time { return now })
+
+	session, err := tr.ClockInWithTime("", "09:00")
+	if err != nil {
+		t.Fatalf("ClockInWithTime: %v", err)
+	}
+
+	if _, err := tr.ClockOutWithTime(session.ID, 180, "", "11:00"); err == nil {
+		t.Error("expected error for a 180min break in a 2h session")
+	}
+	if _, err := tr.ClockOutWithTime(session.ID, -5, "", "11:00"); err == nil {
+		t.Error("expected error for a negative break")
+	}
+	if active, _ := tr.GetActiveSession(); active == nil {
+		t.Fatal("rejected clock-out should leave the session running")
+	}
+
+	if _, err := tr.ClockOutWithTime(session.ID, 30, "", "11:00"); err != nil {
+		t.Fatalf("ClockOutWithTime: %v", err)
+	}
+	if err := tr.EditSessionSelective(session.ID, 120, true, "", false, "", ""); err == nil {
+		t.Error("expected error when editing the break to the full session length")
+	}
+}
+
+func TestOvertimeMatchesWeekProgress(t *testing.T) {
+	tr, db := newTestTracker(t, 10, func() time.Time { return time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC) })
+	tr.SetSplitAtMidnight(true)
+
+	// A Sunday night shift runs into Monday, the next week
+	start := time.Date(2024, 1, 7, 22, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 8, 4, 0, 0, 0, time.UTC)
+	if err := db.InsertSession(&storage.WorkSession{Date: start, StartTime: start, EndTime: &end}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+
+	weeks, err := tr.GetOvertime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("GetOvertime: %v", err)
+	}
+	for _, w := range weeks {
+		progress, err := tr.GetWeekProgressForDate(w.WeekStart)
+		if err != nil {
+			t.Fatalf("GetWeekProgressForDate: %v", err)
+		}
+		if w.Hours != progress.TotalHours || w.Goal != progress.Goal {
+			t.Errorf("week %s: overtime has %.2fh/%.2fh, week view %.2fh/%.2fh",
+				w.WeekStart.Format("Jan 2"), w.Hours, w.Goal, progress.TotalHours, progress.Goal)
+		}
+	}
+	if weeks[0].Hours != 2 || weeks[1].Hours != 4 {
+		t.Errorf("hours = %.2f / %.2f, want 2 / 4 split at midnight", weeks[0].Hours, weeks[1].Hours)
+	}
+}
+
+func TestDefaultBreakFitsShortSession(t *testing.T) {
+	tr, _ := newTestTracker(t, 38.5, func() time.Time { return time.Date(2024, 1, 17, 9, 20, 0, 0, time.UTC) })
+
+	// Wednesday's 30-minute break doesn't fit a 20-minute session, so the
+	// default drops to none rather than blocking the clock-out
+	session, err := tr.ClockInWithTime("", "09:00")
+	if err != nil {
+		t.Fatalf("ClockInWithTime: %v", err)
+	}
+	end := tr.Now()
+	breakMinutes := tr.DefaultBreak(session.StartTime, end)
+	if breakMinutes != 0 {
+		t.Errorf("default break = %d, want 0 for a 20-minute session", breakMinutes)
+	}
+	closed, err := tr.ClockOutAt(session.ID, breakMinutes, "", end)
+	if err != nil {
+		t.Fatalf("ClockOutAt with the default break: %v", err)
+	}
+	if closed.BreakMinutes != 0 {
+		t.Errorf("recorded break = %d, want 0", closed.BreakMinutes)
+	}
+
+	// A session longer than the break keeps it
+	if got := tr.DefaultBreak(session.StartTime, session.StartTime.Add(2*time.Hour)); got != 30 {
+		t.Errorf("default break for 2h = %d, want 30", got)
+	}
+}
+
+func TestBreakExceedingSessionNotRecorded(t *testing.T) {
+	tr, _ := newTestTracker(t, 38.5, func() time.Time { return time.Date(2024, 1, 17, 12, 0, 0, 0, time.UTC) })
+
+	// A 2-hour session with a 150-minute break would count as -0.5h
+	session, err := tr.ClockInWithTime("", "09:00")
+	if err != nil {
+		t.Fatalf("ClockInWithTime: %v", err)
+	}
+	if err := tr.EditSessionSelective(session.ID, -10, true, "", false, "", ""); err == nil {
+		t.Error("expected error for a negative break on an open session")
+	}
+	_, err = tr.ClockOutWithTime(session.ID, 150, "", "11:00")
+	if err == nil || !strings.Contains(err.Error(), "must be shorter than the 120-minute session") {
+		t.Fatalf("clock-out error = %v, want the break rejected against the session length", err)
+	}
+
+	if _, err := tr.ClockOutWithTime(session.ID, 0, "", "11:00"); err != nil {
+		t.Fatalf("ClockOutWithTime: %v", err)
+	}
+	if err := tr.EditSessionSelective(session.ID, 150, true, "", false, "", ""); err == nil {
+		t.Error("expected error when editing the break past the session length")
+	}
+	// Shortening the session below its break is rejected the same way
+	if err := tr.EditSessionSelective(session.ID, 90, true, "", false, "", "10:00"); err == nil {
+		t.Error("expected error when the new end leaves less time than the break")
+	}
+
+	progress, err := tr.GetTodayProgress()
+	if err != nil {
+		t.Fatalf("GetTodayProgress: %v", err)
+	}
+	if progress.TotalHours != 2 {
+		t.Errorf("today = %.2fh, want the 2h session untouched by the rejected breaks", progress.TotalHours)
+	}
+}
+
+func TestEditStartAfterEnd(t *testing.T) {
+	tr, db := newTestTracker(t, 38.5, func() time.Time { return time.Date(2024, 1, 17, 18, 0, 0, 0, time.UTC) })
+
+	session, err := tr.ClockInWithTime("", "09:00")
+	if err != nil {
+		t.Fatalf("ClockInWithTime: %v", err)
+	}
+	if _, err := tr.ClockOutWithTime(session.ID, 0, "", "12:00"); err != nil {
+		t.Fatalf("ClockOutWithTime: %v", err)
+	}
+
+	for _, start := range []string{"13:00", "12:00"} {
+		err := tr.EditSessionSelective(session.ID, 0, false, "", false, start, "")
+		if err == nil {
+			t.Fatalf("moving the start to %s should fail", start)
+		}
+		for _, want := range []string{session.ID[:8], "2024-01-17 12:00", "2024-01-17 " + start} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error %q should mention %q", err, want)
+			}
+		}
+	}
+
+	stored, err := db.GetSessionByID(session.ID)
+	if err != nil {
+		t.Fatalf("GetSessionByID: %v", err)
+	}
+	if stored.StartTime.Hour() != 9 {
+		t.Errorf("start = %s, want the rejected edit not saved", stored.StartTime.Format("15:04"))
+	}
+
+	// Moving both ends together is still fine
+	if err := tr.EditSessionSelective(session.ID, 0, false, "", false, "13:00", "15:00"); err != nil {
+		t.Errorf("moving start and end: %v", err)
+	}
+}
+
+func TestBatchEditIsAllOrNothing(t *testing.T) {
+	tr, db := newTestTracker(t, 38.5, func() time.Time { return time.Date(2024, 1, 17, 18, 0, 0, 0, time.UTC) })
+
+	var ids []string
+	for _, end := range []string{"17:00", "09:30"} {
+		session, err := tr.ClockInWithTime("", "09:00")
+		if err != nil {
+			t.Fatalf("ClockInWithTime: %v", err)
+		}
+		if _, err := tr.ClockOutWithTime(session.ID, 0, "", end); err != nil {
+			t.Fatalf("ClockOutWithTime: %v", err)
+		}
+		ids = append(ids, session.ID)
+	}
+
+	// A 45-minute break fits the first session but not the 30-minute second one
+	breakMinutes := 45
+	err := tr.ApplySessionEdits(ids, SessionEdit{BreakMinutes: &breakMinutes})
+	if err == nil || !strings.Contains(err.Error(), ids[1][:8]) {
+		t.Fatalf("error = %v, want the second session named", err)
+	}
+	for _, id := range ids {
+		stored, err := db.GetSessionByID(id)
+		if err != nil {
+			t.Fatalf("GetSessionByID: %v", err)
+		}
+		if stored.BreakMinutes != 0 {
+			t.Errorf("session %s break = %d, want no session changed", id[:8], stored.BreakMinutes)
+		}
+	}
+
+	breakMinutes = 15
+	if err := tr.ApplySessionEdits(ids, SessionEdit{BreakMinutes: &breakMinutes}); err != nil {
+		t.Fatalf("ApplySessionEdits: %v", err)
+	}
+	for _, id := range ids {
+		if stored, _ := db.GetSessionByID(id); stored.BreakMinutes != 15 {
+			t.Errorf("session %s break = %d, want 15", id[:8], stored.BreakMinutes)
+		}
+	}
+}
+
+func TestResolveActive(t *testing.T) {
+	now := time.Date(2024, 1, 17, 15, 0, 0, 0, time.UTC)
+	doneStart := time.Date(2024, 1, 17, 9, 0, 0, 0, time.UTC)
+	doneEnd := doneStart.Add(3 * time.Hour)
+	sessions := []storage.WorkSession{
+		{ID: "done", StartTime: doneStart, EndTime: &doneEnd},
+		{ID: "open", StartTime: time.Date(2024, 1, 17, 13, 0, 0, 0, time.UTC), BreakMinutes: 30},
+		{ID: "fresh", StartTime: now.Add(-10 * time.Minute), BreakMinutes: 30},
+	}
+
+	excluded := ResolveActive(sessions, now, false)
+	if len(excluded) != 1 || excluded[0].ID != "done" {
+		t.Fatalf("excluded = %v, want only the completed session", excluded)
+	}
+
+	included := ResolveActive(sessions, now, true)
+	if len(included) != 3 {
+		t.Fatalf("included %d sessions, want 3", len(included))
+	}
+	if hours := included[1].EndTime.Sub(included[1].StartTime).Hours() - 0.5; hours != 1.5 {
+		t.Errorf("open session hours = %.2f, want 1.50 elapsed minus break", hours)
+	}
+	if hours := included[2].EndTime.Sub(included[2].StartTime).Hours() - 0.5; hours != 0 {
+		t.Errorf("session shorter than its break = %.2f hours, want 0", hours)
+	}
+	if sessions[1].EndTime != nil {
+		t.Error("ResolveActive must not modify the input sessions")
+	}
+}
+
+func TestPlanWeek(t *testing.T) {
+	plan := PlanWeek(20, 38.5, 6, 3)
+	if plan.ProjectedHours != 38 || plan.Surplus != -0.5 || plan.MeetsGoal() {
+		t.Errorf("PlanWeek(20, 38.5, 6, 3) = %+v, want 38h projected, 0.5h short", plan)
+	}
+
+	plan = PlanWeek(30, 38.5, 8.5, 1)
+	if !plan.MeetsGoal() || plan.Surplus != 0 {
+		t.Errorf("exactly reaching the goal should meet it: %+v", plan)
+	}
+
+	if plan := PlanWeek(10, 38.5, 8, -2); plan.Days != 0 || plan.ProjectedHours != 10 {
+		t.Errorf("negative days should project no extra hours: %+v", plan)
+	}
+}
+
+func TestFindAndApplyRepairs(t *testing.T) {
+	now := time.Date(2024, 1, 17, 11, 0, 0, 0, time.UTC) // Wednesday
+	tr, db := newTestTracker(t, 38.5, func() time.Time { return now })
+
+	at := func(day, hour int) time.Time { return time.Date(2024, 1, day, hour, 0, 0, 0, time.UTC) }
+	ptr := func(t time.Time) *time.Time { return &t }
+	sessions := []*storage.WorkSession{
+		{ID: "stale", StartTime: at(15, 9)},
+		{ID: "zero", StartTime: at(16, 9), EndTime: ptr(at(16, 9))},
+		{ID: "longbreak", StartTime: at(16, 13), EndTime: ptr(at(16, 14)), BreakMinutes: 90},
+		{ID: "datecol", StartTime: at(16, 15), EndTime: ptr(at(16, 17)), BreakMinutes: 30},
+		{ID: "today", StartTime: at(17, 9)},
+		{ID: "overnight", StartTime: at(16, 22)}, // 13h open, under the 16h threshold
+	}
+	for _, s := range sessions {
+		if err := db.InsertSession(s); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+	}
+	if err := db.Exec("UPDATE work_sessions SET date = '2024-01-10' WHERE id = 'datecol'"); err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+
+	repairs, err := tr.FindRepairs()
+	if err != nil {
+		t.Fatalf("FindRepairs: %v", err)
+	}
+	kinds := make(map[string]string)
+	for _, r := range repairs {
+		kinds[r.SessionID] = r.Kind
+	}
+	want := map[string]string{
+		"stale":     RepairStaleOpen,
+		"zero":      RepairZeroDuration,
+		"longbreak": RepairLongBreak,
+		"datecol":   RepairDate,
+	}
+	if len(repairs) != len(want) {
+		t.Fatalf("got %d repairs (%v), want %d", len(repairs), kinds, len(want))
+	}
+	for id, kind := range want {
+		if kinds[id] != kind {
+			t.Errorf("session %s: repair %q, want %q", id, kinds[id], kind)
+		}
+	}
+
+	for _, r := range repairs {
+		if err := tr.ApplyRepair(r); err != nil {
+			t.Fatalf("ApplyRepair(%s): %v", r.SessionID, err)
+		}
+	}
+
+	stale, _ := db.GetSessionByID("stale")
+	if stale.EndTime == nil || !stale.EndTime.Equal(at(15, 9).Add(8*time.Hour+12*time.Minute)) {
+		t.Errorf("stale session end = %v, want start + 7.7h target + 30min break", stale.EndTime)
+	}
+	if zero, _ := db.GetSessionByID("zero"); zero != nil {
+		t.Error("zero-duration session should be deleted")
+	}
+	if lb, _ := db.GetSessionByID("longbreak"); lb.BreakMinutes != 30 {
+		t.Errorf("clamped break = %d, want 30", lb.BreakMinutes)
+	}
+
+	again, err := tr.FindRepairs()
+	if err != nil || len(again) != 0 {
+		t.Errorf("after repair: %d repairs, err %v; want none", len(again), err)
+	}
+}
+
+func TestMonthlyWeekNumbering(t *testing.T) {
+	tr, db := newTestTracker(t, 38.5, func() time.Time { return time.Date(2027, 1, 15, 12, 0, 0, 0, time.UTC) })
+
+	// Jan 1-3, 2027 fall in ISO week 53 of 2026
+	for _, day := range []int{1, 4, 11} {
+		start := time.Date(2027, 1, day, 9, 0, 0, 0, time.UTC)
+		end := start.Add(4 * time.Hour)
+		if err := db.InsertSession(&storage.WorkSession{StartTime: start, EndTime: &end}); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+	}
+
+	iso, err := tr.GetMonthlyProgress()
+	if err != nil {
+		t.Fatalf("GetMonthlyProgress: %v", err)
+	}
+	if got := iso.Weeks; len(got) != 3 || got[0] != 53 || got[1] != 1 || got[2] != 2 {
+		t.Errorf("ISO weeks = %v, want [53 1 2] in calendar order", got)
+	}
+
+	tr.SetWeekNumbering(work.WeekNumberingMonth)
+	month, err := tr.GetMonthlyProgress()
+	if err != nil {
+		t.Fatalf("GetMonthlyProgress: %v", err)
+	}
+	if got := month.Weeks; len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Errorf("month weeks = %v, want [1 2 3]", got)
+	}
+	if month.WeekHours[1] != 4 || month.WeekNumbering != work.WeekNumberingMonth {
+		t.Errorf("week 1 = %.2fh (%s), want 4h with month numbering", month.WeekHours[1], month.WeekNumbering)
+	}
+}
+
+func TestProjectBreakdownAndEdit(t *testing.T) {
+	now := time.Date(2024, 1, 17, 18, 0, 0, 0, time.UTC)
+	tr, db := newTestTracker(t, 38.5, func() time.Time { return now })
+
+	add := func(project string, startHour, hours int) *storage.WorkSession {
+		start := time.Date(2024, 1, 17, startHour, 0, 0, 0, time.UTC)
+		end := start.Add(time.Duration(hours) * time.Hour)
+		s := &storage.WorkSession{StartTime: start, EndTime: &end, Project: project}
+		if err := db.InsertSession(s); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+		return s
+	}
+	add("acme", 8, 2)
+	add("globex", 10, 3)
+	untagged := add("", 14, 1)
+
+	session, err := tr.ClockInWithProject("", "17:00", "acme", "billable", "remote")
+	if err != nil {
+		t.Fatalf("ClockInWithProject: %v", err)
+	}
+	if got, _ := db.GetSessionByID(session.ID); len(got.Tags) != 2 || got.Tags[0] != "billable" {
+		t.Errorf("tags = %v, want [billable remote]", got.Tags)
+	}
+
+	// Move the untagged hour to acme; open sessions are not counted
+	project := "acme"
+	tags := []string{"billable"}
+	if err := tr.ApplySessionEdit(untagged.ID, SessionEdit{Project: &project, Tags: &tags}); err != nil {
+		t.Fatalf("ApplySessionEdit: %v", err)
+	}
+
+	breakdown, err := tr.GetProjectBreakdown(now, now)
+	if err != nil {
+		t.Fatalf("GetProjectBreakdown: %v", err)
+	}
+	if len(breakdown) != 2 {
+		t.Fatalf("got %d projects (%+v), want 2", len(breakdown), breakdown)
+	}
+	if breakdown[0].Name != "acme" || breakdown[0].Hours != 3 || breakdown[0].Sessions != 2 {
+		t.Errorf("breakdown[0] = %+v, want acme 3h over 2 sessions", breakdown[0])
+	}
+	if breakdown[1].Name != "globex" || breakdown[1].Hours != 3 {
+		t.Errorf("breakdown[1] = %+v, want globex 3h", breakdown[1])
+	}
+
+	if _, err := tr.GetProjectBreakdown(now, now.AddDate(0, 0, -1)); err == nil {
+		t.Error("expected error for reversed range")
+	}
+
+	// All-time totals round like every other total and skip the open session
+	start := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	end := start.Add(50 * time.Minute)
+	if err := db.InsertSession(&storage.WorkSession{StartTime: start, EndTime: &end, Project: "globex"}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+	if err := db.EnsureProject("docs"); err != nil {
+		t.Fatalf("EnsureProject: %v", err)
+	}
+	tr.SetRoundingMinutes(15)
+	projects, err := tr.ListProjects()
+	if err != nil {
+		t.Fatalf("ListProjects: %v", err)
+	}
+	want := []storage.ProjectSummary{{Name: "acme", Hours: 3, Sessions: 2}, {Name: "docs"}, {Name: "globex", Hours: 3.75, Sessions: 2}}
+	if len(projects) != len(want) {
+		t.Fatalf("projects = %+v, want %+v", projects, want)
+	}
+	for i := range want {
+		if projects[i] != want[i] {
+			t.Errorf("projects[%d] = %+v, want %+v", i, projects[i], want[i])
+		}
+	}
+}
+
+func TestEditAppendNote(t *testing.T) {
+	tr, db := newTestTracker(t, 38.5, func() time.Time { return time.Date(2024, 1, 17, 12, 0, 0, 0, time.UTC) })
+
+	session, err := tr.ClockInWithTime("", "09:00")
+	if err != nil {
+		t.Fatalf("ClockInWithTime: %v", err)
+	}
+	note := func() string {
+		got, _ := db.GetSessionByID(session.ID)
+		return got.Note
+	}
+
+	// Appending to an empty note adds no separator
+	if err := tr.ApplySessionEdit(session.ID, SessionEdit{AppendNote: "standup"}); err != nil {
+		t.Fatalf("ApplySessionEdit: %v", err)
+	}
+	if got := note(); got != "standup" {
+		t.Errorf("note = %q, want %q", got, "standup")
+	}
+
+	if err := tr.ApplySessionEdit(session.ID, SessionEdit{AppendNote: "finished feature X"}); err != nil {
+		t.Fatalf("ApplySessionEdit: %v", err)
+	}
+	if got := note(); got != "standup; finished feature X" {
+		t.Errorf("note = %q, want the original text kept", got)
+	}
+
+	replacement := "review"
+	if err := tr.ApplySessionEdit(session.ID, SessionEdit{Note: &replacement}); err != nil {
+		t.Fatalf("ApplySessionEdit: %v", err)
+	}
+	if got := note(); got != "review" {
+		t.Errorf("note = %q, want --note to replace it", got)
+	}
+}
+
+func TestOpenSessionAgeStale(t *testing.T) {
+	now := time.Date(2024, 1, 18, 10, 0, 0, 0, time.UTC)
+	session := &storage.WorkSession{ID: "abcdef123456", StartTime: now.Add(-73*time.Hour - 12*time.Minute)}
+
+	age := CheckOpenSession(session, now, DefaultStaleSessionHours)
+	if !age.Stale {
+		t.Fatal("3-day-old open session not flagged as stale")
+	}
+	if age.Elapsed != DefaultStaleSessionHours*time.Hour {
+		t.Errorf("Elapsed = %v, want capped at %dh", age.Elapsed, DefaultStaleSessionHours)
+	}
+	if age.Actual != 73*time.Hour+12*time.Minute {
+		t.Errorf("Actual = %v, want 73h12m", age.Actual)
+	}
+	if want := "session open for 3 days — did you forget to clock out?"; age.Warning != want {
+		t.Errorf("Warning = %q, want %q", age.Warning, want)
+	}
+	if !strings.Contains(age.Fix, "kairos edit abcdef12 --end") {
+		t.Errorf("Fix = %q, want an edit command for the session", age.Fix)
+	}
+
+	fresh := &storage.WorkSession{StartTime: now.Add(-2 * time.Hour)}
+	if age := CheckOpenSession(fresh, now, DefaultStaleSessionHours); age.Stale || age.Elapsed != 2*time.Hour {
+		t.Errorf("2h session = %+v, want 2h and not stale", age)
+	}
+	if age := CheckOpenSession(session, now, 0); age.Stale || age.Elapsed != age.Actual {
+		t.Errorf("threshold 0 = %+v, want no cap", age)
+	}
+}
+
+func TestMaxNoteLength(t *testing.T) {
+	tr, db := newTestTracker(t, 38.5, func() time.Time { return time.Date(2024, 1, 17, 12, 0, 0, 0, time.UTC) })
+
+	// No limit by default
+	long := strings.Repeat("x", 500)
+	if got, err := tr.FitNote(long); err != nil || got != long {
+		t.Errorf("FitNote without limit = %d chars, %v; want the note unchanged", len(got), err)
+	}
+
+	tr.SetMaxNoteLength(10)
+	if got, err := tr.FitNote("exactly10!"); err != nil || got != "exactly10!" {
+		t.Errorf("FitNote at the limit = %q, %v; want it accepted", got, err)
+	}
+	// Characters, not bytes: ten accented letters are 20 bytes
+	if _, err := tr.FitNote(strings.Repeat("é", 10)); err != nil {
+		t.Errorf("FitNote of 10 multi-byte characters: %v", err)
+	}
+	if _, err := tr.FitNote("eleven char"); err == nil || !strings.Contains(err.Error(), "11 characters") {
+		t.Errorf("FitNote over the limit error = %v, want one giving the length", err)
+	}
+	if _, err := tr.ClockInWithTime("eleven char", "09:00"); err == nil {
+		t.Error("ClockInWithTime accepted a note over the limit")
+	}
+
+	session, err := tr.ClockInWithTime("standup", "09:00")
+	if err != nil {
+		t.Fatalf("ClockInWithTime: %v", err)
+	}
+	if err := tr.ApplySessionEdit(session.ID, SessionEdit{AppendNote: "review"}); err == nil {
+		t.Error("appending past the limit was accepted")
+	}
+
+	tr.SetTruncateNotes(true)
+	if err := tr.ApplySessionEdit(session.ID, SessionEdit{AppendNote: "review"}); err != nil {
+		t.Fatalf("ApplySessionEdit with truncation: %v", err)
+	}
+	if got, _ := db.GetSessionByID(session.ID); got.Note != "standup..." {
+		t.Errorf("truncated note = %q, want %q", got.Note, "standup...")
+	}
+}
+
+func TestWeekDayTargets(t *testing.T) {
+	tr, _ := newTestTracker(t, 38.5, func() time.Time { return time.Date(2024, 1, 17, 12, 0, 0, 0, time.UTC) })
+
+	week, err := tr.GetWeeklyProgress()
+	if err != nil {
+		t.Fatalf("GetWeeklyProgress: %v", err)
+	}
+	if len(week.DayTargets) != 7 {
+		t.Fatalf("DayTargets has %d days, want 7", len(week.DayTargets))
+	}
+	if got := week.DayTargets["2024-01-16"]; got != 7.7 {
+		t.Errorf("Tuesday target = %.2f, want 7.70", got)
+	}
+	if got, ok := week.DayTargets["2024-01-20"]; !ok || got != 0 {
+		t.Errorf("Saturday target = %.2f (present %t), want 0", got, ok)
+	}
+
+	// A four-day week spreads the goal over fewer days
+	tr.SetRules(work.Rules{WorkDays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday}})
+	week, _ = tr.GetWeeklyProgress()
+	if got := week.DayTargets["2024-01-16"]; got != 9.625 {
+		t.Errorf("four-day Tuesday target = %.3f, want 9.625", got)
+	}
+	if got := week.DayTargets["2024-01-19"]; got != 0 {
+		t.Errorf("four-day Friday target = %.2f, want 0", got)
+	}
+}
+
+func TestResolveSessionIDs(t *testing.T) {
+	tr, _ := newTestTracker(t, 38.5, func() time.Time { return time.Date(2024, 1, 17, 18, 0, 0, 0, time.UTC) })
+
+	first, err := tr.ClockInWithTime("first", "09:00")
+	if err != nil {
+		t.Fatalf("ClockInWithTime: %v", err)
+	}
+	if _, err := tr.ClockOutWithTime(first.ID, 0, "", "12:00"); err != nil {
+		t.Fatalf("ClockOutWithTime: %v", err)
+	}
+	second, err := tr.ClockInWithTime("second", "13:00")
+	if err != nil {
+		t.Fatalf("ClockInWithTime: %v", err)
+	}
+
+	found, missing, err := tr.ResolveSessionIDs([]string{first.ID[:8], " " + second.ID + " ", first.ID, "", "deadbeef"})
+	if err != nil {
+		t.Fatalf("ResolveSessionIDs: %v", err)
+	}
+	if len(found) != 2 || found[0].ID != first.ID || found[1].ID != second.ID {
+		t.Errorf("found = %v, want the two sessions once each in order", found)
+	}
+	if len(missing) != 1 || missing[0] != "deadbeef" {
+		t.Errorf("missing = %v, want [deadbeef]", missing)
+	}
+}
+
+func TestAddSession(t *testing.T) {
+	tr, _ := newTestTracker(t, 38.5, func() time.Time { return time.Date(2024, 1, 17, 18, 0, 0, 0, time.UTC) })
+	day := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+
+	session, err := tr.AddSession(day, "09:00", "17:30", 30, "backfill")
+	if err != nil {
+		t.Fatalf("AddSession: %v", err)
+	}
+	if session.EndTime == nil || !session.StartTime.Equal(day.Add(9*time.Hour)) || !session.EndTime.Equal(day.Add(17*time.Hour+30*time.Minute)) {
+		t.Errorf("session = %v-%v, want Jan 16 09:00-17:30", session.StartTime, session.EndTime)
+	}
+	if hours, _ := storage.SessionNetHours(*session, tr.Now()); hours != 8 {
+		t.Errorf("hours = %v, want 8", hours)
+	}
+
+	tests := []struct {
+		name       string
+		date       time.Time
+		start, end string
+		brk        int
+	}{
+		{"overlap", day, "17:00", "19:00", 0},
+		{"future", day.AddDate(0, 0, 1), "17:00", "19:00", 0},
+		{"bad time", day, "9am", "12:00", 0},
+		{"break too long", day.AddDate(0, 0, -1), "09:00", "10:00", 60},
+	}
+	for _, tt := range tests {
+		if _, err := tr.AddSession(tt.date, tt.start, tt.end, tt.brk, ""); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestMonthAndDayGoals(t *testing.T) {
+	tr, db := newTestTracker(t, 38.5, func() time.Time { return time.Date(2027, 1, 15, 18, 0, 0, 0, time.UTC) })
+
+	start := time.Date(2027, 1, 15, 8, 0, 0, 0, time.UTC)
+	end := start.Add(8 * time.Hour)
+	if err := db.InsertSession(&storage.WorkSession{StartTime: start, EndTime: &end}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+
+	// January 2027 has 21 weekdays at 7.7h each
+	month, err := tr.GetMonthlyProgress()
+	if err != nil {
+		t.Fatalf("GetMonthlyProgress: %v", err)
+	}
+	if math.Abs(month.GoalHours-161.7) > 0.001 || math.Abs(month.RemainingHours-153.7) > 0.001 {
+		t.Errorf("month goal = %.2f, remaining %.2f; want 161.70 and 153.70", month.GoalHours, month.RemainingHours)
+	}
+
+	day, err := tr.GetTodayProgress()
+	if err != nil {
+		t.Fatalf("GetTodayProgress: %v", err)
+	}
+	if math.Abs(day.Goal-7.7) > 0.001 || day.TotalHours < day.Goal {
+		t.Errorf("Friday goal = %.2f with %.2fh worked, want 7.70 met", day.Goal, day.TotalHours)
+	}
+
+	tr.nowFn = func() time.Time { return time.Date(2027, 1, 16, 12, 0, 0, 0, time.UTC) }
+	if day, _ := tr.GetTodayProgress(); day.Goal != 0 {
+		t.Errorf("Saturday goal = %.2f, want 0", day.Goal)
+	}
+}
+
+func TestParseDateInputWeek(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	weekStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"month and day", "Jan 2"},
+		{"padded day", "Jan 02"},
+		{"numeric", "1/2"},
+		{"ISO date", "2024-01-02"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, err := parseDateInputAt(tt.input, time.UTC, now)
+			if err != nil {
+				t.Fatalf("parseDateInputAt(%q): %v", tt.input, err)
+			}
+			if !date.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("parseDateInputAt(%q) = %v, want 2024-01-02", tt.input, date)
+			}
+			if got := getWeekStartOn(date, time.Monday); !got.Equal(weekStart) {
+				t.Errorf("week of %q starts %v, want %v", tt.input, got, weekStart)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "tomorrow", "13/45", "Feb 30"} {
+		if _, err := parseDateInputAt(input, time.UTC, now); err == nil {
+			t.Errorf("parseDateInputAt(%q) succeeded, want an error", input)
+		}
+	}
+	// Feb 29 only in leap years
+	if _, err := parseDateInputAt("Feb 29", time.UTC, now); err != nil {
+		t.Errorf("Feb 29 in 2024: %v", err)
+	}
+	if _, err := parseDateInputAt("Feb 29", time.UTC, now.AddDate(1, 0, 0)); err == nil {
+		t.Error("Feb 29 in 2025 succeeded, want an error")
+	}
+}
+
+func TestWeekProgressBoundaries(t *testing.T) {
+	tr, db := newTestTracker(t, 38.5, func() time.Time { return time.Date(2024, 1, 17, 12, 0, 0, 0, time.UTC) })
+
+	// A Sunday session, looked at from Wednesday Jan 17 2024
+	start := time.Date(2024, 1, 14, 9, 0, 0, 0, time.UTC)
+	end := start.Add(8 * time.Hour)
+	if err := db.InsertSession(&storage.WorkSession{Date: start, StartTime: start, EndTime: &end}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+
+	tests := []struct {
+		weekStart time.Weekday
+		from, to  string
+		hours     float64
+	}{
+		{time.Monday, "Mon 2024-01-15", "Sun 2024-01-21", 0},
+		{time.Sunday, "Sun 2024-01-14", "Sat 2024-01-20", 8},
+	}
+	for _, tt := range tests {
+		tr.SetWeekStart(tt.weekStart)
+		week, err := tr.GetWeeklyProgress()
+		if err != nil {
+			t.Fatalf("GetWeeklyProgress: %v", err)
+		}
+		from, to := week.WeekStart.Format("Mon 2006-01-02"), week.WeekEnd.Format("Mon 2006-01-02")
+		if from != tt.from || to != tt.to {
+			t.Errorf("%s-start week = %s - %s, want %s - %s", tt.weekStart, from, to, tt.from, tt.to)
+		}
+		if week.TotalHours != tt.hours {
+			t.Errorf("%s-start week total = %.2f, want %.2f", tt.weekStart, week.TotalHours, tt.hours)
+		}
+	}
+}
+
+func TestRoundingMinutes(t *testing.T) {
+	tr, db := newTestTracker(t, 38.5, func() time.Time { return time.Date(2024, 1, 17, 12, 0, 0, 0, time.UTC) })
+
+	// Two 1h07m sessions: 2h14m in all, but 1h each at quarter hours
+	for _, hour := range []int{9, 13} {
+		start := time.Date(2024, 1, 16, hour, 0, 0, 0, time.UTC)
+		end := start.Add(67 * time.Minute)
+		if err := db.InsertSession(&storage.WorkSession{Date: start, StartTime: start, EndTime: &end}); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+	}
+	tr.SetRoundingMinutes(15)
+
+	week, err := tr.GetWeeklyProgress()
+	if err != nil {
+		t.Fatalf("GetWeeklyProgress: %v", err)
+	}
+	if week.TotalHours != 2 || week.DaysWorked["2024-01-16"] != 2 {
+		t.Errorf("rounded week = %.4fh (day %.4fh), want 2h from two rounded sessions", week.TotalHours, week.DaysWorked["2024-01-16"])
+	}
+
+	tr.SetRoundingMinutes(0)
+	week, _ = tr.GetWeeklyProgress()
+	if got := week.TotalHours; got < 2.23 || got > 2.24 {
+		t.Errorf("unrounded week = %.4fh, want 2h14m", got)
+	}
+}
+
+func TestSessionsCountOnStartDay(t *testing.T) {
+	vienna, err := time.LoadLocation("Europe/Vienna")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	db, err := storage.New(t.TempDir()+"/test.db", vienna)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	defer db.Close()
+
+	at := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2024, month, day, hour, min, 0, 0, vienna)
+	}
+	insert := func(start time.Time, end *time.Time) string {
+		s := &storage.WorkSession{Date: start, StartTime: start, EndTime: end}
+		if err := db.InsertSession(s); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+		return s.ID
+	}
+	// Clocks jump from 02:00 to 03:00 on Sunday Mar 31, so Saturday 23:30
+	// to Sunday 03:30 is 3 hours worked, not 4
+	dstEnd := at(time.March, 31, 3, 30)
+	insert(at(time.March, 30, 23, 30), &dstEnd)
+	// Sunday night into Monday crosses the week and month boundary
+	weekEnd := at(time.April, 1, 1, 0)
+	insert(at(time.March, 31, 23, 0), &weekEnd)
+
+	tr := NewWithLocation(db, 38.5, vienna)
+	tr.nowFn = func() time.Time { return at(time.April, 1, 10, 0) }
+
+	today, err := tr.GetTodayProgress()
+	if err != nil {
+		t.Fatalf("GetTodayProgress: %v", err)
+	}
+	if today.TotalHours != 0 || len(today.Sessions) != 0 {
+		t.Errorf("Monday = %.2fh from %d sessions, want Sunday's session left on Sunday", today.TotalHours, len(today.Sessions))
+	}
+	week, _ := tr.GetWeeklyProgress()
+	if week.TotalHours != 0 {
+		t.Errorf("this week = %.2fh, want 0", week.TotalHours)
+	}
+	month, _ := tr.GetMonthlyProgress()
+	if month.TotalHours != 0 {
+		t.Errorf("April = %.2fh, want 0", month.TotalHours)
+	}
+
+	last, err := tr.GetLastWeekProgress()
+	if err != nil {
+		t.Fatalf("GetLastWeekProgress: %v", err)
+	}
+	if last.TotalHours != 5 || last.DaysWorked["2024-03-30"] != 3 || last.DaysWorked["2024-03-31"] != 2 {
+		t.Errorf("last week = %.2fh by day %v, want 3h on Mar 30 and 2h on Mar 31", last.TotalHours, last.DaysWorked)
+	}
+
+	// A session still open after midnight stays current but counts on the
+	// day it started
+	open := insert(at(time.April, 1, 23, 30), nil)
+	tr.nowFn = func() time.Time { return at(time.April, 2, 0, 30) }
+	tr.SetIncludeActive(true)
+	today, err = tr.GetTodayProgress()
+	if err != nil {
+		t.Fatalf("GetTodayProgress: %v", err)
+	}
+	if today.CurrentSessionID != open {
+		t.Errorf("CurrentSessionID = %q, want the open session %q", today.CurrentSessionID, open)
+	}
+	if today.TotalHours != 0 {
+		t.Errorf("Tuesday = %.2fh, want the open session counted on Monday", today.TotalHours)
+	}
+	week, _ = tr.GetWeeklyProgress()
+	if week.ActiveHours != 1 || week.DaysWorked["2024-04-01"] != 1 {
+		t.Errorf("this week active = %.2fh by day %v, want 1h on Apr 1", week.ActiveHours, week.DaysWorked)
+	}
+}
+
+func TestSplitSessionAcrossDays(t *testing.T) {
+	start := time.Date(2024, 1, 15, 22, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 16, 6, 0, 0, 0, time.UTC)
+	pieces := SplitSessionAcrossDays(storage.WorkSession{ID: "night", StartTime: start, EndTime: &end, BreakMinutes: 30, Note: "shift"})
+	if len(pieces) != 2 {
+		t.Fatalf("got %d pieces, want 2", len(pieces))
+	}
+	want := []struct {
+		date         string
+		from, to     string
+		breakMinutes int
+	}{
+		{"2024-01-15", "22:00", "00:00", 8}, // 2h of 8h: a quarter of the break
+		{"2024-01-16", "00:00", "06:00", 22},
+	}
+	for i, w := range want {
+		p := pieces[i]
+		if p.Date.Format("2006-01-02") != w.date || p.StartTime.Format("15:04") != w.from || p.EndTime.Format("15:04") != w.to || p.BreakMinutes != w.breakMinutes {
+			t.Errorf("piece %d = %s %s-%s break %d, want %s %s-%s break %d", i,
+				p.Date.Format("2006-01-02"), p.StartTime.Format("15:04"), p.EndTime.Format("15:04"), p.BreakMinutes,
+				w.date, w.from, w.to, w.breakMinutes)
+		}
+		if p.ID != "night" || p.Note != "shift" {
+			t.Errorf("piece %d lost the session's ID or note: %+v", i, p)
+		}
+	}
+
+	// Sessions within a day and open sessions stay whole
+	dayEnd := start.Add(time.Hour)
+	if got := SplitSessionAcrossDays(storage.WorkSession{StartTime: start, EndTime: &dayEnd}); len(got) != 1 {
+		t.Errorf("same-day session split into %d pieces", len(got))
+	}
+	if got := SplitSessionAcrossDays(storage.WorkSession{StartTime: start}); len(got) != 1 || got[0].EndTime != nil {
+		t.Errorf("open session = %+v, want it unchanged", got)
+	}
+}
+
+func TestSplitAtMidnight(t *testing.T) {
+	tr, db := newTestTracker(t, 38.5, func() time.Time { return time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC) })
+
+	// Sunday 22:00 to Monday 06:00 with no break
+	start := time.Date(2024, 1, 14, 22, 0, 0, 0, time.UTC)
+	end := start.Add(8 * time.Hour)
+	if err := db.InsertSession(&storage.WorkSession{Date: start, StartTime: start, EndTime: &end}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+
+	week, _ := tr.GetWeeklyProgress()
+	if week.TotalHours != 0 {
+		t.Errorf("unsplit week = %.2fh, want the shift on Sunday", week.TotalHours)
+	}
+
+	tr.SetSplitAtMidnight(true)
+	week, err := tr.GetWeeklyProgress()
+	if err != nil {
+		t.Fatalf("GetWeeklyProgress: %v", err)
+	}
+	if week.TotalHours != 6 || week.DaysWorked["2024-01-15"] != 6 {
+		t.Errorf("split week = %.2fh by day %v, want Monday's 6h", week.TotalHours, week.DaysWorked)
+	}
+	last, _ := tr.GetLastWeekProgress()
+	if last.TotalHours != 2 || last.DaysWorked["2024-01-14"] != 2 {
+		t.Errorf("split last week = %.2fh by day %v, want Sunday's 2h", last.TotalHours, last.DaysWorked)
+	}
+	today, _ := tr.GetTodayProgress()
+	if today.TotalHours != 6 {
+		t.Errorf("split Monday = %.2fh, want 6h", today.TotalHours)
+	}
+
+	// Storage keeps the session whole
+	stored, err := db.GetSessionsInRange(start, end)
+	if err != nil || len(stored) != 1 || !stored[0].EndTime.Equal(end) {
+		t.Errorf("stored sessions = %+v, %v; want the one unsplit session", stored, err)
+	}
+}
+
+func TestComputeStats(t *testing.T) {
+	tr, db := newTestTracker(t, 38.5, nil)
+
+	add := func(day, hour, minutes int) {
+		start := time.Date(2024, 1, day, hour, 0, 0, 0, time.UTC)
+		end := start.Add(time.Duration(minutes) * time.Minute)
+		if err := db.InsertSession(&storage.WorkSession{Date: start, StartTime: start, EndTime: &end}); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+	}
+	// Mon 8 - Wed 10 worked, Thu 11 missed, Fri 12 and (after the weekend)
+	// Mon 15 worked; Tuesday 9 has two sessions
+	add(8, 9, 8*60)
+	add(9, 7, 4*60)
+	add(9, 13, 6*60)
+	add(10, 10, 6*60)
+	add(12, 8, 5*60)
+	add(15, 9, 7*60)
+
+	// Tuesday 16, not worked yet
+	tr.nowFn = func() time.Time { return time.Date(2024, 1, 16, 8, 0, 0, 0, time.UTC) }
+
+	stats, err := tr.ComputeStats(time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), tr.Now())
+	if err != nil {
+		t.Fatalf("ComputeStats: %v", err)
+	}
+	if stats.DaysWorked != 5 || stats.Sessions != 6 || stats.TotalHours != 36 {
+		t.Errorf("days/sessions/hours = %d/%d/%.2f, want 5/6/36", stats.DaysWorked, stats.Sessions, stats.TotalHours)
+	}
+	if stats.AvgDailyHours != 7.2 {
+		t.Errorf("AvgDailyHours = %.2f, want 7.2", stats.AvgDailyHours)
+	}
+	if stats.MedianSessionHours != 6 {
+		t.Errorf("MedianSessionHours = %.2f, want 6", stats.MedianSessionHours)
+	}
+	// Thursday breaks the first run; the weekend doesn't break the second,
+	// and an unworked today doesn't end it
+	if stats.LongestStreak != 3 || stats.CurrentStreak != 2 {
+		t.Errorf("streaks = longest %d, current %d; want 3 and 2", stats.LongestStreak, stats.CurrentStreak)
+	}
+	if stats.MostProductiveDay != time.Tuesday {
+		t.Errorf("MostProductiveDay = %s, want Tuesday (10h)", stats.MostProductiveDay)
+	}
+	if got := stats.EarliestClockIn.Format("01-02 15:04"); got != "01-09 07:00" {
+		t.Errorf("EarliestClockIn = %s, want 01-09 07:00", got)
+	}
+	if got := stats.LatestClockIn.Format("01-02 15:04"); got != "01-09 13:00" {
+		t.Errorf("LatestClockIn = %s, want 01-09 13:00", got)
+	}
+
+	// A missed work day before now ends the current streak
+	tr.nowFn = func() time.Time { return time.Date(2024, 1, 17, 8, 0, 0, 0, time.UTC) }
+	if stats, _ := tr.ComputeStats(time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), tr.Now()); stats.CurrentStreak != 0 {
+		t.Errorf("CurrentStreak after a missed Tuesday = %d, want 0", stats.CurrentStreak)
+	}
+
+	empty, _ := tr.ComputeStats(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC))
+	if empty.Sessions != 0 || empty.AvgDailyHours != 0 || empty.MedianSessionHours != 0 || !empty.EarliestClockIn.IsZero() {
+		t.Errorf("empty range = %+v, want zero stats", empty)
+	}
+}
+
+func TestFlexBalance(t *testing.T) {
+	tr, db := newTestTracker(t, 40, func() time.Time { return time.Date(2024, 1, 17, 12, 0, 0, 0, time.UTC) })
+	if balance, err := tr.GetFlexBalance(tr.Now()); err != nil || balance != 0 {
+		t.Errorf("empty database: balance = %.2f, %v; want 0", balance, err)
+	}
+
+	add := func(day int, hours float64) {
+		start := time.Date(2024, 1, day, 9, 0, 0, 0, time.UTC)
+		end := start.Add(time.Duration(hours * float64(time.Hour)))
+		if err := db.InsertSession(&storage.WorkSession{Date: start, StartTime: start, EndTime: &end}); err != nil {
+			t.Fatalf("InsertSession: %v", err)
+		}
+	}
+	// Week of Jan 1: 5 x 9h = 45h (+5). Week of Jan 8: 3 x 8h = 24h (-16).
+	// Week of Jan 15 is still running and not counted.
+	for day := 1; day <= 5; day++ {
+		add(day, 9)
+	}
+	for day := 8; day <= 10; day++ {
+		add(day, 8)
+	}
+	add(15, 10)
+
+	balance, err := tr.GetFlexBalance(tr.Now())
+	if err != nil {
+		t.Fatalf("GetFlexBalance: %v", err)
+	}
+	if balance != -11 {
+		t.Errorf("balance = %.2f, want +5 - 16 = -11", balance)
+	}
+
+	// A start mid-week counts from that week
+	tr.SetFlexStart(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC))
+	if balance, _ := tr.GetFlexBalance(tr.Now()); balance != -16 {
+		t.Errorf("balance from Jan 10 = %.2f, want -16", balance)
+	}
+	// Up to a day in the week of Jan 8, nothing is complete yet
+	if balance, _ := tr.GetFlexBalance(time.Date(2024, 1, 14, 23, 0, 0, 0, time.UTC)); balance != 0 {
+		t.Errorf("balance up to Jan 14 = %.2f, want 0", balance)
+	}
+}
+
+func TestShouldNotify(t *testing.T) {
+	tr, db := newTestTracker(t, 40, nil)
+	tr.SetAutoClockoutMinutes(300)
+	at := func(hour, min int) time.Time { return time.Date(2024, 1, 17, hour, min, 0, 0, time.UTC) }
+	if _, ok := tr.ShouldNotify(at(16, 30)); ok {
+		t.Error("notified without an active session")
+	}
+
+	morning, noon := at(8, 0), at(12, 0)
+	if err := db.InsertSession(&storage.WorkSession{Date: morning, StartTime: morning, EndTime: &noon}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+	afternoon := at(13, 0)
+	if err := db.InsertSession(&storage.WorkSession{Date: afternoon, StartTime: afternoon}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+
+	// 4h in the morning plus the running session against an 8h target and a
+	// 5h auto clock-out (at 18:00)
+	for _, tc := range []struct {
+		now  time.Time
+		want string
+	}{
+		{at(16, 0), ""},
+		{at(16, 30), "30 minutes left to today's 8.00h target"},
+		{at(16, 31), ""},
+		{at(17, 0), "Reached today's 8.00h target"},
+		{at(17, 5), ""},
+		{at(17, 30), "Auto clock-out in 30 minutes"},
+		{at(18, 30), ""},
+	} {
+		reason, ok := tr.ShouldNotify(tc.now)
+		if ok != (tc.want != "") || reason != tc.want {
+			t.Errorf("ShouldNotify(%s) = %q, %v; want %q", tc.now.Format("15:04"), reason, ok, tc.want)
+		}
+	}
+}
+
+func TestWeekGoalProratesHolidays(t *testing.T) {
+	tr, _ := newTestTracker(t, 38.5, func() time.Time { return time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC) })
+	var rules work.Rules
+	rules.AddHoliday("2024-01-17")
+	tr.SetRules(rules)
+
+	week, err := tr.GetWeeklyProgress()
+	if err != nil {
+		t.Fatalf("GetWeeklyProgress: %v", err)
+	}
+	if !week.Holidays["2024-01-17"] || len(week.Holidays) != 1 {
+		t.Errorf("Holidays = %v, want Wednesday", week.Holidays)
+	}
+	if got := week.DayTargets["2024-01-17"]; got != 0 {
+		t.Errorf("holiday target = %.2f, want 0", got)
+	}
+	if week.Goal != 30.8 || week.RemainingHours != 30.8 || week.RemainingWorkDays != 4 {
+		t.Errorf("goal/remaining/days = %.2f/%.2f/%d, want 30.80/30.80/4", week.Goal, week.RemainingHours, week.RemainingWorkDays)
+	}
+	if got := tr.MonthlyGoal(tr.Now()); got != 7.7*22 {
+		t.Errorf("January goal = %.2f, want %.2f (22 work days)", got, 7.7*22)
+	}
+}
+
+func TestAutoCloseStaleSessions(t *testing.T) {
+	tr, db := newTestTracker(t, 38.5, nil)
+	now := time.Date(2024, 1, 15, 16, 0, 0, 0, time.UTC) // Monday
+	tr.nowFn = func() time.Time { return now }
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	if err := db.InsertSession(&storage.WorkSession{Date: start, StartTime: start, Note: "deploy"}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+
+	for _, limit := range []int{0, 480} {
+		if closed, err := tr.AutoCloseStaleSessions(limit); err != nil || len(closed) != 0 {
+			t.Errorf("limit %d after 7h closed %d sessions (%v), want none", limit, len(closed), err)
+		}
+	}
+
+	now = time.Date(2024, 1, 15, 19, 0, 0, 0, time.UTC)
+	closed, err := tr.AutoCloseStaleSessions(480)
+	if err != nil {
+		t.Fatalf("AutoCloseStaleSessions: %v", err)
+	}
+	if len(closed) != 1 || closed[0].EndTime.Format("15:04") != "17:00" || closed[0].BreakMinutes != 30 || closed[0].Note != "deploy" {
+		t.Fatalf("closed = %+v, want the session ended at 17:00 with a 30min break", closed)
+	}
+	if active, _ := tr.GetActiveSession(); active != nil {
+		t.Errorf("session still open: %+v", active)
+	}
+
+	// A limit shorter than the day's break leaves no break
+	tuesday := time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)
+	if err := db.InsertSession(&storage.WorkSession{Date: tuesday, StartTime: tuesday}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+	now = tuesday.Add(time.Hour)
+	if closed, _ := tr.AutoCloseStaleSessions(20); len(closed) != 1 || closed[0].BreakMinutes != 0 {
+		t.Errorf("closed = %+v, want one session without a break", closed)
+	}
+
+	// So does a limit equal to it, which would leave no worked time
+	wednesday := time.Date(2024, 1, 17, 9, 0, 0, 0, time.UTC)
+	if err := db.InsertSession(&storage.WorkSession{Date: wednesday, StartTime: wednesday}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+	now = wednesday.Add(time.Hour)
+	closed, err = tr.AutoCloseStaleSessions(30)
+	if err != nil {
+		t.Fatalf("AutoCloseStaleSessions at the break length: %v", err)
+	}
+	if len(closed) != 1 || closed[0].BreakMinutes != 0 || closed[0].EndTime.Format("15:04") != "09:30" {
+		t.Errorf("closed = %+v, want one session ended at 09:30 without a break", closed)
+	}
+}
+
+func TestWeekGoalOverride(t *testing.T) {
+	tr, db := newTestTracker(t, 38.5, func() time.Time { return time.Date(2024, 1, 17, 12, 0, 0, 0, time.UTC) })
+	var rules work.Rules
+	rules.AddHoliday("2024-01-19")
+	tr.SetRules(rules)
+
+	// Any day of the week sets its goal
+	if err := tr.SetWeekGoal(time.Date(2024, 1, 18, 0, 0, 0, 0, time.UTC), 30); err != nil {
+		t.Fatalf("SetWeekGoal: %v", err)
+	}
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	end := start.Add(8 * time.Hour)
+	if err := db.InsertSession(&storage.WorkSession{Date: start, StartTime: start, EndTime: &end}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+
+	week, err := tr.GetWeeklyProgress()
+	if err != nil {
+		t.Fatalf("GetWeeklyProgress: %v", err)
+	}
+	// The 30h are spread over the four days left after Friday's holiday
+	if !week.GoalOverride || week.Goal != 30 || week.RemainingHours != 22 {
+		t.Errorf("override/goal/remaining = %v/%.2f/%.2f, want true/30/22", week.GoalOverride, week.Goal, week.RemainingHours)
+	}
+	if got := week.DayTargets["2024-01-16"]; got != 7.5 {
+		t.Errorf("Tuesday target = %.2f, want 7.50", got)
+	}
+	if got := tr.DayTarget(tr.Now()); got != 7.5 {
+		t.Errorf("DayTarget today = %.2f, want 7.50", got)
+	}
+	if got := week.WorkDayTarget(); got != 7.5 {
+		t.Errorf("WorkDayTarget = %.2f, want 7.50", got)
+	}
+
+	// Other weeks keep the weekly goal
+	last, err := tr.GetLastWeekProgress()
+	if err != nil {
+		t.Fatalf("GetLastWeekProgress: %v", err)
+	}
+	if last.GoalOverride || last.Goal != 38.5 {
+		t.Errorf("last week override/goal = %v/%.2f, want false/38.5", last.GoalOverride, last.Goal)
+	}
+
+	if err := tr.SetWeekGoal(tr.Now(), -1); err == nil {
+		t.Error("expected an error for a negative goal")
+	}
+	if cleared, err := tr.ClearWeekGoal(tr.Now()); err != nil || !cleared {
+		t.Fatalf("ClearWeekGoal = %v, %v", cleared, err)
+	}
+	if week, _ := tr.GetWeeklyProgress(); week.GoalOverride || week.Goal != 30.8 {
+		t.Errorf("after clearing, override/goal = %v/%.2f, want false/30.80", week.GoalOverride, week.Goal)
+	}
+}
+
+func TestHooksRunAroundClockActions(t *testing.T) {
+	tr, db := newTestTracker(t, 38.5, nil)
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	tr.nowFn = func() time.Time { return now }
+	tr.SetMaxNoteLength(10)
+	var runs []string
+	tr.SetHooks(Hooks{
+		Runner: hooks.New(func(command string, env []string) error {
+			runs = append(runs, command+" "+strings.Join(env, " "))
+			return nil
+		}),
+		PreClockin:   "pre",
+		PostClockout: "post",
+	})
+
+	// A rejected clock-in runs no hook
+	if _, err := tr.ClockInWithTime("a note that is too long", "09:00"); err == nil {
+		t.Fatal("expected the long note to be rejected")
+	}
+	if len(runs) != 0 {
+		t.Fatalf("hooks ran for a rejected clock-in: %v", runs)
+	}
+
+	// The pre-clockin hook sees the --time start, not now
+	session, err := tr.ClockInWithTime("", "09:00")
+	if err != nil {
+		t.Fatalf("ClockInWithTime: %v", err)
+	}
+	if len(runs) != 1 || !strings.HasPrefix(runs[0], "pre ") || !strings.Contains(runs[0], "KAIROS_START=09:00") {
+		t.Fatalf("runs = %v, want pre-clockin with the 09:00 start", runs)
+	}
+
+	if _, err := tr.ClockOutWithTime(session.ID, 30, "", "11:00"); err != nil {
+		t.Fatalf("ClockOutWithTime: %v", err)
+	}
+	if len(runs) != 2 || !strings.HasPrefix(runs[1], "post ") || !strings.Contains(runs[1], "KAIROS_END=11:00") {
+		t.Fatalf("runs = %v, want post-clockout ending 11:00", runs)
+	}
+
+	// The automatic clock-out runs it too
+	start := time.Date(2024, 1, 15, 13, 0, 0, 0, time.UTC)
+	if err := db.InsertSession(&storage.WorkSession{Date: start, StartTime: start}); err != nil {
+		t.Fatalf("InsertSession: %v", err)
+	}
+	now = start.Add(3 * time.Hour)
+	if _, err := tr.AutoCloseStaleSessions(60); err != nil {
+		t.Fatalf("AutoCloseStaleSessions: %v", err)
+	}
+	if len(runs) != 3 || !strings.HasPrefix(runs[2], "post ") || !strings.Contains(runs[2], "KAIROS_END=14:00") {
+		t.Fatalf("runs = %v, want post-clockout for the auto clock-out at 14:00", runs)
+	}
+}
+
+// newTestTracker returns a tracker with the given weekly goal over a fresh
+// database in UTC, reading the time from now when it is not nil
+func newTestTracker(t *testing.T, weeklyGoal float64, now func() time.Time) (*Tracker, *storage.Database) {
+	t.Helper()
+	db, err := storage.New(t.TempDir()+"/test.db", time.UTC)
+	if err != nil {
+		t.Fatalf("storage.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	tr := NewWithLocation(db, weeklyGoal, time.UTC)
+	if now != nil {
+		tr.nowFn = now
+	}
+	return tr, db
+}
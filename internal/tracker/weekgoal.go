@@ -0,0 +1,66 @@
+package tracker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kairos/internal/work"
+)
+
+// SetWeekGoal stores goal as the hours due in the week containing
+// weekStart, in place of the weekly goal, for weeks with different
+// contracted hours. Like the weekly goal, it is spread evenly over the
+// week's work days; holidays in that week don't lower it further.
+func (t *Tracker) SetWeekGoal(weekStart time.Time, goal float64) error {
+	if goal < 0 {
+		return fmt.Errorf("week goal cannot be negative: %g", goal)
+	}
+	return t.db.SetWeekGoal(t.weekKey(weekStart), goal)
+}
+
+// ClearWeekGoal removes the goal set for the week containing weekStart, so
+// it falls back to the weekly goal, and reports whether one was set
+func (t *Tracker) ClearWeekGoal(weekStart time.Time) (bool, error) {
+	return t.db.ClearWeekGoal(t.weekKey(weekStart))
+}
+
+// weekKey is the date of the first day of date's week, as week goals are
+// stored
+func (t *Tracker) weekKey(date time.Time) string {
+	return getWeekStartOn(date.In(t.now().Location()), t.weekStartDay).Format("2006-01-02")
+}
+
+// weekTargets returns the target of each day in the week starting on
+// weekStart, keyed by date, and whether they share a goal set for that week
+// rather than the weekly goal
+func (t *Tracker) weekTargets(weekStart time.Time) (map[string]float64, bool, error) {
+	goal, override, err := t.db.GetWeekGoal(weekStart.Format("2006-01-02"))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get week goal: %w", err)
+	}
+
+	dailyTarget := t.weeklyGoal / float64(t.rules.DaysPerWeek())
+	if override {
+		workDays := 0
+		for i := 0; i < 7; i++ {
+			if work.IsWorkDay(t.rules, weekStart.AddDate(0, 0, i)) {
+				workDays++
+			}
+		}
+		dailyTarget = 0
+		if workDays > 0 {
+			dailyTarget = goal / float64(workDays)
+		}
+	}
+
+	targets := make(map[string]float64, 7)
+	for i := 0; i < 7; i++ {
+		d := weekStart.AddDate(0, 0, i)
+		if work.IsWorkDay(t.rules, d) {
+			targets[d.Format("2006-01-02")] = dailyTarget
+		} else {
+			targets[d.Format("2006-01-02")] = 0
+		}
+	}
+	return targets, override, nil
+}
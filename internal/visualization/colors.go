@@ -0,0 +1,41 @@
+package visualization
+
+// Bar colors by bucket
+const (
+	ColorBelowMin = "#BDBDBD"
+	ColorNormal   = "#4CAF50"
+	ColorLongDay  = "#FF9800"
+	ColorOverwork = "#F44336"
+)
+
+// ColorBuckets maps hours worked on a day to a chart color. Days below
+// MinHours are greyed out (MinHours 0 disables this); days above LongDay and
+// Overwork are highlighted.
+type ColorBuckets struct {
+	MinHours float64
+	LongDay  float64
+	Overwork float64
+}
+
+// DefaultColorBuckets returns the standard thresholds (10h long, 12h overwork)
+func DefaultColorBuckets() ColorBuckets {
+	return ColorBuckets{
+		MinHours: 0,
+		LongDay:  10,
+		Overwork: 12,
+	}
+}
+
+// Color returns the bar color for a day with the given hours
+func (b ColorBuckets) Color(hours float64) string {
+	switch {
+	case b.MinHours > 0 && hours < b.MinHours:
+		return ColorBelowMin
+	case hours > b.Overwork:
+		return ColorOverwork
+	case hours > b.LongDay:
+		return ColorLongDay
+	default:
+		return ColorNormal
+	}
+}
@@ -0,0 +1,165 @@
+package visualization
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kairos/internal/storage"
+)
+
+// Heatmap cell colors: days without work, then lighter to darker greens up
+// to the long-day threshold
+const ColorHeatmapEmpty = "#EBEDF0"
+
+var heatmapShades = []string{"#C8E6C9", "#81C784", ColorNormal, "#2E7D32"}
+
+// HeatColor returns the heatmap cell color for a day with the given hours.
+// Short, long and overwork days use the same buckets as the bar charts.
+func (b ColorBuckets) HeatColor(hours float64) string {
+	switch {
+	case hours <= 0:
+		return ColorHeatmapEmpty
+	case b.MinHours > 0 && hours < b.MinHours, hours > b.LongDay:
+		return b.Color(hours)
+	}
+	level := int(hours / b.LongDay * float64(len(heatmapShades)))
+	if level >= len(heatmapShades) {
+		level = len(heatmapShades) - 1
+	}
+	return heatmapShades[level]
+}
+
+// GenerateHeatmapSVG renders a calendar grid of the days from start to end,
+// one column per week from the configured week start, each cell colored by the hours worked
+// that day. Open sessions are not counted.
+func (v *Visualizer) GenerateHeatmapSVG(sessions []storage.WorkSession, start, end time.Time) string {
+	const (
+		cell    = 14
+		step    = cell + 3
+		left    = 50
+		top     = 90
+		padding = 40
+	)
+
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, start.Location())
+
+	daily := make(map[string]float64)
+	total := 0.0
+	for _, s := range sessions {
+		if hours, complete := storage.SessionNetHours(s, time.Now()); complete {
+			daily[s.Date.Format("2006-01-02")] += hours
+			total += hours
+		}
+	}
+
+	// The grid starts on the first day of start's week; days outside the
+	// range in the first and last week are left blank
+	offset := (int(start.Weekday()) - int(v.weekStart) + 7) % 7
+	gridStart := start.AddDate(0, 0, -offset)
+	weeks := 0
+	for d := gridStart; !d.After(end); d = d.AddDate(0, 0, 7) {
+		weeks++
+	}
+
+	width := left + weeks*step + padding
+	if width < 420 {
+		width = 420
+	}
+	height := top + 7*step + 60
+
+	var cells, monthLabels strings.Builder
+	daysWorked := 0
+	labeled := -1
+	for w := 0; w < weeks; w++ {
+		x := left + w*step
+		for i := 0; i < 7; i++ {
+			day := gridStart.AddDate(0, 0, w*7+i)
+			if day.Before(start) || day.After(end) {
+				continue
+			}
+			// Label each month above the column holding its first day
+			if (day.Day() == 1 || day.Equal(start)) && labeled != w {
+				labeled = w
+				monthLabels.WriteString(fmt.Sprintf(`<text x="%d" y="%d" font-size="10" fill="#666">%s</text>
+  `, x, top-8, day.Format("Jan")))
+			}
+			key := day.Format("2006-01-02")
+			h := daily[key]
+			if h > 0 {
+				daysWorked++
+			}
+			cells.WriteString(fmt.Sprintf(`<rect x="%d" y="%d" width="%d" height="%d" fill="%s" rx="2"><title>%s: %sh</title></rect>
+  `, x, top+i*step, cell, cell, v.buckets.HeatColor(h), key, v.hours(h)))
+		}
+	}
+
+	// Label every other row, from the first day of the week
+	var dayLabels strings.Builder
+	for row := 0; row < 6; row += 2 {
+		name := time.Weekday((int(v.weekStart) + row) % 7).String()[:3]
+		dayLabels.WriteString(fmt.Sprintf(`<text x="%d" y="%d" text-anchor="end" font-size="10" fill="#666">%s</text>
+  `, left-6, top+row*step+cell-3, name))
+	}
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d">
+  <defs>
+    <linearGradient id="bgGrad" x1="0%%" y1="0%%" x2="0%%" y2="100%%">
+      <stop offset="0%%" style="stop-color:#f5f7fa"/>
+      <stop offset="100%%" style="stop-color:#e4e8ec"/>
+    </linearGradient>
+  </defs>
+  <rect width="%d" height="%d" fill="url(#bgGrad)" rx="10"/>
+  <text x="%d" y="30" text-anchor="middle" font-size="18" font-weight="bold" fill="#2c3e50">Activity Heatmap</text>
+  <text x="%d" y="55" text-anchor="middle" font-size="12" fill="#7f8c8d">%s - %s | Total: %sh | Days worked: %d</text>
+
+  <!-- Month labels -->
+  %s
+  <!-- Weekday labels -->
+  %s
+  <!-- Days -->
+  %s
+  <!-- Legend -->
+  %s
+</svg>`,
+		width, height, width, height,
+		width, height,
+		width/2,
+		width/2, start.Format("Jan 2, 2006"), end.Format("Jan 2, 2006"), v.hours(total), daysWorked,
+		monthLabels.String(),
+		dayLabels.String(),
+		cells.String(),
+		v.heatmapLegend(left, top+7*step+25, cell),
+	)
+}
+
+// heatmapLegend draws the cell colors from no work to overwork with their
+// thresholds
+func (v *Visualizer) heatmapLegend(x, y, cell int) string {
+	var legend strings.Builder
+	legend.WriteString(fmt.Sprintf(`<text x="%d" y="%d" font-size="10" fill="#666">Less</text>`, x, y+cell-3))
+	x += 30
+	for _, color := range append([]string{ColorHeatmapEmpty}, heatmapShades...) {
+		legend.WriteString(fmt.Sprintf(`
+  <rect x="%d" y="%d" width="%d" height="%d" fill="%s" rx="2"/>`, x, y, cell, cell, color))
+		x += cell + 3
+	}
+	legend.WriteString(fmt.Sprintf(`
+  <text x="%d" y="%d" font-size="10" fill="#666">More</text>`, x+3, y+cell-3))
+	x += 45
+	for _, band := range []struct {
+		color string
+		label string
+	}{
+		{ColorLongDay, fmt.Sprintf("&gt;%gh", v.buckets.LongDay)},
+		{ColorOverwork, fmt.Sprintf("&gt;%gh", v.buckets.Overwork)},
+	} {
+		legend.WriteString(fmt.Sprintf(`
+  <rect x="%d" y="%d" width="%d" height="%d" fill="%s" rx="2"/>
+  <text x="%d" y="%d" font-size="10" fill="#666">%s</text>`, x, y, cell, cell, band.color, x+cell+4, y+cell-3, band.label))
+		x += cell + 45
+	}
+	return legend.String()
+}
@@ -2,16 +2,54 @@ package visualization
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/kairos/internal/tracker"
+	"github.com/kairos/internal/work"
 )
 
-type Visualizer struct{}
+type Visualizer struct {
+	decimalPlaces int
+	buckets       ColorBuckets
+	weeklyGoal    float64
+	weekStart     time.Weekday
+}
 
 func New() *Visualizer {
-	return &Visualizer{}
+	return &Visualizer{
+		decimalPlaces: work.DefaultDecimalPlaces,
+		buckets:       DefaultColorBuckets(),
+		weeklyGoal:    work.WeeklyGoalHours,
+		weekStart:     time.Monday,
+	}
+}
+
+// SetWeeklyGoal sets the goal that week and month progress is measured against
+func (v *Visualizer) SetWeeklyGoal(goal float64) {
+	v.weeklyGoal = goal
+}
+
+// SetWeekStart sets the first day of the week, the top row of heatmap
+// columns (Monday by default)
+func (v *Visualizer) SetWeekStart(day time.Weekday) {
+	v.weekStart = day
+}
+
+// SetColorBuckets sets the thresholds used to color daily bars
+func (v *Visualizer) SetColorBuckets(buckets ColorBuckets) {
+	v.buckets = buckets
+}
+
+// SetDecimalPlaces sets the precision of displayed hours
+func (v *Visualizer) SetDecimalPlaces(places int) {
+	v.decimalPlaces = places
+}
+
+func (v *Visualizer) hours(h float64) string {
+	return work.FormatHours(h, v.decimalPlaces)
 }
 
 func (v *Visualizer) GenerateWeekSVG(progress *tracker.WeekProgress) string {
@@ -23,12 +61,12 @@ func (v *Visualizer) GenerateWeekSVG(progress *tracker.WeekProgress) string {
 
 	var days []string
 	var hours []float64
-	dayNames := []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
 
+	// Labels follow the dates so any configured week start displays correctly
 	for i := 0; i < 7; i++ {
 		day := progress.WeekStart.AddDate(0, 0, i)
 		dayKey := day.Format("2006-01-02")
-		days = append(days, dayNames[i])
+		days = append(days, day.Format("Mon"))
 		hours = append(hours, progress.DaysWorked[dayKey])
 	}
 
@@ -42,20 +80,24 @@ func (v *Visualizer) GenerateWeekSVG(progress *tracker.WeekProgress) string {
 		x := float64(padding) + float64(i)*barWidth + 5
 		y := float64(height) - float64(padding) - barHeight
 
-		color := "#4CAF50"
-		if h > 10 {
-			color = "#FF9800"
-		}
-		if h > 12 {
-			color = "#F44336"
-		}
+		color := v.buckets.Color(h)
 
 		bars.WriteString(fmt.Sprintf(`<rect x="%.0f" y="%.0f" width="%.0f" height="%.0f" fill="%s" rx="4"/>
-    <text x="%.0f" y="%d" text-anchor="middle" font-size="12" fill="#333">%.1fh</text>`,
+    <text x="%.0f" y="%d" text-anchor="middle" font-size="12" fill="#333">%sh</text>`,
 			x, y, barWidth-10, barHeight, color,
-			x+barWidth/2-5, int(y)-5, h))
+			x+barWidth/2-5, int(y)-5, v.hours(h)))
 	}
 
+	// The goal line sits at a work day's share of the weekly goal
+	dailyGoal := v.weeklyGoal / work.WorkDaysPerWeek
+	for _, target := range progress.DayTargets {
+		if target > 0 {
+			dailyGoal = target
+			break
+		}
+	}
+	goalY := height - padding - int(math.Min(dailyGoal/maxHours, 1)*float64(height-2*padding))
+
 	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
 <svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d">
   <defs>
@@ -66,7 +108,7 @@ func (v *Visualizer) GenerateWeekSVG(progress *tracker.WeekProgress) string {
   </defs>
   <rect width="%d" height="%d" fill="url(#bgGrad)" rx="10"/>
   <text x="%d" y="30" text-anchor="middle" font-size="18" font-weight="bold" fill="#2c3e50">Weekly Overview</text>
-  <text x="%d" y="55" text-anchor="middle" font-size="12" fill="#7f8c8d">%s - %s | Total: %.1f/38.5h</text>
+  <text x="%d" y="55" text-anchor="middle" font-size="12" fill="#7f8c8d">%s - %s | Total: %s/%gh</text>
 
   <!-- Goal line -->
   <line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#E74C3C" stroke-width="2" stroke-dasharray="5,5"/>
@@ -84,9 +126,9 @@ func (v *Visualizer) GenerateWeekSVG(progress *tracker.WeekProgress) string {
 		width, height, width, height,
 		width, height,
 		width/2,
-		width/2, progress.WeekStart.Format("Jan 2"), progress.WeekEnd.Format("Jan 2"), progress.TotalHours,
-		padding, height-padding-30, width-padding, height-padding-30,
-		width-padding+10, height-padding-35,
+		width/2, progress.WeekStart.Format("Jan 2"), progress.WeekEnd.Format("Jan 2"), v.hours(progress.TotalHours), v.weeklyGoal,
+		padding, goalY, width-padding, goalY,
+		width-padding+10, goalY-5,
 		bars.String(),
 		v.generateXLabels(days, float64(padding), barWidth, float64(height-padding)),
 		v.generateGridLines(maxHours, height, padding, width),
@@ -99,23 +141,42 @@ func (v *Visualizer) GenerateMonthSVG(progress *tracker.MonthProgress) string {
 	padding := 50
 	cellSize := float64(width-2*padding) / 4 // 4 weeks
 
-	var weeks []string
-	var weekHours []float64
-	for week, hours := range progress.WeekHours {
-		weeks = append(weeks, fmt.Sprintf("W%d", week))
-		weekHours = append(weekHours, hours)
+	// Weeks in calendar order; fall back to sorted keys for hand-built progress
+	order := progress.Weeks
+	if len(order) == 0 {
+		for week := range progress.WeekHours {
+			order = append(order, week)
+		}
+		sort.Ints(order)
+	}
+	if len(order) > 4 {
+		cellSize = float64(width-2*padding) / float64(len(order))
 	}
 
 	var bars strings.Builder
-	for i, h := range weekHours {
+	for i, week := range order {
+		h := progress.WeekHours[week]
 		barHeight := (h / 40) * float64(height-2*padding)
 		x := float64(padding) + float64(i)*cellSize + 10
 		y := float64(height) - float64(padding) - barHeight
 
 		bars.WriteString(fmt.Sprintf(`<rect x="%.0f" y="%.0f" width="%.0f" height="%.0f" fill="#3498DB" rx="4"/>
-    <text x="%.0f" y="%d" text-anchor="middle" font-size="12" fill="#333">%.1fh</text>`,
+    <text x="%.0f" y="%d" text-anchor="middle" font-size="12" fill="#333">%sh</text>
+    <text x="%.0f" y="%d" text-anchor="middle" font-size="12" fill="#666">%s</text>`,
 			x, y, cellSize-20, barHeight,
-			x+cellSize/2-10, int(y)-5, h))
+			x+cellSize/2-10, int(y)-5, v.hours(h),
+			x+cellSize/2-10, height-padding+18, work.WeekLabel(week, progress.WeekNumbering)))
+	}
+
+	// Progress ring against the month's goal; hand-built progress without one
+	// falls back to four weeks
+	goal := progress.GoalHours
+	if goal <= 0 {
+		goal = 4 * v.weeklyGoal
+	}
+	percent := 0.0
+	if goal > 0 {
+		percent = progress.TotalHours / goal * 100
 	}
 
 	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
@@ -128,7 +189,7 @@ func (v *Visualizer) GenerateMonthSVG(progress *tracker.MonthProgress) string {
   </defs>
   <rect width="%d" height="%d" fill="url(#bgGrad)" rx="10"/>
   <text x="%d" y="30" text-anchor="middle" font-size="18" font-weight="bold" fill="#2c3e50">Monthly Overview</text>
-  <text x="%d" y="55" text-anchor="middle" font-size="12" fill="#7f8c8d">%s | Total: %.1fh | Daily Avg: %.1fh</text>
+  <text x="%d" y="55" text-anchor="middle" font-size="12" fill="#7f8c8d">%s | Total: %sh | Daily Avg: %sh</text>
 
   <!-- Progress ring -->
   <circle cx="%d" cy="%d" r="60" fill="none" stroke="#E0E0E0" stroke-width="10"/>
@@ -142,18 +203,22 @@ func (v *Visualizer) GenerateMonthSVG(progress *tracker.MonthProgress) string {
 		width, height, width, height,
 		width, height,
 		width/2,
-		width/2, progress.Month.Format("January 2006"), progress.TotalHours, progress.DailyAverage,
+		width/2, progress.Month.Format("January 2006"), v.hours(progress.TotalHours), v.hours(progress.DailyAverage),
 		width-100, 120,
 		width-100, 120,
-		2*3.14*60*progress.TotalHours/154, 2*3.14*60,
+		2*3.14*60*percent/100, 2*3.14*60,
 		width-100, 120,
 		width-100, 125,
-		progress.TotalHours/154*100, // percentage of monthly goal (4 weeks * 38.5)
+		percent,
 		bars.String(),
 	)
 }
 
 func (v *Visualizer) GenerateHTMLReport(dayProgress *tracker.DayProgress, weekProgress *tracker.WeekProgress) string {
+	weekPercent := 0.0
+	if v.weeklyGoal > 0 {
+		weekPercent = weekProgress.TotalHours / v.weeklyGoal * 100
+	}
 	return fmt.Sprintf(`<!DOCTYPE html>
 <html>
 <head>
@@ -187,11 +252,11 @@ func (v *Visualizer) GenerateHTMLReport(dayProgress *tracker.DayProgress, weekPr
     <div class="card">
       <h2>Today's Progress</h2>
       <div class="stat">
-        <div class="stat-value">%.2fh</div>
+        <div class="stat-value">%sh</div>
         <div class="stat-label">Hours Today</div>
       </div>
       <div class="stat">
-        <div class="stat-value">%.2fh</div>
+        <div class="stat-value">%sh</div>
         <div class="stat-label">Weekly Total</div>
       </div>
       <div class="stat">
@@ -205,13 +270,12 @@ func (v *Visualizer) GenerateHTMLReport(dayProgress *tracker.DayProgress, weekPr
       <div class="progress-bar">
         <div class="progress-fill" style="width: %.1f%%"></div>
       </div>
-      <p style="color: #7f8c8d; text-align: center;">%.2f / 38.5 hours</p>
+      <p style="color: #7f8c8d; text-align: center;">%s / %g hours</p>
     </div>
 
     <div class="card">
       <h2>Daily Breakdown</h2>
       <table>
-        <tr><th>Day</th><th>Hours</th></tr>
         %s
       </table>
     </div>
@@ -219,29 +283,49 @@ func (v *Visualizer) GenerateHTMLReport(dayProgress *tracker.DayProgress, weekPr
 </body>
 </html>`,
 		time.Now().Format("Monday, January 2, 2006"),
-		dayProgress.TotalHours,
-		weekProgress.TotalHours,
+		v.hours(dayProgress.TotalHours),
+		v.hours(weekProgress.TotalHours),
 		float64(weekProgress.DaysWorkedCount),
-		(weekProgress.TotalHours/38.5)*100,
-		weekProgress.TotalHours,
+		weekPercent,
+		v.hours(weekProgress.TotalHours), v.weeklyGoal,
 		v.formatDailyRows(weekProgress),
 	)
 }
 
 func (v *Visualizer) formatDailyRows(progress *tracker.WeekProgress) string {
-	var rows []string
-	dayNames := []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+	header := "<tr><th>Day</th><th>Hours</th></tr>"
+	if progress.DayTargets != nil {
+		header = "<tr><th>Day</th><th>Hours</th><th>vs Target</th></tr>"
+	}
+	rows := []string{header}
 
 	for i := 0; i < 7; i++ {
 		day := progress.WeekStart.AddDate(0, 0, i)
 		dayKey := day.Format("2006-01-02")
 		hours := progress.DaysWorked[dayKey]
-		rows = append(rows, fmt.Sprintf("<tr><td>%s</td><td>%.2f hours</td></tr>", dayNames[i], hours))
+		row := fmt.Sprintf("<tr><td>%s</td><td>%s hours</td>", day.Format("Monday"), v.hours(hours))
+		if progress.DayTargets != nil {
+			row += v.targetCell(hours, progress.DayTargets[dayKey])
+		}
+		rows = append(rows, row+"</tr>")
 	}
 
 	return strings.Join(rows, "\n")
 }
 
+// targetCell shows hours against the day's target: green at or over, red
+// under, "off" on non-work days
+func (v *Visualizer) targetCell(hours, target float64) string {
+	switch {
+	case target <= 0:
+		return `<td style="color: #7f8c8d">off</td>`
+	case hours >= target:
+		return fmt.Sprintf(`<td style="color: #27AE60">+%s</td>`, v.hours(hours-target))
+	default:
+		return fmt.Sprintf(`<td style="color: #E74C3C">%s</td>`, v.hours(hours-target))
+	}
+}
+
 func (v *Visualizer) generateXLabels(days []string, padding float64, barWidth float64, y float64) string {
 	var labels strings.Builder
 	for i, day := range days {
@@ -5,7 +5,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/kairos/internal/storage"
 	"github.com/kairos/internal/tracker"
+	"github.com/kairos/internal/work"
 )
 
 func TestGenerateWeekSVGBasics(t *testing.T) {
@@ -24,7 +26,7 @@ func TestGenerateWeekSVGBasics(t *testing.T) {
 
 	assertContains(t, svg, "<?xml")
 	assertContains(t, svg, "Weekly Overview")
-	assertContains(t, svg, "Total: 20.5/38.5h")
+	assertContains(t, svg, "Total: 20.50/38.5h")
 	assertContains(t, svg, ">Mon</text>")
 	assertContains(t, svg, ">Sun</text>")
 
@@ -52,7 +54,7 @@ func TestGenerateMonthSVGBasics(t *testing.T) {
 
 	assertContains(t, svg, "Monthly Overview")
 	assertContains(t, svg, "January 2024")
-	assertContains(t, svg, "Total: 80.0h | Daily Avg: 4.0h")
+	assertContains(t, svg, "Total: 80.00h | Daily Avg: 4.00h")
 
 	rectCount := strings.Count(svg, "<rect")
 	expectedRects := len(progress.WeekHours) + 1
@@ -93,9 +95,215 @@ func TestGenerateHTMLReport(t *testing.T) {
 	}
 }
 
+func TestHTMLReportDayTargets(t *testing.T) {
+	v := New()
+	weekStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) // Monday
+	targets := make(map[string]float64)
+	for i := 0; i < 5; i++ {
+		targets[weekStart.AddDate(0, 0, i).Format("2006-01-02")] = 7.7
+	}
+	targets["2024-01-06"], targets["2024-01-07"] = 0, 0
+	weekProgress := &tracker.WeekProgress{
+		WeekStart:  weekStart,
+		WeekEnd:    weekStart.AddDate(0, 0, 6),
+		DaysWorked: map[string]float64{"2024-01-01": 6.5, "2024-01-02": 8},
+		DayTargets: targets,
+	}
+
+	html := v.GenerateHTMLReport(&tracker.DayProgress{}, weekProgress)
+	assertContains(t, html, "<th>vs Target</th>")
+	assertContains(t, html, "<td>Monday</td><td>6.50 hours</td><td style=\"color: #E74C3C\">-1.20</td>")
+	assertContains(t, html, "<td>Tuesday</td><td>8.00 hours</td><td style=\"color: #27AE60\">+0.30</td>")
+	assertContains(t, html, "<td>Saturday</td><td>0.00 hours</td><td style=\"color: #7f8c8d\">off</td>")
+}
+
+func TestDecimalPlaces(t *testing.T) {
+	v := New()
+	v.SetDecimalPlaces(1)
+	weekStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) // Monday
+	progress := &tracker.WeekProgress{
+		WeekStart:  weekStart,
+		WeekEnd:    weekStart.AddDate(0, 0, 6),
+		TotalHours: 20.25,
+		DaysWorked: map[string]float64{
+			weekStart.Format("2006-01-02"): 2.25,
+		},
+	}
+
+	svg := v.GenerateWeekSVG(progress)
+
+	assertContains(t, svg, "Total: 20.2/38.5h")
+	assertContains(t, svg, ">2.2h</text>")
+}
+
+func TestColorBucketBoundaries(t *testing.T) {
+	buckets := ColorBuckets{MinHours: 4, LongDay: 10, Overwork: 12}
+
+	tests := []struct {
+		hours float64
+		want  string
+	}{
+		{0, ColorBelowMin},
+		{3.99, ColorBelowMin},
+		{4, ColorNormal},
+		{10, ColorNormal},
+		{10.01, ColorLongDay},
+		{12, ColorLongDay},
+		{12.01, ColorOverwork},
+	}
+	for _, tt := range tests {
+		if got := buckets.Color(tt.hours); got != tt.want {
+			t.Errorf("Color(%v) = %s, want %s", tt.hours, got, tt.want)
+		}
+	}
+
+	// MinHours 0 never greys out
+	if got := DefaultColorBuckets().Color(0); got != ColorNormal {
+		t.Errorf("default Color(0) = %s, want %s", got, ColorNormal)
+	}
+}
+
+func TestWeekSVGMinHours(t *testing.T) {
+	v := New()
+	buckets := DefaultColorBuckets()
+	buckets.MinHours = 4
+	v.SetColorBuckets(buckets)
+
+	weekStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) // Monday
+	progress := &tracker.WeekProgress{
+		WeekStart: weekStart,
+		WeekEnd:   weekStart.AddDate(0, 0, 6),
+		DaysWorked: map[string]float64{
+			weekStart.Format("2006-01-02"):                  2,
+			weekStart.AddDate(0, 0, 1).Format("2006-01-02"): 8,
+		},
+	}
+
+	svg := v.GenerateWeekSVG(progress)
+
+	// Monday plus the five empty days are below the minimum; only Tuesday is normal
+	if n := strings.Count(svg, `fill="`+ColorBelowMin+`"`); n != 6 {
+		t.Errorf("expected 6 greyed bars, got %d", n)
+	}
+	if n := strings.Count(svg, `fill="`+ColorNormal+`"`); n != 1 {
+		t.Errorf("expected 1 normal bar, got %d", n)
+	}
+}
+
+func TestSundayStartWeekLabels(t *testing.T) {
+	v := New()
+	weekStart := time.Date(2024, 9, 29, 0, 0, 0, 0, time.UTC) // Sunday, week spans into October
+	progress := &tracker.WeekProgress{
+		WeekStart:  weekStart,
+		WeekEnd:    weekStart.AddDate(0, 0, 6),
+		TotalHours: 8,
+		DaysWorked: map[string]float64{
+			"2024-10-01": 8, // Tuesday
+		},
+	}
+
+	svg := v.GenerateWeekSVG(progress)
+	assertContains(t, svg, "Sep 29 - Oct 5")
+	if first, last := strings.Index(svg, ">Sun</text>"), strings.Index(svg, ">Sat</text>"); first < 0 || last < first {
+		t.Fatalf("expected labels to run Sun..Sat, got Sun at %d and Sat at %d", first, last)
+	}
+
+	html := v.GenerateHTMLReport(&tracker.DayProgress{}, progress)
+	assertContains(t, html, "<td>Sunday</td><td>0.00 hours</td>")
+	assertContains(t, html, "<td>Tuesday</td><td>8.00 hours</td>")
+	if first, last := strings.Index(html, "<td>Sunday</td>"), strings.Index(html, "<td>Saturday</td>"); last < first {
+		t.Fatalf("expected HTML rows to run Sunday..Saturday")
+	}
+}
+
+func TestMonthSVGWeekLabelsAcrossYearBoundary(t *testing.T) {
+	v := New()
+	iso := &tracker.MonthProgress{
+		Month:         time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		TotalHours:    12,
+		WeekHours:     map[int]float64{53: 4, 1: 4, 2: 4},
+		Weeks:         []int{53, 1, 2},
+		WeekNumbering: work.WeekNumberingISO,
+	}
+
+	svg := v.GenerateMonthSVG(iso)
+	if w53, w1 := strings.Index(svg, ">W53</text>"), strings.Index(svg, ">W1</text>"); w53 < 0 || w1 < w53 {
+		t.Fatalf("expected W53 before W1, got W53 at %d and W1 at %d", w53, w1)
+	}
+
+	month := &tracker.MonthProgress{
+		Month:         iso.Month,
+		TotalHours:    12,
+		WeekHours:     map[int]float64{1: 4, 2: 4, 3: 4},
+		Weeks:         []int{1, 2, 3},
+		WeekNumbering: work.WeekNumberingMonth,
+	}
+	svg = v.GenerateMonthSVG(month)
+	assertContains(t, svg, ">Wk 1</text>")
+	assertContains(t, svg, ">Wk 3</text>")
+}
+
 func assertContains(t *testing.T, haystack, needle string) {
 	t.Helper()
 	if !strings.Contains(haystack, needle) {
 		t.Fatalf("expected output to contain %q", needle)
 	}
 }
+
+func TestGenerateHeatmapSVG(t *testing.T) {
+	v := New()
+	day := func(d, hours int) storage.WorkSession {
+		start := time.Date(2024, 1, d, 8, 0, 0, 0, time.UTC)
+		end := start.Add(time.Duration(hours) * time.Hour)
+		return storage.WorkSession{Date: start, StartTime: start, EndTime: &end}
+	}
+	open := time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC)
+	sessions := []storage.WorkSession{
+		day(2, 8), day(3, 2), day(3, 1), day(4, 11), day(5, 13),
+		{Date: open, StartTime: open},
+	}
+
+	// January 2024 starts on a Monday and ends on a Wednesday
+	svg := v.GenerateHeatmapSVG(sessions, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC))
+
+	assertContains(t, svg, "Activity Heatmap")
+	assertContains(t, svg, "Total: 35.00h | Days worked: 4")
+	assertContains(t, svg, `fill="#2E7D32" rx="2"><title>2024-01-02: 8.00h`)
+	assertContains(t, svg, `fill="#81C784" rx="2"><title>2024-01-03: 3.00h`)
+	assertContains(t, svg, `fill="`+ColorLongDay+`" rx="2"><title>2024-01-04: 11.00h`)
+	assertContains(t, svg, `fill="`+ColorOverwork+`" rx="2"><title>2024-01-05: 13.00h`)
+	assertContains(t, svg, `fill="`+ColorHeatmapEmpty+`" rx="2"><title>2024-01-31: 0.00h`)
+	assertContains(t, svg, ">Jan</text>")
+	assertContains(t, svg, "&gt;10h")
+
+	// Only the 31 days of the month get cells: the partial last week is blank
+	if got := strings.Count(svg, "<title>"); got != 31 {
+		t.Errorf("day cells = %d, want 31", got)
+	}
+	if strings.Contains(svg, "2024-02-01") {
+		t.Error("heatmap drew a day after the end date")
+	}
+}
+
+func TestVisualizerWeeklyGoal(t *testing.T) {
+	v := New()
+	v.SetWeeklyGoal(40)
+	weekStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) // Monday
+	week := &tracker.WeekProgress{
+		WeekStart:  weekStart,
+		WeekEnd:    weekStart.AddDate(0, 0, 6),
+		TotalHours: 20,
+		DaysWorked: map[string]float64{},
+	}
+
+	assertContains(t, v.GenerateWeekSVG(week), "Total: 20.00/40h")
+	html := v.GenerateHTMLReport(&tracker.DayProgress{}, week)
+	assertContains(t, html, "20.00 / 40 hours")
+	assertContains(t, html, "style=\"width: 50.0%\"")
+
+	// The ring uses the month's goal, or four weekly goals without one
+	month := &tracker.MonthProgress{Month: weekStart, TotalHours: 80, WeekHours: map[int]float64{}}
+	assertContains(t, v.GenerateMonthSVG(month), ">50%</text>")
+	month.GoalHours = 184
+	assertContains(t, v.GenerateMonthSVG(month), ">43%</text>")
+}
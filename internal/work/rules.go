@@ -1,14 +1,22 @@
 package work
 
-import "time"
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // =============================================================================
 // WORK RULES CONFIGURATION
 // =============================================================================
-// Edit these values to match your local work regulations.
+// These are the defaults behind Rules. The config keys weekly_goal,
+// daily_break_minutes, work_days and friday_short_shift_hours override them
+// without a rebuild.
 // Current defaults: Austrian work rules
 //
-// To customize for your country/company:
+// To change the built-in defaults:
 // 1. Change WeeklyGoalHours to your standard work week
 // 2. Change DefaultBreakMinutes to your standard break duration
 // 3. Change FridayBreakMinutes if Friday has different break rules
@@ -27,6 +35,10 @@ const (
 	// Set to 0 if your workplace has no Friday breaks (common in Austria)
 	FridayBreakMinutes = 0
 
+	// FridayShortShiftHours - Friday shifts shorter than this skip the break;
+	// longer ones get DefaultBreakMinutes. 0 exempts every Friday.
+	FridayShortShiftHours = 0
+
 	// WorkDaysPerWeek - standard work week (typically 5)
 	WorkDaysPerWeek = 5
 
@@ -34,33 +46,210 @@ const (
 	DailyTargetHours = WeeklyGoalHours / WorkDaysPerWeek
 )
 
-// GetBreakMinutesForDay returns the appropriate break time based on the day of week
-func GetBreakMinutesForDay(t time.Time) int {
-	if t.Weekday() == time.Friday {
+// Rules are the work regulations that decide breaks and work days. Empty
+// fields fall back to the constants above, so the zero value gives the
+// default rules.
+type Rules struct {
+	// DailyBreakMinutes sets the break per weekday; days not listed get
+	// FridayBreakMinutes on Fridays and DefaultBreakMinutes otherwise
+	DailyBreakMinutes map[time.Weekday]int
+	// WorkDays are the days expected to be worked (Mon-Fri when empty)
+	WorkDays []time.Weekday
+	// FridayShortShiftHours - Friday shifts of at least this many hours get
+	// DefaultBreakMinutes (0 exempts every Friday)
+	FridayShortShiftHours float64
+	// Holidays are dates (YYYY-MM-DD) off on top of the days outside
+	// WorkDays; they have no target, so weeks and months holding one have a
+	// smaller goal
+	Holidays map[string]bool
+}
+
+// AddHoliday marks date (YYYY-MM-DD) as a holiday
+func (r *Rules) AddHoliday(date string) {
+	if r.Holidays == nil {
+		r.Holidays = make(map[string]bool)
+	}
+	r.Holidays[date] = true
+}
+
+var defaultWorkDays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+// workDays returns the configured work days or Mon-Fri
+func (r Rules) workDays() []time.Weekday {
+	if len(r.WorkDays) == 0 {
+		return defaultWorkDays
+	}
+	return r.WorkDays
+}
+
+// DaysPerWeek returns the number of work days in a week
+func (r Rules) DaysPerWeek() int {
+	return len(r.workDays())
+}
+
+// BreakSummary describes the breaks on work days, e.g. "30min (Fri: 0min)"
+func (r Rules) BreakSummary() string {
+	var days []time.Weekday
+	counts := make(map[int]int)
+	for i := 1; i <= 7; i++ {
+		day := time.Weekday(i % 7)
+		if r.isWorkWeekday(day) {
+			days = append(days, day)
+			counts[r.breakForWeekday(day)]++
+		}
+	}
+	if len(days) == 0 {
+		return fmt.Sprintf("%dmin", DefaultBreakMinutes)
+	}
+
+	// The most common break is the standard; other days are listed
+	standard := r.breakForWeekday(days[0])
+	for _, day := range days {
+		if counts[r.breakForWeekday(day)] > counts[standard] {
+			standard = r.breakForWeekday(day)
+		}
+	}
+	var exceptions []string
+	for _, day := range days {
+		if m := r.breakForWeekday(day); m != standard {
+			exceptions = append(exceptions, fmt.Sprintf("%s: %dmin", day.String()[:3], m))
+		}
+	}
+	if len(exceptions) == 0 {
+		return fmt.Sprintf("%dmin", standard)
+	}
+	return fmt.Sprintf("%dmin (%s)", standard, strings.Join(exceptions, ", "))
+}
+
+func (r Rules) breakForWeekday(day time.Weekday) int {
+	if m, ok := r.DailyBreakMinutes[day]; ok {
+		return m
+	}
+	if day == time.Friday {
 		return FridayBreakMinutes
 	}
 	return DefaultBreakMinutes
 }
 
+func (r Rules) isWorkWeekday(day time.Weekday) bool {
+	for _, d := range r.workDays() {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseWeekday reads a weekday name ("monday", "Mon") or number (0 = Sunday)
+func ParseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 || n > 6 {
+			return 0, false
+		}
+		return time.Weekday(n), true
+	}
+	if len(s) < 3 {
+		return 0, false
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.HasPrefix(strings.ToLower(d.String()), s) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// GetBreakMinutesForDay returns the appropriate break time based on the day of week
+func GetBreakMinutesForDay(rules Rules, t time.Time) int {
+	return rules.breakForWeekday(t.Weekday())
+}
+
+// GetBreakMinutesForShift is GetBreakMinutesForDay for a known shift: the
+// Friday exemption only applies when the shift from start to end is shorter
+// than rules.FridayShortShiftHours (0 keeps the exemption for every Friday)
+func GetBreakMinutesForShift(rules Rules, start, end time.Time) int {
+	if start.Weekday() == time.Friday && rules.FridayShortShiftHours > 0 && end.Sub(start).Hours() >= rules.FridayShortShiftHours {
+		return DefaultBreakMinutes
+	}
+	return GetBreakMinutesForDay(rules, start)
+}
+
 // GetBreakMinutesForToday returns break minutes for today
-func GetBreakMinutesForToday() int {
-	return GetBreakMinutesForDay(time.Now())
+func GetBreakMinutesForToday(rules Rules) int {
+	return GetBreakMinutesForDay(rules, time.Now())
 }
 
-// IsWorkDay returns true if the given day is a standard work day (Mon-Fri)
-func IsWorkDay(t time.Time) bool {
-	day := t.Weekday()
-	return day >= time.Monday && day <= time.Friday
+// IsWorkDay returns true if the given day is one of the rules' work days
+// and not a holiday
+func IsWorkDay(rules Rules, t time.Time) bool {
+	return rules.isWorkWeekday(t.Weekday()) && !IsHoliday(rules, t)
 }
 
-// RemainingWorkDaysInWeek returns how many work days are left in the current week
-func RemainingWorkDaysInWeek(t time.Time) int {
-	day := t.Weekday()
-	if day == time.Saturday || day == time.Sunday {
-		return 0
+// IsHoliday returns true if the given day is one of the rules' holidays
+func IsHoliday(rules Rules, t time.Time) bool {
+	return rules.Holidays[t.Format("2006-01-02")]
+}
+
+// WorkDaysInMonth returns the number of work days in a month
+func WorkDaysInMonth(rules Rules, year int, month time.Month) int {
+	count := 0
+	for d := time.Date(year, month, 1, 12, 0, 0, 0, time.UTC); d.Month() == month; d = d.AddDate(0, 0, 1) {
+		if IsWorkDay(rules, d) {
+			count++
+		}
+	}
+	return count
+}
+
+// RemainingWorkDaysInWeek returns how many work days are left in the
+// Monday-Sunday week of t, today included
+func RemainingWorkDaysInWeek(rules Rules, t time.Time) int {
+	return RemainingWorkDaysInWeekFrom(rules, t, time.Monday)
+}
+
+// RemainingWorkDaysInWeekFrom is RemainingWorkDaysInWeek for weeks starting
+// on weekStart
+func RemainingWorkDaysInWeekFrom(rules Rules, t time.Time, weekStart time.Weekday) int {
+	lastDay := (weekStart + 6) % 7
+	count := 0
+	for d := t; ; d = d.AddDate(0, 0, 1) {
+		if IsWorkDay(rules, d) {
+			count++
+		}
+		if d.Weekday() == lastDay {
+			return count
+		}
+	}
+}
+
+// RoundUpToQuarterHour returns t moved forward to the next quarter hour on
+// the wall clock (14:07 -> 14:15); times already on a quarter hour are kept
+func RoundUpToQuarterHour(t time.Time) time.Time {
+	const quarter = 15 * time.Minute
+	hour := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	elapsed := t.Sub(hour)
+	if rem := elapsed % quarter; rem != 0 {
+		elapsed += quarter - rem
 	}
-	// Monday=1, Friday=5, so remaining = 5 - current + 1 (including today)
-	return int(time.Friday) - int(day) + 1
+	return hour.Add(elapsed)
+}
+
+// RoundHours returns d in hours, rounded to the nearest mode minutes (15 for
+// quarter hours, 6 for tenths of an hour); mode 0 leaves d unrounded
+func RoundHours(d time.Duration, mode int) float64 {
+	if mode <= 0 {
+		return d.Hours()
+	}
+	return d.Round(time.Duration(mode) * time.Minute).Hours()
+}
+
+// RoundSessionHours rounds a session's net hours with RoundHours
+func RoundSessionHours(hours float64, mode int) float64 {
+	if mode <= 0 {
+		return hours
+	}
+	return RoundHours(time.Duration(math.Round(hours*float64(time.Hour))), mode)
 }
 
 // CalculateRequiredDailyHours calculates hours needed per remaining day to meet goal
@@ -74,3 +263,65 @@ func CalculateRequiredDailyHours(hoursWorked float64, remainingDays int) float64
 	}
 	return remaining / float64(remainingDays)
 }
+
+// DefaultDecimalPlaces is the precision used when displaying hours
+const DefaultDecimalPlaces = 2
+
+// FormatHours renders hours with the given number of decimal places
+func FormatHours(hours float64, places int) string {
+	if places < 0 {
+		places = DefaultDecimalPlaces
+	}
+	return strconv.FormatFloat(hours, 'f', places, 64)
+}
+
+// Duration display formats for at-a-glance output
+const (
+	DurationDecimal = "decimal" // 7.13h
+	DurationHM      = "hm"      // 7h 8m
+)
+
+// FormatHoursHM renders hours as whole hours and minutes, e.g. "7h 8m"
+func FormatHoursHM(hours float64) string {
+	sign := ""
+	if hours < 0 {
+		sign = "-"
+		hours = -hours
+	}
+	minutes := int(math.Round(hours * 60))
+	return fmt.Sprintf("%s%dh %dm", sign, minutes/60, minutes%60)
+}
+
+// Week numbering for monthly breakdowns
+const (
+	WeekNumberingISO   = "iso"   // ISO 8601 week of the year (W52, W1, ...)
+	WeekNumberingMonth = "month" // calendar week within the month (1-6)
+)
+
+// MonthWeek returns the calendar week of date within its month, counting
+// weeks that start on weekStart: the days before the first weekStart are
+// week 1
+func MonthWeek(date time.Time, weekStart time.Weekday) int {
+	first := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	offset := (int(first.Weekday()) - int(weekStart) + 7) % 7
+	return (date.Day()-1+offset)/7 + 1
+}
+
+// WeekNumber returns date's week under numbering (WeekNumberingISO unless
+// numbering is WeekNumberingMonth)
+func WeekNumber(date time.Time, numbering string, weekStart time.Weekday) int {
+	if numbering == WeekNumberingMonth {
+		return MonthWeek(date, weekStart)
+	}
+	_, week := date.ISOWeek()
+	return week
+}
+
+// WeekLabel renders a week number for charts and tables: "W5" for ISO weeks,
+// "Wk 2" for weeks of the month
+func WeekLabel(week int, numbering string) string {
+	if numbering == WeekNumberingMonth {
+		return fmt.Sprintf("Wk %d", week)
+	}
+	return fmt.Sprintf("W%d", week)
+}
@@ -27,7 +27,7 @@ func TestGetBreakMinutesForDay(t *testing.T) {
 			daysToAdd := (int(tt.weekday) - int(date.Weekday()) + 7) % 7
 			targetDate := date.AddDate(0, 0, daysToAdd)
 
-			result := GetBreakMinutesForDay(targetDate)
+			result := GetBreakMinutesForDay(Rules{}, targetDate)
 			if result != tt.expected {
 				t.Errorf("GetBreakMinutesForDay(%v) = %d, want %d", tt.weekday, result, tt.expected)
 			}
@@ -35,26 +35,76 @@ func TestGetBreakMinutesForDay(t *testing.T) {
 	}
 }
 
+func TestGetBreakMinutesForShiftFriday(t *testing.T) {
+	friday := time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)
+	short := friday.Add(3 * time.Hour)
+	long := friday.Add(8 * time.Hour)
+
+	tests := []struct {
+		name      string
+		end       time.Time
+		threshold float64
+		expected  int
+	}{
+		{"3h Friday, always exempt", short, 0, FridayBreakMinutes},
+		{"8h Friday, always exempt", long, 0, FridayBreakMinutes},
+		{"3h Friday under threshold", short, 6, FridayBreakMinutes},
+		{"8h Friday over threshold", long, 6, DefaultBreakMinutes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetBreakMinutesForShift(Rules{FridayShortShiftHours: tt.threshold}, friday, tt.end); got != tt.expected {
+				t.Errorf("GetBreakMinutesForShift = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+
+	monday := friday.AddDate(0, 0, 3)
+	if got := GetBreakMinutesForShift(Rules{FridayShortShiftHours: 6}, monday, monday.Add(3*time.Hour)); got != DefaultBreakMinutes {
+		t.Errorf("Monday shift = %d, want %d", got, DefaultBreakMinutes)
+	}
+}
+
 func TestIsWorkDay(t *testing.T) {
 	monday := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) // Monday Jan 1, 2024
 	friday := monday.AddDate(0, 0, 4)
 	saturday := monday.AddDate(0, 0, 5)
 	sunday := monday.AddDate(0, 0, 6)
 
-	if !IsWorkDay(monday) {
+	if !IsWorkDay(Rules{}, monday) {
 		t.Error("Monday should be a work day")
 	}
-	if !IsWorkDay(friday) {
+	if !IsWorkDay(Rules{}, friday) {
 		t.Error("Friday should be a work day")
 	}
-	if IsWorkDay(saturday) {
+	if IsWorkDay(Rules{}, saturday) {
 		t.Error("Saturday should not be a work day")
 	}
-	if IsWorkDay(sunday) {
+	if IsWorkDay(Rules{}, sunday) {
 		t.Error("Sunday should not be a work day")
 	}
 }
 
+func TestHolidays(t *testing.T) {
+	var rules Rules
+	rules.AddHoliday("2024-12-25")
+	christmas := time.Date(2024, 12, 25, 12, 0, 0, 0, time.UTC) // Wednesday
+
+	if IsWorkDay(rules, christmas) || !IsHoliday(rules, christmas) {
+		t.Error("Dec 25 should be a holiday, not a work day")
+	}
+	if !IsWorkDay(rules, christmas.AddDate(0, 0, 1)) {
+		t.Error("Dec 26 should still be a work day")
+	}
+	if got := RemainingWorkDaysInWeek(rules, christmas.AddDate(0, 0, -2)); got != 4 {
+		t.Errorf("remaining from Monday Dec 23 = %d, want 4", got)
+	}
+	if got, without := WorkDaysInMonth(rules, 2024, time.December), WorkDaysInMonth(Rules{}, 2024, time.December); got != without-1 {
+		t.Errorf("work days in December = %d, want %d", got, without-1)
+	}
+}
+
 func TestRemainingWorkDaysInWeek(t *testing.T) {
 	monday := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
 
@@ -77,7 +127,7 @@ func TestRemainingWorkDaysInWeek(t *testing.T) {
 			daysToAdd := (int(tt.weekday) - int(monday.Weekday()) + 7) % 7
 			targetDate := monday.AddDate(0, 0, daysToAdd)
 
-			result := RemainingWorkDaysInWeek(targetDate)
+			result := RemainingWorkDaysInWeek(Rules{}, targetDate)
 			if result != tt.expected {
 				t.Errorf("RemainingWorkDaysInWeek(%v) = %d, want %d", tt.weekday, result, tt.expected)
 			}
@@ -85,6 +135,136 @@ func TestRemainingWorkDaysInWeek(t *testing.T) {
 	}
 }
 
+func TestRoundHours(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		mode int
+		want float64
+	}{
+		{time.Hour + 7*time.Minute, 15, 1.0},
+		{time.Hour + 8*time.Minute, 15, 1.25},
+		{time.Hour + 3*time.Minute, 6, 1.1},
+		{time.Hour + 2*time.Minute, 6, 1.0},
+		{time.Hour + 30*time.Minute, 0, 1.5},
+	}
+	for _, tt := range tests {
+		if got := RoundHours(tt.d, tt.mode); got != tt.want {
+			t.Errorf("RoundHours(%s, %d) = %v, want %v", tt.d, tt.mode, got, tt.want)
+		}
+	}
+
+	// Float hours from SessionNetHours round the same way
+	if got := RoundSessionHours(1.05, 6); got != 1.1 {
+		t.Errorf("RoundSessionHours(1.05, 6) = %v, want 1.1", got)
+	}
+	if got := RoundSessionHours(1.1166, 0); got != 1.1166 {
+		t.Errorf("RoundSessionHours(1.1166, 0) = %v, want it unchanged", got)
+	}
+}
+
+func TestRemainingWorkDaysInWeekFrom(t *testing.T) {
+	monday := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	saturday := monday.AddDate(0, 0, 5)
+	sunday := monday.AddDate(0, 0, 6)
+
+	// A Sunday-start week ends on Saturday, so Sunday opens a new week
+	if got := RemainingWorkDaysInWeekFrom(Rules{}, sunday, time.Sunday); got != 5 {
+		t.Errorf("Sunday-start RemainingWorkDaysInWeekFrom(Sunday) = %d, want 5", got)
+	}
+	if got := RemainingWorkDaysInWeekFrom(Rules{}, saturday, time.Sunday); got != 0 {
+		t.Errorf("Sunday-start RemainingWorkDaysInWeekFrom(Saturday) = %d, want 0", got)
+	}
+	if got := RemainingWorkDaysInWeekFrom(Rules{}, sunday, time.Monday); got != 0 {
+		t.Errorf("Monday-start RemainingWorkDaysInWeekFrom(Sunday) = %d, want 0", got)
+	}
+}
+
+func TestCustomRules(t *testing.T) {
+	// A Sunday-Thursday week with a 45 minute break on Mondays and none on Thursdays
+	rules := Rules{
+		WorkDays:          []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday},
+		DailyBreakMinutes: map[time.Weekday]int{time.Monday: 45, time.Thursday: 0},
+	}
+	monday := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	friday := monday.AddDate(0, 0, 4)
+	sunday := monday.AddDate(0, 0, 6)
+
+	if got := GetBreakMinutesForDay(rules, monday); got != 45 {
+		t.Errorf("Monday break = %d, want 45", got)
+	}
+	if got := GetBreakMinutesForDay(rules, monday.AddDate(0, 0, 1)); got != DefaultBreakMinutes {
+		t.Errorf("Tuesday break = %d, want the default %d", got, DefaultBreakMinutes)
+	}
+	if IsWorkDay(rules, friday) || !IsWorkDay(rules, sunday) {
+		t.Error("want Friday off and Sunday worked")
+	}
+	if got := RemainingWorkDaysInWeek(rules, friday); got != 1 {
+		t.Errorf("RemainingWorkDaysInWeek(Friday) = %d, want 1 (Sunday)", got)
+	}
+	if got := WorkDaysInMonth(rules, 2024, time.January); got != 23 {
+		t.Errorf("WorkDaysInMonth = %d, want 23", got)
+	}
+	if got := rules.DaysPerWeek(); got != 5 {
+		t.Errorf("DaysPerWeek = %d, want 5", got)
+	}
+	if got := rules.BreakSummary(); got != "30min (Mon: 45min, Thu: 0min)" {
+		t.Errorf("BreakSummary = %q", got)
+	}
+	if got := (Rules{}).BreakSummary(); got != "30min (Fri: 0min)" {
+		t.Errorf("default BreakSummary = %q", got)
+	}
+}
+
+func TestParseWeekday(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Weekday
+		ok   bool
+	}{
+		{"monday", time.Monday, true},
+		{"Fri", time.Friday, true},
+		{"0", time.Sunday, true},
+		{"6", time.Saturday, true},
+		{"7", 0, false},
+		{"mo", 0, false},
+		{"holiday", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseWeekday(tt.in)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("ParseWeekday(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRoundUpToQuarterHour(t *testing.T) {
+	at := func(h, m, sec int) time.Time { return time.Date(2024, 1, 15, h, m, sec, 0, time.UTC) }
+	tests := []struct {
+		name string
+		in   time.Time
+		want time.Time
+	}{
+		{"14:07", at(14, 7, 0), at(14, 15, 0)},
+		{"on the quarter", at(14, 15, 0), at(14, 15, 0)},
+		{"seconds past", at(14, 15, 1), at(14, 30, 0)},
+		{"last quarter", at(14, 46, 0), at(15, 0, 0)},
+		{"midnight", at(23, 53, 0), time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RoundUpToQuarterHour(tt.in); !got.Equal(tt.want) {
+				t.Errorf("RoundUpToQuarterHour(%s) = %s, want %s", tt.in.Format("15:04:05"), got.Format("15:04:05"), tt.want.Format("15:04:05"))
+			}
+		})
+	}
+
+	// Zones with a 45 minute offset still land on a wall-clock quarter
+	kathmandu := time.FixedZone("NPT", 5*3600+45*60)
+	if got := RoundUpToQuarterHour(time.Date(2024, 1, 15, 14, 7, 0, 0, kathmandu)); got.Format("15:04") != "14:15" {
+		t.Errorf("+05:45 zone = %s, want 14:15", got.Format("15:04"))
+	}
+}
+
 func TestCalculateRequiredDailyHours(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -116,3 +296,74 @@ func TestConstants(t *testing.T) {
 		t.Errorf("DailyTargetHours = %f, expected %f", DailyTargetHours, expectedDailyTarget)
 	}
 }
+
+func TestFormatHours(t *testing.T) {
+	tests := []struct {
+		hours  float64
+		places int
+		want   string
+	}{
+		{7.5, 2, "7.50"},
+		{7.5, 1, "7.5"},
+		{7.456, 3, "7.456"},
+		{7.6, 0, "8"},
+		{7.5, -1, "7.50"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatHours(tt.hours, tt.places); got != tt.want {
+			t.Errorf("FormatHours(%v, %d) = %q, want %q", tt.hours, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestFormatHoursHM(t *testing.T) {
+	tests := []struct {
+		hours float64
+		want  string
+	}{
+		{7.13, "7h 8m"},
+		{0.5, "0h 30m"},
+		{8, "8h 0m"},
+		{1.999, "2h 0m"},
+		{-1.25, "-1h 15m"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatHoursHM(tt.hours); got != tt.want {
+			t.Errorf("FormatHoursHM(%v) = %q, want %q", tt.hours, got, tt.want)
+		}
+	}
+}
+
+func TestWeekNumberAcrossYearBoundary(t *testing.T) {
+	// Jan 1, 2027 is a Friday, so ISO puts Jan 1-3 in week 53 of 2026
+	tests := []struct {
+		day   int
+		iso   int
+		month int
+	}{
+		{1, 53, 1},
+		{3, 53, 1},
+		{4, 1, 2},
+		{11, 2, 3},
+		{31, 4, 5},
+	}
+
+	for _, tt := range tests {
+		date := time.Date(2027, time.January, tt.day, 12, 0, 0, 0, time.UTC)
+		if got := WeekNumber(date, WeekNumberingISO, time.Monday); got != tt.iso {
+			t.Errorf("Jan %d ISO week = %d, want %d", tt.day, got, tt.iso)
+		}
+		if got := WeekNumber(date, WeekNumberingMonth, time.Monday); got != tt.month {
+			t.Errorf("Jan %d month week = %d, want %d", tt.day, got, tt.month)
+		}
+	}
+
+	if got := WeekLabel(53, WeekNumberingISO); got != "W53" {
+		t.Errorf("ISO label = %q, want W53", got)
+	}
+	if got := WeekLabel(1, WeekNumberingMonth); got != "Wk 1" {
+		t.Errorf("month label = %q, want Wk 1", got)
+	}
+}